@@ -3,12 +3,20 @@ package chain
 import (
 	"errors"
 	"fmt"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 )
 
+// IsContractAccount checks the builtin contracts in use at the latest snapshot height
 func (c *chain) IsContractAccount(address types.Address) (bool, error) {
-	if ok := types.IsBuiltinContractAddrInUse(address); ok {
+	return c.IsContractAccountInSnapshot(address, c.latestSnapshotHeight())
+}
+
+// IsContractAccountInSnapshot checks the builtin contracts in use at snapshotHeight, which should be the snapshot height
+// the block is verified or executed with
+func (c *chain) IsContractAccountInSnapshot(address types.Address, snapshotHeight uint64) (bool, error) {
+	if ok := fork.IsBuiltinContractAddrInUse(address, snapshotHeight); ok {
 		return ok, nil
 	}
 
@@ -16,7 +24,7 @@ func (c *chain) IsContractAccount(address types.Address) (bool, error) {
 
 	if err != nil {
 		cErr := errors.New(fmt.Sprintf("c.stateDB.HasContractMeta failed, error is %s, address is %s", err.Error(), address))
-		c.log.Error(cErr.Error(), "method", "IsContractAccount")
+		c.log.Error(cErr.Error(), "method", "IsContractAccountInSnapshot")
 		return false, cErr
 	}
 
@@ -0,0 +1,296 @@
+package chain_archive
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"golang.org/x/crypto/blake2b"
+)
+
+const (
+	// Version is the version of the archive format
+	Version = uint32(1)
+
+	manifestName = "manifest.json"
+
+	// every file of the ledger directory is a segment of the archive and is stored under segmentDir
+	segmentDir = "ledger"
+)
+
+// Manifest describes an archive of the ledger directory at a snapshot height, it's the first entry of the archive.
+type Manifest struct {
+	Version uint32 `json:"version"`
+
+	GenesisHash    types.Hash `json:"genesisHash"`
+	SnapshotHeight uint64     `json:"snapshotHeight"`
+	SnapshotHash   types.Hash `json:"snapshotHash"`
+
+	CreateTime int64 `json:"createTime"`
+
+	Segments   []*Segment                   `json:"segments"`
+	BlockFiles []*ledger.CompressedFileMeta `json:"blockFiles"`
+}
+
+// Segment is a file of the ledger directory, Name is the path relative to the ledger directory.
+type Segment struct {
+	Name string     `json:"name"`
+	Size int64      `json:"size"`
+	Hash types.Hash `json:"hash"`
+}
+
+// Write writes the files of ledgerDir into a gzipped tar archive, the segments of the manifest are filled.
+func Write(ledgerDir string, manifest *Manifest, archivePath string) error {
+	manifest.Version = Version
+
+	segments, err := hashSegments(ledgerDir)
+	if err != nil {
+		return err
+	}
+	manifest.Segments = segments
+
+	for _, meta := range manifest.BlockFiles {
+		for _, segment := range segments {
+			if segment.Name == meta.Filename {
+				meta.FileSize = segment.Size
+				break
+			}
+		}
+	}
+
+	return writeArchive(ledgerDir, manifest, archivePath)
+}
+
+func writeArchive(ledgerDir string, manifest *Manifest, archivePath string) error {
+	manifestBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	file, err := os.Create(archivePath)
+	if err != nil {
+		return errors.New(fmt.Sprintf("os.Create failed, path is %s. Error: %s", archivePath, err))
+	}
+	defer file.Close()
+
+	gw := gzip.NewWriter(file)
+	tw := tar.NewWriter(gw)
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: manifestName,
+		Mode: 0644,
+		Size: int64(len(manifestBytes)),
+	}); err != nil {
+		return err
+	}
+	if _, err := tw.Write(manifestBytes); err != nil {
+		return err
+	}
+
+	for _, segment := range manifest.Segments {
+		if err := writeSegment(tw, ledgerDir, segment); err != nil {
+			return err
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if err := gw.Close(); err != nil {
+		return err
+	}
+	return file.Sync()
+}
+
+// Extract extracts the archive into ledgerDir, the version of the archive and the size and hash of every segment are verified.
+func Extract(archivePath string, ledgerDir string) (*Manifest, error) {
+	file, err := os.Open(archivePath)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("os.Open failed, path is %s. Error: %s", archivePath, err))
+	}
+	defer file.Close()
+
+	gr, err := gzip.NewReader(file)
+	if err != nil {
+		return nil, err
+	}
+	defer gr.Close()
+
+	tr := tar.NewReader(gr)
+
+	// manifest
+	header, err := tr.Next()
+	if err != nil {
+		return nil, err
+	}
+	if header.Name != manifestName {
+		return nil, errors.New(fmt.Sprintf("the first entry of the archive is %s, not the manifest", header.Name))
+	}
+	manifestBytes, err := ioutil.ReadAll(tr)
+	if err != nil {
+		return nil, err
+	}
+	manifest := &Manifest{}
+	if err := json.Unmarshal(manifestBytes, manifest); err != nil {
+		return nil, errors.New(fmt.Sprintf("json.Unmarshal manifest failed. Error: %s", err))
+	}
+	if manifest.Version != Version {
+		return nil, errors.New(fmt.Sprintf("archive version %d is not supported, the supported version is %d", manifest.Version, Version))
+	}
+
+	segmentMap := make(map[string]*Segment, len(manifest.Segments))
+	for _, segment := range manifest.Segments {
+		segmentMap[segment.Name] = segment
+	}
+
+	// segments
+	extracted := make(map[string]struct{}, len(manifest.Segments))
+	for {
+		header, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimPrefix(header.Name, segmentDir+"/")
+		segment, ok := segmentMap[name]
+		if !ok {
+			return nil, errors.New(fmt.Sprintf("entry %s is not in the manifest", header.Name))
+		}
+		if _, ok := extracted[name]; ok {
+			return nil, errors.New(fmt.Sprintf("entry %s is duplicated", header.Name))
+		}
+
+		if err := extractSegment(tr, ledgerDir, segment); err != nil {
+			return nil, err
+		}
+		extracted[name] = struct{}{}
+	}
+
+	if len(extracted) != len(segmentMap) {
+		return nil, errors.New(fmt.Sprintf("%d segments are missing in the archive", len(segmentMap)-len(extracted)))
+	}
+	return manifest, nil
+}
+
+func hashSegments(ledgerDir string) ([]*Segment, error) {
+	var segments []*Segment
+	err := filepath.Walk(ledgerDir, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+
+		name, err := filepath.Rel(ledgerDir, filePath)
+		if err != nil {
+			return err
+		}
+
+		file, err := os.Open(filePath)
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+
+		hash, size, err := hashReader(file)
+		if err != nil {
+			return errors.New(fmt.Sprintf("hash %s failed. Error: %s", filePath, err))
+		}
+
+		segments = append(segments, &Segment{
+			Name: filepath.ToSlash(name),
+			Size: size,
+			Hash: hash,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(segments, func(i, j int) bool {
+		return segments[i].Name < segments[j].Name
+	})
+	return segments, nil
+}
+
+func writeSegment(tw *tar.Writer, ledgerDir string, segment *Segment) error {
+	file, err := os.Open(filepath.Join(ledgerDir, filepath.FromSlash(segment.Name)))
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	if err := tw.WriteHeader(&tar.Header{
+		Name: path.Join(segmentDir, segment.Name),
+		Mode: 0644,
+		Size: segment.Size,
+	}); err != nil {
+		return err
+	}
+
+	// the ledger must not be modified while writing
+	if _, err := io.CopyN(tw, file, segment.Size); err != nil {
+		return errors.New(fmt.Sprintf("write segment %s failed. Error: %s", segment.Name, err))
+	}
+	return nil
+}
+
+func extractSegment(r io.Reader, ledgerDir string, segment *Segment) error {
+	name := filepath.FromSlash(segment.Name)
+	if filepath.IsAbs(name) || strings.HasPrefix(filepath.Clean(name), "..") {
+		return errors.New(fmt.Sprintf("segment name %s is illegal", segment.Name))
+	}
+
+	filePath := filepath.Join(ledgerDir, name)
+	if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.Create(filePath)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash, size, err := hashReader(io.TeeReader(r, file))
+	if err != nil {
+		return errors.New(fmt.Sprintf("extract segment %s failed. Error: %s", segment.Name, err))
+	}
+	if size != segment.Size {
+		return errors.New(fmt.Sprintf("the size of segment %s is %d, but it is %d in the manifest", segment.Name, size, segment.Size))
+	}
+	if hash != segment.Hash {
+		return errors.New(fmt.Sprintf("the hash of segment %s is %s, but it is %s in the manifest", segment.Name, hash, segment.Hash))
+	}
+	return file.Sync()
+}
+
+func hashReader(r io.Reader) (types.Hash, int64, error) {
+	h, _ := blake2b.New256(nil)
+	size, err := io.Copy(h, r)
+	if err != nil {
+		return types.Hash{}, 0, err
+	}
+
+	hash, err := types.BytesToHash(h.Sum(nil))
+	if err != nil {
+		return types.Hash{}, 0, err
+	}
+	return hash, size, nil
+}
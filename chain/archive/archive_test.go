@@ -0,0 +1,88 @@
+package chain_archive
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func writeTestLedger(t *testing.T, dir string) map[string]string {
+	files := map[string]string{
+		"blocks/f1":        "snapshot blocks",
+		"index/000001.ldb": "index",
+		"state/CURRENT":    "MANIFEST-000001",
+	}
+	for name, content := range files {
+		filePath := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(filePath), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(filePath, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return files
+}
+
+func TestWriteAndExtract(t *testing.T) {
+	dir, err := ioutil.TempDir("", "archive")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	files := writeTestLedger(t, filepath.Join(dir, "ledger"))
+
+	manifest := &Manifest{
+		SnapshotHeight: 10,
+		SnapshotHash:   types.DataHash([]byte{10}),
+		BlockFiles: []*ledger.CompressedFileMeta{{
+			StartHeight:  1,
+			EndHeight:    10,
+			Filename:     "blocks/f1",
+			BlockNumbers: 10,
+		}},
+	}
+	archivePath := filepath.Join(dir, "ledger.tar.gz")
+	if err := Write(filepath.Join(dir, "ledger"), manifest, archivePath); err != nil {
+		t.Fatal(err)
+	}
+	if len(manifest.Segments) != len(files) {
+		t.Fatalf("segment count is %d, expected %d", len(manifest.Segments), len(files))
+	}
+	if manifest.BlockFiles[0].FileSize != int64(len(files["blocks/f1"])) {
+		t.Fatalf("file size is %d", manifest.BlockFiles[0].FileSize)
+	}
+
+	extracted, err := Extract(archivePath, filepath.Join(dir, "extracted"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if extracted.SnapshotHeight != manifest.SnapshotHeight || extracted.SnapshotHash != manifest.SnapshotHash {
+		t.Fatalf("manifest is %+v, expected %+v", extracted, manifest)
+	}
+	for name, content := range files {
+		data, err := ioutil.ReadFile(filepath.Join(dir, "extracted", filepath.FromSlash(name)))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != content {
+			t.Fatalf("%s is %s, expected %s", name, data, content)
+		}
+	}
+
+	// a segment modified after the manifest is created
+	if err := ioutil.WriteFile(filepath.Join(dir, "ledger", "blocks", "f1"), []byte("tampered blocks"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := writeArchive(filepath.Join(dir, "ledger"), manifest, archivePath); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Extract(archivePath, filepath.Join(dir, "tampered")); err == nil {
+		t.Fatal("the tampered segment is extracted")
+	}
+}
@@ -0,0 +1,116 @@
+package chain_archive
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/file_manager"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const verifyBatchCount = 1000
+
+type Chain interface {
+	GetGenesisSnapshotBlock() *ledger.SnapshotBlock
+
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+
+	GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error)
+}
+
+type locationReader interface {
+	GetSnapshotBlockLocation(height uint64) (*chain_file_manager.Location, error)
+}
+
+// VerifySnapshotChain verifies that the snapshot chain of c links the genesis snapshot block to the snapshot block of the manifest,
+// the hash and the signature of every snapshot block are verified. The manifest is written by whoever made the archive,
+// so the snapshot block of the manifest must be trustedHash, which is got from a trusted source.
+// The fork points must be set before verifying.
+func VerifySnapshotChain(c Chain, manifest *Manifest, trustedHash types.Hash) error {
+	if manifest.SnapshotHash != trustedHash {
+		return errors.New(fmt.Sprintf("the snapshot block of the archive is %d %s, not the trusted %s",
+			manifest.SnapshotHeight, manifest.SnapshotHash, trustedHash))
+	}
+
+	genesis := c.GetGenesisSnapshotBlock()
+	if genesis.Hash != manifest.GenesisHash {
+		return errors.New(fmt.Sprintf("the genesis snapshot block is %s, but it is %s in the manifest", genesis.Hash, manifest.GenesisHash))
+	}
+
+	latest := c.GetLatestSnapshotBlock()
+	if latest.Height != manifest.SnapshotHeight || latest.Hash != manifest.SnapshotHash {
+		return errors.New(fmt.Sprintf("the latest snapshot block is %d %s, but it is %d %s in the manifest",
+			latest.Height, latest.Hash, manifest.SnapshotHeight, manifest.SnapshotHash))
+	}
+
+	prev := genesis
+	for height := genesis.Height + 1; height <= latest.Height; height += verifyBatchCount {
+		// the snapshot content is needed to compute the hash
+		blocks, err := c.GetSnapshotBlocksByHeight(height, true, verifyBatchCount)
+		if err != nil {
+			return errors.New(fmt.Sprintf("c.GetSnapshotBlocksByHeight failed, height is %d. Error: %s", height, err))
+		}
+
+		for _, header := range blocks {
+			if header.Height != prev.Height+1 || header.PrevHash != prev.Hash {
+				return errors.New(fmt.Sprintf("snapshot block %d %s is not linked to snapshot block %d %s",
+					header.Height, header.Hash, prev.Height, prev.Hash))
+			}
+			if header.ComputeHash() != header.Hash {
+				return errors.New(fmt.Sprintf("the hash of snapshot block %d is %s, but it is computed as %s", header.Height, header.Hash, header.ComputeHash()))
+			}
+			if !header.VerifySignature() {
+				return errors.New(fmt.Sprintf("the signature of snapshot block %d %s is invalid", header.Height, header.Hash))
+			}
+			prev = header
+		}
+
+		if len(blocks) <= 0 {
+			break
+		}
+	}
+
+	if prev.Hash != latest.Hash {
+		return errors.New(fmt.Sprintf("the snapshot chain ends at %d %s, not the latest snapshot block", prev.Height, prev.Hash))
+	}
+	return nil
+}
+
+// BlockFileMetas returns the meta of the block files which contain the snapshot blocks lower than or equal to snapshotHeight,
+// StartHeight and EndHeight are the heights of the first and the last snapshot block in the file.
+func BlockFileMetas(reader locationReader, snapshotHeight uint64) ([]*ledger.CompressedFileMeta, error) {
+	metaMap := make(map[uint64]*ledger.CompressedFileMeta)
+	for height := uint64(1); height <= snapshotHeight; height++ {
+		location, err := reader.GetSnapshotBlockLocation(height)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("GetSnapshotBlockLocation failed, height is %d. Error: %s", height, err))
+		}
+		if location == nil {
+			return nil, errors.New(fmt.Sprintf("the location of snapshot block %d is not exist", height))
+		}
+
+		meta, ok := metaMap[location.FileId]
+		if !ok {
+			meta = &ledger.CompressedFileMeta{
+				StartHeight: height,
+				Filename:    path.Join("blocks", "f"+strconv.FormatUint(location.FileId, 10)),
+			}
+			metaMap[location.FileId] = meta
+		}
+		meta.EndHeight = height
+		meta.BlockNumbers++
+	}
+
+	metas := make([]*ledger.CompressedFileMeta, 0, len(metaMap))
+	for _, meta := range metaMap {
+		metas = append(metas, meta)
+	}
+	sort.Slice(metas, func(i, j int) bool {
+		return metas[i].StartHeight < metas[j].StartHeight
+	})
+	return metas, nil
+}
@@ -0,0 +1,48 @@
+package chain_archive
+
+import (
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type mockChain struct {
+	genesis *ledger.SnapshotBlock
+	latest  *ledger.SnapshotBlock
+}
+
+func (c *mockChain) GetGenesisSnapshotBlock() *ledger.SnapshotBlock {
+	return c.genesis
+}
+
+func (c *mockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	return c.latest
+}
+
+func (c *mockChain) GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error) {
+	return nil, nil
+}
+
+func TestVerifySnapshotChain_TrustedHash(t *testing.T) {
+	c := &mockChain{
+		genesis: &ledger.SnapshotBlock{Height: 1, Hash: types.DataHash([]byte{1})},
+		latest:  &ledger.SnapshotBlock{Height: 10, Hash: types.DataHash([]byte{10})},
+	}
+	manifest := &Manifest{
+		GenesisHash:    c.genesis.Hash,
+		SnapshotHeight: c.latest.Height,
+		SnapshotHash:   c.latest.Hash,
+	}
+
+	// the manifest is consistent with the ledger, but it's not the trusted one
+	if err := VerifySnapshotChain(c, manifest, types.DataHash([]byte{11})); err == nil {
+		t.Fatal("the archive which is not the trusted one should be rejected")
+	}
+
+	// the ledger is different from the manifest
+	c.latest = &ledger.SnapshotBlock{Height: 10, Hash: types.DataHash([]byte{12})}
+	if err := VerifySnapshotChain(c, manifest, manifest.SnapshotHash); err == nil {
+		t.Fatal("the ledger which is different from the manifest should be rejected")
+	}
+}
@@ -6,6 +6,7 @@ import (
 	"github.com/golang/snappy"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain/file_manager"
+	"github.com/vitelabs/go-vite/common/db/xleveldb"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/crypto"
 	"github.com/vitelabs/go-vite/interfaces"
@@ -29,6 +30,11 @@ type BlockDB struct {
 	flushTargetLocation *chain_file_manager.Location
 	flushBuf            *BufWriter
 
+	prunedDir  string
+	prunedMu   sync.RWMutex
+	prunedDB   *leveldb.DB
+	prunedMeta *pruneMeta
+
 	log log15.Logger
 }
 
@@ -41,13 +47,20 @@ func NewBlockDB(chainDir string) (*BlockDB, error) {
 		return nil, err
 	}
 
-	return &BlockDB{
+	bDB := &BlockDB{
 		fm:                fm,
 		fileSize:          fileSize,
 		snappyWriteBuffer: make([]byte, fileSize),
 		id:                id,
+		prunedDir:         prunedDir(chainDir),
 		log:               log15.New("module", "blockDB"),
-	}, nil
+	}
+
+	if err := bDB.loadPruned(); err != nil {
+		fm.Close()
+		return nil, errors.New(fmt.Sprintf("bDB.loadPruned failed. Error: %s", err))
+	}
+	return bDB, nil
 }
 
 func (bDB *BlockDB) FileSize() int64 {
@@ -55,6 +68,10 @@ func (bDB *BlockDB) FileSize() int64 {
 }
 
 func (bDB *BlockDB) Close() error {
+	if err := bDB.closePruned(); err != nil {
+		return errors.New(fmt.Sprintf("bDB.closePruned failed, error is %s", err))
+	}
+
 	if err := bDB.fm.Close(); err != nil {
 		return errors.New(fmt.Sprintf("bDB.fm.Close failed, error is %s", err))
 	}
@@ -96,10 +113,16 @@ func (bDB *BlockDB) Write(ss *ledger.SnapshotChunk) ([]*chain_file_manager.Locat
 }
 
 func (bDB *BlockDB) Read(location *chain_file_manager.Location) ([]byte, error) {
-	buf, _, err := bDB.fm.Read(location)
+	ok, buf, err := bDB.readPruned(location)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		buf, _, err = bDB.fm.Read(location)
+		if err != nil {
+			return nil, err
+		}
+	}
 	if len(buf) <= 0 {
 		return nil, nil
 	}
@@ -112,10 +135,17 @@ func (bDB *BlockDB) Read(location *chain_file_manager.Location) ([]byte, error)
 }
 
 func (bDB *BlockDB) ReadRaw(startLocation *chain_file_manager.Location, buf []byte) (*chain_file_manager.Location, int, error) {
+	if bDB.isPruned(startLocation) {
+		return nil, 0, ErrPruned
+	}
 	return bDB.fm.ReadRaw(startLocation, buf)
 }
 
 func (bDB *BlockDB) ReadRange(startLocation *chain_file_manager.Location, endLocation *chain_file_manager.Location) ([]*ledger.SnapshotChunk, error) {
+	if bDB.isPruned(startLocation) {
+		return nil, ErrPruned
+	}
+
 	bfp := newBlockFileParser()
 
 	endLocation = bDB.maxLocation(endLocation)
@@ -200,6 +230,10 @@ func (bDB *BlockDB) GetNextLocation(location *chain_file_manager.Location) (*cha
 }
 
 func (bDB *BlockDB) PrepareRollback(location *chain_file_manager.Location) ([]*ledger.SnapshotChunk, error) {
+	if bDB.isPruned(location) {
+		return nil, ErrPruned
+	}
+
 	bfp := newBlockFileParser()
 
 	bDB.wg.Add(1)
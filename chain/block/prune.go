@@ -0,0 +1,294 @@
+package chain_block
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/file_manager"
+	"github.com/vitelabs/go-vite/chain/utils"
+	"github.com/vitelabs/go-vite/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/common/db/xleveldb/opt"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+var ErrPruned = errors.New("the block has been pruned")
+
+const (
+	prunedDirName = "pruned_blocks"
+
+	// location -> record, the records of the pruned files which are retained
+	prunedRecordKeyPrefix = byte(1)
+	prunedMetaKey         = byte(2)
+)
+
+// the first file holds the genesis snapshot block and is never removed
+const genesisFileId = 1
+
+// KeepFunc decides whether an account block in a pruned file is retained
+type KeepFunc func(block *ledger.AccountBlock) (bool, error)
+
+type pruneMeta struct {
+	// the files with id in (genesisFileId, fileId] are pruned
+	fileId uint64
+	// the highest snapshot height in the pruned files
+	snapshotHeight uint64
+	// the location of the next record to prune
+	nextLocation *chain_file_manager.Location
+}
+
+func (meta *pruneMeta) serialize() []byte {
+	buf := make([]byte, 0, 16+chain_file_manager.LocationSize)
+	buf = append(buf, chain_utils.Uint64ToBytes(meta.fileId)...)
+	buf = append(buf, chain_utils.Uint64ToBytes(meta.snapshotHeight)...)
+	buf = append(buf, chain_utils.SerializeLocation(meta.nextLocation)...)
+	return buf
+}
+
+func (meta *pruneMeta) deserialize(buf []byte) error {
+	if len(buf) != 16+chain_file_manager.LocationSize {
+		return errors.New(fmt.Sprintf("the length of prune meta is %d", len(buf)))
+	}
+	meta.fileId = chain_utils.BytesToUint64(buf[:8])
+	meta.snapshotHeight = chain_utils.BytesToUint64(buf[8:16])
+	meta.nextLocation = chain_utils.DeserializeLocation(buf[16:])
+	return nil
+}
+
+func createPrunedRecordKey(location *chain_file_manager.Location) []byte {
+	return append([]byte{prunedRecordKeyPrefix}, chain_utils.SerializeLocation(location)...)
+}
+
+// loadPruned opens the store of the pruned records if the ledger has been pruned
+func (bDB *BlockDB) loadPruned() error {
+	if _, err := os.Stat(bDB.prunedDir); err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	return bDB.openPruned()
+}
+
+func (bDB *BlockDB) openPruned() error {
+	db, err := leveldb.OpenFile(bDB.prunedDir, nil)
+	if err != nil {
+		return errors.New(fmt.Sprintf("leveldb.OpenFile failed, dir is %s. Error: %s", bDB.prunedDir, err))
+	}
+
+	meta := &pruneMeta{
+		nextLocation: chain_file_manager.NewLocation(genesisFileId, 0),
+	}
+	value, err := db.Get([]byte{prunedMetaKey}, nil)
+	if err != nil && err != leveldb.ErrNotFound {
+		db.Close()
+		return err
+	}
+	if len(value) > 0 {
+		if err := meta.deserialize(value); err != nil {
+			db.Close()
+			return err
+		}
+	}
+
+	bDB.prunedMu.Lock()
+	bDB.prunedDB = db
+	bDB.prunedMeta = meta
+	bDB.prunedMu.Unlock()
+
+	// the files may not be removed if the node is shut down while pruning
+	for fileId := uint64(genesisFileId + 1); fileId <= meta.fileId; fileId++ {
+		if err := bDB.fm.RemoveFile(fileId); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (bDB *BlockDB) closePruned() error {
+	bDB.prunedMu.Lock()
+	defer bDB.prunedMu.Unlock()
+
+	if bDB.prunedDB == nil {
+		return nil
+	}
+	err := bDB.prunedDB.Close()
+	bDB.prunedDB = nil
+	return err
+}
+
+// PrunedSnapshotHeight returns the highest snapshot height in the pruned files, the account blocks confirmed
+// by the snapshot blocks not higher than it may have been pruned. Returns 0 if the ledger is not pruned.
+func (bDB *BlockDB) PrunedSnapshotHeight() uint64 {
+	bDB.prunedMu.RLock()
+	defer bDB.prunedMu.RUnlock()
+
+	if bDB.prunedMeta == nil {
+		return 0
+	}
+	return bDB.prunedMeta.snapshotHeight
+}
+
+// isPruned returns true if the records of the range may be pruned
+func (bDB *BlockDB) isPruned(location *chain_file_manager.Location) bool {
+	bDB.prunedMu.RLock()
+	defer bDB.prunedMu.RUnlock()
+
+	return bDB.prunedMeta != nil && location.FileId <= bDB.prunedMeta.fileId
+}
+
+// readPruned reads the record of location from the retained records, returns false if the file of location is not pruned
+func (bDB *BlockDB) readPruned(location *chain_file_manager.Location) (bool, []byte, error) {
+	bDB.prunedMu.RLock()
+	defer bDB.prunedMu.RUnlock()
+
+	if bDB.prunedMeta == nil || location.FileId > bDB.prunedMeta.fileId {
+		return false, nil, nil
+	}
+
+	value, err := bDB.prunedDB.Get(createPrunedRecordKey(location), nil)
+	if err != nil {
+		if err != leveldb.ErrNotFound {
+			return true, nil, err
+		}
+		// the genesis file is not removed
+		if location.FileId == genesisFileId {
+			return false, nil, nil
+		}
+		return true, nil, ErrPruned
+	}
+	return true, value, nil
+}
+
+// Prune removes the files lower than toFileId, at most maxFiles files are removed. The snapshot blocks and the
+// account blocks kept by keep are retained, the records of the genesis file are retained only if they span into
+// a removed file.
+func (bDB *BlockDB) Prune(toFileId uint64, maxFiles uint64, keep KeepFunc) (uint64, error) {
+	if bDB.prunedDB == nil {
+		if err := os.MkdirAll(bDB.prunedDir, 0700); err != nil {
+			return 0, err
+		}
+		if err := bDB.openPruned(); err != nil {
+			return 0, err
+		}
+	}
+
+	bDB.prunedMu.RLock()
+	meta := *bDB.prunedMeta
+	bDB.prunedMu.RUnlock()
+
+	// the files to flush must not be removed
+	flushLocation := bDB.fm.NextFlushStartLocation()
+	if flushLocation == nil {
+		return 0, nil
+	}
+	if toFileId > flushLocation.FileId {
+		toFileId = flushLocation.FileId
+	}
+	if toFileId > meta.fileId+1+maxFiles {
+		toFileId = meta.fileId + 1 + maxFiles
+	}
+	if toFileId <= genesisFileId+1 || toFileId <= meta.fileId+1 {
+		return 0, nil
+	}
+
+	batch := new(leveldb.Batch)
+	location := meta.nextLocation
+	// the account blocks after the last snapshot block are confirmed by the next snapshot block
+	hasUnconfirmed := false
+	for location.FileId < toFileId {
+		buf, nextLocation, err := bDB.fm.Read(location)
+		if err != nil {
+			if err == io.EOF {
+				break
+			}
+			return 0, errors.New(fmt.Sprintf("bDB.fm.Read failed, location is %+v. Error: %s", location, err))
+		}
+		if len(buf) <= 0 {
+			break
+		}
+
+		retain, snapshotHeight, err := bDB.retainRecord(location, nextLocation, buf, keep)
+		if err != nil {
+			return 0, err
+		}
+		if retain {
+			batch.Put(createPrunedRecordKey(location), buf)
+		}
+		if snapshotHeight > 0 {
+			if snapshotHeight > meta.snapshotHeight {
+				meta.snapshotHeight = snapshotHeight
+			}
+			hasUnconfirmed = false
+		} else {
+			hasUnconfirmed = true
+		}
+
+		location = nextLocation
+	}
+
+	if hasUnconfirmed {
+		meta.snapshotHeight++
+	}
+
+	fromFileId := meta.fileId + 1
+	if fromFileId <= genesisFileId {
+		fromFileId = genesisFileId + 1
+	}
+	meta.fileId = toFileId - 1
+	meta.nextLocation = location
+	batch.Put([]byte{prunedMetaKey}, meta.serialize())
+
+	if err := bDB.prunedDB.Write(batch, &opt.WriteOptions{Sync: true}); err != nil {
+		return 0, errors.New(fmt.Sprintf("bDB.prunedDB.Write failed. Error: %s", err))
+	}
+
+	bDB.prunedMu.Lock()
+	bDB.prunedMeta = &meta
+	bDB.prunedMu.Unlock()
+
+	for fileId := fromFileId; fileId <= meta.fileId; fileId++ {
+		if err := bDB.fm.RemoveFile(fileId); err != nil {
+			return 0, err
+		}
+	}
+	return meta.fileId - fromFileId + 1, nil
+}
+
+func (bDB *BlockDB) retainRecord(location, nextLocation *chain_file_manager.Location, buf []byte, keep KeepFunc) (bool, uint64, error) {
+	sBuf, err := snappy.Decode(nil, buf[1:])
+	if err != nil {
+		return false, 0, err
+	}
+
+	switch buf[0] {
+	case BlockTypeSnapshotBlock:
+		sb := &ledger.SnapshotBlock{}
+		if err := sb.Deserialize(sBuf); err != nil {
+			return false, 0, err
+		}
+		return true, sb.Height, nil
+
+	case BlockTypeAccountBlock:
+		if location.FileId == genesisFileId {
+			// the record is read from the removed file if it spans into it
+			return nextLocation.FileId > genesisFileId+1 ||
+				(nextLocation.FileId == genesisFileId+1 && nextLocation.Offset > 0), 0, nil
+		}
+
+		ab := &ledger.AccountBlock{}
+		if err := ab.Deserialize(sBuf); err != nil {
+			return false, 0, err
+		}
+		retain, err := keep(ab)
+		return retain, 0, err
+	}
+	return false, 0, errors.New(fmt.Sprintf("unknown block type %d, location is %+v", buf[0], location))
+}
+
+func prunedDir(chainDir string) string {
+	return path.Join(chainDir, prunedDirName)
+}
@@ -68,6 +68,9 @@ type chain struct {
 
 	plugins *chain_plugins.Plugins
 
+	pruneTerminal chan struct{}
+	pruneWg       sync.WaitGroup
+
 	status uint32
 }
 
@@ -151,6 +154,11 @@ func (c *chain) Start() error {
 	c.flusher.Start()
 	c.log.Info("Start flusher", "method", "Start")
 
+	if c.chainCfg.LedgerPrune {
+		c.startPrune()
+		c.log.Info("Start pruning", "method", "Start")
+	}
+
 	return nil
 }
 
@@ -159,6 +167,11 @@ func (c *chain) Stop() error {
 		return nil
 	}
 
+	if c.chainCfg.LedgerPrune {
+		c.stopPrune()
+		c.log.Info("Stop pruning", "method", "Stop")
+	}
+
 	c.flusher.Stop()
 
 	c.log.Info("Stop flusher", "method", "Stop")
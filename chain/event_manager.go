@@ -139,8 +139,8 @@ func (em *eventManager) UnRegister(listener EventListener) {
 	em.mu.Lock()
 	defer em.mu.Unlock()
 
-	for index, listener := range em.listenerList {
-		if listener == listener {
+	for index, item := range em.listenerList {
+		if item == listener {
 			em.listenerList = append(em.listenerList[:index], em.listenerList[index+1:]...)
 			break
 		}
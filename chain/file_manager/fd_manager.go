@@ -202,6 +202,20 @@ func (fdSet *fdManager) DiskDelete(highLocation *Location, lowLocation *Location
 	return nil
 }
 
+func (fdSet *fdManager) RemoveFile(fileId uint64) error {
+	fdSet.changeFdMu.Lock()
+	defer fdSet.changeFdMu.Unlock()
+
+	if fileId >= fdSet.latestFileId() {
+		return errors.New(fmt.Sprintf("can't remove the write file, fileId is %d", fileId))
+	}
+
+	if err := os.Remove(fdSet.fileIdToAbsoluteFilename(fileId)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
 func (fdSet *fdManager) CreateNextFd() error {
 	fdSet.changeFdMu.Lock()
 	defer fdSet.changeFdMu.Unlock()
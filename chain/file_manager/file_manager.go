@@ -261,6 +261,11 @@ func (fm *FileManager) ReadRange(startLocation *Location, endLocation *Location,
 	}
 }
 
+// RemoveFile removes the file of fileId from the disk, the file must have been flushed and must not be read any more
+func (fm *FileManager) RemoveFile(fileId uint64) error {
+	return fm.fdSet.RemoveFile(fileId)
+}
+
 func (fm *FileManager) SetLog(h log15.Handler) {
 	fm.log.SetHandler(h)
 }
@@ -0,0 +1,100 @@
+package chain
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/chain/state"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/interfaces"
+)
+
+func (c *chain) GetSnapshotBalance(snapshotHeight uint64, addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
+	if err := c.checkSnapshotHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+
+	balance, err := c.stateDB.GetSnapshotBalance(snapshotHeight, addr, tokenId)
+	if err != nil {
+		cErr := errors.New(fmt.Sprintf("c.stateDB.GetSnapshotBalance failed, snapshotHeight is %d, addr is %s, tokenId is %s. Error: %s",
+			snapshotHeight, addr, tokenId, err))
+		c.log.Error(cErr.Error(), "method", "GetSnapshotBalance")
+		return nil, cErr
+	}
+	return balance, nil
+}
+
+func (c *chain) GetSnapshotBalanceMap(snapshotHeight uint64, addr types.Address) (map[types.TokenTypeId]*big.Int, error) {
+	if err := c.checkSnapshotHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+
+	balanceMap, err := c.stateDB.GetSnapshotBalanceMap(snapshotHeight, addr)
+	if err != nil {
+		cErr := errors.New(fmt.Sprintf("c.stateDB.GetSnapshotBalanceMap failed, snapshotHeight is %d, addr is %s. Error: %s",
+			snapshotHeight, addr, err))
+		c.log.Error(cErr.Error(), "method", "GetSnapshotBalanceMap")
+		return nil, cErr
+	}
+	return balanceMap, nil
+}
+
+func (c *chain) GetSnapshotValue(snapshotHeight uint64, addr types.Address, key []byte) ([]byte, error) {
+	if err := c.checkSnapshotHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+
+	value, err := c.stateDB.GetSnapshotValue(snapshotHeight, addr, key)
+	if err != nil {
+		cErr := errors.New(fmt.Sprintf("c.stateDB.GetSnapshotValue failed, snapshotHeight is %d, addr is %s, key is %s. Error: %s",
+			snapshotHeight, addr, key, err))
+		c.log.Error(cErr.Error(), "method", "GetSnapshotValue")
+		return nil, cErr
+	}
+	return value, nil
+}
+
+func (c *chain) GetSnapshotStorageIterator(snapshotHeight uint64, addr types.Address, prefix []byte) (interfaces.StorageIterator, error) {
+	if err := c.checkSnapshotHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+
+	iter, err := c.stateDB.NewSnapshotStorageIteratorByHeight(snapshotHeight, &addr, prefix)
+	if err != nil {
+		cErr := errors.New(fmt.Sprintf("c.stateDB.NewSnapshotStorageIteratorByHeight failed, snapshotHeight is %d, addr is %s, prefix is %s. Error: %s",
+			snapshotHeight, addr, prefix, err))
+		c.log.Error(cErr.Error(), "method", "GetSnapshotStorageIterator")
+		return nil, cErr
+	}
+	return iter, nil
+}
+
+func (c *chain) GetSnapshotStateLog(snapshotHeight uint64) (chain_state.SnapshotLog, error) {
+	if err := c.checkSnapshotHeight(snapshotHeight); err != nil {
+		return nil, err
+	}
+
+	snapshotLog, ok, err := c.stateDB.GetSnapshotLog(snapshotHeight)
+	if err != nil {
+		cErr := errors.New(fmt.Sprintf("c.stateDB.GetSnapshotLog failed, snapshotHeight is %d. Error: %s", snapshotHeight, err))
+		c.log.Error(cErr.Error(), "method", "GetSnapshotStateLog")
+		return nil, cErr
+	}
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("the redo log of snapshot height %d is too old and has been discarded", snapshotHeight))
+	}
+	return snapshotLog, nil
+}
+
+func (c *chain) checkSnapshotHeight(snapshotHeight uint64) error {
+	if snapshotHeight <= 0 {
+		return errors.New("snapshot height can't be 0")
+	}
+
+	latestHeight := c.GetLatestSnapshotBlock().Height
+	if snapshotHeight > latestHeight {
+		return errors.New(fmt.Sprintf("snapshot height %d is higher than the latest snapshot height %d", snapshotHeight, latestHeight))
+	}
+	return nil
+}
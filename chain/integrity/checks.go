@@ -0,0 +1,249 @@
+package chain_integrity
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/chain/state"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// checkHeight checks the snapshot block of height, the account blocks confirmed by it and the redo log of it
+func (v *Verifier) checkHeight(height uint64) *heightResult {
+	result := &heightResult{height: height}
+
+	chunks, err := v.chain.GetSubLedger(height-1, height)
+	if err != nil {
+		result.issues = append(result.issues, newIssue(IssueRead, height, fmt.Sprintf("c.GetSubLedger failed. Error: %s", err)))
+		return result
+	}
+
+	var chunk *ledger.SnapshotChunk
+	for _, item := range chunks {
+		if item.SnapshotBlock != nil && item.SnapshotBlock.Height == height {
+			chunk = item
+			break
+		}
+	}
+	if chunk == nil {
+		result.issues = append(result.issues, newIssue(IssueRead, height, "the snapshot block is not exist in the block files"))
+		return result
+	}
+
+	result.accountBlocks = uint64(len(chunk.AccountBlocks))
+	result.issues = append(result.issues, v.checkSnapshotBlock(chunk)...)
+
+	for _, block := range chunk.AccountBlocks {
+		result.issues = append(result.issues, v.checkAccountBlock(height, block)...)
+	}
+
+	result.issues = append(result.issues, v.checkRedo(chunk)...)
+	return result
+}
+
+func (v *Verifier) checkSnapshotBlock(chunk *ledger.SnapshotChunk) []*Issue {
+	var issues []*Issue
+	sb := chunk.SnapshotBlock
+
+	addIssue := func(kind string, format string, args ...interface{}) {
+		issue := newIssue(kind, sb.Height, fmt.Sprintf(format, args...))
+		issue.Hash = &sb.Hash
+		issues = append(issues, issue)
+	}
+
+	if header, err := v.chain.GetSnapshotHeaderByHeight(sb.Height); err != nil {
+		addIssue(IssueRead, "c.GetSnapshotHeaderByHeight failed. Error: %s", err)
+	} else if header == nil || header.Hash != sb.Hash {
+		addIssue(IssueIndex, "the snapshot block of height %d in the index is %+v", sb.Height, header)
+	}
+
+	if v.chain.IsGenesisSnapshotBlock(sb.Hash) {
+		return issues
+	}
+
+	if computedHash := sb.ComputeHash(); computedHash != sb.Hash {
+		addIssue(IssueSnapshotHash, "the hash is computed as %s", computedHash)
+	}
+	if len(sb.Signature) <= 0 || len(sb.PublicKey) <= 0 || !sb.VerifySignature() {
+		addIssue(IssueSnapshotSignature, "the signature is invalid")
+	}
+
+	if prev, err := v.chain.GetSnapshotHeaderByHeight(sb.Height - 1); err != nil {
+		addIssue(IssueRead, "c.GetSnapshotHeaderByHeight failed, height is %d. Error: %s", sb.Height-1, err)
+	} else if prev == nil || prev.Hash != sb.PrevHash {
+		addIssue(IssueSnapshotPrev, "the prev hash is %s, but the snapshot block of height %d is %+v", sb.PrevHash, sb.Height-1, prev)
+	}
+
+	// the snapshot content points to the latest account block of every account confirmed by the snapshot block
+	latestBlocks := make(map[types.Address]*ledger.AccountBlock)
+	for _, block := range chunk.AccountBlocks {
+		if latest, ok := latestBlocks[block.AccountAddress]; !ok || latest.Height < block.Height {
+			latestBlocks[block.AccountAddress] = block
+		}
+	}
+	for addr, hashHeight := range sb.SnapshotContent {
+		latest, ok := latestBlocks[addr]
+		if !ok {
+			addIssue(IssueSnapshotContent, "no account block of %s is confirmed, but the snapshot content is %d %s", addr, hashHeight.Height, hashHeight.Hash)
+			continue
+		}
+		if latest.Hash != hashHeight.Hash || latest.Height != hashHeight.Height {
+			addIssue(IssueSnapshotContent, "the latest account block of %s is %d %s, but the snapshot content is %d %s",
+				addr, latest.Height, latest.Hash, hashHeight.Height, hashHeight.Hash)
+		}
+	}
+	for addr, latest := range latestBlocks {
+		if _, ok := sb.SnapshotContent[addr]; !ok {
+			addIssue(IssueSnapshotContent, "account block %d %s of %s is confirmed, but it's not in the snapshot content", latest.Height, latest.Hash, addr)
+		}
+	}
+
+	return issues
+}
+
+func (v *Verifier) checkAccountBlock(snapshotHeight uint64, block *ledger.AccountBlock) []*Issue {
+	var issues []*Issue
+
+	addIssue := func(kind string, format string, args ...interface{}) {
+		issue := newIssue(kind, snapshotHeight, fmt.Sprintf(format, args...))
+		issue.Address = &block.AccountAddress
+		issue.Height = block.Height
+		issue.Hash = &block.Hash
+		issues = append(issues, issue)
+	}
+
+	// index
+	if indexed, err := v.chain.GetAccountBlockByHeight(block.AccountAddress, block.Height); err != nil {
+		addIssue(IssueRead, "c.GetAccountBlockByHeight failed. Error: %s", err)
+	} else if indexed == nil || indexed.Hash != block.Hash {
+		addIssue(IssueIndex, "the account block of height %d in the index is %+v", block.Height, indexed)
+	}
+	if confirmSb, err := v.chain.GetConfirmSnapshotHeaderByAbHash(block.Hash); err != nil {
+		addIssue(IssueRead, "c.GetConfirmSnapshotHeaderByAbHash failed. Error: %s", err)
+	} else if confirmSb == nil || confirmSb.Height != snapshotHeight {
+		addIssue(IssueIndex, "the confirming snapshot block in the index is %+v", confirmSb)
+	}
+
+	if v.chain.IsGenesisAccountBlock(block.Hash) {
+		return issues
+	}
+
+	// hash and signature
+	if computedHash := block.ComputeHash(); computedHash != block.Hash {
+		addIssue(IssueAccountHash, "the hash is computed as %s", computedHash)
+	}
+	if len(block.Signature) <= 0 || len(block.PublicKey) <= 0 || !block.VerifySignature() {
+		addIssue(IssueAccountSignature, "the signature is invalid")
+	}
+	// the send blocks of a contract are hashed with the receive block and their index
+	for i, sendBlock := range block.SendBlockList {
+		if computedHash := sendBlock.ComputeSendHash(block, uint8(i)); computedHash != sendBlock.Hash {
+			addIssue(IssueAccountHash, "the hash of send block %s is computed as %s", sendBlock.Hash, computedHash)
+		}
+	}
+
+	// prev
+	if block.Height <= 1 {
+		if !block.PrevHash.IsZero() {
+			addIssue(IssueAccountPrev, "the prev hash of the first account block is %s", block.PrevHash)
+		}
+	} else if prev, err := v.chain.GetAccountBlockByHash(block.PrevHash); err != nil {
+		addIssue(IssueRead, "c.GetAccountBlockByHash failed, prev hash is %s. Error: %s", block.PrevHash, err)
+	} else if prev == nil || prev.AccountAddress != block.AccountAddress || prev.Height+1 != block.Height {
+		addIssue(IssueAccountPrev, "the prev account block %s is %+v", block.PrevHash, prev)
+	}
+
+	// receive
+	if block.IsReceiveBlock() {
+		sendBlock, err := v.chain.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil {
+			addIssue(IssueRead, "c.GetAccountBlockByHash failed, from block hash is %s. Error: %s", block.FromBlockHash, err)
+		} else if sendBlock == nil || !sendBlock.IsSendBlock() || sendBlock.ToAddress != block.AccountAddress {
+			addIssue(IssueReceive, "the send block %s is %+v", block.FromBlockHash, sendBlock)
+		}
+
+		if receiveBlock, err := v.chain.GetReceiveAbBySendAb(block.FromBlockHash); err != nil {
+			addIssue(IssueRead, "c.GetReceiveAbBySendAb failed, from block hash is %s. Error: %s", block.FromBlockHash, err)
+		} else if receiveBlock == nil || receiveBlock.Hash != block.Hash {
+			addIssue(IssueIndex, "the receive block of send block %s in the index is %+v", block.FromBlockHash, receiveBlock)
+		}
+	}
+
+	return issues
+}
+
+// checkRedo checks that the redo log has an item for every confirmed account block, and the history
+// balances of the state store equal the balances replayed from the redo log.
+func (v *Verifier) checkRedo(chunk *ledger.SnapshotChunk) []*Issue {
+	var issues []*Issue
+	sb := chunk.SnapshotBlock
+
+	_, _, stateDB := v.chain.DBs()
+	snapshotLog, ok, err := stateDB.GetSnapshotLog(sb.Height)
+	if err != nil {
+		return []*Issue{newIssue(IssueRead, sb.Height, fmt.Sprintf("stateDB.GetSnapshotLog failed. Error: %s", err))}
+	}
+	// the redo log has been discarded
+	if !ok {
+		return nil
+	}
+
+	blockCount := make(map[types.Address]int)
+	for _, block := range chunk.AccountBlocks {
+		blockCount[block.AccountAddress]++
+	}
+	for addr, count := range blockCount {
+		if len(snapshotLog[addr]) != count {
+			addr := addr
+			issue := newIssue(IssueRedo, sb.Height, fmt.Sprintf("%d account blocks are confirmed, but there are %d redo log items", count, len(snapshotLog[addr])))
+			issue.Address = &addr
+			issues = append(issues, issue)
+		}
+	}
+
+	for addr, logItems := range snapshotLog {
+		if _, ok := blockCount[addr]; !ok {
+			addr := addr
+			issue := newIssue(IssueRedo, sb.Height, fmt.Sprintf("no account block is confirmed, but there are %d redo log items", len(logItems)))
+			issue.Address = &addr
+			issues = append(issues, issue)
+		}
+
+		for tokenId, balance := range replayBalances(logItems) {
+			stored, err := v.chain.GetSnapshotBalance(sb.Height, addr, tokenId)
+			addr := addr
+			if err != nil {
+				issue := newIssue(IssueRead, sb.Height, fmt.Sprintf("c.GetSnapshotBalance failed, token id is %s. Error: %s", tokenId, err))
+				issue.Address = &addr
+				issues = append(issues, issue)
+				continue
+			}
+			if stored.Cmp(balance) != 0 {
+				issue := newIssue(IssueBalance, sb.Height, fmt.Sprintf("the balance of %s is %s, but it's replayed as %s", tokenId, stored, balance))
+				issue.Address = &addr
+				issues = append(issues, issue)
+			}
+		}
+	}
+	return issues
+}
+
+// replayBalances returns the balances of an account after the log items are applied in order
+func replayBalances(logItems []chain_state.LogItem) map[types.TokenTypeId]*big.Int {
+	balances := make(map[types.TokenTypeId]*big.Int)
+	for _, item := range logItems {
+		for tokenId, balance := range item.BalanceMap {
+			balances[tokenId] = balance
+		}
+	}
+	return balances
+}
+
+func newIssue(kind string, snapshotHeight uint64, message string) *Issue {
+	return &Issue{
+		Kind:           kind,
+		SnapshotHeight: snapshotHeight,
+		Message:        message,
+	}
+}
@@ -0,0 +1,233 @@
+package chain_integrity
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// mockChain serves the blocks from memory, only the methods used by the checks are implemented
+type mockChain struct {
+	chain.Chain
+
+	snapshotBlocks map[uint64]*ledger.SnapshotBlock
+	accountBlocks  map[types.Hash]*ledger.AccountBlock
+	confirmHeights map[types.Hash]uint64
+}
+
+func newMockChain() *mockChain {
+	return &mockChain{
+		snapshotBlocks: make(map[uint64]*ledger.SnapshotBlock),
+		accountBlocks:  make(map[types.Hash]*ledger.AccountBlock),
+		confirmHeights: make(map[types.Hash]uint64),
+	}
+}
+
+func (c *mockChain) IsGenesisSnapshotBlock(hash types.Hash) bool {
+	return false
+}
+
+func (c *mockChain) IsGenesisAccountBlock(hash types.Hash) bool {
+	return false
+}
+
+func (c *mockChain) GetSnapshotHeaderByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	return c.snapshotBlocks[height], nil
+}
+
+func (c *mockChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	return c.accountBlocks[hash], nil
+}
+
+func (c *mockChain) GetAccountBlockByHeight(addr types.Address, height uint64) (*ledger.AccountBlock, error) {
+	for _, block := range c.accountBlocks {
+		if block.AccountAddress == addr && block.Height == height {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *mockChain) GetConfirmSnapshotHeaderByAbHash(hash types.Hash) (*ledger.SnapshotBlock, error) {
+	return c.snapshotBlocks[c.confirmHeights[hash]], nil
+}
+
+func (c *mockChain) GetReceiveAbBySendAb(sendBlockHash types.Hash) (*ledger.AccountBlock, error) {
+	for _, block := range c.accountBlocks {
+		if block.IsReceiveBlock() && block.FromBlockHash == sendBlockHash {
+			return block, nil
+		}
+	}
+	return nil, nil
+}
+
+func (c *mockChain) insertAccountBlock(block *ledger.AccountBlock, snapshotHeight uint64) {
+	c.accountBlocks[block.Hash] = block
+	c.confirmHeights[block.Hash] = snapshotHeight
+}
+
+func signAccountBlock(t *testing.T, block *ledger.AccountBlock) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	block.Hash = block.ComputeHash()
+	block.PublicKey = pub
+	block.Signature = ed25519.Sign(priv, block.Hash.Bytes())
+}
+
+func issueKinds(issues []*Issue) map[string]int {
+	kinds := make(map[string]int)
+	for _, issue := range issues {
+		kinds[issue.Kind]++
+	}
+	return kinds
+}
+
+func TestCheckAccountBlock(t *testing.T) {
+	c := newMockChain()
+	v := NewVerifier(c, 1, "")
+
+	user := types.Address{1}
+	contract := types.AddressPledge
+	for height := uint64(1); height <= 3; height++ {
+		c.snapshotBlocks[height] = &ledger.SnapshotBlock{Height: height}
+	}
+
+	send := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: user,
+		Height:         1,
+		ToAddress:      contract,
+		Amount:         big.NewInt(10),
+		TokenId:        ledger.ViteTokenId,
+	}
+	signAccountBlock(t, send)
+	c.insertAccountBlock(send, 2)
+
+	// the receive block of the contract emits two send blocks
+	receive := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		AccountAddress: contract,
+		Height:         1,
+		FromBlockHash:  send.Hash,
+	}
+	for i := 0; i < 2; i++ {
+		receive.SendBlockList = append(receive.SendBlockList, &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeSendReward,
+			AccountAddress: contract,
+			ToAddress:      user,
+			Amount:         big.NewInt(int64(i + 1)),
+			TokenId:        ledger.ViteTokenId,
+		})
+	}
+	for i, sendBlock := range receive.SendBlockList {
+		sendBlock.Hash = sendBlock.ComputeSendHash(receive, uint8(i))
+	}
+	signAccountBlock(t, receive)
+	c.insertAccountBlock(receive, 2)
+
+	if issues := v.checkAccountBlock(2, send); len(issues) > 0 {
+		t.Fatalf("send block has issues %+v", issues[0])
+	}
+	if issues := v.checkAccountBlock(2, receive); len(issues) > 0 {
+		t.Fatalf("receive block with send blocks has issues %+v", issues[0])
+	}
+
+	// the send blocks are swapped, the index is a part of their hashes and the order is a part of the receive block hash
+	receive.SendBlockList[0], receive.SendBlockList[1] = receive.SendBlockList[1], receive.SendBlockList[0]
+	if kinds := issueKinds(v.checkAccountBlock(2, receive)); kinds[IssueAccountHash] != 3 {
+		t.Fatalf("swapped send blocks should have 3 hash issues, the issues are %v", kinds)
+	}
+	receive.SendBlockList[0], receive.SendBlockList[1] = receive.SendBlockList[1], receive.SendBlockList[0]
+
+	// the block is modified after signed
+	tampered := *send
+	tampered.Amount = big.NewInt(100)
+	if kinds := issueKinds(v.checkAccountBlock(2, &tampered)); kinds[IssueAccountHash] != 1 {
+		t.Fatalf("tampered block should have a hash issue, the issues are %v", kinds)
+	}
+
+	// the prev block is not exist
+	next := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: user,
+		Height:         2,
+		PrevHash:       types.Hash{9},
+		ToAddress:      contract,
+		Amount:         big.NewInt(1),
+		TokenId:        ledger.ViteTokenId,
+	}
+	signAccountBlock(t, next)
+	c.insertAccountBlock(next, 3)
+	if kinds := issueKinds(v.checkAccountBlock(3, next)); kinds[IssueAccountPrev] != 1 || len(kinds) != 1 {
+		t.Fatalf("block with a missing prev should have a prev issue, the issues are %v", kinds)
+	}
+
+	// the prev block is linked
+	next.PrevHash = send.Hash
+	delete(c.accountBlocks, next.Hash)
+	signAccountBlock(t, next)
+	c.insertAccountBlock(next, 3)
+	if issues := v.checkAccountBlock(3, next); len(issues) > 0 {
+		t.Fatalf("linked block has issues %+v", issues[0])
+	}
+}
+
+func TestCheckSnapshotBlock(t *testing.T) {
+	c := newMockChain()
+	v := NewVerifier(c, 1, "")
+
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sign := func(sb *ledger.SnapshotBlock) {
+		sb.PublicKey = pub
+		sb.Hash = sb.ComputeHash()
+		sb.Signature = ed25519.Sign(priv, sb.Hash.Bytes())
+		c.snapshotBlocks[sb.Height] = sb
+	}
+
+	time1 := time.Unix(1000, 0)
+	time2, time3 := time1.Add(time.Second), time1.Add(2*time.Second)
+
+	prev := &ledger.SnapshotBlock{Height: 1, Timestamp: &time1}
+	sign(prev)
+
+	block := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, AccountAddress: types.Address{1}, Height: 1, Amount: big.NewInt(1), TokenId: ledger.ViteTokenId}
+	signAccountBlock(t, block)
+
+	sb := &ledger.SnapshotBlock{
+		Height:          2,
+		PrevHash:        prev.Hash,
+		Timestamp:       &time2,
+		SnapshotContent: ledger.SnapshotContent{block.AccountAddress: &ledger.HashHeight{Hash: block.Hash, Height: block.Height}},
+	}
+	sign(sb)
+	chunk := &ledger.SnapshotChunk{SnapshotBlock: sb, AccountBlocks: []*ledger.AccountBlock{block}}
+	if issues := v.checkSnapshotBlock(chunk); len(issues) > 0 {
+		t.Fatalf("snapshot block has issues %+v", issues[0])
+	}
+
+	// the prev hash doesn't link to the snapshot block of the lower height
+	sb.PrevHash = types.Hash{9}
+	sign(sb)
+	if kinds := issueKinds(v.checkSnapshotBlock(chunk)); kinds[IssueSnapshotPrev] != 1 || len(kinds) != 1 {
+		t.Fatalf("unlinked snapshot block should have a prev issue, the issues are %v", kinds)
+	}
+	sb.PrevHash = prev.Hash
+	sign(sb)
+
+	// the snapshot block is modified after signed
+	tampered := *sb
+	tampered.Timestamp = &time3
+	if kinds := issueKinds(v.checkSnapshotBlock(&ledger.SnapshotChunk{SnapshotBlock: &tampered, AccountBlocks: chunk.AccountBlocks})); kinds[IssueSnapshotHash] != 1 {
+		t.Fatalf("tampered snapshot block should have a hash issue, the issues are %v", kinds)
+	}
+}
@@ -0,0 +1,96 @@
+package chain_integrity
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+const (
+	IssueRead              = "read"
+	IssueSnapshotHash      = "snapshotHash"
+	IssueSnapshotSignature = "snapshotSignature"
+	IssueSnapshotPrev      = "snapshotPrev"
+	IssueSnapshotContent   = "snapshotContent"
+	IssueAccountHash       = "accountHash"
+	IssueAccountSignature  = "accountSignature"
+	IssueAccountPrev       = "accountPrev"
+	IssueReceive           = "receive"
+	IssueIndex             = "index"
+	IssueRedo              = "redo"
+	IssueBalance           = "balance"
+)
+
+// only the first maxReportIssues issues are kept in the report, IssueCount is the total
+const maxReportIssues = 10000
+
+// Issue is an inconsistency found in the ledger
+type Issue struct {
+	Kind           string         `json:"kind"`
+	SnapshotHeight uint64         `json:"snapshotHeight"`
+	Address        *types.Address `json:"address,omitempty"`
+	Height         uint64         `json:"height,omitempty"`
+	Hash           *types.Hash    `json:"hash,omitempty"`
+	Message        string         `json:"message"`
+}
+
+// Report is the result of the verification, it's also the checkpoint to resume the verification.
+type Report struct {
+	GenesisHash types.Hash `json:"genesisHash"`
+
+	StartTime int64 `json:"startTime"`
+	EndTime   int64 `json:"endTime"`
+
+	ToHeight      uint64 `json:"toHeight"`
+	CheckedHeight uint64 `json:"checkedHeight"`
+	Completed     bool   `json:"completed"`
+
+	SnapshotBlocks uint64 `json:"snapshotBlocks"`
+	AccountBlocks  uint64 `json:"accountBlocks"`
+
+	IssueCount uint64   `json:"issueCount"`
+	Issues     []*Issue `json:"issues"`
+}
+
+func (r *Report) addIssues(issues []*Issue) {
+	r.IssueCount += uint64(len(issues))
+	for _, issue := range issues {
+		if len(r.Issues) >= maxReportIssues {
+			return
+		}
+		r.Issues = append(r.Issues, issue)
+	}
+}
+
+// LoadReport loads the report saved by SaveReport, returns nil if the file is not exist.
+func LoadReport(filename string) (*Report, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	report := &Report{}
+	if err := json.Unmarshal(data, report); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// SaveReport writes the report to a temporary file and renames it to filename.
+func SaveReport(report *Report, filename string) error {
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	tmpFilename := filename + ".tmp"
+	if err := ioutil.WriteFile(tmpFilename, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilename, filename)
+}
@@ -0,0 +1,61 @@
+package chain_integrity
+
+import (
+	"io/ioutil"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vitelabs/go-vite/chain/state"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestReplayBalances(t *testing.T) {
+	otherTokenId := types.TokenTypeId{1}
+	logItems := []chain_state.LogItem{
+		{BalanceMap: map[types.TokenTypeId]*big.Int{ledger.ViteTokenId: big.NewInt(10)}},
+		{BalanceMap: map[types.TokenTypeId]*big.Int{otherTokenId: big.NewInt(5)}},
+		{BalanceMap: map[types.TokenTypeId]*big.Int{ledger.ViteTokenId: big.NewInt(3)}},
+	}
+
+	balances := replayBalances(logItems)
+	if len(balances) != 2 || balances[ledger.ViteTokenId].Cmp(big.NewInt(3)) != 0 || balances[otherTokenId].Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("balances are %v", balances)
+	}
+}
+
+func TestSaveAndLoadReport(t *testing.T) {
+	dir, err := ioutil.TempDir("", "integrity")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	filename := filepath.Join(dir, "checkpoint")
+	if report, err := LoadReport(filename); err != nil || report != nil {
+		t.Fatalf("report is %+v, error is %v", report, err)
+	}
+
+	report := &Report{ToHeight: 100, CheckedHeight: 50}
+	issues := make([]*Issue, maxReportIssues+1)
+	for i := range issues {
+		issues[i] = newIssue(IssueIndex, uint64(i), "test")
+	}
+	report.addIssues(issues)
+	if report.IssueCount != uint64(len(issues)) || len(report.Issues) != maxReportIssues {
+		t.Fatalf("issue count is %d, %d issues are kept", report.IssueCount, len(report.Issues))
+	}
+
+	if err := SaveReport(report, filename); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := LoadReport(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.CheckedHeight != report.CheckedHeight || loaded.IssueCount != report.IssueCount || len(loaded.Issues) != len(report.Issues) {
+		t.Fatalf("loaded report is %+v", loaded)
+	}
+}
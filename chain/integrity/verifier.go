@@ -0,0 +1,211 @@
+package chain_integrity
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/log15"
+)
+
+// the checkpoint is saved after every checkpointInterval snapshot heights are checked
+const checkpointInterval = 1000
+
+type heightResult struct {
+	height        uint64
+	accountBlocks uint64
+	issues        []*Issue
+}
+
+// Verifier walks every snapshot block and the account blocks confirmed by it, the snapshot heights are
+// checked by parallel workers independently.
+type Verifier struct {
+	chain chain.Chain
+
+	workers        int
+	checkpointPath string
+
+	log log15.Logger
+}
+
+// NewVerifier returns a Verifier, the progress is saved to checkpointPath if it's not empty
+func NewVerifier(c chain.Chain, workers int, checkpointPath string) *Verifier {
+	if workers <= 0 {
+		workers = 1
+	}
+	return &Verifier{
+		chain:          c,
+		workers:        workers,
+		checkpointPath: checkpointPath,
+		log:            log15.New("module", "chain_integrity"),
+	}
+}
+
+// Verify checks the snapshot heights in [1, toHeight], the latest snapshot height is used if toHeight is 0.
+// The verification is resumed from the checkpoint if it exists.
+func (v *Verifier) Verify(toHeight uint64) (*Report, error) {
+	latestHeight := v.chain.GetLatestSnapshotBlock().Height
+	if toHeight <= 0 || toHeight > latestHeight {
+		toHeight = latestHeight
+	}
+
+	report, err := v.loadCheckpoint(toHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	// the account blocks of the pruned snapshot heights are not exist any more
+	if prunedHeight := v.chain.GetPrunedSnapshotHeight(); report.CheckedHeight < prunedHeight {
+		v.log.Info(fmt.Sprintf("skip the pruned snapshot heights lower than or equal to %d", prunedHeight), "method", "Verify")
+		report.CheckedHeight = prunedHeight
+	}
+
+	fromHeight := report.CheckedHeight + 1
+	v.log.Info(fmt.Sprintf("verify snapshot height from %d to %d, %d workers", fromHeight, toHeight, v.workers), "method", "Verify")
+
+	heights := make(chan uint64, v.workers)
+	results := make(chan *heightResult, v.workers)
+	quit := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for i := 0; i < v.workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for height := range heights {
+				results <- v.checkHeight(height)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(heights)
+		for height := fromHeight; height <= toHeight; height++ {
+			select {
+			case heights <- height:
+			case <-quit:
+				return
+			}
+		}
+	}()
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	// the results are merged in the order of height, so the checkpoint is contiguous
+	pending := make(map[uint64]*heightResult)
+	var saveErr error
+	for result := range results {
+		pending[result.height] = result
+
+		for {
+			next, ok := pending[report.CheckedHeight+1]
+			if !ok {
+				break
+			}
+			delete(pending, next.height)
+
+			report.CheckedHeight = next.height
+			report.SnapshotBlocks++
+			report.AccountBlocks += next.accountBlocks
+			report.addIssues(next.issues)
+
+			if report.CheckedHeight%checkpointInterval == 0 {
+				v.log.Info(fmt.Sprintf("checked snapshot height %d, %d issues", report.CheckedHeight, report.IssueCount), "method", "Verify")
+				if err := v.saveCheckpoint(report); err != nil && saveErr == nil {
+					saveErr = err
+					close(quit)
+				}
+			}
+		}
+	}
+
+	if saveErr != nil {
+		return nil, errors.New(fmt.Sprintf("save checkpoint failed. Error: %s", saveErr))
+	}
+
+	report.Completed = report.CheckedHeight >= report.ToHeight
+	report.EndTime = time.Now().Unix()
+
+	if err := v.saveCheckpoint(report); err != nil {
+		return nil, errors.New(fmt.Sprintf("save checkpoint failed. Error: %s", err))
+	}
+	return report, nil
+}
+
+// VerifySample checks one of every interval snapshot heights in [1, toHeight] and toHeight itself, it's a quick check
+// of a ledger which is not built locally, the checkpoint is not used.
+func (v *Verifier) VerifySample(toHeight uint64, interval uint64) *Report {
+	latestHeight := v.chain.GetLatestSnapshotBlock().Height
+	if toHeight <= 0 || toHeight > latestHeight {
+		toHeight = latestHeight
+	}
+	if interval <= 0 {
+		interval = 1
+	}
+
+	report := &Report{
+		GenesisHash: v.chain.GetGenesisSnapshotBlock().Hash,
+		StartTime:   time.Now().Unix(),
+		ToHeight:    toHeight,
+		Issues:      make([]*Issue, 0),
+	}
+
+	prunedHeight := v.chain.GetPrunedSnapshotHeight()
+	for height := interval; ; height += interval {
+		if height > toHeight {
+			height = toHeight
+		}
+		if height > prunedHeight {
+			result := v.checkHeight(height)
+			report.SnapshotBlocks++
+			report.AccountBlocks += result.accountBlocks
+			report.addIssues(result.issues)
+		}
+		report.CheckedHeight = height
+
+		if height >= toHeight {
+			break
+		}
+	}
+
+	report.Completed = true
+	report.EndTime = time.Now().Unix()
+	return report
+}
+
+func (v *Verifier) loadCheckpoint(toHeight uint64) (*Report, error) {
+	genesisHash := v.chain.GetGenesisSnapshotBlock().Hash
+
+	if len(v.checkpointPath) > 0 {
+		report, err := LoadReport(v.checkpointPath)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("load checkpoint %s failed. Error: %s", v.checkpointPath, err))
+		}
+
+		if report != nil && report.GenesisHash == genesisHash && report.CheckedHeight <= toHeight {
+			v.log.Info(fmt.Sprintf("resume from the checkpoint, checked snapshot height is %d", report.CheckedHeight), "method", "loadCheckpoint")
+			report.ToHeight = toHeight
+			report.Completed = false
+			return report, nil
+		}
+	}
+
+	return &Report{
+		GenesisHash: genesisHash,
+		StartTime:   time.Now().Unix(),
+		ToHeight:    toHeight,
+		Issues:      make([]*Issue, 0),
+	}, nil
+}
+
+func (v *Verifier) saveCheckpoint(report *Report) error {
+	if len(v.checkpointPath) <= 0 {
+		return nil
+	}
+	return SaveReport(report, v.checkpointPath)
+}
@@ -176,6 +176,9 @@ type Chain interface {
 
 	GetSubLedgerAfterHeight(height uint64) ([]*ledger.SnapshotChunk, error)
 
+	// the account blocks confirmed by the snapshot blocks not higher than the pruned height may have been pruned
+	GetPrunedSnapshotHeight() uint64
+
 	// ====== Query unconfirmed pool ======
 	GetAllUnconfirmedBlocks() []*ledger.AccountBlock
 
@@ -189,6 +192,8 @@ type Chain interface {
 	// In others words, The first receive block of the address is not contract address when the block has not yet been inserted into the chain
 	IsContractAccount(address types.Address) (bool, error)
 
+	IsContractAccountInSnapshot(address types.Address, snapshotHeight uint64) (bool, error)
+
 	IterateContracts(iterateFunc func(addr types.Address, meta *ledger.ContractMeta, err error) bool)
 
 	IterateAccounts(iterateFunc func(addr types.Address, accountId uint64, err error) bool)
@@ -224,6 +229,20 @@ type Chain interface {
 
 	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
 
+	// ===== Query history state ======
+	// get Balance after the snapshot block of snapshotHeight was inserted
+	GetSnapshotBalance(snapshotHeight uint64, addr types.Address, tokenId types.TokenTypeId) (*big.Int, error)
+
+	// get Balance map after the snapshot block of snapshotHeight was inserted
+	GetSnapshotBalanceMap(snapshotHeight uint64, addr types.Address) (map[types.TokenTypeId]*big.Int, error)
+
+	GetSnapshotValue(snapshotHeight uint64, addr types.Address, key []byte) ([]byte, error)
+
+	GetSnapshotStorageIterator(snapshotHeight uint64, addr types.Address, prefix []byte) (interfaces.StorageIterator, error)
+
+	// get the state changes confirmed by the snapshot block, if history is too old, failed
+	GetSnapshotStateLog(snapshotHeight uint64) (chain_state.SnapshotLog, error)
+
 	// ====== Query built-in contract storage ======
 
 	GetRegisterList(snapshotHash types.Hash, gid types.Gid) ([]*types.Registration, error)
@@ -0,0 +1,195 @@
+package chain_plugins
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/db"
+	"github.com/vitelabs/go-vite/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+// AddressTx indexes every confirmed account block by the accounts it touches:
+// the account itself, the ToAddress of a send block and the sender of a receive block.
+type AddressTx struct {
+	store *chain_db.Store
+	chain Chain
+}
+
+type addressTxItem struct {
+	addr types.Address
+	hash types.Hash
+}
+
+func newAddressTx(store *chain_db.Store, chain Chain) Plugin {
+	return &AddressTx{
+		store: store,
+		chain: chain,
+	}
+}
+
+func (at *AddressTx) SetStore(store *chain_db.Store) {
+	at.store = store
+}
+
+// only confirmed blocks are indexed, they are indexed in InsertSnapshotBlock
+func (at *AddressTx) InsertAccountBlock(batch *leveldb.Batch, accountBlock *ledger.AccountBlock) error {
+	return nil
+}
+
+func (at *AddressTx) InsertSnapshotBlock(batch *leveldb.Batch, snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock) error {
+	if snapshotBlock == nil {
+		return nil
+	}
+
+	items, err := at.parseItems(confirmedBlocks)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		batch.Put(createAddressTxKey(item.addr, snapshotBlock.Height, item.hash), nil)
+	}
+	return nil
+}
+
+// unconfirmed blocks are not indexed
+func (at *AddressTx) DeleteAccountBlocks(batch *leveldb.Batch, accountBlocks []*ledger.AccountBlock) error {
+	return nil
+}
+
+func (at *AddressTx) DeleteSnapshotBlocks(batch *leveldb.Batch, chunks []*ledger.SnapshotChunk) error {
+	for _, chunk := range chunks {
+		if chunk == nil || chunk.SnapshotBlock == nil {
+			continue
+		}
+
+		items, err := at.parseItems(chunk.AccountBlocks)
+		if err != nil {
+			return err
+		}
+
+		for _, item := range items {
+			batch.Delete(createAddressTxKey(item.addr, chunk.SnapshotBlock.Height, item.hash))
+		}
+	}
+	return nil
+}
+
+func (at *AddressTx) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// high to low, contains the account blocks which are confirmed by the snapshot blocks between startHeight and endHeight
+func (at *AddressTx) GetBlocks(addr types.Address, startHeight, endHeight uint64, index, count uint64) ([]*ledger.AccountBlock, error) {
+	if startHeight > endHeight {
+		return nil, errors.New(fmt.Sprintf("startHeight %d is higher than endHeight %d", startHeight, endHeight))
+	}
+	if count > 0 && index > math.MaxUint64/count {
+		return nil, errors.New(fmt.Sprintf("index %d and count %d are too large", index, count))
+	}
+
+	iter := at.store.NewIterator(&util.Range{Start: createAddressTxHeightKey(addr, startHeight), Limit: createAddressTxHeightKey(addr, endHeight+1)})
+	defer iter.Release()
+
+	skip := index * count
+	var blocks []*ledger.AccountBlock
+
+	for iterOk := iter.Last(); iterOk && uint64(len(blocks)) < count; iterOk = iter.Prev() {
+		if skip > 0 {
+			skip--
+			continue
+		}
+
+		key := iter.Key()
+		hash, err := types.BytesToHash(key[len(key)-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+
+		block, err := at.chain.GetAccountBlockByHash(hash)
+		if err != nil {
+			return nil, err
+		}
+
+		if block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+
+	return blocks, nil
+}
+
+func (at *AddressTx) parseItems(blocks []*ledger.AccountBlock) ([]addressTxItem, error) {
+	sendBlocksMap := make(map[types.Hash]*ledger.AccountBlock)
+	for _, block := range blocks {
+		if block.IsSendBlock() {
+			sendBlocksMap[block.Hash] = block
+		}
+		for _, sendBlock := range block.SendBlockList {
+			sendBlocksMap[sendBlock.Hash] = sendBlock
+		}
+	}
+
+	items := make([]addressTxItem, 0, len(blocks)*2)
+	for _, block := range blocks {
+		items = append(items, addressTxItem{addr: block.AccountAddress, hash: block.Hash})
+
+		if block.IsSendBlock() {
+			if block.ToAddress != block.AccountAddress {
+				items = append(items, addressTxItem{addr: block.ToAddress, hash: block.Hash})
+			}
+			continue
+		}
+
+		if block.BlockType != ledger.BlockTypeGenesisReceive && !at.chain.IsGenesisAccountBlock(block.Hash) {
+			sendBlock, ok := sendBlocksMap[block.FromBlockHash]
+			if !ok {
+				var err error
+				sendBlock, err = at.chain.GetAccountBlockByHash(block.FromBlockHash)
+				if err != nil {
+					return nil, errors.New(fmt.Sprintf("at.chain.GetAccountBlockByHash failed. Error: %s", err))
+				}
+			}
+
+			if sendBlock == nil {
+				return nil, errors.New(fmt.Sprintf("send block %s is nil", block.FromBlockHash))
+			}
+
+			if sendBlock.AccountAddress != block.AccountAddress {
+				items = append(items, addressTxItem{addr: sendBlock.AccountAddress, hash: block.Hash})
+			}
+		}
+
+		for _, sendBlock := range block.SendBlockList {
+			items = append(items, addressTxItem{addr: sendBlock.AccountAddress, hash: sendBlock.Hash})
+			if sendBlock.ToAddress != sendBlock.AccountAddress {
+				items = append(items, addressTxItem{addr: sendBlock.ToAddress, hash: sendBlock.Hash})
+			}
+		}
+	}
+	return items, nil
+}
+
+func createAddressTxHeightKey(addr types.Address, snapshotHeight uint64) []byte {
+	key := make([]byte, 1+types.AddressSize+8)
+	key[0] = AddressTxKeyPrefix
+	copy(key[1:1+types.AddressSize], addr.Bytes())
+	binary.BigEndian.PutUint64(key[1+types.AddressSize:], snapshotHeight)
+	return key
+}
+
+func createAddressTxKey(addr types.Address, snapshotHeight uint64, blockHash types.Hash) []byte {
+	key := make([]byte, 0, 1+types.AddressSize+8+types.HashSize)
+	key = append(key, createAddressTxHeightKey(addr, snapshotHeight)...)
+	key = append(key, blockHash.Bytes()...)
+	return key
+}
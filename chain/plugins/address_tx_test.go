@@ -0,0 +1,100 @@
+package chain_plugins
+
+import (
+	"bytes"
+	"math"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type addressTxChain struct {
+	Chain
+	blocks map[types.Hash]*ledger.AccountBlock
+}
+
+func (c *addressTxChain) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[blockHash], nil
+}
+
+func (c *addressTxChain) IsGenesisAccountBlock(hash types.Hash) bool {
+	return false
+}
+
+func TestAddressTx_parseItems(t *testing.T) {
+	caller1, caller2, caller3 := types.Address{1, 1}, types.Address{1, 2}, types.Address{1, 3}
+	contract1 := types.Address{0, 1}
+
+	oldSend := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           types.Hash{1},
+		AccountAddress: caller2,
+		ToAddress:      contract1,
+	}
+	send := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeSendCall,
+		Hash:           types.Hash{2},
+		AccountAddress: caller1,
+		ToAddress:      contract1,
+	}
+	receive := &ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		Hash:           types.Hash{3},
+		AccountAddress: contract1,
+		FromBlockHash:  oldSend.Hash,
+		SendBlockList: []*ledger.AccountBlock{{
+			BlockType:      ledger.BlockTypeSendCall,
+			Hash:           types.Hash{4},
+			AccountAddress: contract1,
+			ToAddress:      caller3,
+		}},
+	}
+
+	at := newAddressTx(nil, &addressTxChain{
+		blocks: map[types.Hash]*ledger.AccountBlock{oldSend.Hash: oldSend},
+	}).(*AddressTx)
+
+	items, err := at.parseItems([]*ledger.AccountBlock{send, receive})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []addressTxItem{
+		{addr: caller1, hash: send.Hash},
+		{addr: contract1, hash: send.Hash},
+		{addr: contract1, hash: receive.Hash},
+		{addr: caller2, hash: receive.Hash},
+		{addr: contract1, hash: receive.SendBlockList[0].Hash},
+		{addr: caller3, hash: receive.SendBlockList[0].Hash},
+	}
+	if len(items) != len(expected) {
+		t.Fatalf("item count is %d, expected %d", len(items), len(expected))
+	}
+	for i, item := range items {
+		if item != expected[i] {
+			t.Fatalf("item %d is %+v, expected %+v", i, item, expected[i])
+		}
+	}
+}
+
+func TestAddressTx_GetBlocksOverflow(t *testing.T) {
+	at := newAddressTx(nil, &addressTxChain{}).(*AddressTx)
+
+	if _, err := at.GetBlocks(types.Address{1}, 1, 10, math.MaxUint64/2, 3); err == nil {
+		t.Fatal("index * count overflowing uint64 should be rejected")
+	}
+}
+
+func TestCreateAddressTxKey(t *testing.T) {
+	addr := types.Address{1, 1}
+	low := createAddressTxKey(addr, 10, types.Hash{0xff})
+	high := createAddressTxKey(addr, 11, types.Hash{})
+
+	if bytes.Compare(low, high) >= 0 {
+		t.Fatal("keys should be sorted by snapshot height")
+	}
+	if !bytes.HasPrefix(low, createAddressTxHeightKey(addr, 10)) {
+		t.Fatal("key should have the height key as prefix")
+	}
+}
@@ -6,6 +6,14 @@ const (
 	OnRoadInfoKeyPrefix = byte(1)
 
 	DiffTokenHash = byte(2)
+
+	AddressTxKeyPrefix = byte(3)
+
+	VmLogAddrKeyPrefix = byte(4)
+
+	VmLogTopicKeyPrefix = byte(5)
+
+	VmLogBloomKeyPrefix = byte(6)
 )
 
 func CreateOnRoadInfoKey(addr *types.Address, tId *types.TokenTypeId) []byte {
@@ -0,0 +1,427 @@
+package chain_plugins
+
+import (
+	"context"
+	"encoding/binary"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain/db"
+	"github.com/vitelabs/go-vite/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const (
+	// every bucket keeps a bloom of the addresses and topics of the logs confirmed by vmLogBucketSize snapshot blocks
+	vmLogBucketSize = uint64(256)
+
+	// a bucket has thousands of addresses and topics at most, 65536 bits with 3 hashes keep the false positive rate
+	// under 1% for 5000 of them
+	vmLogBloomSize = 8192
+)
+
+// VmLogIndex indexes the vm logs of confirmed account blocks by contract address and by each topic.
+type VmLogIndex struct {
+	store *chain_db.Store
+	chain Chain
+}
+
+type vmLogIndexItem struct {
+	snapshotHeight uint64
+	blockHash      types.Hash
+}
+
+func newVmLogIndex(store *chain_db.Store, chain Chain) Plugin {
+	return &VmLogIndex{
+		store: store,
+		chain: chain,
+	}
+}
+
+func (vi *VmLogIndex) SetStore(store *chain_db.Store) {
+	vi.store = store
+}
+
+// only confirmed blocks are indexed, they are indexed in InsertSnapshotBlock
+func (vi *VmLogIndex) InsertAccountBlock(batch *leveldb.Batch, accountBlock *ledger.AccountBlock) error {
+	return nil
+}
+
+func (vi *VmLogIndex) InsertSnapshotBlock(batch *leveldb.Batch, snapshotBlock *ledger.SnapshotBlock, confirmedBlocks []*ledger.AccountBlock) error {
+	if snapshotBlock == nil {
+		return nil
+	}
+
+	var bloom logBloom
+	bloomChanged := false
+
+	for _, block := range confirmedBlocks {
+		logList, err := vi.getVmLogList(block)
+		if err != nil {
+			return err
+		}
+		if len(logList) <= 0 {
+			continue
+		}
+
+		if !bloomChanged {
+			value, err := vi.store.Get(createVmLogBloomKey(snapshotBlock.Height / vmLogBucketSize))
+			if err != nil {
+				return err
+			}
+			copy(bloom[:], value)
+			bloomChanged = true
+		}
+
+		batch.Put(createVmLogAddrKey(block.AccountAddress, snapshotBlock.Height, block.Hash), nil)
+		for _, vmLog := range logList {
+			for _, topic := range vmLog.Topics {
+				batch.Put(createVmLogTopicKey(topic, snapshotBlock.Height, block.Hash), nil)
+			}
+		}
+		bloom.addLogs(block.AccountAddress, logList)
+	}
+
+	if bloomChanged {
+		batch.Put(createVmLogBloomKey(snapshotBlock.Height/vmLogBucketSize), bloom[:])
+	}
+	return nil
+}
+
+// unconfirmed blocks are not indexed
+func (vi *VmLogIndex) DeleteAccountBlocks(batch *leveldb.Batch, accountBlocks []*ledger.AccountBlock) error {
+	return nil
+}
+
+// the blooms of the buckets higher than the deleted snapshot blocks are deleted, the bloom of the bucket contains
+// the lowest deleted snapshot block is rebuilt from the snapshot blocks left in the bucket
+func (vi *VmLogIndex) DeleteSnapshotBlocks(batch *leveldb.Batch, chunks []*ledger.SnapshotChunk) error {
+	lowHeight, highHeight := uint64(0), uint64(0)
+	for _, chunk := range chunks {
+		if chunk == nil || chunk.SnapshotBlock == nil {
+			continue
+		}
+
+		height := chunk.SnapshotBlock.Height
+		if lowHeight == 0 || height < lowHeight {
+			lowHeight = height
+		}
+		if height > highHeight {
+			highHeight = height
+		}
+
+		for _, block := range chunk.AccountBlocks {
+			logList, err := vi.getVmLogList(block)
+			if err != nil {
+				return err
+			}
+			if len(logList) <= 0 {
+				continue
+			}
+
+			batch.Delete(createVmLogAddrKey(block.AccountAddress, chunk.SnapshotBlock.Height, block.Hash))
+			for _, vmLog := range logList {
+				for _, topic := range vmLog.Topics {
+					batch.Delete(createVmLogTopicKey(topic, chunk.SnapshotBlock.Height, block.Hash))
+				}
+			}
+		}
+	}
+
+	if lowHeight == 0 {
+		return nil
+	}
+	for bucket := lowHeight/vmLogBucketSize + 1; bucket <= highHeight/vmLogBucketSize; bucket++ {
+		batch.Delete(createVmLogBloomKey(bucket))
+	}
+	return vi.rebuildBloom(batch, lowHeight/vmLogBucketSize, lowHeight-1)
+}
+
+// rebuildBloom computes the bloom of bucket from the logs confirmed by the snapshot blocks from the start of bucket to endHeight
+func (vi *VmLogIndex) rebuildBloom(batch *leveldb.Batch, bucket uint64, endHeight uint64) error {
+	var bloom logBloom
+	bloomChanged := false
+
+	startHeight := bucket * vmLogBucketSize
+	if endHeight >= startHeight {
+		// the first chunk is the snapshot block of the start height without account blocks
+		fromHeight := startHeight
+		if fromHeight > 0 {
+			fromHeight--
+		}
+		chunks, err := vi.chain.GetSubLedger(fromHeight, endHeight)
+		if err != nil {
+			return errors.New(fmt.Sprintf("vi.chain.GetSubLedger failed, height is %d-%d. Error: %s", fromHeight, endHeight, err))
+		}
+
+		for _, chunk := range chunks {
+			if chunk == nil || chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height < startHeight {
+				continue
+			}
+			for _, block := range chunk.AccountBlocks {
+				logList, err := vi.getVmLogList(block)
+				if err != nil {
+					return err
+				}
+				if len(logList) > 0 {
+					bloom.addLogs(block.AccountAddress, logList)
+					bloomChanged = true
+				}
+			}
+		}
+	}
+
+	if bloomChanged {
+		batch.Put(createVmLogBloomKey(bucket), bloom[:])
+	} else {
+		batch.Delete(createVmLogBloomKey(bucket))
+	}
+	return nil
+}
+
+func (vi *VmLogIndex) getVmLogList(block *ledger.AccountBlock) (ledger.VmLogList, error) {
+	if block.LogHash == nil {
+		return nil, nil
+	}
+	logList, err := vi.chain.GetVmLogList(block.LogHash)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("vi.chain.GetVmLogList failed, block is %s. Error: %s", block.Hash, err))
+	}
+	return logList, nil
+}
+
+func (vi *VmLogIndex) RemoveNewUnconfirmed(*leveldb.Batch, []*ledger.AccountBlock) error {
+	return nil
+}
+
+// GetBlocks returns the account blocks confirmed by the snapshot blocks between startHeight and endHeight, which may contain
+// the logs matched the addresses and topics, low to high. An empty addrList matches all addresses, topics has the same
+// meaning as the topics of the log filter. The caller has to filter the logs of the blocks.
+// It fails if more than limit blocks are matched, or ctx is done.
+func (vi *VmLogIndex) GetBlocks(ctx context.Context, addrList []types.Address, topics [][]types.Hash, startHeight, endHeight uint64, limit int) ([]*ledger.AccountBlock, error) {
+	if startHeight > endHeight {
+		return nil, errors.New(fmt.Sprintf("startHeight %d is higher than endHeight %d", startHeight, endHeight))
+	}
+
+	// the index used to find the blocks
+	var keyPrefixList [][]byte
+	if len(addrList) > 0 {
+		for _, addr := range addrList {
+			keyPrefixList = append(keyPrefixList, createVmLogAddrPrefixKey(addr))
+		}
+	} else {
+		for _, topicRange := range topics {
+			if len(topicRange) <= 0 {
+				continue
+			}
+			for _, topic := range topicRange {
+				keyPrefixList = append(keyPrefixList, createVmLogTopicPrefixKey(topic))
+			}
+			break
+		}
+	}
+	if len(keyPrefixList) <= 0 {
+		return nil, errors.New("addrList and topics can't be both empty")
+	}
+
+	var items []vmLogIndexItem
+	hashSet := make(map[types.Hash]struct{})
+
+	for bucket := startHeight / vmLogBucketSize; bucket <= endHeight/vmLogBucketSize; bucket++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		value, err := vi.store.Get(createVmLogBloomKey(bucket))
+		if err != nil {
+			return nil, err
+		}
+		if len(value) <= 0 {
+			continue
+		}
+
+		// the bucket is iterated if the bloom is not of the current size
+		if len(value) == vmLogBloomSize {
+			var bloom logBloom
+			copy(bloom[:], value)
+			if !bloom.match(addrList, topics) {
+				continue
+			}
+		}
+
+		bucketStart := bucket * vmLogBucketSize
+		if bucketStart < startHeight {
+			bucketStart = startHeight
+		}
+		bucketEnd := bucket*vmLogBucketSize + vmLogBucketSize - 1
+		if bucketEnd > endHeight {
+			bucketEnd = endHeight
+		}
+
+		for _, keyPrefix := range keyPrefixList {
+			bucketItems, err := vi.iterate(keyPrefix, bucketStart, bucketEnd)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, item := range bucketItems {
+				if _, ok := hashSet[item.blockHash]; ok {
+					continue
+				}
+				hashSet[item.blockHash] = struct{}{}
+				items = append(items, item)
+			}
+			if len(items) > limit {
+				return nil, errors.New(fmt.Sprintf("more than %d blocks are matched", limit))
+			}
+		}
+	}
+
+	sort.SliceStable(items, func(i, j int) bool {
+		return items[i].snapshotHeight < items[j].snapshotHeight
+	})
+
+	blocks := make([]*ledger.AccountBlock, 0, len(items))
+	for _, item := range items {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		block, err := vi.chain.GetAccountBlockByHash(item.blockHash)
+		if err != nil {
+			return nil, err
+		}
+		if block != nil {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks, nil
+}
+
+func (vi *VmLogIndex) iterate(keyPrefix []byte, startHeight, endHeight uint64) ([]vmLogIndexItem, error) {
+	iter := vi.store.NewIterator(&util.Range{
+		Start: appendHeight(keyPrefix, startHeight),
+		Limit: appendHeight(keyPrefix, endHeight+1),
+	})
+	defer iter.Release()
+
+	var items []vmLogIndexItem
+	for iter.Next() {
+		key := iter.Key()
+		hash, err := types.BytesToHash(key[len(key)-types.HashSize:])
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, vmLogIndexItem{
+			snapshotHeight: binary.BigEndian.Uint64(key[len(key)-types.HashSize-8 : len(key)-types.HashSize]),
+			blockHash:      hash,
+		})
+	}
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+	return items, nil
+}
+
+type logBloom [vmLogBloomSize]byte
+
+func (b *logBloom) add(data []byte) {
+	for _, bit := range bloomBits(data) {
+		b[bit/8] |= 1 << (bit % 8)
+	}
+}
+
+func (b *logBloom) addLogs(addr types.Address, logList ledger.VmLogList) {
+	b.add(addr.Bytes())
+	for _, vmLog := range logList {
+		for _, topic := range vmLog.Topics {
+			b.add(topic.Bytes())
+		}
+	}
+}
+
+func (b *logBloom) test(data []byte) bool {
+	for _, bit := range bloomBits(data) {
+		if b[bit/8]&(1<<(bit%8)) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *logBloom) match(addrList []types.Address, topics [][]types.Hash) bool {
+	if len(addrList) > 0 {
+		matched := false
+		for _, addr := range addrList {
+			if b.test(addr.Bytes()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	for _, topicRange := range topics {
+		if len(topicRange) <= 0 {
+			continue
+		}
+		matched := false
+		for _, topic := range topicRange {
+			if b.test(topic.Bytes()) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+func bloomBits(data []byte) [3]uint {
+	hash := crypto.Hash256(data)
+	var bits [3]uint
+	for i := range bits {
+		bits[i] = uint(binary.BigEndian.Uint16(hash[2*i:])) % (vmLogBloomSize * 8)
+	}
+	return bits
+}
+
+func appendHeight(prefix []byte, height uint64) []byte {
+	key := make([]byte, len(prefix)+8)
+	copy(key, prefix)
+	binary.BigEndian.PutUint64(key[len(prefix):], height)
+	return key
+}
+
+func createVmLogAddrPrefixKey(addr types.Address) []byte {
+	key := make([]byte, 0, 1+types.AddressSize)
+	key = append(key, VmLogAddrKeyPrefix)
+	key = append(key, addr.Bytes()...)
+	return key
+}
+
+func createVmLogAddrKey(addr types.Address, snapshotHeight uint64, blockHash types.Hash) []byte {
+	return append(appendHeight(createVmLogAddrPrefixKey(addr), snapshotHeight), blockHash.Bytes()...)
+}
+
+func createVmLogTopicPrefixKey(topic types.Hash) []byte {
+	key := make([]byte, 0, 1+types.HashSize)
+	key = append(key, VmLogTopicKeyPrefix)
+	key = append(key, topic.Bytes()...)
+	return key
+}
+
+func createVmLogTopicKey(topic types.Hash, snapshotHeight uint64, blockHash types.Hash) []byte {
+	return append(appendHeight(createVmLogTopicPrefixKey(topic), snapshotHeight), blockHash.Bytes()...)
+}
+
+func createVmLogBloomKey(bucket uint64) []byte {
+	return appendHeight([]byte{VmLogBloomKeyPrefix}, bucket)
+}
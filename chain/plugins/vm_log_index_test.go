@@ -0,0 +1,191 @@
+package chain_plugins
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"os"
+	"path"
+	"testing"
+
+	"github.com/vitelabs/go-vite/chain/db"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type vmLogChain struct {
+	Chain
+	chunks map[uint64]*ledger.SnapshotChunk
+	blocks map[types.Hash]*ledger.AccountBlock
+	logs   map[types.Hash]ledger.VmLogList
+}
+
+func (c *vmLogChain) GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	return c.blocks[blockHash], nil
+}
+
+func (c *vmLogChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	return c.logs[*logListHash], nil
+}
+
+func (c *vmLogChain) GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	var chunks []*ledger.SnapshotChunk
+	for height := startHeight; height <= endHeight; height++ {
+		if chunk, ok := c.chunks[height]; ok {
+			if height == startHeight {
+				chunk = &ledger.SnapshotChunk{SnapshotBlock: chunk.SnapshotBlock}
+			}
+			chunks = append(chunks, chunk)
+		}
+	}
+	return chunks, nil
+}
+
+// insert confirms a block with a log of topic by the snapshot block of height
+func (c *vmLogChain) insert(t *testing.T, vi *VmLogIndex, height uint64, addr types.Address, topic types.Hash) {
+	logHash := types.DataHash(append(addr.Bytes(), topic.Bytes()...))
+	block := &ledger.AccountBlock{AccountAddress: addr, Hash: types.DataHash(logHash.Bytes()), LogHash: &logHash}
+	c.blocks[block.Hash] = block
+	c.logs[logHash] = ledger.VmLogList{{Topics: []types.Hash{topic}}}
+
+	chunk := &ledger.SnapshotChunk{
+		SnapshotBlock: &ledger.SnapshotBlock{Height: height},
+		AccountBlocks: []*ledger.AccountBlock{block},
+	}
+	c.chunks[height] = chunk
+
+	batch := vi.store.NewBatch()
+	if err := vi.InsertSnapshotBlock(batch, chunk.SnapshotBlock, chunk.AccountBlocks); err != nil {
+		t.Fatal(err)
+	}
+	vi.store.WriteDirectly(batch)
+}
+
+func (c *vmLogChain) bloom(t *testing.T, vi *VmLogIndex, bucket uint64) *logBloom {
+	value, err := vi.store.Get(createVmLogBloomKey(bucket))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(value) <= 0 {
+		return nil
+	}
+	var bloom logBloom
+	copy(bloom[:], value)
+	return &bloom
+}
+
+func TestVmLogIndex(t *testing.T) {
+	dir, err := ioutil.TempDir("", "vm_log_index")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store, err := chain_db.NewStore(path.Join(dir, "plugins"), "plugins")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	c := &vmLogChain{
+		chunks: make(map[uint64]*ledger.SnapshotChunk),
+		blocks: make(map[types.Hash]*ledger.AccountBlock),
+		logs:   make(map[types.Hash]ledger.VmLogList),
+	}
+	vi := newVmLogIndex(store, c).(*VmLogIndex)
+
+	addr := types.Address{1}
+	topic1, topic2, topic3 := types.Hash{1}, types.Hash{2}, types.Hash{3}
+	c.insert(t, vi, 10, addr, topic1)
+	c.insert(t, vi, 20, addr, topic2)
+	c.insert(t, vi, vmLogBucketSize+10, addr, topic3)
+
+	blocks, err := vi.GetBlocks(context.Background(), []types.Address{addr}, nil, 1, vmLogBucketSize+10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 3 {
+		t.Fatalf("%d blocks are got, expected 3", len(blocks))
+	}
+
+	if _, err = vi.GetBlocks(context.Background(), []types.Address{addr}, nil, 1, vmLogBucketSize+10, 2); err == nil {
+		t.Fatal("more blocks than the limit should be rejected")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err = vi.GetBlocks(ctx, []types.Address{addr}, nil, 1, vmLogBucketSize+10, 10); err != context.Canceled {
+		t.Fatalf("canceled ctx should stop the query, err is %v", err)
+	}
+
+	// the snapshot blocks from height 20 are deleted
+	batch := vi.store.NewBatch()
+	if err = vi.DeleteSnapshotBlocks(batch, []*ledger.SnapshotChunk{c.chunks[20], c.chunks[vmLogBucketSize+10]}); err != nil {
+		t.Fatal(err)
+	}
+	vi.store.WriteDirectly(batch)
+	delete(c.chunks, 20)
+	delete(c.chunks, vmLogBucketSize+10)
+
+	if bloom := c.bloom(t, vi, 0); bloom == nil || !bloom.test(topic1.Bytes()) || bloom.test(topic2.Bytes()) {
+		t.Fatal("the bloom of the bucket should be rebuilt from the snapshot blocks left")
+	}
+	if bloom := c.bloom(t, vi, 1); bloom != nil {
+		t.Fatal("the bloom of the bucket without snapshot blocks left should be deleted")
+	}
+
+	blocks, err = vi.GetBlocks(context.Background(), nil, [][]types.Hash{{topic1, topic2}}, 1, vmLogBucketSize+10, 10)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blocks) != 1 || *blocks[0].LogHash != *c.chunks[10].AccountBlocks[0].LogHash {
+		t.Fatalf("only the block of height 10 should be left, %d blocks are got", len(blocks))
+	}
+}
+
+func TestLogBloom_match(t *testing.T) {
+	addr := types.Address{1}
+	topic1, topic2 := types.Hash{1}, types.Hash{2}
+
+	var bloom logBloom
+	bloom.add(addr.Bytes())
+	bloom.add(topic1.Bytes())
+
+	cases := []struct {
+		addrList []types.Address
+		topics   [][]types.Hash
+		matched  bool
+	}{
+		{nil, nil, true},
+		{[]types.Address{addr}, nil, true},
+		{[]types.Address{{2}}, nil, false},
+		{[]types.Address{{2}, addr}, nil, true},
+		{nil, [][]types.Hash{{topic1}}, true},
+		{nil, [][]types.Hash{{topic2}}, false},
+		{nil, [][]types.Hash{{topic2, topic1}}, true},
+		{nil, [][]types.Hash{{}, {topic1}}, true},
+		{[]types.Address{addr}, [][]types.Hash{{topic1}, {topic2}}, false},
+	}
+
+	for i, c := range cases {
+		if bloom.match(c.addrList, c.topics) != c.matched {
+			t.Fatalf("case %d, expected matched is %v", i, c.matched)
+		}
+	}
+}
+
+func TestCreateVmLogKey(t *testing.T) {
+	topic := types.Hash{1}
+	low := createVmLogTopicKey(topic, 10, types.Hash{0xff})
+	high := createVmLogTopicKey(topic, 11, types.Hash{})
+
+	if bytes.Compare(low, high) >= 0 {
+		t.Fatal("keys should be sorted by snapshot height")
+	}
+	if !bytes.HasPrefix(low, createVmLogTopicPrefixKey(topic)) {
+		t.Fatal("key should have the topic key as prefix")
+	}
+	if bytes.Equal(createVmLogAddrPrefixKey(types.Address{1}), createVmLogTopicPrefixKey(types.Hash{1})[:1+types.AddressSize]) {
+		t.Fatal("address key and topic key should have different prefixes")
+	}
+}
@@ -0,0 +1,126 @@
+package chain
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const (
+	defaultPruneRetain = 24 * 3600
+
+	// the snapshot blocks which may be rolled back by the redo log must not be pruned
+	minPruneRetain = 1200
+
+	pruneInterval = time.Minute
+
+	// the max count of the block files removed by a round of pruning
+	maxPruneFiles = 10
+)
+
+func (c *chain) GetPrunedSnapshotHeight() uint64 {
+	return c.blockDB.PrunedSnapshotHeight()
+}
+
+func (c *chain) pruneRetain() uint64 {
+	retain := c.chainCfg.LedgerPruneRetain
+	if retain <= 0 {
+		retain = defaultPruneRetain
+	}
+	if retain < minPruneRetain {
+		retain = minPruneRetain
+	}
+	return retain
+}
+
+func (c *chain) startPrune() {
+	c.pruneTerminal = make(chan struct{})
+
+	c.pruneWg.Add(1)
+	go func() {
+		defer c.pruneWg.Done()
+
+		ticker := time.NewTicker(pruneInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-c.pruneTerminal:
+				return
+			case <-ticker.C:
+				if err := c.prune(); err != nil {
+					c.log.Error(fmt.Sprintf("c.prune failed. Error: %s", err), "method", "startPrune")
+				}
+			}
+		}
+	}()
+}
+
+func (c *chain) stopPrune() {
+	close(c.pruneTerminal)
+	c.pruneWg.Wait()
+}
+
+// prune removes the block files lower than the file of the snapshot block of latest height - retain
+func (c *chain) prune() error {
+	retain := c.pruneRetain()
+
+	latestHeight := c.GetLatestSnapshotBlock().Height
+	if latestHeight <= retain {
+		return nil
+	}
+	targetHeight := latestHeight - retain
+
+	c.flushMu.RLock()
+	defer c.flushMu.RUnlock()
+
+	location, err := c.indexDB.GetSnapshotBlockLocation(targetHeight)
+	if err != nil {
+		return errors.New(fmt.Sprintf("c.indexDB.GetSnapshotBlockLocation failed, height is %d. Error: %s", targetHeight, err))
+	}
+	if location == nil {
+		return nil
+	}
+
+	count, err := c.blockDB.Prune(location.FileId, maxPruneFiles, c.isRetainedAccountBlock)
+	if err != nil {
+		return errors.New(fmt.Sprintf("c.blockDB.Prune failed, file id is %d. Error: %s", location.FileId, err))
+	}
+	if count > 0 {
+		c.log.Info(fmt.Sprintf("prune %d block files, pruned snapshot height is %d", count, c.blockDB.PrunedSnapshotHeight()), "method", "prune")
+	}
+	return nil
+}
+
+// isRetainedAccountBlock returns true if the account block is still needed to insert or verify new blocks:
+// the genesis blocks, the latest block of every account and the blocks related to the unreceived send blocks.
+func (c *chain) isRetainedAccountBlock(block *ledger.AccountBlock) (bool, error) {
+	if c.IsGenesisAccountBlock(block.Hash) {
+		return true, nil
+	}
+
+	latestHeight, _, err := c.indexDB.GetLatestAccountBlock(&block.AccountAddress)
+	if err != nil {
+		return false, err
+	}
+	if latestHeight == block.Height {
+		return true, nil
+	}
+
+	sendBlocks := block.SendBlockList
+	if block.IsSendBlock() {
+		sendBlocks = []*ledger.AccountBlock{block}
+	}
+	for _, sendBlock := range sendBlocks {
+		received, err := c.indexDB.IsReceived(&sendBlock.Hash)
+		if err != nil {
+			return false, err
+		}
+		if !received {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -42,6 +42,14 @@ func (c *chain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
 	return c.cache.GetLatestSnapshotBlock()
 }
 
+// latestSnapshotHeight returns 0 before the genesis snapshot block is inserted
+func (c *chain) latestSnapshotHeight() uint64 {
+	if sb := c.GetLatestSnapshotBlock(); sb != nil {
+		return sb.Height
+	}
+	return 0
+}
+
 func (c *chain) GetSnapshotHeightByHash(hash types.Hash) (uint64, error) {
 	// cache
 	if header := c.cache.GetSnapshotHeaderByHash(hash); header != nil {
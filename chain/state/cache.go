@@ -47,7 +47,9 @@ func (sDB *StateDB) enableCache() {
 }
 
 func (sDB *StateDB) initSnapshotValueCache() error {
-	for _, contractAddr := range types.BuiltinContractAddrList {
+	// the storage of fork builtin contracts is cached even before the fork point, they are read by the address pattern
+	contractAddrList := append(append([]types.Address{}, types.BuiltinContractAddrList...), types.ForkBuiltinContractAddrList...)
+	for _, contractAddr := range contractAddrList {
 
 		iter := sDB.NewStorageIterator(&contractAddr, nil)
 		for iter.Next() {
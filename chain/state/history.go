@@ -0,0 +1,67 @@
+package chain_state
+
+import (
+	"encoding/binary"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/chain/utils"
+	"github.com/vitelabs/go-vite/common/db/xleveldb"
+	"github.com/vitelabs/go-vite/common/db/xleveldb/util"
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// balance of the account after the snapshot block of snapshotHeight was inserted
+func (sDB *StateDB) GetSnapshotBalance(snapshotHeight uint64, addr types.Address, tokenTypeId types.TokenTypeId) (*big.Int, error) {
+	startKey := chain_utils.CreateHistoryBalanceKey(addr, tokenTypeId, 0)
+	endKey := chain_utils.CreateHistoryBalanceKey(addr, tokenTypeId, snapshotHeight+1)
+
+	iter := sDB.store.NewIterator(&util.Range{Start: startKey, Limit: endKey})
+	defer iter.Release()
+
+	if iter.Last() {
+		return big.NewInt(0).SetBytes(iter.Value()), nil
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+
+	return big.NewInt(0), nil
+}
+
+// balance map of the account after the snapshot block of snapshotHeight was inserted
+func (sDB *StateDB) GetSnapshotBalanceMap(snapshotHeight uint64, addr types.Address) (map[types.TokenTypeId]*big.Int, error) {
+	prefix := make([]byte, 1+types.AddressSize)
+	prefix[0] = chain_utils.BalanceHistoryKeyPrefix
+	copy(prefix[1:], addr.Bytes())
+
+	iter := sDB.store.NewIterator(util.BytesPrefix(prefix))
+	defer iter.Release()
+
+	balanceMap := make(map[types.TokenTypeId]*big.Int)
+
+	// keys are sorted by token id and then by snapshot height, so the last matched key of a token is the latest balance
+	for iter.Next() {
+		key := iter.Key()
+		if binary.BigEndian.Uint64(key[len(key)-8:]) > snapshotHeight {
+			continue
+		}
+
+		tokenTypeId, err := types.BytesToTokenTypeId(key[1+types.AddressSize : 1+types.AddressSize+types.TokenTypeIdSize])
+		if err != nil {
+			return nil, err
+		}
+		balanceMap[tokenTypeId] = big.NewInt(0).SetBytes(iter.Value())
+	}
+
+	if err := iter.Error(); err != nil && err != leveldb.ErrNotFound {
+		return nil, err
+	}
+
+	return balanceMap, nil
+}
+
+// redo log of the snapshot block, returns false if the log of snapshotHeight has been discarded
+func (sDB *StateDB) GetSnapshotLog(snapshotHeight uint64) (SnapshotLog, bool, error) {
+	return sDB.redo.QueryLog(snapshotHeight)
+}
@@ -40,6 +40,10 @@ func testState(t *testing.T, chainInstance *chain, accounts map[types.Address]*A
 		GetConfirmedBalanceList(chainInstance, accounts, snapshotBlocks)
 	})
 
+	t.Run("GetSnapshotBalance", func(t *testing.T) {
+		GetSnapshotBalance(chainInstance, accounts, snapshotBlocks)
+	})
+
 	t.Run("GetContractMeta", func(t *testing.T) {
 		GetContractMeta(chainInstance, accounts)
 	})
@@ -73,6 +77,8 @@ func testStateNoTesting(chainInstance *chain, accounts map[types.Address]*Accoun
 
 	GetConfirmedBalanceList(chainInstance, accounts, snapshotBlocks)
 
+	GetSnapshotBalance(chainInstance, accounts, snapshotBlocks)
+
 	GetContractMeta(chainInstance, accounts)
 
 	GetContractCode(chainInstance, accounts)
@@ -172,6 +178,47 @@ func GetConfirmedBalanceList(chainInstance *chain, accounts map[types.Address]*A
 	}
 }
 
+func GetSnapshotBalance(chainInstance *chain, accounts map[types.Address]*Account, snapshotBlocks []*ledger.SnapshotBlock) {
+	var addrList []types.Address
+	for addr := range accounts {
+		addrList = append(addrList, addr)
+	}
+
+	for _, snapshotBlock := range snapshotBlocks {
+		confirmedBalanceMap, err := chainInstance.GetConfirmedBalanceList(addrList, ledger.ViteTokenId, snapshotBlock.Hash)
+		if err != nil {
+			panic(err)
+		}
+
+		for _, addr := range addrList {
+			expected := confirmedBalanceMap[addr]
+			if expected == nil {
+				expected = big.NewInt(0)
+			}
+
+			balance, err := chainInstance.GetSnapshotBalance(snapshotBlock.Height, addr, ledger.ViteTokenId)
+			if err != nil {
+				panic(err)
+			}
+			if balance.Cmp(expected) != 0 {
+				panic(fmt.Sprintf("snapshotBlock %d, addr: %s, snapshotBalance: %d, confirmedBalance: %d", snapshotBlock.Height, addr, balance, expected))
+			}
+
+			balanceMap, err := chainInstance.GetSnapshotBalanceMap(snapshotBlock.Height, addr)
+			if err != nil {
+				panic(err)
+			}
+			balance = balanceMap[ledger.ViteTokenId]
+			if balance == nil {
+				balance = big.NewInt(0)
+			}
+			if balance.Cmp(expected) != 0 {
+				panic(fmt.Sprintf("snapshotBlock %d, addr: %s, snapshotBalanceMap: %d, confirmedBalance: %d", snapshotBlock.Height, addr, balance, expected))
+			}
+		}
+	}
+}
+
 func GetContractCode(chainInstance *chain, accounts map[types.Address]*Account) {
 	for _, account := range accounts {
 		code, err := chainInstance.GetContractCode(account.Addr)
@@ -0,0 +1,112 @@
+package chain_stream
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+const (
+	EventSnapshotBlock = "snapshotBlock"
+	EventAccountBlock  = "accountBlock"
+	EventVmLog         = "vmLog"
+)
+
+// Offset is the deterministic position of an event, the events of a snapshot height are indexed from 0,
+// the snapshot block first, then every confirmed account block followed by its vm logs.
+type Offset struct {
+	Height uint64 `json:"height"`
+	Index  uint32 `json:"index"`
+}
+
+func (o Offset) String() string {
+	return fmt.Sprintf("%d.%d", o.Height, o.Index)
+}
+
+// Event is published to the broker as json, a revert event has the same offset and content as the event it reverts.
+type Event struct {
+	Offset Offset `json:"offset"`
+	Type   string `json:"type"`
+	Revert bool   `json:"revert"`
+
+	SnapshotBlock *ledger.SnapshotBlock `json:"snapshotBlock,omitempty"`
+	AccountBlock  *ledger.AccountBlock  `json:"accountBlock,omitempty"`
+
+	// the account block which emits the vm log
+	BlockHash *types.Hash   `json:"blockHash,omitempty"`
+	VmLog     *ledger.VmLog `json:"vmLog,omitempty"`
+}
+
+// Message is the unit sent to the broker
+type Message struct {
+	Key   []byte
+	Value []byte
+}
+
+type logGetter func(block *ledger.AccountBlock) ledger.VmLogList
+
+// chunkEvents returns the events of a confirmed snapshot chunk in the order of offset
+func chunkEvents(chunk *ledger.SnapshotChunk, getLogs logGetter) []*Event {
+	height := chunk.SnapshotBlock.Height
+	var index uint32
+
+	events := make([]*Event, 0, 1+len(chunk.AccountBlocks))
+	events = append(events, &Event{
+		Offset:        Offset{height, index},
+		Type:          EventSnapshotBlock,
+		SnapshotBlock: chunk.SnapshotBlock,
+	})
+
+	for _, block := range chunk.AccountBlocks {
+		index++
+		events = append(events, &Event{
+			Offset:       Offset{height, index},
+			Type:         EventAccountBlock,
+			AccountBlock: block,
+		})
+
+		if block.LogHash == nil {
+			continue
+		}
+		hash := block.Hash
+		for _, vmLog := range getLogs(block) {
+			index++
+			events = append(events, &Event{
+				Offset:    Offset{height, index},
+				Type:      EventVmLog,
+				BlockHash: &hash,
+				VmLog:     vmLog,
+			})
+		}
+	}
+
+	return events
+}
+
+// revertEvents returns the revert events in the reverse order of the inserted events
+func revertEvents(events []*Event) []*Event {
+	reverts := make([]*Event, len(events))
+	for i, e := range events {
+		revert := *e
+		revert.Revert = true
+		reverts[len(events)-1-i] = &revert
+	}
+	return reverts
+}
+
+func eventMessages(events []*Event) ([]*Message, error) {
+	msgs := make([]*Message, len(events))
+	for i, e := range events {
+		value, err := json.Marshal(e)
+		if err != nil {
+			return nil, err
+		}
+		msgs[i] = &Message{
+			Key:   []byte(e.Offset.String()),
+			Value: value,
+		}
+	}
+	return msgs, nil
+}
@@ -0,0 +1,425 @@
+package chain_stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	kafkaClientId       = "gvite"
+	kafkaApiProduce     = 0
+	kafkaProduceV3      = 3
+	kafkaApiMetadata    = 3
+	kafkaMetadataV0     = 0
+	kafkaAcksAll        = -1
+	kafkaDialTimeout    = 5 * time.Second
+	kafkaIOTimeout      = 15 * time.Second
+	kafkaProduceTimeout = 10 * time.Second
+	kafkaMaxResponse    = 1 << 20
+	kafkaMaxAttempts    = 3
+	kafkaRetryBackoff   = 100 * time.Millisecond
+)
+
+const (
+	kafkaUnknownTopicOrPartition kafkaError = 3
+	kafkaLeaderNotAvailable      kafkaError = 5
+	kafkaNotLeaderForPartition   kafkaError = 6
+	kafkaNotEnoughReplicas       kafkaError = 19
+)
+
+var castagnoli = crc32.MakeTable(crc32.Castagnoli)
+
+// ParseKafkaProducer parses the producer config "broker1,broker2,...|topic"
+func ParseKafkaProducer(spec string) (brokers []string, topic string, err error) {
+	parts := strings.Split(spec, "|")
+	if len(parts) != 2 {
+		return nil, "", errors.New(fmt.Sprintf("wrong kafka producer %q, should be broker1,broker2,...|topic", spec))
+	}
+
+	topic = strings.TrimSpace(parts[1])
+	if topic == "" {
+		return nil, "", errors.New(fmt.Sprintf("missing topic of kafka producer %q", spec))
+	}
+
+	for _, broker := range strings.Split(parts[0], ",") {
+		if broker = strings.TrimSpace(broker); broker != "" {
+			brokers = append(brokers, broker)
+		}
+	}
+	if len(brokers) == 0 {
+		return nil, "", errors.New(fmt.Sprintf("missing brokers of kafka producer %q", spec))
+	}
+
+	return
+}
+
+// kafkaProducer writes the messages to the partition 0 of the topic with the Produce API v3, one partition keeps
+// the order of the messages. The leader of the partition is found by the Metadata API v0 from the brokers.
+//
+// A batch is sent again only if the broker answers it's not appended, such as the leader is changed. The batch
+// may be appended if the response is lost, the error is returned and the caller decides whether to send it again.
+type kafkaProducer struct {
+	brokers []string
+	topic   string
+
+	leader        string // address of the leader of partition 0, empty if unknown
+	conn          net.Conn
+	correlationId int32
+}
+
+// kafkaError is the error code in the responses
+type kafkaError int16
+
+func (e kafkaError) Error() string {
+	return fmt.Sprintf("kafka error code %d", int16(e))
+}
+
+// the records are not appended on these errors, it's safe to send them again
+var kafkaRetriableErrors = map[kafkaError]bool{
+	kafkaUnknownTopicOrPartition: true,
+	kafkaLeaderNotAvailable:      true,
+	kafkaNotLeaderForPartition:   true,
+	kafkaNotEnoughReplicas:       true,
+}
+
+// NewKafkaProducer returns a Producer connects to the brokers lazily.
+// Send and Close must not be called concurrently.
+func NewKafkaProducer(brokers []string, topic string) Producer {
+	return &kafkaProducer{
+		brokers: brokers,
+		topic:   topic,
+	}
+}
+
+func (k *kafkaProducer) Send(msgs []*Message) (err error) {
+	if len(msgs) == 0 {
+		return nil
+	}
+
+	body := k.produceBody(encodeRecordBatch(msgs, time.Now().UnixNano()/int64(time.Millisecond)))
+
+	for i := 0; i < kafkaMaxAttempts; i++ {
+		if i > 0 {
+			time.Sleep(kafkaRetryBackoff)
+		}
+
+		// nothing is sent if the leader is not connected
+		if err = k.connectLeader(); err != nil {
+			continue
+		}
+
+		var resp []byte
+		if resp, err = k.request(k.conn, kafkaApiProduce, kafkaProduceV3, body); err == nil {
+			if err = parseProduceResponse(resp); err == nil {
+				return nil
+			}
+		}
+
+		k.closeConn()
+		if code, ok := err.(kafkaError); !ok || !kafkaRetriableErrors[code] {
+			return
+		}
+	}
+
+	return
+}
+
+func (k *kafkaProducer) Close() error {
+	k.closeConn()
+	return nil
+}
+
+// closeConn closes the connection to the leader, the leader is found again before the next request
+func (k *kafkaProducer) closeConn() {
+	if k.conn != nil {
+		_ = k.conn.Close()
+		k.conn = nil
+	}
+	k.leader = ""
+}
+
+func (k *kafkaProducer) connectLeader() (err error) {
+	if k.conn != nil {
+		return nil
+	}
+
+	if k.leader, err = k.findLeader(); err != nil {
+		return
+	}
+	if k.conn, err = net.DialTimeout("tcp", k.leader, kafkaDialTimeout); err != nil {
+		k.leader = ""
+	}
+	return
+}
+
+// findLeader asks the brokers in turn for the leader of partition 0
+func (k *kafkaProducer) findLeader() (leader string, err error) {
+	var body bytes.Buffer
+	writeInt32(&body, 1) // topics
+	writeString(&body, k.topic)
+
+	for _, broker := range k.brokers {
+		var conn net.Conn
+		if conn, err = net.DialTimeout("tcp", broker, kafkaDialTimeout); err != nil {
+			continue
+		}
+
+		var resp []byte
+		resp, err = k.request(conn, kafkaApiMetadata, kafkaMetadataV0, body.Bytes())
+		_ = conn.Close()
+		if err != nil {
+			continue
+		}
+		if leader, err = parseMetadataResponse(resp, k.topic); err == nil {
+			return
+		}
+	}
+
+	return "", errors.New(fmt.Sprintf("find the leader of kafka topic %s failed. Error: %v", k.topic, err))
+}
+
+// request writes the request and returns the response after the correlation id
+func (k *kafkaProducer) request(conn net.Conn, apiKey, apiVersion int16, body []byte) (resp []byte, err error) {
+	k.correlationId++
+	correlationId := k.correlationId
+
+	var req bytes.Buffer
+	writeInt32(&req, int32(2+2+4+2+len(kafkaClientId)+len(body)))
+	writeInt16(&req, apiKey)
+	writeInt16(&req, apiVersion)
+	writeInt32(&req, correlationId)
+	writeString(&req, kafkaClientId)
+	req.Write(body)
+
+	if err = conn.SetDeadline(time.Now().Add(kafkaIOTimeout)); err != nil {
+		return
+	}
+	if _, err = conn.Write(req.Bytes()); err != nil {
+		return
+	}
+
+	var head [4]byte
+	if _, err = io.ReadFull(conn, head[:]); err != nil {
+		return
+	}
+	size := binary.BigEndian.Uint32(head[:])
+	if size < 4 || size > kafkaMaxResponse {
+		return nil, errors.New(fmt.Sprintf("wrong kafka response size: %d", size))
+	}
+	resp = make([]byte, size)
+	if _, err = io.ReadFull(conn, resp); err != nil {
+		return
+	}
+
+	if id := int32(binary.BigEndian.Uint32(resp)); id != correlationId {
+		return nil, errors.New(fmt.Sprintf("wrong kafka correlation id %d, should be %d", id, correlationId))
+	}
+	return resp[4:], nil
+}
+
+func (k *kafkaProducer) produceBody(batch []byte) []byte {
+	var buf bytes.Buffer
+	writeInt16(&buf, -1) // null transactional id
+	writeInt16(&buf, kafkaAcksAll)
+	writeInt32(&buf, int32(kafkaProduceTimeout/time.Millisecond))
+	writeInt32(&buf, 1) // topics
+	writeString(&buf, k.topic)
+	writeInt32(&buf, 1) // partitions
+	writeInt32(&buf, 0) // partition index
+	writeInt32(&buf, int32(len(batch)))
+	buf.Write(batch)
+	return buf.Bytes()
+}
+
+// encodeRecordBatch encodes the messages to a RecordBatch of magic 2
+func encodeRecordBatch(msgs []*Message, timestamp int64) []byte {
+	var records bytes.Buffer
+	for i, msg := range msgs {
+		var rec bytes.Buffer
+		rec.WriteByte(0) // attributes
+		writeVarint(&rec, 0)
+		writeVarint(&rec, int64(i))
+		writeVarint(&rec, int64(len(msg.Key)))
+		rec.Write(msg.Key)
+		writeVarint(&rec, int64(len(msg.Value)))
+		rec.Write(msg.Value)
+		writeVarint(&rec, 0) // headers
+
+		writeVarint(&records, int64(rec.Len()))
+		records.Write(rec.Bytes())
+	}
+
+	// the crc covers the bytes from attributes to the end
+	var body bytes.Buffer
+	writeInt16(&body, 0) // attributes
+	writeInt32(&body, int32(len(msgs)-1))
+	writeInt64(&body, timestamp)
+	writeInt64(&body, timestamp)
+	writeInt64(&body, -1) // producer id
+	writeInt16(&body, -1) // producer epoch
+	writeInt32(&body, -1) // base sequence
+	writeInt32(&body, int32(len(msgs)))
+	body.Write(records.Bytes())
+
+	var batch bytes.Buffer
+	writeInt64(&batch, 0)                       // base offset
+	writeInt32(&batch, int32(4+1+4+body.Len())) // batch length
+	writeInt32(&batch, -1)                      // partition leader epoch
+	batch.WriteByte(2)                          // magic
+	writeInt32(&batch, int32(crc32.Checksum(body.Bytes(), castagnoli)))
+	batch.Write(body.Bytes())
+
+	return batch.Bytes()
+}
+
+func parseProduceResponse(resp []byte) error {
+	r := &kafkaReader{buf: resp}
+
+	for topics := r.int32(); topics > 0; topics-- {
+		r.string()
+		for partitions := r.int32(); partitions > 0; partitions-- {
+			r.int32() // partition index
+			code := r.int16()
+			r.int64() // base offset
+			r.int64() // log append time
+			if r.err == nil && code != 0 {
+				return kafkaError(code)
+			}
+		}
+	}
+
+	return r.err
+}
+
+// parseMetadataResponse returns the address of the leader of partition 0
+func parseMetadataResponse(resp []byte, topic string) (string, error) {
+	r := &kafkaReader{buf: resp}
+
+	brokers := make(map[int32]string)
+	for n := r.int32(); n > 0 && r.err == nil; n-- {
+		nodeId := r.int32()
+		host := r.string()
+		port := r.int32()
+		brokers[nodeId] = net.JoinHostPort(host, strconv.Itoa(int(port)))
+	}
+
+	for topics := r.int32(); topics > 0 && r.err == nil; topics-- {
+		topicCode := r.int16()
+		name := r.string()
+		for partitions := r.int32(); partitions > 0 && r.err == nil; partitions-- {
+			code := r.int16()
+			index := r.int32()
+			leader := r.int32()
+			for replicas := r.int32(); replicas > 0 && r.err == nil; replicas-- {
+				r.int32()
+			}
+			for isr := r.int32(); isr > 0 && r.err == nil; isr-- {
+				r.int32()
+			}
+			if r.err != nil || name != topic || index != 0 {
+				continue
+			}
+
+			if code != 0 {
+				return "", kafkaError(code)
+			}
+			if addr, ok := brokers[leader]; ok {
+				return addr, nil
+			}
+			return "", kafkaLeaderNotAvailable
+		}
+		if r.err == nil && name == topic && topicCode != 0 {
+			return "", kafkaError(topicCode)
+		}
+	}
+
+	if r.err != nil {
+		return "", r.err
+	}
+	return "", kafkaUnknownTopicOrPartition
+}
+
+type kafkaReader struct {
+	buf []byte
+	err error
+}
+
+func (r *kafkaReader) next(n int) []byte {
+	if r.err != nil {
+		return nil
+	}
+	if len(r.buf) < n {
+		r.err = errors.New("kafka response is too short")
+		return nil
+	}
+	b := r.buf[:n]
+	r.buf = r.buf[n:]
+	return b
+}
+
+func (r *kafkaReader) int16() int16 {
+	if b := r.next(2); b != nil {
+		return int16(binary.BigEndian.Uint16(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int32() int32 {
+	if b := r.next(4); b != nil {
+		return int32(binary.BigEndian.Uint32(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) int64() int64 {
+	if b := r.next(8); b != nil {
+		return int64(binary.BigEndian.Uint64(b))
+	}
+	return 0
+}
+
+func (r *kafkaReader) string() string {
+	n := r.int16()
+	if n < 0 {
+		return ""
+	}
+	return string(r.next(int(n)))
+}
+
+func writeInt16(buf *bytes.Buffer, v int16) {
+	var b [2]byte
+	binary.BigEndian.PutUint16(b[:], uint16(v))
+	buf.Write(b[:])
+}
+
+func writeInt32(buf *bytes.Buffer, v int32) {
+	var b [4]byte
+	binary.BigEndian.PutUint32(b[:], uint32(v))
+	buf.Write(b[:])
+}
+
+func writeInt64(buf *bytes.Buffer, v int64) {
+	var b [8]byte
+	binary.BigEndian.PutUint64(b[:], uint64(v))
+	buf.Write(b[:])
+}
+
+func writeString(buf *bytes.Buffer, s string) {
+	writeInt16(buf, int16(len(s)))
+	buf.WriteString(s)
+}
+
+// writeVarint writes the zigzag varint used in the records
+func writeVarint(buf *bytes.Buffer, v int64) {
+	var b [binary.MaxVarintLen64]byte
+	n := binary.PutVarint(b[:], v)
+	buf.Write(b[:n])
+}
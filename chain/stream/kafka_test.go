@@ -0,0 +1,204 @@
+package chain_stream
+
+import (
+	"bytes"
+	"encoding/binary"
+	"hash/crc32"
+	"io"
+	"net"
+	"strconv"
+	"testing"
+)
+
+func TestParseKafkaProducer(t *testing.T) {
+	brokers, topic, err := ParseKafkaProducer("127.0.0.1:9092, 127.0.0.2:9092|ledger")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(brokers) != 2 || brokers[1] != "127.0.0.2:9092" || topic != "ledger" {
+		t.Errorf("wrong producer %v %s", brokers, topic)
+	}
+
+	for _, spec := range []string{"127.0.0.1:9092", "|ledger", "127.0.0.1:9092|"} {
+		if _, _, err = ParseKafkaProducer(spec); err == nil {
+			t.Errorf("%q should be invalid", spec)
+		}
+	}
+}
+
+// fakeBroker answers the metadata with itself as the leader of partition 0, and the produce requests with the codes in turn
+type fakeBroker struct {
+	ln      net.Listener
+	codes   chan int16 // -1 means no response
+	batches chan []byte
+}
+
+func newFakeBroker(t *testing.T, codes ...int16) *fakeBroker {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := &fakeBroker{ln: ln, codes: make(chan int16, len(codes)), batches: make(chan []byte, 10)}
+	for _, code := range codes {
+		b.codes <- code
+	}
+	go b.loop()
+	return b
+}
+
+func (b *fakeBroker) loop() {
+	for {
+		conn, err := b.ln.Accept()
+		if err != nil {
+			return
+		}
+		go b.serve(conn)
+	}
+}
+
+func (b *fakeBroker) serve(conn net.Conn) {
+	defer conn.Close()
+	for {
+		var head [4]byte
+		if _, err := io.ReadFull(conn, head[:]); err != nil {
+			return
+		}
+		req := make([]byte, binary.BigEndian.Uint32(head[:]))
+		if _, err := io.ReadFull(conn, req); err != nil {
+			return
+		}
+
+		r := &kafkaReader{buf: req}
+		apiKey, version, id := r.int16(), r.int16(), r.int32()
+		r.string() // client id
+
+		var resp bytes.Buffer
+		writeInt32(&resp, id)
+		switch {
+		case apiKey == kafkaApiMetadata && version == kafkaMetadataV0:
+			host, port, _ := net.SplitHostPort(b.ln.Addr().String())
+			portNum, _ := strconv.Atoi(port)
+			writeInt32(&resp, 1) // brokers
+			writeInt32(&resp, 7)
+			writeString(&resp, host)
+			writeInt32(&resp, int32(portNum))
+			writeInt32(&resp, 1) // topics
+			writeInt16(&resp, 0)
+			writeString(&resp, "ledger")
+			writeInt32(&resp, 1) // partitions
+			writeInt16(&resp, 0)
+			writeInt32(&resp, 0)
+			writeInt32(&resp, 7) // leader
+			writeInt32(&resp, 1) // replicas
+			writeInt32(&resp, 7)
+			writeInt32(&resp, 1) // isr
+			writeInt32(&resp, 7)
+		case apiKey == kafkaApiProduce && version == kafkaProduceV3:
+			r.string() // transactional id
+			r.int16()  // acks
+			r.int32()  // timeout
+			r.int32()  // topics
+			r.string()
+			r.int32() // partitions
+			r.int32()
+			b.batches <- r.next(int(r.int32()))
+
+			var code int16
+			select {
+			case code = <-b.codes:
+			default:
+			}
+			if code < 0 {
+				return
+			}
+			writeInt32(&resp, 1)
+			writeString(&resp, "ledger")
+			writeInt32(&resp, 1)
+			writeInt32(&resp, 0)
+			writeInt16(&resp, code)
+			writeInt64(&resp, 100)
+			writeInt64(&resp, -1)
+		default:
+			panic("wrong api")
+		}
+
+		var msg bytes.Buffer
+		writeInt32(&msg, int32(resp.Len()))
+		msg.Write(resp.Bytes())
+		conn.Write(msg.Bytes())
+	}
+}
+
+func TestKafkaProducer_Send(t *testing.T) {
+	broker := newFakeBroker(t)
+	defer broker.ln.Close()
+
+	// the first broker is unreachable, the leader is found by the second one
+	unreachable, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	unreachable.Close()
+
+	producer := NewKafkaProducer([]string{unreachable.Addr().String(), broker.ln.Addr().String()}, "ledger")
+	defer producer.Close()
+
+	msgs := []*Message{
+		{Key: []byte("1.0"), Value: []byte("hello")},
+		{Key: []byte("1.1"), Value: []byte("world")},
+	}
+	if err = producer.Send(msgs); err != nil {
+		t.Fatal(err)
+	}
+
+	batch := <-broker.batches
+	if batch[16] != 2 {
+		t.Errorf("wrong magic %d", batch[16])
+	}
+	crc := binary.BigEndian.Uint32(batch[17:21])
+	if crc != crc32.Checksum(batch[21:], castagnoli) {
+		t.Errorf("wrong crc")
+	}
+	if count := binary.BigEndian.Uint32(batch[57:61]); count != uint32(len(msgs)) {
+		t.Errorf("wrong record count %d", count)
+	}
+	if !bytes.Contains(batch, []byte("world")) {
+		t.Errorf("missing record value")
+	}
+}
+
+func TestKafkaProducer_Retry(t *testing.T) {
+	msgs := []*Message{{Key: []byte("1.0"), Value: []byte("hello")}}
+
+	// the batch is not appended by the broker which is not the leader, it's sent again
+	broker := newFakeBroker(t, int16(kafkaNotLeaderForPartition), 0)
+	producer := NewKafkaProducer([]string{broker.ln.Addr().String()}, "ledger")
+	if err := producer.Send(msgs); err != nil {
+		t.Fatal(err)
+	}
+	if n := len(broker.batches); n != 2 {
+		t.Errorf("the batch should be sent twice, but %d", n)
+	}
+	producer.Close()
+	broker.ln.Close()
+
+	// the batch may be appended if the response is lost, it's not sent again
+	broker = newFakeBroker(t, -1, 2)
+	producer = NewKafkaProducer([]string{broker.ln.Addr().String()}, "ledger")
+	if err := producer.Send(msgs); err == nil {
+		t.Error("the lost response should fail")
+	}
+	if n := len(broker.batches); n != 1 {
+		t.Errorf("the batch should be sent once, but %d", n)
+	}
+
+	// the unknown error is not retried
+	if err := producer.Send(msgs); err != kafkaError(2) {
+		t.Errorf("wrong error %v", err)
+	}
+	if n := len(broker.batches); n != 2 {
+		t.Errorf("the batch should be sent once more, but %d", n-1)
+	}
+	producer.Close()
+	broker.ln.Close()
+}
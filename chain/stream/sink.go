@@ -0,0 +1,522 @@
+package chain_stream
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vm_db"
+)
+
+const (
+	// the inserted snapshot chunks are dropped if there are too many pending operations, they will be read from the chain later
+	maxPendingOps = 10000
+	// the snapshot heights read from the chain at a time when catch up
+	catchUpBatch  = 10
+	retryInterval = 3 * time.Second
+	// the events of the recent published snapshot heights are kept to be reverted
+	maxRecentHeights = 600
+)
+
+// Producer publishes the messages to the broker
+type Producer interface {
+	// Send returns nil only if all the messages are acknowledged by the broker in order.
+	// The messages may be appended even if an error is returned, such as the acknowledgement is lost.
+	Send(msgs []*Message) error
+	Close() error
+}
+
+// Chain is the part of chain.Chain used by the Sink
+type Chain interface {
+	Register(listener chain.EventListener)
+	UnRegister(listener chain.EventListener)
+
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetSnapshotHeaderByHeight(height uint64) (*ledger.SnapshotBlock, error)
+	GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error)
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
+}
+
+// checkpoint is the last snapshot block acknowledged by the broker
+type checkpoint struct {
+	Height uint64     `json:"height"`
+	Hash   types.Hash `json:"hash"`
+}
+
+type sinkOp struct {
+	revert   bool
+	height   uint64
+	hash     types.Hash
+	prevHash types.Hash
+	events   []*Event
+}
+
+// Sink publishes the confirmed ledger to a Producer. Every snapshot height is published after it's
+// inserted, with the snapshot block, the account blocks confirmed by it and their vm logs. The deleted
+// snapshot heights are published as revert events in the reverse order.
+//
+// The last acknowledged snapshot block is saved to the checkpoint file, the sink resumes from it after restart.
+// A new sink starts from the latest snapshot block.
+//
+// The events are published at least once, not exactly once. A failed Send is retried with the same events,
+// so the events of a snapshot height are published twice if they were appended but not acknowledged, and they
+// are published again after restart if the checkpoint failed to be saved. The consumers should
+// skip the duplicated events by the offset and the revert flag, otherwise a block may be seen more than once.
+type Sink struct {
+	chain          Chain
+	producer       Producer
+	checkpointPath string
+
+	// written by the chain events, read by the loop
+	mu              sync.Mutex
+	ops             []*sinkOp
+	reverts         []*sinkOp
+	unconfirmedLogs map[types.Hash]ledger.VmLogList
+	notify          chan struct{}
+
+	// only accessed by the loop
+	acked  checkpoint
+	recent map[uint64][]*Event
+
+	term chan struct{}
+	wg   sync.WaitGroup
+
+	log log15.Logger
+}
+
+func NewSink(c Chain, producer Producer, checkpointPath string) *Sink {
+	return &Sink{
+		chain:           c,
+		producer:        producer,
+		checkpointPath:  checkpointPath,
+		unconfirmedLogs: make(map[types.Hash]ledger.VmLogList),
+		recent:          make(map[uint64][]*Event),
+		notify:          make(chan struct{}, 1),
+		log:             log15.New("module", "chain_stream"),
+	}
+}
+
+func (s *Sink) Start() error {
+	if err := os.MkdirAll(filepath.Dir(s.checkpointPath), 0700); err != nil {
+		return err
+	}
+
+	cp, err := loadCheckpoint(s.checkpointPath)
+	if err != nil {
+		return errors.New(fmt.Sprintf("load checkpoint %s failed. Error: %s", s.checkpointPath, err))
+	}
+
+	if cp == nil {
+		latest := s.chain.GetLatestSnapshotBlock()
+		cp = &checkpoint{Height: latest.Height, Hash: latest.Hash}
+		if err = saveCheckpoint(cp, s.checkpointPath); err != nil {
+			return err
+		}
+	}
+	s.acked = *cp
+
+	s.term = make(chan struct{})
+	s.chain.Register(s)
+
+	s.wg.Add(1)
+	go s.loop()
+
+	s.log.Info(fmt.Sprintf("start from snapshot height %d", s.acked.Height), "method", "Start")
+	return nil
+}
+
+func (s *Sink) Stop() {
+	if s.term == nil {
+		return
+	}
+	s.chain.UnRegister(s)
+
+	close(s.term)
+	s.wg.Wait()
+
+	if err := s.producer.Close(); err != nil {
+		s.log.Warn(fmt.Sprintf("close producer failed. Error: %s", err), "method", "Stop")
+	}
+}
+
+func (s *Sink) loop() {
+	defer s.wg.Done()
+
+	s.checkFork()
+
+	ticker := time.NewTicker(retryInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := s.flush(); err != nil {
+			s.log.Warn(fmt.Sprintf("publish failed. Error: %s", err), "method", "loop")
+		}
+
+		select {
+		case <-s.term:
+			return
+		case <-s.notify:
+		case <-ticker.C:
+		}
+	}
+}
+
+// checkFork publishes the revert events for the checkpoint if it has been deleted from the chain while
+// the sink is not running, only the snapshot block hash and height are known in the events.
+func (s *Sink) checkFork() {
+	for s.acked.Height > 0 {
+		block, err := s.chain.GetSnapshotHeaderByHeight(s.acked.Height)
+		if err != nil {
+			s.log.Error(fmt.Sprintf("s.chain.GetSnapshotHeaderByHeight failed, height is %d. Error: %s", s.acked.Height, err), "method", "checkFork")
+			return
+		}
+		if block != nil && block.Hash == s.acked.Hash {
+			return
+		}
+
+		var prevHash types.Hash
+		prev, err := s.chain.GetSnapshotHeaderByHeight(s.acked.Height - 1)
+		if err != nil {
+			s.log.Error(fmt.Sprintf("s.chain.GetSnapshotHeaderByHeight failed, height is %d. Error: %s", s.acked.Height-1, err), "method", "checkFork")
+			return
+		}
+		if prev != nil {
+			prevHash = prev.Hash
+		}
+
+		s.log.Warn(fmt.Sprintf("snapshot block %d %s has been deleted", s.acked.Height, s.acked.Hash), "method", "checkFork")
+
+		events := revertEvents([]*Event{{
+			Offset: Offset{s.acked.Height, 0},
+			Type:   EventSnapshotBlock,
+			SnapshotBlock: &ledger.SnapshotBlock{
+				Hash:   s.acked.Hash,
+				Height: s.acked.Height,
+			},
+		}})
+
+		for {
+			if err = s.publish(events, s.acked.Height-1, prevHash); err == nil {
+				break
+			}
+			s.log.Warn(fmt.Sprintf("publish revert of %d failed. Error: %s", s.acked.Height, err), "method", "checkFork")
+
+			select {
+			case <-s.term:
+				return
+			case <-time.After(retryInterval):
+			}
+		}
+	}
+}
+
+// flush publishes the pending operations in order, the operation is kept if it failed.
+func (s *Sink) flush() error {
+	// the snapshot heights inserted before start or dropped at the tail
+	s.mu.Lock()
+	pending := len(s.ops)
+	s.mu.Unlock()
+	if pending == 0 {
+		if err := s.catchUp(s.chain.GetLatestSnapshotBlock().Height); err != nil {
+			return err
+		}
+	}
+
+	for {
+		select {
+		case <-s.term:
+			return nil
+		default:
+		}
+
+		s.mu.Lock()
+		if len(s.ops) == 0 {
+			s.mu.Unlock()
+			return nil
+		}
+		op := s.ops[0]
+		s.mu.Unlock()
+
+		if err := s.handle(op); err != nil {
+			return err
+		}
+
+		s.mu.Lock()
+		s.ops = s.ops[1:]
+		s.mu.Unlock()
+	}
+}
+
+func (s *Sink) handle(op *sinkOp) error {
+	if op.revert {
+		// the snapshot block is not published or it's another fork
+		if op.height != s.acked.Height || op.hash != s.acked.Hash {
+			return nil
+		}
+		// the account blocks of the chunk may be kept as unconfirmed blocks, revert the published events
+		events, ok := s.recent[op.height]
+		if !ok {
+			events = op.events
+		}
+		if err := s.publish(revertEvents(events), op.height-1, op.prevHash); err != nil {
+			return err
+		}
+		delete(s.recent, op.height)
+		return nil
+	}
+
+	if op.height <= s.acked.Height {
+		return nil
+	}
+	if op.height > s.acked.Height+1 {
+		if err := s.catchUp(op.height - 1); err != nil {
+			return err
+		}
+	}
+	// the snapshot block has been reverted, the revert operation is pending
+	if op.height != s.acked.Height+1 || op.prevHash != s.acked.Hash {
+		return nil
+	}
+	return s.publishInsert(op.events, op.height, op.hash)
+}
+
+// catchUp reads the snapshot heights which are not in the pending operations from the chain
+func (s *Sink) catchUp(toHeight uint64) error {
+	for s.acked.Height < toHeight {
+		select {
+		case <-s.term:
+			return nil
+		default:
+		}
+
+		end := s.acked.Height + catchUpBatch
+		if end > toHeight {
+			end = toHeight
+		}
+
+		chunks, err := s.chain.GetSubLedger(s.acked.Height, end)
+		if err != nil {
+			return errors.New(fmt.Sprintf("s.chain.GetSubLedger failed, startHeight is %d, endHeight is %d. Error: %s", s.acked.Height, end, err))
+		}
+
+		for _, chunk := range chunks {
+			sb := chunk.SnapshotBlock
+			if sb == nil || sb.Height <= s.acked.Height {
+				continue
+			}
+			// the chain has been forked, wait for the pending revert operations
+			if sb.Height != s.acked.Height+1 || sb.PrevHash != s.acked.Hash {
+				return nil
+			}
+
+			if err = s.publishInsert(chunkEvents(chunk, s.readLogs), sb.Height, sb.Hash); err != nil {
+				return err
+			}
+		}
+
+		if s.acked.Height < end {
+			return errors.New(fmt.Sprintf("snapshot height %d is missing in the chain", s.acked.Height+1))
+		}
+	}
+	return nil
+}
+
+func (s *Sink) publishInsert(events []*Event, height uint64, hash types.Hash) error {
+	if err := s.publish(events, height, hash); err != nil {
+		return err
+	}
+
+	s.recent[height] = events
+	if height > maxRecentHeights {
+		delete(s.recent, height-maxRecentHeights)
+	}
+	return nil
+}
+
+func (s *Sink) publish(events []*Event, height uint64, hash types.Hash) error {
+	msgs, err := eventMessages(events)
+	if err != nil {
+		return err
+	}
+	if err = s.producer.Send(msgs); err != nil {
+		return err
+	}
+
+	s.acked = checkpoint{Height: height, Hash: hash}
+	if err = saveCheckpoint(&s.acked, s.checkpointPath); err != nil {
+		s.log.Error(fmt.Sprintf("save checkpoint failed. Error: %s", err), "method", "publish")
+	}
+	return nil
+}
+
+func (s *Sink) readLogs(block *ledger.AccountBlock) ledger.VmLogList {
+	logs, err := s.chain.GetVmLogList(block.LogHash)
+	if err != nil {
+		s.log.Error(fmt.Sprintf("s.chain.GetVmLogList failed, hash is %s. Error: %s", block.Hash, err), "method", "readLogs")
+	}
+	return logs
+}
+
+// the logs of unconfirmed blocks are cached, so the chain isn't read when they are confirmed
+func (s *Sink) getLogs(block *ledger.AccountBlock) ledger.VmLogList {
+	if logs, ok := s.unconfirmedLogs[block.Hash]; ok {
+		delete(s.unconfirmedLogs, block.Hash)
+		return logs
+	}
+	return s.readLogs(block)
+}
+
+func (s *Sink) addOps(ops ...*sinkOp) {
+	s.mu.Lock()
+	s.ops = append(s.ops, ops...)
+	s.mu.Unlock()
+
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+func (s *Sink) PrepareInsertAccountBlocks(blocks []*vm_db.VmAccountBlock) error {
+	return nil
+}
+
+func (s *Sink) InsertAccountBlocks(blocks []*vm_db.VmAccountBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, block := range blocks {
+		if block.AccountBlock.LogHash != nil {
+			s.unconfirmedLogs[block.AccountBlock.Hash] = block.VmDb.GetLogList()
+		}
+	}
+	return nil
+}
+
+func (s *Sink) PrepareInsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	return nil
+}
+
+func (s *Sink) InsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	ops := make([]*sinkOp, 0, len(chunks))
+
+	s.mu.Lock()
+	for _, chunk := range chunks {
+		sb := chunk.SnapshotBlock
+		if sb == nil {
+			continue
+		}
+
+		if len(s.ops)+len(ops) >= maxPendingOps {
+			for _, block := range chunk.AccountBlocks {
+				delete(s.unconfirmedLogs, block.Hash)
+			}
+			continue
+		}
+
+		ops = append(ops, &sinkOp{
+			height:   sb.Height,
+			hash:     sb.Hash,
+			prevHash: sb.PrevHash,
+			events:   chunkEvents(chunk, s.getLogs),
+		})
+	}
+	s.mu.Unlock()
+
+	s.addOps(ops...)
+	return nil
+}
+
+func (s *Sink) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
+	return nil
+}
+
+func (s *Sink) DeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, block := range blocks {
+		delete(s.unconfirmedLogs, block.Hash)
+	}
+	return nil
+}
+
+// the revert events are built before the blocks are deleted, the vm logs can't be read after that
+func (s *Sink) PrepareDeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	reverts := make([]*sinkOp, 0, len(chunks))
+	for i := len(chunks) - 1; i >= 0; i-- {
+		sb := chunks[i].SnapshotBlock
+		if sb == nil {
+			continue
+		}
+		reverts = append(reverts, &sinkOp{
+			revert:   true,
+			height:   sb.Height,
+			hash:     sb.Hash,
+			prevHash: sb.PrevHash,
+			events:   chunkEvents(chunks[i], s.readLogs),
+		})
+	}
+
+	s.mu.Lock()
+	s.reverts = reverts
+	s.mu.Unlock()
+	return nil
+}
+
+func (s *Sink) DeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	s.mu.Lock()
+	reverts := s.reverts
+	s.reverts = nil
+
+	for _, chunk := range chunks {
+		if chunk.SnapshotBlock != nil {
+			continue
+		}
+		for _, block := range chunk.AccountBlocks {
+			delete(s.unconfirmedLogs, block.Hash)
+		}
+	}
+	s.mu.Unlock()
+
+	s.addOps(reverts...)
+	return nil
+}
+
+func loadCheckpoint(filename string) (*checkpoint, error) {
+	data, err := ioutil.ReadFile(filename)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	cp := &checkpoint{}
+	if err := json.Unmarshal(data, cp); err != nil {
+		return nil, err
+	}
+	return cp, nil
+}
+
+func saveCheckpoint(cp *checkpoint, filename string) error {
+	data, err := json.Marshal(cp)
+	if err != nil {
+		return err
+	}
+
+	tmpFilename := filename + ".tmp"
+	if err := ioutil.WriteFile(tmpFilename, data, 0600); err != nil {
+		return err
+	}
+	return os.Rename(tmpFilename, filename)
+}
@@ -0,0 +1,249 @@
+package chain_stream
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+type mockChain struct {
+	mu     sync.Mutex
+	chunks []*ledger.SnapshotChunk
+	logs   map[types.Hash]ledger.VmLogList
+}
+
+func newMockChain(height uint64) *mockChain {
+	c := &mockChain{
+		logs: make(map[types.Hash]ledger.VmLogList),
+	}
+	for i := uint64(1); i <= height; i++ {
+		c.insert(c.newChunk(byte(i), 1))
+	}
+	return c
+}
+
+// newChunk returns a chunk on top of the latest snapshot block, the fork byte makes the hash different
+func (c *mockChain) newChunk(fork byte, accountBlocks int) *ledger.SnapshotChunk {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	height := uint64(len(c.chunks)) + 1
+	sb := &ledger.SnapshotBlock{Height: height, Hash: types.Hash{byte(height), fork}}
+	if height > 1 {
+		sb.PrevHash = c.chunks[height-2].SnapshotBlock.Hash
+	}
+
+	chunk := &ledger.SnapshotChunk{SnapshotBlock: sb}
+	for i := 0; i < accountBlocks; i++ {
+		logHash := types.Hash{byte(height), fork, byte(i), 1}
+		chunk.AccountBlocks = append(chunk.AccountBlocks, &ledger.AccountBlock{
+			Hash:    types.Hash{byte(height), fork, byte(i)},
+			Height:  uint64(i + 1),
+			LogHash: &logHash,
+		})
+		c.logs[logHash] = ledger.VmLogList{{Data: []byte{byte(i)}}}
+	}
+	return chunk
+}
+
+func (c *mockChain) insert(chunk *ledger.SnapshotChunk) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.chunks = append(c.chunks, chunk)
+}
+
+func (c *mockChain) deleteTo(height uint64) []*ledger.SnapshotChunk {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	deleted := c.chunks[height:]
+	c.chunks = c.chunks[:height]
+	return deleted
+}
+
+func (c *mockChain) Register(listener chain.EventListener) {}
+
+func (c *mockChain) UnRegister(listener chain.EventListener) {}
+
+func (c *mockChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.chunks[len(c.chunks)-1].SnapshotBlock
+}
+
+func (c *mockChain) GetSnapshotHeaderByHeight(height uint64) (*ledger.SnapshotBlock, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height == 0 || height > uint64(len(c.chunks)) {
+		return nil, nil
+	}
+	return c.chunks[height-1].SnapshotBlock, nil
+}
+
+func (c *mockChain) GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if endHeight > uint64(len(c.chunks)) {
+		endHeight = uint64(len(c.chunks))
+	}
+	return c.chunks[startHeight:endHeight], nil
+}
+
+func (c *mockChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.logs[*logListHash], nil
+}
+
+type mockProducer struct {
+	mu     sync.Mutex
+	events []*Event
+}
+
+func (p *mockProducer) Send(msgs []*Message) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for _, msg := range msgs {
+		e := &Event{}
+		if err := json.Unmarshal(msg.Value, e); err != nil {
+			return err
+		}
+		if string(msg.Key) != e.Offset.String() {
+			panic("wrong message key")
+		}
+		p.events = append(p.events, e)
+	}
+	return nil
+}
+
+func (p *mockProducer) Close() error {
+	return nil
+}
+
+func (p *mockProducer) wait(t *testing.T, n int) []*Event {
+	for i := 0; i < 100; i++ {
+		p.mu.Lock()
+		if len(p.events) >= n {
+			events := p.events
+			p.mu.Unlock()
+			return events
+		}
+		p.mu.Unlock()
+		time.Sleep(20 * time.Millisecond)
+	}
+	t.Fatalf("wait %d events timeout", n)
+	return nil
+}
+
+type eventCase struct {
+	offset string
+	typ    string
+	revert bool
+}
+
+func checkEvents(t *testing.T, events []*Event, cases []eventCase) {
+	if len(events) != len(cases) {
+		t.Fatalf("%d events, should be %d", len(events), len(cases))
+	}
+	for i, c := range cases {
+		e := events[i]
+		if e.Offset.String() != c.offset || e.Type != c.typ || e.Revert != c.revert {
+			t.Errorf("event %d is %s %s %v, should be %s %s %v", i, e.Offset, e.Type, e.Revert, c.offset, c.typ, c.revert)
+		}
+	}
+}
+
+func newTestSink(t *testing.T, c *mockChain) (*Sink, *mockProducer, string) {
+	dir, err := ioutil.TempDir("", "stream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	producer := &mockProducer{}
+	return NewSink(c, producer, filepath.Join(dir, "topic.json")), producer, dir
+}
+
+func TestSink_InsertAndRevert(t *testing.T) {
+	c := newMockChain(2)
+	sink, producer, dir := newTestSink(t, c)
+	defer os.RemoveAll(dir)
+
+	if err := sink.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Stop()
+
+	chunk := c.newChunk(0, 2)
+	c.insert(chunk)
+	sink.InsertSnapshotBlocks([]*ledger.SnapshotChunk{chunk})
+
+	// the account blocks are kept as unconfirmed blocks
+	deleted := []*ledger.SnapshotChunk{{SnapshotBlock: chunk.SnapshotBlock}}
+	sink.PrepareDeleteSnapshotBlocks(deleted)
+	c.deleteTo(2)
+	sink.DeleteSnapshotBlocks(deleted)
+
+	fork := c.newChunk(1, 0)
+	c.insert(fork)
+	sink.InsertSnapshotBlocks([]*ledger.SnapshotChunk{fork})
+
+	checkEvents(t, producer.wait(t, 11), []eventCase{
+		{"3.0", EventSnapshotBlock, false},
+		{"3.1", EventAccountBlock, false},
+		{"3.2", EventVmLog, false},
+		{"3.3", EventAccountBlock, false},
+		{"3.4", EventVmLog, false},
+		{"3.4", EventVmLog, true},
+		{"3.3", EventAccountBlock, true},
+		{"3.2", EventVmLog, true},
+		{"3.1", EventAccountBlock, true},
+		{"3.0", EventSnapshotBlock, true},
+		{"3.0", EventSnapshotBlock, false},
+	})
+
+	cp, err := loadCheckpoint(sink.checkpointPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cp.Height != 3 || cp.Hash != fork.SnapshotBlock.Hash {
+		t.Errorf("wrong checkpoint %d %s", cp.Height, cp.Hash)
+	}
+}
+
+func TestSink_Resume(t *testing.T) {
+	c := newMockChain(4)
+	sink, producer, dir := newTestSink(t, c)
+	defer os.RemoveAll(dir)
+
+	// snapshot block 4 is deleted while the sink is not running
+	if err := saveCheckpoint(&checkpoint{Height: 4, Hash: types.Hash{4, 9}}, sink.checkpointPath); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := sink.Start(); err != nil {
+		t.Fatal(err)
+	}
+	defer sink.Stop()
+
+	chunk := c.newChunk(0, 0)
+	c.insert(chunk)
+	sink.InsertSnapshotBlocks([]*ledger.SnapshotChunk{chunk})
+
+	events := producer.wait(t, 5)
+	checkEvents(t, events, []eventCase{
+		{"4.0", EventSnapshotBlock, true},
+		{"4.0", EventSnapshotBlock, false},
+		{"4.1", EventAccountBlock, false},
+		{"4.2", EventVmLog, false},
+		{"5.0", EventSnapshotBlock, false},
+	})
+	if events[0].SnapshotBlock.Hash != (types.Hash{4, 9}) {
+		t.Errorf("wrong reverted hash %s", events[0].SnapshotBlock.Hash)
+	}
+}
@@ -0,0 +1,42 @@
+package gvite_plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	dnsTreeCommand = cli.Command{
+		Action:    utils.MigrateFlags(dnsTreeAction),
+		Name:      "dns-tree",
+		Usage:     "dns-tree --domain=nodes.example.org --treekeyfile=tree.key --records=records.json",
+		ArgsUsage: "--domain=nodes.example.org --treekeyfile=tree.key --crawl=2m --seq=1 --links=<url>,<url> --records=records.json",
+		Flags:     append(dnsTreeFlags, configFlags...),
+		Category:  "P2P COMMANDS",
+		Description: `
+Crawl the network from the boot nodes of the config, then sign the tree of the found nodes.
+The TXT records of the tree are emitted as json, key is the domain name, publish them in DNS
+and set the printed url to BootDNS of other nodes.
+The tree key file contains the ed25519 private key as hex, it must not be accessible by other users, e.g. chmod 600 tree.key.
+`,
+	}
+)
+
+func dnsTreeAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewDNSTreeNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
@@ -0,0 +1,69 @@
+package gvite_plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	ledgerExportCommand = cli.Command{
+		Action:    utils.MigrateFlags(ledgerExportAction),
+		Name:      "ledger-export",
+		Usage:     "ledger-export --archive=ledger.tar.gz --sbHeight=5000000",
+		ArgsUsage: "--archive=ledger.tar.gz --sbHeight=5000000",
+		Flags:     append(append(ledgerArchiveFlags, exportFlags...), configFlags...),
+		Category:  "LEDGER ARCHIVE COMMANDS",
+		Description: `
+Export the ledger at the snapshot block height into an archive, the latest snapshot block is used if sbHeight is not set.
+The node must be stopped while exporting.
+`,
+	}
+
+	ledgerImportCommand = cli.Command{
+		Action:    utils.MigrateFlags(ledgerImportAction),
+		Name:      "ledger-import",
+		Usage:     "ledger-import --archive=ledger.tar.gz --trustedHash=<snapshot block hash>",
+		ArgsUsage: "--archive=ledger.tar.gz --trustedHash=<snapshot block hash>",
+		Flags:     append(append(ledgerArchiveFlags, ledgerImportFlags...), configFlags...),
+		Category:  "LEDGER ARCHIVE COMMANDS",
+		Description: `
+Import the ledger from an archive. The snapshot block of the archive must be the trustedHash, which is got from a trusted source.
+The hash and the signature of every snapshot block are verified, the state and the index of the archive are installed
+as they are, they are only sample checked. The ledger of the data directory must be empty.
+`,
+	}
+)
+
+func ledgerExportAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewLedgerExportNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
+
+func ledgerImportAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewLedgerImportNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
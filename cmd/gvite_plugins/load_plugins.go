@@ -98,6 +98,9 @@ var (
 	netFlags = []cli.Flag{
 		utils.SingleFlag,
 		utils.FilePortFlag,
+		utils.ArchiveFlag,
+		utils.LightFlag,
+		utils.LightServeFlag,
 	}
 
 	//Stat
@@ -127,6 +130,31 @@ var (
 	exportFlags = []cli.Flag{
 		utils.ExportSbHeightFlags,
 	}
+
+	// Ledger archive
+	ledgerArchiveFlags = []cli.Flag{
+		utils.LedgerArchiveFlag,
+	}
+	ledgerImportFlags = []cli.Flag{
+		utils.LedgerTrustedHashFlag,
+	}
+
+	// Verify ledger
+	verifyLedgerFlags = []cli.Flag{
+		utils.VerifyLedgerWorkersFlag,
+		utils.VerifyLedgerCheckpointFlag,
+		utils.VerifyLedgerReportFlag,
+	}
+
+	// DNS tree
+	dnsTreeFlags = []cli.Flag{
+		utils.DNSTreeDomainFlag,
+		utils.DNSTreeKeyFileFlag,
+		utils.DNSTreeSeqFlag,
+		utils.DNSTreeCrawlFlag,
+		utils.DNSTreeLinksFlag,
+		utils.DNSTreeRecordsFlag,
+	}
 )
 
 func init() {
@@ -155,13 +183,17 @@ func init() {
 		exportCommand,
 		pluginDataCommand,
 		checkChainCommand,
+		ledgerExportCommand,
+		ledgerImportCommand,
+		verifyLedgerCommand,
+		dnsTreeCommand,
 	}
 	sort.Sort(cli.CommandsByName(app.Commands))
 
 	//Import: Please add the New Flags here
 	app.Flags = utils.MergeFlags(configFlags, generalFlags, p2pFlags,
 		ipcFlags, httpFlags, wsFlags, consoleFlags, producerFlags, logFlags,
-		vmFlags, netFlags, statFlags, metricsFlags, ledgerFlags, exportFlags)
+		vmFlags, netFlags, statFlags, metricsFlags, ledgerFlags, exportFlags, ledgerArchiveFlags, ledgerImportFlags, verifyLedgerFlags, dnsTreeFlags)
 
 	app.Before = beforeAction
 	app.Action = action
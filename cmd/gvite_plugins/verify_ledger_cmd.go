@@ -0,0 +1,41 @@
+package gvite_plugins
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/vitelabs/go-vite/cmd/nodemanager"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"gopkg.in/urfave/cli.v1"
+)
+
+var (
+	verifyLedgerCommand = cli.Command{
+		Action:    utils.MigrateFlags(verifyLedgerAction),
+		Name:      "verify-ledger",
+		Usage:     "verify-ledger --workers=8 --checkpoint=verify.checkpoint --report=verify.json",
+		ArgsUsage: "--workers=8 --checkpoint=verify.checkpoint --report=verify.json --sbHeight=5000000",
+		Flags:     append(append(verifyLedgerFlags, exportFlags...), configFlags...),
+		Category:  "CHECK CHAIN COMMANDS",
+		Description: `
+Verify the ledger offline. Every snapshot block and account block is verified: hash, signature, prev hash and height,
+the send block of every receive block, the index and the balances of the redo log.
+The verification is resumed from the checkpoint if it's set. The node must be stopped while verifying.
+`,
+	}
+)
+
+func verifyLedgerAction(ctx *cli.Context) error {
+	nodeManager, err := nodemanager.NewVerifyLedgerNodeManager(ctx, nodemanager.FullNodeMaker{})
+	if err != nil {
+		log.Error(fmt.Sprintf("new Node error, %+v", err))
+		return err
+	}
+	if err := nodeManager.Start(); err != nil {
+		log.Error(err.Error())
+		fmt.Println(err.Error())
+		return err
+	}
+	os.Exit(0)
+	return nil
+}
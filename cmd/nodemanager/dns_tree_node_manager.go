@@ -0,0 +1,161 @@
+package nodemanager
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/node"
+	"github.com/vitelabs/go-vite/p2p/discovery"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+	"gopkg.in/urfave/cli.v1"
+)
+
+// DNSTreeNodeManager crawl the network and emit the signed node tree, no node is created
+type DNSTreeNodeManager struct {
+	ctx    *cli.Context
+	config *node.Config
+}
+
+func NewDNSTreeNodeManager(ctx *cli.Context, maker NodeMaker) (*DNSTreeNodeManager, error) {
+	config, err := maker.MakeNodeConfig(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &DNSTreeNodeManager{
+		ctx:    ctx,
+		config: config,
+	}, nil
+}
+
+func (nodeManager *DNSTreeNodeManager) Start() error {
+	ctx := nodeManager.ctx
+
+	domain := ctx.GlobalString(utils.DNSTreeDomainFlag.Name)
+	if domain == "" {
+		return errors.New("domain is required")
+	}
+
+	key, err := readTreeKey(ctx.GlobalString(utils.DNSTreeKeyFileFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	seq := uint64(time.Now().Unix())
+	if ctx.GlobalIsSet(utils.DNSTreeSeqFlag.Name) {
+		seq = ctx.GlobalUint64(utils.DNSTreeSeqFlag.Name)
+	}
+
+	var links []string
+	if str := ctx.GlobalString(utils.DNSTreeLinksFlag.Name); str != "" {
+		links = strings.Split(str, ",")
+	}
+
+	nodes, err := nodeManager.crawl(ctx.GlobalDuration(utils.DNSTreeCrawlFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	tree, err := discovery.MakeDNSTree(seq, nodes, links)
+	if err != nil {
+		return err
+	}
+	url := tree.Sign(key, domain)
+
+	records, err := tree.Records(domain)
+	if err != nil {
+		return err
+	}
+	data, err := json.MarshalIndent(records, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if recordsPath := ctx.GlobalString(utils.DNSTreeRecordsFlag.Name); len(recordsPath) > 0 {
+		if err = ioutil.WriteFile(recordsPath, data, 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Write the records to %s\n", recordsPath)
+	} else {
+		fmt.Println(string(data))
+	}
+
+	fmt.Printf("Tree of %d nodes, seq %d, url %s\n", len(nodes), seq, url)
+	return nil
+}
+
+// readTreeKey read the hex private key from the file, the key is not passed on the command line so it can't be seen by other users
+func readTreeKey(keyFile string) (ed25519.PrivateKey, error) {
+	if keyFile == "" {
+		return nil, fmt.Errorf("--%s is required", utils.DNSTreeKeyFileFlag.Name)
+	}
+
+	info, err := os.Stat(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm()&0077 != 0 {
+		return nil, fmt.Errorf("tree key file %s is accessible by other users, permissions %s", keyFile, info.Mode().Perm())
+	}
+
+	data, err := ioutil.ReadFile(keyFile)
+	if err != nil {
+		return nil, err
+	}
+	key, err := ed25519.HexToPrivateKey(strings.TrimSpace(string(data)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse tree key file %s: %v", keyFile, err)
+	}
+	return key, nil
+}
+
+// crawl start a discovery with random key, the nodes in table are returned after duration
+func (nodeManager *DNSTreeNodeManager) crawl(duration time.Duration) (nodes []*vnode.Node, err error) {
+	cfg := &discovery.Config{
+		ListenAddress: "0.0.0.0:0",
+		BootNodes:     nodeManager.config.BootNodes,
+		BootSeeds:     nodeManager.config.BootSeeds,
+		BootDNS:       nodeManager.config.BootDNS,
+		NetID:         nodeManager.config.NetID,
+	}
+	if err = cfg.Ensure(); err != nil {
+		return nil, err
+	}
+
+	d := discovery.New(cfg, nil)
+	if err = d.Start(); err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("Crawl the network %d for %s\n", cfg.NetID, duration)
+	time.Sleep(duration)
+
+	for _, n := range d.AllNodes() {
+		if n.EndPoint.Hostname() != "" {
+			nodes = append(nodes, n)
+		}
+	}
+	_ = d.Stop()
+
+	if len(nodes) == 0 {
+		return nil, errors.New("no node is found")
+	}
+
+	return nodes, nil
+}
+
+func (nodeManager *DNSTreeNodeManager) Stop() error {
+	return nil
+}
+
+func (nodeManager *DNSTreeNodeManager) Node() *node.Node {
+	return nil
+}
@@ -178,6 +178,18 @@ func mappingNodeConfig(ctx *cli.Context, cfg *node.Config) {
 		cfg.FileListenAddress = "0.0.0.0:" + utils.FilePortFlag.Name
 	}
 
+	if ctx.GlobalIsSet(utils.ArchiveFlag.Name) {
+		cfg.Archive = ctx.GlobalBool(utils.ArchiveFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(utils.LightFlag.Name) {
+		cfg.LightMode = ctx.GlobalBool(utils.LightFlag.Name)
+	}
+
+	if ctx.GlobalIsSet(utils.LightServeFlag.Name) {
+		cfg.LightServe = ctx.GlobalBool(utils.LightServeFlag.Name)
+	}
+
 	//metrics
 	if ctx.GlobalIsSet(utils.MetricsEnabledFlag.Name) {
 		mBool := ctx.GlobalBool(utils.MetricsEnabledFlag.Name)
@@ -0,0 +1,299 @@
+package nodemanager
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/archive"
+	"github.com/vitelabs/go-vite/chain/integrity"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+const ledgerDirName = "ledger"
+
+// the account blocks of one of every importSampleInterval snapshot blocks of an imported archive are checked
+const importSampleInterval = 1000
+
+type LedgerExportNodeManager struct {
+	ctx  *cli.Context
+	node *node.Node
+}
+
+func NewLedgerExportNodeManager(ctx *cli.Context, maker NodeMaker) (*LedgerExportNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LedgerExportNodeManager{
+		ctx:  ctx,
+		node: node,
+	}, nil
+}
+
+func (nodeManager *LedgerExportNodeManager) Start() error {
+	viteConfig := nodeManager.node.ViteConfig()
+
+	archivePath, err := getArchivePath(nodeManager.ctx)
+	if err != nil {
+		return err
+	}
+
+	sbHeight := uint64(0)
+	if nodeManager.ctx.GlobalIsSet(utils.ExportSbHeightFlags.Name) {
+		sbHeight = nodeManager.ctx.GlobalUint64(utils.ExportSbHeightFlags.Name)
+	}
+
+	fork.SetForkPoints(viteConfig.ForkPoints)
+
+	// the ledger is copied, the copy is rolled back to sbHeight and archived
+	tmpDir, err := ioutil.TempDir(viteConfig.DataDir, "export")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fmt.Printf("Copy the ledger to %s\n", tmpDir)
+	if err := copyDir(filepath.Join(viteConfig.DataDir, ledgerDirName), filepath.Join(tmpDir, ledgerDirName)); err != nil {
+		return err
+	}
+
+	c := chain.NewChain(tmpDir, viteConfig.Chain, viteConfig.Genesis)
+	if err := c.Init(); err != nil {
+		return err
+	}
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	latest := c.GetLatestSnapshotBlock()
+	fmt.Printf("Latest snapshot block height is %d\n", latest.Height)
+	if sbHeight > latest.Height {
+		closeChain(c)
+		return errors.New(fmt.Sprintf("sbHeight %d is higher than the latest snapshot block height %d", sbHeight, latest.Height))
+	}
+
+	if sbHeight > 0 && sbHeight < latest.Height {
+		fmt.Printf("Delete to height %d\n", sbHeight+1)
+		if _, err := c.DeleteSnapshotBlocksToHeight(sbHeight + 1); err != nil {
+			closeChain(c)
+			return err
+		}
+		latest = c.GetLatestSnapshotBlock()
+	}
+	c.Stop()
+	c.Flusher().Flush()
+
+	indexDB, _, _ := c.DBs()
+	blockFiles, err := chain_archive.BlockFileMetas(indexDB, latest.Height)
+	if err != nil {
+		closeChain(c)
+		return err
+	}
+
+	manifest := &chain_archive.Manifest{
+		GenesisHash:    c.GetGenesisSnapshotBlock().Hash,
+		SnapshotHeight: latest.Height,
+		SnapshotHash:   latest.Hash,
+		CreateTime:     time.Now().Unix(),
+		BlockFiles:     blockFiles,
+	}
+
+	if err := closeChain(c); err != nil {
+		return err
+	}
+
+	fmt.Printf("Write the ledger at snapshot block %d %s to %s\n", manifest.SnapshotHeight, manifest.SnapshotHash, archivePath)
+	if err := chain_archive.Write(filepath.Join(tmpDir, ledgerDirName), manifest, archivePath); err != nil {
+		return err
+	}
+
+	fmt.Printf("Export successfully, %d segments\n", len(manifest.Segments))
+	return nil
+}
+
+func (nodeManager *LedgerExportNodeManager) Stop() error {
+	return nil
+}
+
+func (nodeManager *LedgerExportNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
+
+type LedgerImportNodeManager struct {
+	ctx  *cli.Context
+	node *node.Node
+}
+
+func NewLedgerImportNodeManager(ctx *cli.Context, maker NodeMaker) (*LedgerImportNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &LedgerImportNodeManager{
+		ctx:  ctx,
+		node: node,
+	}, nil
+}
+
+func (nodeManager *LedgerImportNodeManager) Start() error {
+	viteConfig := nodeManager.node.ViteConfig()
+
+	archivePath, err := getArchivePath(nodeManager.ctx)
+	if err != nil {
+		return err
+	}
+
+	if !nodeManager.ctx.GlobalIsSet(utils.LedgerTrustedHashFlag.Name) {
+		return errors.New(fmt.Sprintf("--%s is required", utils.LedgerTrustedHashFlag.Name))
+	}
+	trustedHash, err := types.HexToHash(nodeManager.ctx.GlobalString(utils.LedgerTrustedHashFlag.Name))
+	if err != nil {
+		return errors.New(fmt.Sprintf("failed to parse %s. Error: %s", utils.LedgerTrustedHashFlag.Name, err))
+	}
+
+	ledgerDir := filepath.Join(viteConfig.DataDir, ledgerDirName)
+	if files, err := ioutil.ReadDir(ledgerDir); err == nil && len(files) > 0 {
+		return errors.New(fmt.Sprintf("the ledger %s is not empty, remove it before importing", ledgerDir))
+	}
+
+	fork.SetForkPoints(viteConfig.ForkPoints)
+
+	// the archive is extracted and verified in a temporary directory, then it's installed
+	tmpDir, err := ioutil.TempDir(viteConfig.DataDir, "import")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	fmt.Printf("Extract %s to %s\n", archivePath, tmpDir)
+	manifest, err := chain_archive.Extract(archivePath, filepath.Join(tmpDir, ledgerDirName))
+	if err != nil {
+		return err
+	}
+
+	if err := verifyArchivedLedger(tmpDir, viteConfig, manifest, trustedHash); err != nil {
+		return err
+	}
+
+	if err := os.RemoveAll(ledgerDir); err != nil {
+		return err
+	}
+	if err := os.Rename(filepath.Join(tmpDir, ledgerDirName), ledgerDir); err != nil {
+		return err
+	}
+
+	fmt.Printf("Import successfully, latest snapshot block is %d %s\n", manifest.SnapshotHeight, manifest.SnapshotHash)
+	return nil
+}
+
+func (nodeManager *LedgerImportNodeManager) Stop() error {
+	return nil
+}
+
+func (nodeManager *LedgerImportNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
+
+// verifyArchivedLedger verifies the snapshot chain of the archived ledger against trustedHash, the hash and the signature
+// of every snapshot block are verified. The state and the index of the archive are installed as they are,
+// so they are only sample checked with the account blocks of one of every importSampleInterval snapshot blocks.
+func verifyArchivedLedger(dataDir string, viteConfig *config.Config, manifest *chain_archive.Manifest, trustedHash types.Hash) error {
+	c := chain.NewChain(dataDir, viteConfig.Chain, viteConfig.Genesis)
+
+	// the ledger is cleaned by Init if the genesis snapshot block is different
+	if genesisHash := c.GetGenesisSnapshotBlock().Hash; genesisHash != manifest.GenesisHash {
+		return errors.New(fmt.Sprintf("the genesis snapshot block is %s, but it is %s in the archive", genesisHash, manifest.GenesisHash))
+	}
+
+	if err := c.Init(); err != nil {
+		return err
+	}
+	defer closeChain(c)
+
+	fmt.Printf("Verify the snapshot chain to %d %s\n", manifest.SnapshotHeight, manifest.SnapshotHash)
+	if err := chain_archive.VerifySnapshotChain(c, manifest, trustedHash); err != nil {
+		return err
+	}
+
+	fmt.Printf("Check the account blocks of one of every %d snapshot blocks\n", importSampleInterval)
+	report := chain_integrity.NewVerifier(c, 1, "").VerifySample(manifest.SnapshotHeight, importSampleInterval)
+	if report.IssueCount > 0 {
+		issue := report.Issues[0]
+		return errors.New(fmt.Sprintf("%d issues are found in the archive, the first one is %s at snapshot height %d: %s",
+			report.IssueCount, issue.Kind, issue.SnapshotHeight, issue.Message))
+	}
+	return nil
+}
+
+func getArchivePath(ctx *cli.Context) (string, error) {
+	if !ctx.GlobalIsSet(utils.LedgerArchiveFlag.Name) {
+		return "", errors.New(fmt.Sprintf("--%s is required", utils.LedgerArchiveFlag.Name))
+	}
+	return filepath.Abs(ctx.GlobalString(utils.LedgerArchiveFlag.Name))
+}
+
+// closeChain closes the databases of c, Destroy doesn't close the plugins store.
+func closeChain(c chain.Chain) error {
+	plugins := c.Plugins()
+	if err := c.Destroy(); err != nil {
+		return err
+	}
+	if plugins != nil {
+		return plugins.Close()
+	}
+	return nil
+}
+
+func copyDir(src, dst string) error {
+	return filepath.Walk(src, func(filePath string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		name, err := filepath.Rel(src, filePath)
+		if err != nil {
+			return err
+		}
+		target := filepath.Join(dst, name)
+
+		if info.IsDir() {
+			return os.MkdirAll(target, 0700)
+		}
+		if !info.Mode().IsRegular() {
+			return nil
+		}
+		return copyFile(filePath, target)
+	})
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	defer out.Close()
+
+	if _, err := io.Copy(out, in); err != nil {
+		return err
+	}
+	return out.Sync()
+}
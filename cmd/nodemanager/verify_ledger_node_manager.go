@@ -0,0 +1,87 @@
+package nodemanager
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/integrity"
+	"github.com/vitelabs/go-vite/cmd/utils"
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/node"
+	"gopkg.in/urfave/cli.v1"
+)
+
+type VerifyLedgerNodeManager struct {
+	ctx  *cli.Context
+	node *node.Node
+}
+
+func NewVerifyLedgerNodeManager(ctx *cli.Context, maker NodeMaker) (*VerifyLedgerNodeManager, error) {
+	node, err := maker.MakeNode(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return &VerifyLedgerNodeManager{
+		ctx:  ctx,
+		node: node,
+	}, nil
+}
+
+func (nodeManager *VerifyLedgerNodeManager) Start() error {
+	ctx := nodeManager.ctx
+	viteConfig := nodeManager.node.ViteConfig()
+
+	// the fork points are needed to compute the hash of snapshot blocks
+	fork.SetForkPoints(viteConfig.ForkPoints)
+
+	c := chain.NewChain(viteConfig.DataDir, viteConfig.Chain, viteConfig.Genesis)
+	if err := c.Init(); err != nil {
+		return err
+	}
+	defer closeChain(c)
+
+	toHeight := uint64(0)
+	if ctx.GlobalIsSet(utils.ExportSbHeightFlags.Name) {
+		toHeight = ctx.GlobalUint64(utils.ExportSbHeightFlags.Name)
+	}
+
+	verifier := chain_integrity.NewVerifier(c, ctx.GlobalInt(utils.VerifyLedgerWorkersFlag.Name),
+		ctx.GlobalString(utils.VerifyLedgerCheckpointFlag.Name))
+
+	fmt.Printf("Start verifying, view the process through the log in %s\n", viteConfig.RunLogDir())
+	report, err := verifier.Verify(toHeight)
+	if err != nil {
+		return err
+	}
+
+	if reportPath := ctx.GlobalString(utils.VerifyLedgerReportFlag.Name); len(reportPath) > 0 {
+		if err := chain_integrity.SaveReport(report, reportPath); err != nil {
+			return err
+		}
+		fmt.Printf("Write the report to %s\n", reportPath)
+	} else {
+		data, err := json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+	}
+
+	if report.IssueCount > 0 {
+		return errors.New(fmt.Sprintf("%d issues are found in %d snapshot blocks and %d account blocks",
+			report.IssueCount, report.SnapshotBlocks, report.AccountBlocks))
+	}
+	fmt.Printf("Verify successfully, %d snapshot blocks and %d account blocks\n", report.SnapshotBlocks, report.AccountBlocks)
+	return nil
+}
+
+func (nodeManager *VerifyLedgerNodeManager) Stop() error {
+	return nil
+}
+
+func (nodeManager *VerifyLedgerNodeManager) Node() *node.Node {
+	return nodeManager.node
+}
@@ -186,6 +186,58 @@ var (
 		Usage: "The snapshot block height",
 	}
 
+	// Ledger archive
+	LedgerArchiveFlag = cli.StringFlag{
+		Name:  "archive",
+		Usage: "The path of the ledger archive",
+	}
+	LedgerTrustedHashFlag = cli.StringFlag{
+		Name:  "trustedHash",
+		Usage: "The hash of the snapshot block of the ledger archive, get it from a trusted source",
+	}
+
+	// Verify ledger
+	VerifyLedgerWorkersFlag = cli.IntFlag{
+		Name:  "workers",
+		Usage: "The number of parallel workers to verify the ledger",
+		Value: 4,
+	}
+	VerifyLedgerCheckpointFlag = cli.StringFlag{
+		Name:  "checkpoint",
+		Usage: "The checkpoint file to resume the verification",
+	}
+	VerifyLedgerReportFlag = cli.StringFlag{
+		Name:  "report",
+		Usage: "The file to write the json report, the report is printed if it's not set",
+	}
+
+	// DNS tree
+	DNSTreeDomainFlag = cli.StringFlag{
+		Name:  "domain",
+		Usage: "The domain to publish the node tree",
+	}
+	DNSTreeKeyFileFlag = cli.StringFlag{
+		Name:  "treekeyfile",
+		Usage: "The file of the ed25519 private key as hex to sign the node tree, it must not be accessible by other users",
+	}
+	DNSTreeSeqFlag = cli.Uint64Flag{
+		Name:  "seq",
+		Usage: "The sequence number of the node tree, the current unix time is used if it's not set",
+	}
+	DNSTreeCrawlFlag = cli.DurationFlag{
+		Name:  "crawl",
+		Usage: "How long to crawl the network",
+		Value: 2 * time.Minute,
+	}
+	DNSTreeLinksFlag = cli.StringFlag{
+		Name:  "links",
+		Usage: "The urls of other node trees to link, separated by comma",
+	}
+	DNSTreeRecordsFlag = cli.StringFlag{
+		Name:  "records",
+		Usage: "The file to write the json TXT records, the records are printed if it's not set",
+	}
+
 	//Net
 	SingleFlag = cli.BoolFlag{
 		Name:  "single",
@@ -197,6 +249,19 @@ var (
 		Usage: "File transfer listening port",
 	}
 
+	ArchiveFlag = cli.BoolFlag{
+		Name:  "archivenode",
+		Usage: "Keep the full history of ledger and serve the old ledger to the pruned nodes",
+	}
+	LightFlag = cli.BoolFlag{
+		Name:  "light",
+		Usage: "Run as a light node, follow the snapshot headers and verify the ledger queries by proofs",
+	}
+	LightServeFlag = cli.BoolFlag{
+		Name:  "lightserve",
+		Usage: "Serve the light protocol to light nodes",
+	}
+
 	//Stat
 	PProfEnabledFlag = cli.BoolFlag{
 		Name:  "pprof",
@@ -1,6 +1,7 @@
 package fork
 
 import (
+	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
 	"reflect"
 	"sort"
@@ -23,12 +24,17 @@ func (a ForkPointList) Less(i, j int) bool { return a[i].Height < a[j].Height }
 
 func SetForkPoints(points *config.ForkPoints) {
 	forkPoints = *points
+	forkPointList = nil
 
 	t := reflect.TypeOf(forkPoints)
 	v := reflect.ValueOf(forkPoints)
 
 	for k := 0; k < t.NumField(); k++ {
 		forkPoint := v.Field(k).Interface().(*config.ForkPoint)
+		// fork points not configured are never activated
+		if forkPoint == nil {
+			continue
+		}
 		forkPointList = append(forkPointList, &ForkPointItem{
 			ForkPoint: *forkPoint,
 			forkName:  t.Field(k).Name,
@@ -51,3 +57,48 @@ func GetRecentForkName(blockHeight uint64) string {
 	}
 	return ""
 }
+
+func IsMultiSigFork(snapshotHeight uint64) bool {
+	return forkPoints.MultiSigFork != nil && snapshotHeight >= forkPoints.MultiSigFork.Height
+}
+
+// isForkBuiltinContractActive returns false for the builtin contracts which are not activated at the snapshot height
+func isForkBuiltinContractActive(addr types.Address, snapshotHeight uint64) bool {
+	if addr == types.AddressMultiSig {
+		return IsMultiSigFork(snapshotHeight)
+	}
+	return true
+}
+
+func IsBuiltinContractAddrInUse(addr types.Address, snapshotHeight uint64) bool {
+	if types.IsBuiltinContractAddrInUse(addr) {
+		return true
+	}
+	return types.IsForkBuiltinContractAddr(addr) && isForkBuiltinContractActive(addr, snapshotHeight)
+}
+
+func IsBuiltinContractAddrInUseWithoutQuota(addr types.Address, snapshotHeight uint64) bool {
+	if types.IsBuiltinContractAddrInUseWithoutQuota(addr) {
+		return true
+	}
+	return types.IsForkBuiltinContractAddr(addr) && isForkBuiltinContractActive(addr, snapshotHeight)
+}
+
+func IsBuiltinContractAddrInUseWithSendConfirm(addr types.Address, snapshotHeight uint64) bool {
+	if types.IsBuiltinContractAddrInUseWithSendConfirm(addr) {
+		return true
+	}
+	return types.IsForkBuiltinContractAddr(addr) && isForkBuiltinContractActive(addr, snapshotHeight)
+}
+
+// GetBuiltinContractAddrList returns the builtin contracts in use at the snapshot height
+func GetBuiltinContractAddrList(snapshotHeight uint64) []types.Address {
+	list := make([]types.Address, 0, len(types.BuiltinContractAddrList)+len(types.ForkBuiltinContractAddrList))
+	list = append(list, types.BuiltinContractAddrList...)
+	for _, addr := range types.ForkBuiltinContractAddrList {
+		if isForkBuiltinContractActive(addr, snapshotHeight) {
+			list = append(list, addr)
+		}
+	}
+	return list
+}
@@ -35,10 +35,13 @@ var (
 	AddressPledge, _         = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 3, ContractAddrByte})
 	AddressConsensusGroup, _ = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 4, ContractAddrByte})
 	AddressMintage, _        = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 5, ContractAddrByte})
+	AddressMultiSig, _       = BytesToAddress([]byte{0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 6, ContractAddrByte})
 
 	BuiltinContractAddrList             = []Address{AddressPledge, AddressConsensusGroup, AddressMintage}
 	BuiltinContractWithoutQuotaAddrList = []Address{AddressPledge, AddressConsensusGroup, AddressMintage}
 	BuiltinContractWithSendConfirm      = []Address{AddressPledge, AddressConsensusGroup, AddressMintage}
+	// builtin contracts activated at a fork point, check with common/fork before use
+	ForkBuiltinContractAddrList = []Address{AddressMultiSig}
 )
 
 func IsContractAddr(addr Address) bool {
@@ -61,6 +64,15 @@ func IsBuiltinContractAddrInUse(addr Address) bool {
 	return false
 }
 
+func IsForkBuiltinContractAddr(addr Address) bool {
+	for _, cAddr := range ForkBuiltinContractAddrList {
+		if cAddr == addr {
+			return true
+		}
+	}
+	return false
+}
+
 func IsBuiltinContractAddrInUseWithoutQuota(addr Address) bool {
 	for _, cAddr := range BuiltinContractWithoutQuotaAddrList {
 		if cAddr == addr {
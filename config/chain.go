@@ -5,4 +5,8 @@ type Chain struct {
 	GenesisFile    string
 	LedgerGc       bool
 	OpenPlugins    bool
+
+	// drop the account block bodies confirmed by the snapshot blocks lower than latest - LedgerPruneRetain
+	LedgerPrune       bool
+	LedgerPruneRetain uint64
 }
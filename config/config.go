@@ -14,6 +14,7 @@ type Config struct {
 	*Chain      `json:"Chain"`
 	*Vm         `json:"Vm"`
 	*Subscribe  `json:"Subscribe"`
+	*Stream     `json:"Stream"`
 	*Net        `json:"Net"`
 	*biz.Reward `json:"Reward"`
 	*Genesis    `json:"Genesis"`
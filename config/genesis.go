@@ -31,7 +31,9 @@ type ForkPoint struct {
 	Hash   *types.Hash
 }
 
-type ForkPoints struct{}
+type ForkPoints struct {
+	MultiSigFork *ForkPoint
+}
 
 type GenesisVmLog struct {
 	Data   string
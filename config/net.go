@@ -11,6 +11,8 @@ type Net struct {
 	AccessAllowKeys    []string `json:"AccessAllowKeys"`
 	AccessDenyKeys     []string `json:"AccessDenyKeys"`
 	BlackBlockHashList []string `json:"BlackBlockHashList"`
+	Archive            bool     `json:"Archive"`
+	ArchiveRateLimit   int64    `json:"ArchiveRateLimit"`
 
 	MinePrivateKey ed25519.PrivateKey
 	P2PPrivateKey  ed25519.PrivateKey
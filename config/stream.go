@@ -0,0 +1,6 @@
+package config
+
+type Stream struct {
+	// template：["broker1,broker2,...|topic",""]
+	KafkaProducers []string `json:"KafkaProducers"`
+}
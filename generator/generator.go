@@ -67,6 +67,11 @@ func NewGenerator(chain vm_db.Chain, consensus Consensus, addr types.Address, la
 	return gen, nil
 }
 
+// SetTracer sets a tracer to the vm to collect the execution information of the block generated.
+func (gen *Generator) SetTracer(tracer vm.Tracer) {
+	gen.vm.SetTracer(tracer)
+}
+
 // GenerateWithBlock implements the method to generate a transaction with VM execution results
 // from a block which contains the complete transaction info.
 func (gen *Generator) GenerateWithBlock(block *ledger.AccountBlock, fromBlock *ledger.AccountBlock) (*GenResult, error) {
@@ -1,6 +1,9 @@
 package ledger
 
-import "github.com/vitelabs/go-vite/common/types"
+import (
+	"github.com/vitelabs/go-vite/common/fork"
+	"github.com/vitelabs/go-vite/common/types"
+)
 
 type ContractMeta struct {
 	Gid                types.Gid
@@ -41,10 +44,11 @@ func (cm *ContractMeta) Deserialize(buf []byte) error {
 	return nil
 }
 
-func GetBuiltinContractMeta(addr types.Address) *ContractMeta {
-	if types.IsBuiltinContractAddrInUseWithSendConfirm(addr) {
+// GetBuiltinContractMeta returns nil if the contract is not in use at the snapshot height
+func GetBuiltinContractMeta(addr types.Address, snapshotHeight uint64) *ContractMeta {
+	if fork.IsBuiltinContractAddrInUseWithSendConfirm(addr, snapshotHeight) {
 		return &ContractMeta{types.DELEGATE_GID, 1, types.Hash{}, 10}
-	} else if types.IsBuiltinContractAddrInUse(addr) {
+	} else if fork.IsBuiltinContractAddrInUse(addr, snapshotHeight) {
 		return &ContractMeta{types.DELEGATE_GID, 0, types.Hash{}, 10}
 	}
 	return nil
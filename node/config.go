@@ -2,6 +2,7 @@ package node
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -12,14 +13,19 @@ import (
 
 	"github.com/vitelabs/go-vite/p2p/discovery"
 
+	"github.com/vitelabs/go-vite/chain/genesis"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/config/biz"
 	"github.com/vitelabs/go-vite/config/gen"
 	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/metrics"
 	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/rpc"
+	"github.com/vitelabs/go-vite/vite/net"
 	"github.com/vitelabs/go-vite/wallet"
 )
 
@@ -38,6 +44,9 @@ type Config struct {
 	LedgerGc       *bool  `json:"LedgerGc"`
 	OpenPlugins    *bool  `json:"OpenPlugins"`
 
+	LedgerPrune       *bool  `json:"LedgerPrune"`
+	LedgerPruneRetain uint64 `json:"LedgerPruneRetain"`
+
 	// genesis
 	GenesisFile string `json:"GenesisFile"`
 
@@ -51,6 +60,7 @@ type Config struct {
 	MaxPendingPeers    int      `json:"MaxPendingPeers"`
 	BootNodes          []string `json:"BootNodes"`
 	BootSeeds          []string `json:"BootSeeds"`
+	BootDNS            []string `json:"BootDNS"`
 	StaticNodes        []string `json:"StaticNodes"`
 	ListenInterface    string   `json:"ListenInterface"`
 	Port               int      `json:"Port"`
@@ -62,13 +72,20 @@ type Config struct {
 	AccessAllowKeys    []string `json:"AccessAllowKeys"`
 	AccessDenyKeys     []string `json:"AccessDenyKeys"`
 	BlackBlockHashList []string `json:"BlackBlockHashList"`
+	P2PEncrypt         bool     `json:"P2PEncrypt"`        // encrypt the connections with peers support it
+	P2PRequireEncrypt  bool     `json:"P2PRequireEncrypt"` // reject the peers don't support encryption
+	P2PRequiredCaps    []string `json:"P2PRequiredCaps"`   // reject the peers don't support these sub-protocols
 
 	//producer
-	EntropyStorePath     string `json:"EntropyStorePath"`
-	EntropyStorePassword string `json:"EntropyStorePassword"`
-	CoinBase             string `json:"CoinBase"`
-	MinerEnabled         bool   `json:"Miner"`
-	MinerInterval        int    `json:"MinerInterval"`
+	EntropyStorePath           string `json:"EntropyStorePath"`
+	EntropyStorePassword       string `json:"EntropyStorePassword"`
+	EntropyStorePasswordSource string `json:"EntropyStorePasswordSource"` // fd:N, file:PATH, env:NAME or exec:CMD, instead of the plaintext password
+	ExternalSigner             string `json:"ExternalSigner"`
+	WalletAuditLog             string `json:"WalletAuditLog"`   // file of unlock, lock and sign events, relative to DataDir
+	WalletKDFProfile           string `json:"WalletKDFProfile"` // standard, scrypt-strong, argon2id or argon2id-strong
+	CoinBase                   string `json:"CoinBase"`
+	MinerEnabled               bool   `json:"Miner"`
+	MinerInterval              int    `json:"MinerInterval"`
 
 	//rpc
 	RPCEnabled bool `json:"RPCEnabled"`
@@ -82,13 +99,15 @@ type Config struct {
 	WSHost           string   `json:"WSHost"`
 	WSPort           int      `json:"WSPort"`
 
-	HTTPCors            []string `json:"HTTPCors"`
-	WSOrigins           []string `json:"WSOrigins"`
-	PublicModules       []string `json:"PublicModules"`
-	WSExposeAll         bool     `json:"WSExposeAll"`
-	HttpExposeAll       bool     `json:"HttpExposeAll"`
-	TestTokenHexPrivKey string   `json:"TestTokenHexPrivKey"`
-	TestTokenTti        string   `json:"TestTokenTti"`
+	HTTPCors            []string         `json:"HTTPCors"`
+	WSOrigins           []string         `json:"WSOrigins"`
+	PublicModules       []string         `json:"PublicModules"`
+	WSExposeAll         bool             `json:"WSExposeAll"`
+	HttpExposeAll       bool             `json:"HttpExposeAll"`
+	RPCAuth             *rpc.AuthConfig  `json:"RPCAuth"`   // tokens, rate limits and access log of rpc, disabled if nil
+	RPCLimits           *rpc.LimitConfig `json:"RPCLimits"` // batch length, response size and timeouts of http and ws, unlimited if nil
+	TestTokenHexPrivKey string           `json:"TestTokenHexPrivKey"`
+	TestTokenTti        string           `json:"TestTokenTti"`
 
 	PowServerUrl string `json:"PowServerUrl"`
 
@@ -111,6 +130,16 @@ type Config struct {
 	FilePublicAddress string `json:"FileAddress"`
 	ForwardStrategy   string `json:"ForwardStrategy"`
 	TraceEnabled      bool   `json:"TraceEnabled"`
+	Archive           bool   `json:"Archive"`
+	ArchiveRateLimit  int64  `json:"ArchiveRateLimit"`
+
+	// light
+	LightServe            bool     `json:"LightServe"`            // serve the light protocol to light clients
+	LightMode             bool     `json:"LightMode"`             // follow the snapshot headers only, instead of the whole ledger
+	LightCheckpointHeight uint64   `json:"LightCheckpointHeight"` // trusted snapshot block to follow from, default is the genesis
+	LightCheckpointHash   string   `json:"LightCheckpointHash"`
+	LightMembers          []string `json:"LightMembers"` // trusted producers of the round contains the checkpoint
+	LightQuorum           int      `json:"LightQuorum"`  // how many peers should agree the same round members and balance, they are not proved
 
 	// dashboard
 	DashboardTargetURL string
@@ -129,7 +158,11 @@ type Config struct {
 }
 
 func (c *Config) makeWalletConfig() *wallet.Config {
-	return &wallet.Config{DataDir: c.KeyStoreDir}
+	auditLog := c.WalletAuditLog
+	if auditLog != "" && !filepath.IsAbs(auditLog) {
+		auditLog = filepath.Join(c.DataDir, auditLog)
+	}
+	return &wallet.Config{DataDir: c.KeyStoreDir, ExternalSigner: c.ExternalSigner, AuditLog: auditLog, KDFProfile: c.WalletKDFProfile}
 }
 
 func (c *Config) makeViteConfig() *config.Config {
@@ -140,6 +173,7 @@ func (c *Config) makeViteConfig() *config.Config {
 		Net:       c.makeNetConfig(),
 		Vm:        c.makeVmConfig(),
 		Subscribe: c.makeSubscribeConfig(),
+		Stream:    c.makeStreamConfig(),
 		Reward:    c.makeRewardConfig(),
 		Genesis:   config_gen.MakeGenesisConfig(c.GenesisFile),
 		LogLevel:  c.LogLevel,
@@ -161,6 +195,8 @@ func (c *Config) makeNetConfig() *config.Net {
 		AccessAllowKeys:    c.AccessAllowKeys,
 		AccessDenyKeys:     c.AccessDenyKeys,
 		BlackBlockHashList: c.BlackBlockHashList,
+		Archive:            c.Archive,
+		ArchiveRateLimit:   c.ArchiveRateLimit,
 	}
 }
 
@@ -185,6 +221,12 @@ func (c *Config) makeSubscribeConfig() *config.Subscribe {
 	}
 }
 
+func (c *Config) makeStreamConfig() *config.Stream {
+	return &config.Stream{
+		KafkaProducers: c.KafkaProducers,
+	}
+}
+
 func (c *Config) makeMetricsConfig() *metrics.Config {
 	mc := &metrics.Config{
 		IsEnable:         false,
@@ -244,6 +286,7 @@ func (c *Config) makeP2PConfig() (cfg *p2p.Config, err error) {
 			PeerKey:       peerKey,
 			BootNodes:     c.BootNodes,
 			BootSeeds:     c.BootSeeds,
+			BootDNS:       c.BootDNS,
 			NetID:         c.NetID,
 		},
 		Discover:          c.Discover,
@@ -255,6 +298,10 @@ func (c *Config) makeP2PConfig() (cfg *p2p.Config, err error) {
 		StaticNodes:       c.StaticNodes,
 		FilePublicAddress: c.FilePublicAddress,
 		FilePort:          c.FilePort,
+		Archive:           c.Archive,
+		Encrypt:           c.P2PEncrypt || c.P2PRequireEncrypt,
+		RequireEncrypt:    c.P2PRequireEncrypt,
+		RequiredCaps:      c.makeRequiredCaps(),
 	}
 
 	err = cfg.Ensure()
@@ -266,6 +313,62 @@ func (c *Config) makeP2PConfig() (cfg *p2p.Config, err error) {
 	return cfg, nil
 }
 
+func (c *Config) makeRequiredCaps() []string {
+	// light node can do nothing with the peers don`t serve the light protocol
+	if c.LightMode {
+		for _, name := range c.P2PRequiredCaps {
+			if name == net.LightProtocolName {
+				return c.P2PRequiredCaps
+			}
+		}
+		return append(c.P2PRequiredCaps, net.LightProtocolName)
+	}
+	return c.P2PRequiredCaps
+}
+
+func (c *Config) makeLightConfig(genesis *config.Genesis) (*net.LightConfig, error) {
+	if genesis == nil || genesis.ConsensusGroupInfo == nil {
+		return nil, errors.New("missing genesis config")
+	}
+	info, ok := genesis.ConsensusGroupInfo.ConsensusGroupInfoMap[types.SNAPSHOT_GID.String()]
+	if !ok {
+		return nil, errors.New("missing snapshot consensus group in genesis config")
+	}
+
+	lightConfig := &net.LightConfig{
+		Genesis: chain_genesis.NewGenesisSnapshotBlock(chain_genesis.NewGenesisAccountBlocks(genesis)),
+		Group: types.ConsensusGroupInfo{
+			Gid:        types.SNAPSHOT_GID,
+			NodeCount:  info.NodeCount,
+			Interval:   info.Interval,
+			PerCount:   info.PerCount,
+			RandCount:  info.RandCount,
+			RandRank:   info.RandRank,
+			Repeat:     info.Repeat,
+			CheckLevel: info.CheckLevel,
+		},
+		Quorum: c.LightQuorum,
+	}
+
+	if c.LightCheckpointHeight > 1 {
+		hash, err := types.HexToHash(c.LightCheckpointHash)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LightCheckpointHash: %v", err)
+		}
+		lightConfig.Checkpoint = &ledger.HashHeight{Height: c.LightCheckpointHeight, Hash: hash}
+	}
+
+	for _, str := range c.LightMembers {
+		addr, err := types.HexToAddress(str)
+		if err != nil {
+			return nil, fmt.Errorf("invalid LightMembers: %v", err)
+		}
+		lightConfig.Members = append(lightConfig.Members, addr)
+	}
+
+	return lightConfig, nil
+}
+
 func (c *Config) makeChainConfig() *config.Chain {
 
 	// is open ledger gc
@@ -278,11 +381,22 @@ func (c *Config) makeChainConfig() *config.Chain {
 	if c.OpenPlugins != nil {
 		openPlugins = *c.OpenPlugins
 	}
+	// is open ledger prune
+	ledgerPrune := false
+	if c.LedgerPrune != nil {
+		ledgerPrune = *c.LedgerPrune
+	}
+	// archive node keeps the full history of ledger
+	if c.Archive {
+		ledgerPrune = false
+	}
 
 	return &config.Chain{
-		LedgerGcRetain: c.LedgerGcRetain,
-		LedgerGc:       ledgerGc,
-		OpenPlugins:    openPlugins,
+		LedgerGcRetain:    c.LedgerGcRetain,
+		LedgerGc:          ledgerGc,
+		OpenPlugins:       openPlugins,
+		LedgerPrune:       ledgerPrune,
+		LedgerPruneRetain: c.LedgerPruneRetain,
 	}
 }
 
@@ -14,6 +14,7 @@ var (
 	ErrEntropyStorePathInvalid = errors.New("entropyStorePath is invalid")
 	ErrViteConfigNil           = errors.New("vite config is nil")
 	ErrP2PConfigNil            = errors.New("p2p config is nil")
+	ErrPassphraseSourceInvalid = errors.New("entropyStorePasswordSource should be fd:N, file:PATH, env:NAME or exec:CMD")
 	datadirInUseErrnos         = map[uint]bool{11: true, 32: true, 35: true}
 )
 
@@ -19,6 +19,7 @@ import (
 	"github.com/vitelabs/go-vite/wallet/hd-bip/derivation"
 
 	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
 
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/metrics"
@@ -28,6 +29,7 @@ import (
 	"github.com/pkg/errors"
 
 	"github.com/vitelabs/go-vite/cmd/utils/flock"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/p2p"
@@ -36,6 +38,7 @@ import (
 	"github.com/vitelabs/go-vite/rpc"
 	"github.com/vitelabs/go-vite/rpcapi"
 	"github.com/vitelabs/go-vite/vite"
+	vnet "github.com/vitelabs/go-vite/vite/net"
 	"github.com/vitelabs/go-vite/wallet"
 )
 
@@ -59,6 +62,9 @@ type Node struct {
 	viteConfig *config.Config
 	viteServer *vite.Vite
 
+	// light mode, runs instead of viteServer
+	lightClient *vnet.LightClient
+
 	// metrics
 	metricsConfig *metrics.Config
 	ifxReporter   *influxdb.Reporter
@@ -138,7 +144,7 @@ func (node *Node) Prepare() (err error) {
 		return ErrNodeRunning
 	}
 
-	if node.viteServer != nil {
+	if node.viteServer != nil || node.lightClient != nil {
 		return ErrNodeRunning
 	}
 
@@ -173,25 +179,38 @@ func (node *Node) Prepare() (err error) {
 
 		var key *derivation.Key
 		_, key, _, err = node.walletManager.GlobalFindAddr(addr)
-		if err != nil {
-			return
-		}
+		if err == walleterrors.ErrAddressNotFound && node.walletManager.ExternalSigner() != nil {
+			// the key is kept by the external signer, only the node id is signed
+			var signature, pubkey []byte
+			if signature, pubkey, err = node.walletManager.SignData(addr, p2pConfig.Node().ID.Bytes()); err != nil {
+				return
+			}
+			p2pConfig.Node().Ext = append(pubkey, signature...)
+		} else {
+			if err != nil {
+				return
+			}
 
-		minePublicKey, err = key.PublicKey()
-		if err != nil {
-			return
-		}
-		minePrivateKey, err = key.PrivateKey()
-		if err != nil {
-			return
+			minePublicKey, err = key.PublicKey()
+			if err != nil {
+				return
+			}
+			minePrivateKey, err = key.PrivateKey()
+			if err != nil {
+				return
+			}
+			// pub + sign(nodeID)
+			p2pConfig.Node().Ext = append(minePublicKey, ed25519.Sign(minePrivateKey, p2pConfig.Node().ID.Bytes())...)
+			p2pConfig.MineKey = minePrivateKey
 		}
-		// pub + sign(nodeID)
-		p2pConfig.Node().Ext = append(minePublicKey, ed25519.Sign(minePrivateKey, p2pConfig.Node().ID.Bytes())...)
-		p2pConfig.MineKey = minePrivateKey
 	}
 
 	node.p2pServer = p2p.New(p2pConfig)
 
+	if node.config.LightMode {
+		return node.prepareLight()
+	}
+
 	//Initialize the vite server
 	node.viteConfig.MinePrivateKey = minePrivateKey
 	node.viteConfig.P2PPrivateKey = p2pConfig.PrivateKey()
@@ -208,6 +227,14 @@ func (node *Node) Prepare() (err error) {
 		return err
 	}
 
+	if node.config.LightServe {
+		err = node.p2pServer.RegisterSub(vnet.NewLightServer(node.viteServer.Chain(), node.viteServer.Consensus()))
+		if err != nil {
+			log.Error(fmt.Sprintf("Failed to mount light protocol on p2p server: %v", err))
+			return err
+		}
+	}
+
 	//init rpc_PowServerUrl
 	remote.InitRawUrl(node.Config().PowServerUrl)
 	pow.Init(node.Config().VMTestParamEnabled)
@@ -220,6 +247,34 @@ func (node *Node) Prepare() (err error) {
 	return nil
 }
 
+// prepareLight mount the light client instead of the vite server
+func (node *Node) prepareLight() (err error) {
+	fork.SetForkPoints(node.viteConfig.ForkPoints)
+
+	lightConfig, err := node.config.makeLightConfig(node.viteConfig.Genesis)
+	if err != nil {
+		log.Error(fmt.Sprintf("Failed to initial light config: %v", err))
+		return err
+	}
+
+	if node.lightClient, err = vnet.NewLightClient(*lightConfig); err != nil {
+		log.Error(fmt.Sprintf("LightClient new error: %v", err))
+		return err
+	}
+
+	if err = node.p2pServer.Register(node.lightClient); err != nil {
+		log.Error(fmt.Sprintf("Failed to mount light client on p2p server: %v", err))
+		return err
+	}
+
+	if err = node.p2pServer.RegisterSub(node.lightClient.Sub()); err != nil {
+		log.Error(fmt.Sprintf("Failed to mount light protocol on p2p server: %v", err))
+		return err
+	}
+
+	return nil
+}
+
 func (node *Node) Start() error {
 	node.lock.Lock()
 	defer node.lock.Unlock()
@@ -351,7 +406,16 @@ func (node *Node) startWallet() (err error) {
 		}
 
 		//unlock
-		if err = entropyStoreManager.Unlock(node.config.EntropyStorePassword); err != nil {
+		password := node.config.EntropyStorePassword
+		if node.config.EntropyStorePasswordSource != "" {
+			if password, err = readPassphrase(node.config.EntropyStorePasswordSource); err != nil {
+				log.Error(fmt.Sprintf("readPassphrase error: %v", err))
+				return err
+			}
+		} else if password != "" {
+			log.Warn("EntropyStorePassword is plaintext in the config file, use EntropyStorePasswordSource instead")
+		}
+		if err = entropyStoreManager.Unlock(password); err != nil {
 			log.Error(fmt.Sprintf("entropyStoreManager.Unlock error: %v", err))
 			return err
 		}
@@ -402,6 +466,9 @@ func (node *Node) stopMetrics() {
 }
 
 func (node *Node) startVite() error {
+	if node.lightClient != nil {
+		return node.lightClient.Start()
+	}
 	return node.viteServer.Start(node.p2pServer)
 }
 
@@ -410,31 +477,43 @@ func (node *Node) startRPC() error {
 	// Init rpc log
 	rpcapi.Init(node.config.DataDir, node.config.LogLevel, node.config.TestTokenHexPrivKey, node.config.TestTokenTti, uint(node.config.NetID))
 
+	var auth *rpc.Auth
+	if node.config.RPCAuth != nil {
+		var err error
+		if auth, err = rpc.NewAuth(node.config.RPCAuth); err != nil {
+			return err
+		}
+	}
+	var limits *rpc.Limits
+	if node.config.RPCLimits != nil {
+		var err error
+		if limits, err = rpc.NewLimits(node.config.RPCLimits); err != nil {
+			return err
+		}
+	}
+
 	// Start the various API endpoints, terminating all in case of errors
 	if err := node.startInProcess(node.GetInProcessApis()); err != nil {
 		return err
 	}
 
 	// start event system
-	if node.config.SubscribeEnabled {
+	if node.config.SubscribeEnabled && node.viteServer != nil {
 		filters.Es = filters.NewEventSystem(node.Vite())
 		filters.Es.Start()
 	}
 
 	// Start rpc
 	if node.config.IPCEnabled {
-		if err := node.startIPC(node.GetIpcApis()); err != nil {
+		if err := node.startIPC(node.GetIpcApis(), auth); err != nil {
 			node.stopInProcess()
 			return err
 		}
 	}
 
 	if node.config.RPCEnabled {
-		apis := rpcapi.GetPublicApis(node.viteServer)
-		if len(node.config.PublicModules) != 0 {
-			apis = rpcapi.GetApis(node.viteServer, node.config.PublicModules...)
-		}
-		if err := node.startHTTP(node.httpEndpoint, apis, nil, node.config.HTTPCors, node.config.HttpVirtualHosts, rpc.HTTPTimeouts{}, node.config.HttpExposeAll); err != nil {
+		apis := node.getPublicApis()
+		if err := node.startHTTP(node.httpEndpoint, apis, nil, node.config.HTTPCors, node.config.HttpVirtualHosts, rpc.HTTPTimeouts{}, node.config.HttpExposeAll, auth, limits); err != nil {
 			node.stopInProcess()
 			node.stopIPC()
 			return err
@@ -442,11 +521,8 @@ func (node *Node) startRPC() error {
 	}
 
 	if node.config.WSEnabled {
-		apis := rpcapi.GetPublicApis(node.viteServer)
-		if len(node.config.PublicModules) != 0 {
-			apis = rpcapi.GetApis(node.viteServer, node.config.PublicModules...)
-		}
-		if err := node.startWS(node.wsEndpoint, apis, nil, node.config.WSOrigins, node.config.WSExposeAll); err != nil {
+		apis := node.getPublicApis()
+		if err := node.startWS(node.wsEndpoint, apis, nil, node.config.WSOrigins, node.config.WSExposeAll, auth, limits); err != nil {
 			node.stopInProcess()
 			node.stopIPC()
 			node.stopHTTP()
@@ -454,10 +530,7 @@ func (node *Node) startRPC() error {
 		}
 	}
 	if len(node.config.DashboardTargetURL) > 0 {
-		apis := rpcapi.GetPublicApis(node.viteServer)
-		if len(node.config.PublicModules) != 0 {
-			apis = rpcapi.GetApis(node.viteServer, node.config.PublicModules...)
-		}
+		apis := node.getPublicApis()
 
 		targetUrl := node.config.DashboardTargetURL + "/ws/gvite/" + strconv.FormatUint(uint64(node.config.NetID), 10) + "@" + hex.EncodeToString(node.p2pServer.Config().PrivateKey().PubByte())
 
@@ -482,6 +555,16 @@ func (node *Node) startRPC() error {
 	return nil
 }
 
+func (node *Node) getPublicApis() []rpc.API {
+	if node.lightClient != nil {
+		return rpcapi.GetLightApis(node.lightClient)
+	}
+	if len(node.config.PublicModules) != 0 {
+		return rpcapi.GetApis(node.viteServer, node.config.PublicModules...)
+	}
+	return rpcapi.GetPublicApis(node.viteServer)
+}
+
 func (node *Node) stopWallet() error {
 
 	if node.walletManager == nil {
@@ -504,6 +587,10 @@ func (node *Node) stopP2P() error {
 
 func (node *Node) stopVite() error {
 
+	if node.lightClient != nil {
+		return node.lightClient.Stop()
+	}
+
 	if node.viteServer == nil {
 		return ErrNodeStopped
 	}
@@ -0,0 +1,78 @@
+package node
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// readPassphrase reads the passphrase of the entropy store from the source, so it's not kept in the config file:
+//
+//	fd:3                read a line from the file descriptor 3, e.g. `gvite 3<<<"$PASS"`
+//	file:/path/to/file  read the first line of the file
+//	env:NAME            read the environment variable
+//	exec:/path/to/cmd   run the secret provider and read the first line of its output
+func readPassphrase(source string) (string, error) {
+	i := strings.Index(source, ":")
+	if i < 0 {
+		return "", ErrPassphraseSourceInvalid
+	}
+	kind, value := source[:i], source[i+1:]
+
+	switch kind {
+	case "fd":
+		fd, err := strconv.ParseUint(value, 10, 32)
+		if err != nil {
+			return "", ErrPassphraseSourceInvalid
+		}
+		f := os.NewFile(uintptr(fd), "passphrase")
+		if f == nil {
+			return "", ErrPassphraseSourceInvalid
+		}
+		defer f.Close()
+		line, err := bufio.NewReader(f).ReadString('\n')
+		if err != nil && line == "" {
+			return "", errors.New(fmt.Sprintf("read passphrase from fd %d failed: %v", fd, err))
+		}
+		return strings.TrimRight(line, "\r\n"), nil
+	case "file":
+		data, err := ioutil.ReadFile(value)
+		if err != nil {
+			return "", err
+		}
+		return firstLine(data), nil
+	case "env":
+		pass, ok := os.LookupEnv(value)
+		if !ok {
+			return "", errors.New(fmt.Sprintf("environment variable %s is not set", value))
+		}
+		return pass, nil
+	case "exec":
+		args := strings.Fields(value)
+		if len(args) == 0 {
+			return "", ErrPassphraseSourceInvalid
+		}
+		cmd := exec.Command(args[0], args[1:]...)
+		cmd.Stderr = os.Stderr
+		data, err := cmd.Output()
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("secret provider %s failed: %v", args[0], err))
+		}
+		return firstLine(data), nil
+	default:
+		return "", ErrPassphraseSourceInvalid
+	}
+}
+
+func firstLine(data []byte) string {
+	s := string(data)
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	return strings.TrimRight(s, "\r")
+}
@@ -8,6 +8,7 @@ import (
 
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/common"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/math"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
@@ -368,7 +369,7 @@ func (w *ContractWorker) releaseContractCallers(contract types.Address, state in
 
 // GetPledgeQuota returns the available quota the contract can use at current.
 func (w *ContractWorker) GetPledgeQuota(addr types.Address) uint64 {
-	if types.IsBuiltinContractAddrInUseWithoutQuota(addr) {
+	if fork.IsBuiltinContractAddrInUseWithoutQuota(addr, w.manager.Chain().GetLatestSnapshotBlock().Height) {
 		return math.MaxUint64
 	}
 	quota, err := w.manager.Chain().GetPledgeQuota(addr)
@@ -386,8 +387,9 @@ func (w *ContractWorker) GetPledgeQuotas(beneficialList []types.Address) map[typ
 	quotas := make(map[types.Address]uint64)
 	if w.gid == types.DELEGATE_GID {
 		commonContractAddressList := make([]types.Address, 0, len(beneficialList))
+		sbHeight := w.manager.Chain().GetLatestSnapshotBlock().Height
 		for _, addr := range beneficialList {
-			if types.IsBuiltinContractAddrInUseWithoutQuota(addr) {
+			if fork.IsBuiltinContractAddrInUseWithoutQuota(addr, sbHeight) {
 				quotas[addr] = math.MaxUint64
 			} else {
 				commonContractAddressList = append(commonContractAddressList, addr)
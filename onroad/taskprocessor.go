@@ -2,6 +2,7 @@ package onroad
 
 import (
 	"fmt"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/generator"
 	"github.com/vitelabs/go-vite/log15"
@@ -103,11 +104,7 @@ func (tp *ContractTaskProcessor) processOneAddress(task *contractTask) (canConti
 	}
 	genResult, err := gen.GenerateWithOnRoad(sBlock, &tp.worker.address,
 		func(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
-			_, key, _, err := tp.worker.manager.wallet.GlobalFindAddr(addr)
-			if err != nil {
-				return nil, nil, err
-			}
-			return key.SignData(data)
+			return tp.worker.manager.wallet.SignData(addr, data)
 		}, nil)
 	if err != nil {
 		blog.Error(fmt.Sprintf("GenerateWithOnRoad failed, err:%v", err))
@@ -139,7 +136,7 @@ func (tp *ContractTaskProcessor) processOneAddress(task *contractTask) (canConti
 		if genResult.IsRetry {
 			// vmRetry it in next turn
 			blog.Info("genResult.IsRetry true")
-			if !types.IsBuiltinContractAddrInUseWithoutQuota(task.Addr) {
+			if !fork.IsBuiltinContractAddrInUseWithoutQuota(task.Addr, tp.worker.manager.Chain().GetLatestSnapshotBlock().Height) {
 				q, err := tp.worker.manager.Chain().GetPledgeQuota(task.Addr)
 				if err != nil {
 					blog.Error(fmt.Sprintf("failed to get pledge quota, err:%v", err))
@@ -0,0 +1,166 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// baseCodeLength is the count of message codes reserved for the main Protocol,
+// codes of sub-protocols are allocated after it
+const baseCodeLength = 160
+const maxCodeLength = 256
+
+var errInvalidCap = errors.New("invalid capability")
+var errSubCodeOutOfRange = errors.New("message code out of range of the sub-protocol")
+
+// Cap is a sub-protocol and it`s version, advertised in handshake as `name/version`
+type Cap struct {
+	Name    string
+	Version uint32
+}
+
+func (c Cap) String() string {
+	return c.Name + "/" + strconv.FormatUint(uint64(c.Version), 10)
+}
+
+func ParseCap(str string) (c Cap, err error) {
+	i := strings.LastIndexByte(str, '/')
+	if i <= 0 {
+		return c, errInvalidCap
+	}
+
+	v, err := strconv.ParseUint(str[i+1:], 10, 32)
+	if err != nil {
+		return c, errInvalidCap
+	}
+
+	return Cap{str[:i], uint32(v)}, nil
+}
+
+// SubProtocol shares the connection with the main Protocol, it will only run with the peers have the same capability.
+// The message codes of a SubProtocol begin from 0, they are offset on the wire by the position negotiated in handshake,
+// so a new protocol, or a new version of protocol, can be added without changing the codes of others.
+// Register several versions with the same name, the highest version both sides support will be used.
+type SubProtocol interface {
+	Cap() Cap
+
+	// CodeLength is the count of message codes, the codes are 0 ~ CodeLength-1
+	CodeLength() int
+
+	// Handle message from sender, msg.Code is the relative code, and msg.Sender writes the relative codes.
+	// If the return error is not nil, will disconnect with peer
+	Handle(msg Msg) error
+
+	// OnPeerAdded will be invoked after Peer run, peer will be closed if return error is not nil
+	OnPeerAdded(peer Peer) error
+
+	// OnPeerRemoved will be invoked after Peer closed
+	OnPeerRemoved(peer Peer) error
+}
+
+type capSet []SubProtocol
+
+func (cs capSet) caps() []Cap {
+	caps := make([]Cap, len(cs))
+	for i, sp := range cs {
+		caps[i] = sp.Cap()
+	}
+	return caps
+}
+
+func (cs capSet) add(sp SubProtocol) (capSet, error) {
+	c := sp.Cap()
+	if c.Name == "" || strings.ContainsRune(c.Name, '/') {
+		return cs, errInvalidCap
+	}
+	if sp.CodeLength() <= 0 {
+		return cs, fmt.Errorf("code length of %s should be positive", c)
+	}
+	for _, sp2 := range cs {
+		if sp2.Cap() == c {
+			return cs, fmt.Errorf("capability %s is already registered", c)
+		}
+	}
+
+	return append(cs, sp), nil
+}
+
+type matchedCap struct {
+	sub    SubProtocol
+	offset int
+}
+
+// match returns the highest common version of each name, sorted by name then allocated codes in order.
+// The sub-protocols can`t allocate codes will be ignored.
+func (cs capSet) match(theirs []Cap) (matched []matchedCap) {
+	best := make(map[string]SubProtocol)
+	for _, sp := range cs {
+		c := sp.Cap()
+		for _, c2 := range theirs {
+			if c == c2 {
+				if old, ok := best[c.Name]; !ok || old.Cap().Version < c.Version {
+					best[c.Name] = sp
+				}
+				break
+			}
+		}
+	}
+
+	names := make([]string, 0, len(best))
+	for name := range best {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	offset := baseCodeLength
+	for _, name := range names {
+		sp := best[name]
+		if offset+sp.CodeLength() > maxCodeLength {
+			break
+		}
+		matched = append(matched, matchedCap{sp, offset})
+		offset += sp.CodeLength()
+	}
+
+	return
+}
+
+// subPeer is the Peer of a SubProtocol, translate the relative codes when write messages
+type subPeer struct {
+	*peerMux
+	matchedCap
+}
+
+func (p *subPeer) WriteMsg(msg Msg) error {
+	if int(msg.Code) >= p.sub.CodeLength() {
+		return errSubCodeOutOfRange
+	}
+
+	msg.Code += Code(p.offset)
+	return p.peerMux.WriteMsg(msg)
+}
+
+func (p *subPeer) contains(code Code) bool {
+	return int(code) >= p.offset && int(code) < p.offset+p.sub.CodeLength()
+}
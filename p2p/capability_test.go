@@ -0,0 +1,156 @@
+package p2p
+
+import (
+	"testing"
+)
+
+type mockSub struct {
+	cap    Cap
+	length int
+	msgs   chan Msg
+}
+
+func newMockSub(name string, version uint32, length int) *mockSub {
+	return &mockSub{Cap{name, version}, length, make(chan Msg, 1)}
+}
+
+func (m *mockSub) Cap() Cap {
+	return m.cap
+}
+
+func (m *mockSub) CodeLength() int {
+	return m.length
+}
+
+func (m *mockSub) Handle(msg Msg) error {
+	m.msgs <- msg
+	return nil
+}
+
+func (m *mockSub) OnPeerAdded(peer Peer) error {
+	return nil
+}
+
+func (m *mockSub) OnPeerRemoved(peer Peer) error {
+	return nil
+}
+
+func TestParseCap(t *testing.T) {
+	c, err := ParseCap("light/2")
+	if err != nil || c != (Cap{"light", 2}) {
+		t.Fatalf("wrong cap: %v %v", c, err)
+	}
+	if c.String() != "light/2" {
+		t.Fatalf("wrong string: %s", c)
+	}
+	for _, str := range []string{"light", "/1", "light/", "light/x", "light/-1"} {
+		if _, err = ParseCap(str); err == nil {
+			t.Errorf("%s should be invalid", str)
+		}
+	}
+}
+
+func TestCapSet_Match(t *testing.T) {
+	var cs capSet
+	var err error
+	for _, sp := range []SubProtocol{
+		newMockSub("snap", 1, 10),
+		newMockSub("light", 1, 8),
+		newMockSub("light", 2, 12),
+		newMockSub("archive", 1, 90),
+	} {
+		if cs, err = cs.add(sp); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err = cs.add(newMockSub("light", 2, 12)); err == nil {
+		t.Fatal("duplicated capability should be rejected")
+	}
+
+	matched := cs.match([]Cap{{"light", 1}, {"light", 2}, {"snap", 1}, {"archive", 1}, {"unknown", 1}})
+	// archive needs 90 codes, can`t be allocated after light
+	if len(matched) != 1 || matched[0].sub.Cap() != (Cap{"archive", 1}) || matched[0].offset != baseCodeLength {
+		t.Fatalf("wrong matched: %v", matched)
+	}
+
+	matched = cs.match([]Cap{{"snap", 1}, {"light", 1}})
+	if len(matched) != 2 {
+		t.Fatalf("wrong matched: %v", matched)
+	}
+	if matched[0].sub.Cap() != (Cap{"light", 1}) || matched[0].offset != baseCodeLength {
+		t.Errorf("wrong light: %v %d", matched[0].sub.Cap(), matched[0].offset)
+	}
+	if matched[1].sub.Cap() != (Cap{"snap", 1}) || matched[1].offset != baseCodeLength+8 {
+		t.Errorf("wrong snap: %v %d", matched[1].sub.Cap(), matched[1].offset)
+	}
+}
+
+func TestHandshake_Caps(t *testing.T) {
+	receiver, receiverId := newTestHandshaker("receiver", false, false)
+	initiator, _ := newTestHandshaker("initiator", false, false)
+
+	light1, light2 := newMockSub("light", 1, 8), newMockSub("light", 2, 12)
+	snap := newMockSub("snap", 1, 10)
+	receiver.subs, _ = receiver.subs.add(light1)
+	receiver.subs, _ = receiver.subs.add(light2)
+	receiver.subs, _ = receiver.subs.add(snap)
+	initiatorLight := newMockSub("light", 2, 12)
+	initiator.subs, _ = initiator.subs.add(initiatorLight)
+	initiator.requiredCaps = []string{"light"}
+
+	conn1, conn2 := pipeCodecs()
+	errCh := make(chan error, 1)
+	var p1 PeerMux
+	go func() {
+		var err error
+		p1, err = receiver.ReceiveHandshake(conn1)
+		errCh <- err
+	}()
+	p2, err := initiator.InitiateHandshake(conn2, receiverId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = <-errCh; err != nil {
+		t.Fatal(err)
+	}
+
+	if caps := p1.Caps(); len(caps) != 1 || caps[0] != (Cap{"light", 2}) {
+		t.Fatalf("wrong caps: %v", caps)
+	}
+	if caps := p2.Caps(); len(caps) != 1 || caps[0] != (Cap{"light", 2}) {
+		t.Fatalf("wrong caps: %v", caps)
+	}
+
+	// sub-protocol writes relative code, peer dispatches by absolute code
+	sp := p2.(*peerMux).subs[0]
+	if err = sp.WriteMsg(Msg{Code: 12}); err != errSubCodeOutOfRange {
+		t.Fatalf("code should be out of range: %v", err)
+	}
+	go func() {
+		_ = conn2.WriteMsg(Msg{Code: baseCodeLength + 3, Payload: []byte{1}})
+	}()
+	msg, err := conn1.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg.Sender = p1
+	if err = p1.(*peerMux).handle(msg); err != nil {
+		t.Fatal(err)
+	}
+	msg = <-light2.msgs
+	if msg.Code != 3 || msg.Sender.(*subPeer).sub != light2 {
+		t.Fatalf("wrong message: %d", msg.Code)
+	}
+	if err = p1.(*peerMux).handle(Msg{Code: baseCodeLength + 12}); err != PeerUnknownMessage {
+		t.Fatalf("unknown code should be rejected: %v", err)
+	}
+
+	// missing required capability
+	receiver, receiverId = newTestHandshaker("receiver", false, false)
+	receiver.subs, _ = receiver.subs.add(snap)
+	initiator.subs = nil
+	_, _, err1, err2 := handshakeOverPipe(receiver, initiator, receiverId)
+	if err1 != nil || err2 != PeerMissingCapability {
+		t.Fatalf("capability should be required: %v %v", err1, err2)
+	}
+}
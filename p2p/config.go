@@ -75,6 +75,17 @@ type Config struct {
 	FilePublicAddress string
 	FilePort          int
 
+	// Archive will be advertised in handshake, peers prefer archive nodes to download the old ledger
+	Archive bool
+
+	// Encrypt will negotiate an encrypted transport with the peers of version 1 or higher,
+	// RequireEncrypt will reject the peers can't negotiate it
+	Encrypt        bool
+	RequireEncrypt bool
+
+	// RequiredCaps is the names of sub-protocols, the peers don't support all of them will be rejected
+	RequiredCaps []string
+
 	fileAddress []byte
 	MineKey     ed25519.PrivateKey // will be set in net
 }
@@ -43,6 +43,8 @@ type nodeDB struct {
 // node:active:ID -> int64	// active time, easy to compare time and clean
 // node:mark:ID -> int64	// mark weight
 // node:check:ID -> int64 // check time
+// node:ban:ID -> int64 // ban expiration
+// node:bans:ID -> int64 // ban times, the next ban will be longer
 var (
 	versionKey         = []byte("version")
 	nodeDataPrefix     = []byte("node:data:")
@@ -50,6 +52,8 @@ var (
 	nodeCheckPrefix    = []byte("node:check:")
 	nodeMarkPrefix     = []byte("node:mark:")
 	nodeEndPointPrefix = []byte("node:ep:")
+	nodeBanPrefix      = []byte("node:ban:")
+	nodeBansPrefix     = []byte("node:bans:")
 )
 
 func newNodeDB(path string, version int, id vnode.NodeID) (db *nodeDB, err error) {
@@ -154,6 +158,18 @@ func (db *nodeDB) StoreCheckAt(id vnode.NodeID, v int64) {
 	db.StoreInt64(key, v)
 }
 
+// RetrieveBan return the ban expiration and ban times of the node, zero if never banned
+func (db *nodeDB) RetrieveBan(id vnode.NodeID) (until int64, times int64) {
+	until = db.RetrieveInt64(append(nodeBanPrefix, id.Bytes()...))
+	times = db.RetrieveInt64(append(nodeBansPrefix, id.Bytes()...))
+	return
+}
+
+func (db *nodeDB) StoreBan(id vnode.NodeID, until int64, times int64) {
+	db.StoreInt64(append(nodeBanPrefix, id.Bytes()...), until)
+	db.StoreInt64(append(nodeBansPrefix, id.Bytes()...), times)
+}
+
 // RetrieveNode Node according to the special nodeID
 func (db *nodeDB) RetrieveNode(ID vnode.NodeID) (node *vnode.Node, err error) {
 	id := ID.Bytes()
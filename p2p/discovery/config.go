@@ -65,6 +65,10 @@ type Config struct {
 	// BootSeeds are the address where can query BootNodes, is a more flexible option than BootNodes
 	BootSeeds []string
 
+	// BootDNS are urls of the signed node trees published in DNS TXT records, looks like:
+	//  vnode-tree://<base32 public key>@nodes.example.org
+	BootDNS []string
+
 	// NetID is to mark which network our node in, nodes from different network can`t connect each other
 	NetID int
 
@@ -21,6 +21,7 @@ package discovery
 import (
 	"errors"
 	"fmt"
+	"net"
 	"sync"
 	"sync/atomic"
 	"time"
@@ -221,6 +222,14 @@ func (d *discovery) Start() (err error) {
 	if len(d.BootSeeds) > 0 {
 		d.booters = append(d.booters, newNetBooter(d.node, d.BootSeeds))
 	}
+	if len(d.BootDNS) > 0 {
+		var bt booter
+		bt, err = newDNSBooter(d.node, d.BootDNS, net.DefaultResolver)
+		if err != nil {
+			return err
+		}
+		d.booters = append(d.booters, bt)
+	}
 	if len(d.BootNodes) > 0 {
 		var bt booter
 		bt, err = newCfgBooter(d.BootNodes, d.node)
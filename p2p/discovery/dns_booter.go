@@ -0,0 +1,317 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+)
+
+const dnsTimeout = 10 * time.Second
+
+// the root of a tree will be resolved again after dnsRefresh
+const dnsRefresh = 30 * time.Minute
+
+// limit the tree depth and the number of linked trees, avoid endless resolving
+const maxDNSTreeDepth = 8
+const maxDNSTrees = 16
+
+// limit the TXT lookups of a sync, the entries of a tree are cached only for its current seq
+const maxDNSLookups = 2000
+
+var errDNSMissingRoot = errors.New("missing dns tree root")
+var errDNSMissingEntry = errors.New("missing dns entry")
+var errDNSSeqRollback = errors.New("seq of dns tree rolled back")
+var errDNSTooDeep = errors.New("dns tree is too deep")
+var errDNSUnexpectedEntry = errors.New("unexpected dns entry")
+var errDNSTooManyLookups = errors.New("too many dns lookups")
+
+// DNSResolver look up TXT records, *net.Resolver is the default implementation
+type DNSResolver interface {
+	LookupTXT(ctx context.Context, domain string) ([]string, error)
+}
+
+type dnsTreeState struct {
+	seq     uint64
+	nodes   []*vnode.Node
+	links   []*dnsLink
+	entries map[string]dnsEntry // entries of the seq by hash, the unchanged ones are reused by the next seq
+	fetched time.Time
+}
+
+// dnsClient resolve and verify trees, the entries are cached by the tree state
+type dnsClient struct {
+	resolver   DNSResolver
+	trees      map[string]*dnsTreeState // key is the tree url
+	lookups    int                      // lookups of the current sync
+	maxLookups int
+	now        func() time.Time
+}
+
+func newDNSClient(resolver DNSResolver) *dnsClient {
+	return &dnsClient{
+		resolver:   resolver,
+		trees:      make(map[string]*dnsTreeState),
+		maxLookups: maxDNSLookups,
+		now:        time.Now,
+	}
+}
+
+// sync return nodes of the trees and all linked trees, the states of the trees not linked any more are removed
+func (c *dnsClient) sync(links []*dnsLink, onError func(link *dnsLink, err error)) (nodes []*vnode.Node) {
+	c.lookups = 0
+
+	var visited = make(map[string]struct{})
+	for _, link := range links {
+		vnodes, err := c.syncTree(link, visited)
+		if err != nil {
+			onError(link, err)
+		}
+		nodes = append(nodes, vnodes...)
+	}
+
+	for url := range c.trees {
+		if _, ok := visited[url]; !ok {
+			delete(c.trees, url)
+		}
+	}
+
+	return
+}
+
+func (c *dnsClient) lookup(name string) ([]string, error) {
+	if c.lookups >= c.maxLookups {
+		return nil, errDNSTooManyLookups
+	}
+	c.lookups++
+
+	ctx, cancel := context.WithTimeout(context.Background(), dnsTimeout)
+	defer cancel()
+
+	return c.resolver.LookupTXT(ctx, name)
+}
+
+func (c *dnsClient) resolveRoot(link *dnsLink) (*dnsRoot, error) {
+	txts, err := c.lookup(link.domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, txt := range txts {
+		if !strings.HasPrefix(txt, dnsRootPrefix) {
+			continue
+		}
+
+		root, err := parseDNSRoot(txt)
+		if err != nil {
+			return nil, err
+		}
+		if !root.verify(link.pub) {
+			return nil, errDNSInvalidSignature
+		}
+		return root, nil
+	}
+
+	return nil, errDNSMissingRoot
+}
+
+// resolveEntry will verify the entry content against the hash, the entry is reused if it's in the previous seq
+func (c *dnsClient) resolveEntry(domain, hash string, prev, state *dnsTreeState) (dnsEntry, error) {
+	if e, ok := state.entries[hash]; ok {
+		return e, nil
+	}
+	if prev != nil {
+		if e, ok := prev.entries[hash]; ok {
+			state.entries[hash] = e
+			return e, nil
+		}
+	}
+
+	txts, err := c.lookup(hash + "." + domain)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, txt := range txts {
+		if dnsHash(txt) != hash {
+			continue
+		}
+
+		e, err := parseDNSEntry(txt)
+		if err != nil {
+			return nil, err
+		}
+		state.entries[hash] = e
+		return e, nil
+	}
+
+	return nil, errDNSMissingEntry
+}
+
+// traverse the subtree, nodes and links are collected, one subtree should contain only one kind of them
+func (c *dnsClient) traverse(domain, hash string, depth int, prev, state *dnsTreeState, isLink bool) error {
+	if depth > maxDNSTreeDepth {
+		return errDNSTooDeep
+	}
+
+	e, err := c.resolveEntry(domain, hash, prev, state)
+	if err != nil {
+		return fmt.Errorf("failed to resolve %s.%s: %v", hash, domain, err)
+	}
+
+	switch e := e.(type) {
+	case dnsBranch:
+		for _, child := range e {
+			if err = c.traverse(domain, child, depth+1, prev, state, isLink); err != nil {
+				return err
+			}
+		}
+	case *dnsNode:
+		if isLink {
+			return errDNSUnexpectedEntry
+		}
+		state.nodes = append(state.nodes, e.node)
+	case *dnsLink:
+		if !isLink {
+			return errDNSUnexpectedEntry
+		}
+		state.links = append(state.links, e)
+	default:
+		return errDNSUnexpectedEntry
+	}
+
+	return nil
+}
+
+// syncTree return nodes of the tree and all linked trees, the tree is resolved only if the seq changed.
+// visited avoid resolving a tree twice.
+func (c *dnsClient) syncTree(link *dnsLink, visited map[string]struct{}) (nodes []*vnode.Node, err error) {
+	url := link.String()
+	if _, ok := visited[url]; ok || len(visited) >= maxDNSTrees {
+		return nil, nil
+	}
+	visited[url] = struct{}{}
+
+	now := c.now()
+	state := c.trees[url]
+	if state == nil || now.Sub(state.fetched) > dnsRefresh {
+		var root *dnsRoot
+		if root, err = c.resolveRoot(link); err != nil {
+			return nil, err
+		}
+
+		if state != nil && root.seq < state.seq {
+			return nil, errDNSSeqRollback
+		}
+
+		if state == nil || root.seq > state.seq {
+			prev := state
+			state = &dnsTreeState{
+				seq:     root.seq,
+				entries: make(map[string]dnsEntry),
+			}
+			if err = c.traverse(link.domain, root.eroot, 0, prev, state, false); err != nil {
+				return nil, err
+			}
+			if err = c.traverse(link.domain, root.lroot, 0, prev, state, true); err != nil {
+				return nil, err
+			}
+		}
+
+		state.fetched = now
+		c.trees[url] = state
+	}
+
+	nodes = append(nodes, state.nodes...)
+	for _, l := range state.links {
+		var linked []*vnode.Node
+		if linked, err = c.syncTree(l, visited); err != nil {
+			return nodes, fmt.Errorf("failed to resolve linked tree %s: %v", l, err)
+		}
+		nodes = append(nodes, linked...)
+	}
+
+	return nodes, nil
+}
+
+// dnsBooter supply bootNodes from trees published in DNS
+type dnsBooter struct {
+	self   *vnode.Node
+	links  []*dnsLink
+	mu     sync.Mutex
+	client *dnsClient
+	log    log15.Logger
+}
+
+func newDNSBooter(self *vnode.Node, urls []string, resolver DNSResolver) (booter, error) {
+	var d = &dnsBooter{
+		self:   self,
+		links:  make([]*dnsLink, len(urls)),
+		client: newDNSClient(resolver),
+		log:    discvLog.New("module", "dnsBooter"),
+	}
+
+	var err error
+	for i, url := range urls {
+		d.links[i], err = parseDNSLink(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse BootDNS: %s", url)
+		}
+	}
+
+	return d, nil
+}
+
+func (d *dnsBooter) getBootNodes(count int) (nodes []*Node) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	vnodes := d.client.sync(d.links, func(link *dnsLink, err error) {
+		d.log.Error(fmt.Sprintf("failed to resolve dns tree %s: %v", link, err))
+	})
+
+	for _, n := range vnodes {
+		if n.ID == d.self.ID || (n.Net != 0 && n.Net != d.self.Net) {
+			continue
+		}
+
+		node := &Node{
+			Node: *n,
+		}
+		node.Net = d.self.Net
+		nodes = append(nodes, node)
+	}
+
+	if count < len(nodes) {
+		rand.Shuffle(len(nodes), func(i, j int) {
+			nodes[i], nodes[j] = nodes[j], nodes[i]
+		})
+		nodes = nodes[:count]
+	}
+
+	return
+}
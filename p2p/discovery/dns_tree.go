@@ -0,0 +1,315 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"encoding/base32"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vitelabs/go-vite/crypto"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+)
+
+// Nodes are published in DNS TXT records as a tree, like EIP-1459:
+//  <domain>          vnode-root:v1 e=<hash> l=<hash> seq=<seq> sig=<signature>
+//  <hash>.<domain>   vnode-branch:<hash>,<hash>,...
+//  <hash>.<domain>   vnode://<node>
+//  <hash>.<domain>   vnode-tree://<public key>@<domain>
+// the e subtree contains nodes, the l subtree contains links to other trees.
+// only the root is signed, every other record is named by the hash of its content.
+// the url of a tree is vnode-tree://<public key>@<domain>
+
+const (
+	dnsRootPrefix   = "vnode-root:v1"
+	dnsBranchPrefix = "vnode-branch:"
+	dnsLinkPrefix   = "vnode-tree://"
+	dnsNodePrefix   = "vnode://"
+)
+
+// a TXT string is no longer than 255 bytes, so a branch can hold 9 hashes at most
+const maxDNSBranchChildren = 9
+const dnsHashLength = 16
+
+var dnsB32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+var errDNSInvalidEntry = errors.New("invalid dns entry")
+var errDNSInvalidURL = errors.New("invalid dns tree url")
+var errDNSInvalidSignature = errors.New("invalid signature of dns tree root")
+var errDNSUnsigned = errors.New("dns tree is not signed")
+
+type dnsEntry interface {
+	String() string
+}
+
+type dnsRoot struct {
+	eroot string
+	lroot string
+	seq   uint64
+	sig   []byte
+}
+
+func (r *dnsRoot) signedText() string {
+	return fmt.Sprintf("%s e=%s l=%s seq=%d", dnsRootPrefix, r.eroot, r.lroot, r.seq)
+}
+
+func (r *dnsRoot) String() string {
+	return r.signedText() + " sig=" + base64.RawURLEncoding.EncodeToString(r.sig)
+}
+
+func (r *dnsRoot) verify(pub ed25519.PublicKey) bool {
+	return len(r.sig) == ed25519.SignatureSize && ed25519.Verify(pub, crypto.Hash256([]byte(r.signedText())), r.sig)
+}
+
+type dnsBranch []string
+
+func (b dnsBranch) String() string {
+	return dnsBranchPrefix + strings.Join(b, ",")
+}
+
+type dnsNode struct {
+	node *vnode.Node
+}
+
+func (n *dnsNode) String() string {
+	return dnsNodePrefix + n.node.String()
+}
+
+type dnsLink struct {
+	pub    ed25519.PublicKey
+	domain string
+}
+
+func (l *dnsLink) String() string {
+	return dnsLinkPrefix + dnsB32.EncodeToString(l.pub) + "@" + l.domain
+}
+
+func dnsHash(txt string) string {
+	return dnsB32.EncodeToString(crypto.Hash256([]byte(txt))[:dnsHashLength])
+}
+
+func isDNSHash(str string) bool {
+	buf, err := dnsB32.DecodeString(str)
+	return err == nil && len(buf) == dnsHashLength
+}
+
+func parseDNSLink(url string) (*dnsLink, error) {
+	if !strings.HasPrefix(url, dnsLinkPrefix) {
+		return nil, errDNSInvalidURL
+	}
+
+	url = url[len(dnsLinkPrefix):]
+	index := strings.IndexRune(url, '@')
+	if index < 0 || index == len(url)-1 {
+		return nil, errDNSInvalidURL
+	}
+
+	pub, err := dnsB32.DecodeString(url[:index])
+	if err != nil || len(pub) != ed25519.PublicKeySize {
+		return nil, errDNSInvalidURL
+	}
+
+	return &dnsLink{
+		pub:    pub,
+		domain: url[index+1:],
+	}, nil
+}
+
+func parseDNSRoot(txt string) (r *dnsRoot, err error) {
+	fields := strings.Fields(txt)
+	if len(fields) != 5 || fields[0] != dnsRootPrefix {
+		return nil, errDNSInvalidEntry
+	}
+
+	r = new(dnsRoot)
+	for _, field := range fields[1:] {
+		index := strings.IndexRune(field, '=')
+		if index < 0 {
+			return nil, errDNSInvalidEntry
+		}
+
+		value := field[index+1:]
+		switch field[:index] {
+		case "e":
+			r.eroot = value
+		case "l":
+			r.lroot = value
+		case "seq":
+			if r.seq, err = strconv.ParseUint(value, 10, 64); err != nil {
+				return nil, errDNSInvalidEntry
+			}
+		case "sig":
+			if r.sig, err = base64.RawURLEncoding.DecodeString(value); err != nil {
+				return nil, errDNSInvalidEntry
+			}
+		default:
+			return nil, errDNSInvalidEntry
+		}
+	}
+
+	if !isDNSHash(r.eroot) || !isDNSHash(r.lroot) {
+		return nil, errDNSInvalidEntry
+	}
+
+	return r, nil
+}
+
+func parseDNSEntry(txt string) (dnsEntry, error) {
+	switch {
+	case strings.HasPrefix(txt, dnsRootPrefix):
+		return parseDNSRoot(txt)
+	case strings.HasPrefix(txt, dnsBranchPrefix):
+		txt = txt[len(dnsBranchPrefix):]
+		if txt == "" {
+			return dnsBranch{}, nil
+		}
+
+		branch := dnsBranch(strings.Split(txt, ","))
+		if len(branch) > maxDNSBranchChildren {
+			return nil, errDNSInvalidEntry
+		}
+		for _, hash := range branch {
+			if !isDNSHash(hash) {
+				return nil, errDNSInvalidEntry
+			}
+		}
+		return branch, nil
+	case strings.HasPrefix(txt, dnsLinkPrefix):
+		return parseDNSLink(txt)
+	case strings.HasPrefix(txt, dnsNodePrefix):
+		node, err := vnode.ParseNode(txt)
+		if err != nil {
+			return nil, fmt.Errorf("invalid node entry %s: %v", txt, err)
+		}
+		return &dnsNode{node}, nil
+	default:
+		return nil, errDNSInvalidEntry
+	}
+}
+
+// DNSTree is a tree of nodes and links to other trees, can be published in DNS TXT records after signed
+type DNSTree struct {
+	root    *dnsRoot
+	entries map[string]dnsEntry
+}
+
+// MakeDNSTree create an unsigned tree, links are urls of other trees
+func MakeDNSTree(seq uint64, nodes []*vnode.Node, links []string) (*DNSTree, error) {
+	t := &DNSTree{
+		entries: make(map[string]dnsEntry),
+	}
+
+	var nodeEntries = make([]dnsEntry, 0, len(nodes))
+	for _, n := range nodes {
+		if n.EndPoint.Hostname() == "" {
+			return nil, fmt.Errorf("node %s missing host", n.ID)
+		}
+		nodeEntries = append(nodeEntries, &dnsNode{n})
+	}
+
+	var linkEntries = make([]dnsEntry, 0, len(links))
+	for _, url := range links {
+		link, err := parseDNSLink(url)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse link %s: %v", url, err)
+		}
+		linkEntries = append(linkEntries, link)
+	}
+
+	t.root = &dnsRoot{
+		eroot: t.build(nodeEntries),
+		lroot: t.build(linkEntries),
+		seq:   seq,
+	}
+
+	return t, nil
+}
+
+// build the subtree and return hash of the subtree root, entries are sorted to make the tree stable
+func (t *DNSTree) build(entries []dnsEntry) string {
+	hashes := make([]string, len(entries))
+	for i, e := range entries {
+		hashes[i] = t.add(e)
+	}
+	sort.Strings(hashes)
+
+	for len(hashes) > maxDNSBranchChildren {
+		var parents []string
+		for i := 0; i < len(hashes); i += maxDNSBranchChildren {
+			end := i + maxDNSBranchChildren
+			if end > len(hashes) {
+				end = len(hashes)
+			}
+			parents = append(parents, t.add(dnsBranch(hashes[i:end])))
+		}
+		hashes = parents
+	}
+
+	return t.add(dnsBranch(hashes))
+}
+
+func (t *DNSTree) add(e dnsEntry) string {
+	hash := dnsHash(e.String())
+	t.entries[hash] = e
+	return hash
+}
+
+// Sign the tree root and return url of the tree
+func (t *DNSTree) Sign(key ed25519.PrivateKey, domain string) (url string) {
+	t.root.sig = ed25519.Sign(key, crypto.Hash256([]byte(t.root.signedText())))
+
+	link := &dnsLink{
+		pub:    key.PubByte(),
+		domain: domain,
+	}
+	return link.String()
+}
+
+func (t *DNSTree) Seq() uint64 {
+	return t.root.seq
+}
+
+func (t *DNSTree) Nodes() (nodes []*vnode.Node) {
+	for _, e := range t.entries {
+		if n, ok := e.(*dnsNode); ok {
+			nodes = append(nodes, n.node)
+		}
+	}
+	return
+}
+
+// Records return the TXT records to publish, key is the domain name
+func (t *DNSTree) Records(domain string) (map[string]string, error) {
+	if len(t.root.sig) == 0 {
+		return nil, errDNSUnsigned
+	}
+
+	records := make(map[string]string, len(t.entries)+1)
+	records[domain] = t.root.String()
+	for hash, e := range t.entries {
+		records[hash+"."+domain] = e.String()
+	}
+
+	return records, nil
+}
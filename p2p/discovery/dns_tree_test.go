@@ -0,0 +1,224 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+)
+
+// stubResolver answer TXT records from memory
+type stubResolver map[string]string
+
+func (r stubResolver) LookupTXT(ctx context.Context, domain string) ([]string, error) {
+	if txt, ok := r[domain]; ok {
+		return []string{txt}, nil
+	}
+	return nil, fmt.Errorf("no such host %s", domain)
+}
+
+func (r stubResolver) publish(t *testing.T, tree *DNSTree, domain string) {
+	records, err := tree.Records(domain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, txt := range records {
+		if len(txt) > 255 {
+			t.Fatalf("record %s is too long: %d", name, len(txt))
+		}
+		r[name] = txt
+	}
+}
+
+func mockDNSNodes(count int) (nodes []*vnode.Node) {
+	for i := 0; i < count; i++ {
+		n := vnode.MockNode(i%2 == 0, false)
+		n.Net = 0
+		nodes = append(nodes, n)
+	}
+	return
+}
+
+func makeSignedDNSTree(t *testing.T, seq uint64, nodes []*vnode.Node, links []string, domain string) (*DNSTree, string, ed25519.PrivateKey) {
+	tree, err := MakeDNSTree(seq, nodes, links)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, key, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return tree, tree.Sign(key, domain), key
+}
+
+func TestParseDNSEntry(t *testing.T) {
+	tree, url, _ := makeSignedDNSTree(t, 3, mockDNSNodes(30), nil, "nodes.vite.org")
+
+	records, err := tree.Records("nodes.vite.org")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, txt := range records {
+		e, err := parseDNSEntry(txt)
+		if err != nil {
+			t.Fatalf("failed to parse %s: %v", name, err)
+		}
+		if e.String() != txt {
+			t.Errorf("different entry %s %s", e, txt)
+		}
+	}
+
+	link, err := parseDNSLink(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if link.String() != url || link.domain != "nodes.vite.org" {
+		t.Errorf("wrong link %s", link)
+	}
+
+	for _, txt := range []string{
+		"vnode-root:v1 e=xxx l=xxx seq=1 sig=",
+		"vnode-branch:abc",
+		"vnode-tree://abc@nodes.vite.org",
+		"vnode://@",
+		"unknown",
+	} {
+		if _, err = parseDNSEntry(txt); err == nil {
+			t.Errorf("should fail to parse %s", txt)
+		}
+	}
+}
+
+func TestDNSBooter(t *testing.T) {
+	resolver := make(stubResolver)
+
+	// the linked tree
+	linked, linkedURL, _ := makeSignedDNSTree(t, 1, mockDNSNodes(2), nil, "linked.vite.org")
+	resolver.publish(t, linked, "linked.vite.org")
+
+	nodes := mockDNSNodes(30)
+	tree, url, key := makeSignedDNSTree(t, 1, nodes, []string{linkedURL}, "nodes.vite.org")
+	resolver.publish(t, tree, "nodes.vite.org")
+
+	self := vnode.MockNode(false, false)
+	bt, err := newDNSBooter(self, []string{url}, resolver)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bootNodes := bt.getBootNodes(100)
+	if len(bootNodes) != 32 {
+		t.Fatalf("should get 32 nodes, not %d", len(bootNodes))
+	}
+	for _, n := range bootNodes {
+		if n.Net != self.Net {
+			t.Errorf("net of boot node should be %d, not %d", self.Net, n.Net)
+		}
+	}
+	if bootNodes = bt.getBootNodes(10); len(bootNodes) != 10 {
+		t.Errorf("should get 10 nodes, not %d", len(bootNodes))
+	}
+
+	// the entries of the previous seq are reused, only the new root is resolved
+	client := bt.(*dnsBooter).client
+	tree2, _, _ := makeSignedDNSTree(t, 2, nodes, []string{linkedURL}, "nodes.vite.org")
+	tree2.Sign(key, "nodes.vite.org")
+	for name := range resolver {
+		if name != "nodes.vite.org" && name != "linked.vite.org" {
+			delete(resolver, name)
+		}
+	}
+	resolver.publish(t, tree2, "nodes.vite.org")
+	for name := range resolver {
+		if name != "nodes.vite.org" && name != "linked.vite.org" && name != tree2.root.eroot+".nodes.vite.org" && name != tree2.root.lroot+".nodes.vite.org" {
+			delete(resolver, name)
+		}
+	}
+	now := time.Now()
+	client.now = func() time.Time { return now.Add(2 * dnsRefresh) }
+	if bootNodes = bt.getBootNodes(100); len(bootNodes) != 32 {
+		t.Errorf("should get 32 nodes from cache, not %d", len(bootNodes))
+	}
+	if state := client.trees[url]; state == nil || state.seq != 2 {
+		t.Errorf("tree should be updated to seq 2")
+	}
+	client.now = time.Now
+
+	// the lookups of a sync are limited
+	client.trees = make(map[string]*dnsTreeState)
+	resolver.publish(t, tree2, "nodes.vite.org")
+	resolver.publish(t, linked, "linked.vite.org")
+	client.maxLookups = 10
+	if bootNodes = bt.getBootNodes(100); len(bootNodes) != 0 {
+		t.Errorf("should get no nodes if the lookups are limited, not %d", len(bootNodes))
+	}
+	if client.lookups != 10 {
+		t.Errorf("should lookup 10 times, not %d", client.lookups)
+	}
+	client.maxLookups = maxDNSLookups
+
+	// the trees not linked any more are removed
+	client.trees["vnode-tree://unlinked@unlinked.vite.org"] = &dnsTreeState{seq: 1}
+	if bootNodes = bt.getBootNodes(100); len(bootNodes) != 32 {
+		t.Errorf("should get 32 nodes, not %d", len(bootNodes))
+	}
+	if len(client.trees) != 2 {
+		t.Errorf("should keep 2 trees, not %d", len(client.trees))
+	}
+
+	// seq rolled back
+	client.trees = make(map[string]*dnsTreeState)
+	client.trees[url] = &dnsTreeState{seq: 3}
+	if _, err = client.syncTree(mustParseDNSLink(t, url), make(map[string]struct{})); err != errDNSSeqRollback {
+		t.Errorf("should be rolled back: %v", err)
+	}
+
+	// the root is signed by another key
+	_, other, _ := ed25519.GenerateKey(nil)
+	tree.Sign(other, "nodes.vite.org")
+	resolver.publish(t, tree, "nodes.vite.org")
+	client.trees = make(map[string]*dnsTreeState)
+	if _, err = client.syncTree(mustParseDNSLink(t, url), make(map[string]struct{})); err != errDNSInvalidSignature {
+		t.Errorf("should be invalid signature: %v", err)
+	}
+
+	// tampered entry
+	tree.Sign(key, "nodes.vite.org")
+	resolver.publish(t, tree, "nodes.vite.org")
+	client.trees = make(map[string]*dnsTreeState)
+	resolver[tree.root.eroot+".nodes.vite.org"] = dnsBranchPrefix
+	if _, err = client.syncTree(mustParseDNSLink(t, url), make(map[string]struct{})); err == nil {
+		t.Error("tampered entry should be rejected")
+	}
+}
+
+func mustParseDNSLink(t *testing.T, url string) *dnsLink {
+	link, err := parseDNSLink(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return link
+}
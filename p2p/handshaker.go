@@ -70,21 +70,35 @@ type HandshakeMsg struct {
 	Token []byte
 
 	FileAddress []byte
+
+	// Archive is true if the peer keeps the full history of ledger
+	Archive bool
+
+	// EphemeralKey is the x25519 public key to negotiate the encrypted transport, since versionEncrypted
+	EphemeralKey []byte
+
+	// Caps is the sub-protocols supported
+	Caps []Cap
 }
 
 func (b *HandshakeMsg) Serialize() (data []byte, err error) {
 	pb := &vitepb.Handshake{
-		Version:     b.Version,
-		NetId:       b.NetID,
-		Name:        b.Name,
-		ID:          b.ID.Bytes(),
-		Timestamp:   b.Timestamp,
-		Genesis:     b.Genesis.Bytes(),
-		Height:      b.Height,
-		Head:        b.Head.Bytes(),
-		FileAddress: b.FileAddress,
-		Key:         b.Key,
-		Token:       b.Token,
+		Version:      b.Version,
+		NetId:        b.NetID,
+		Name:         b.Name,
+		ID:           b.ID.Bytes(),
+		Timestamp:    b.Timestamp,
+		Genesis:      b.Genesis.Bytes(),
+		Height:       b.Height,
+		Head:         b.Head.Bytes(),
+		FileAddress:  b.FileAddress,
+		Key:          b.Key,
+		Token:        b.Token,
+		Archive:      b.Archive,
+		EphemeralKey: b.EphemeralKey,
+	}
+	for _, c := range b.Caps {
+		pb.Caps = append(pb.Caps, c.String())
 	}
 
 	return proto.Marshal(pb)
@@ -117,6 +131,14 @@ func (b *HandshakeMsg) Deserialize(data []byte) (err error) {
 		return
 	}
 	b.FileAddress = pb.FileAddress
+	b.Archive = pb.Archive
+	b.EphemeralKey = pb.EphemeralKey
+	for _, str := range pb.Caps {
+		// ignore the capabilities can`t understand
+		if c, err := ParseCap(str); err == nil {
+			b.Caps = append(b.Caps, c)
+		}
+	}
 
 	b.Key = pb.Key
 	b.Token = pb.Token
@@ -131,6 +153,15 @@ type handshaker struct {
 	id          vnode.NodeID
 	genesis     types.Hash
 	fileAddress []byte
+	archive     bool
+
+	// encrypt the transport if the peer supports, reject the peer doesn't support if requireEncrypt
+	encrypt        bool
+	requireEncrypt bool
+
+	// subs is the sub-protocols registered, reject the peer doesn't support all of requiredCaps
+	subs         capSet
+	requiredCaps []string
 
 	peerKey ed25519.PrivateKey
 	key     ed25519.PrivateKey
@@ -140,7 +171,7 @@ type handshaker struct {
 	log log15.Logger
 }
 
-func (h *handshaker) readHandshake(c Codec) (their *HandshakeMsg, id MsgId, err error) {
+func (h *handshaker) readHandshake(c Codec) (their *HandshakeMsg, payload []byte, id MsgId, err error) {
 	c.SetReadTimeout(handshakeTimeout)
 	msg, err := c.ReadMsg()
 	if err != nil {
@@ -164,6 +195,7 @@ func (h *handshaker) readHandshake(c Codec) (their *HandshakeMsg, id MsgId, err
 		return
 	}
 
+	payload = msg.Payload
 	their = new(HandshakeMsg)
 	err = their.Deserialize(msg.Payload)
 	if err != nil {
@@ -174,8 +206,44 @@ func (h *handshaker) readHandshake(c Codec) (their *HandshakeMsg, id MsgId, err
 	return
 }
 
+// advertisedVersion is the version sent in handshake, the minimal version accepted is still h.version
+func (h *handshaker) advertisedVersion() int64 {
+	if h.encrypt && h.version < versionEncrypted {
+		return versionEncrypted
+	}
+	return int64(h.version)
+}
+
+// canEncrypt returns true if the encrypted transport should be used with the peer
+func (h *handshaker) canEncrypt(c Codec, their *HandshakeMsg) bool {
+	if _, ok := c.(secureCodec); !ok || !h.encrypt {
+		return false
+	}
+	return their.Version >= versionEncrypted && len(their.EphemeralKey) == ephemeralKeyLength
+}
+
+// secure switches the codec to the encrypted transport, must be called after both handshake messages are transmitted
+func (h *handshaker) secure(c Codec, ephemeral *ephemeralKey, their *HandshakeMsg, staticSecret, initiatorHandshake, receiverHandshake []byte, initiator bool) error {
+	ephemeralSecret, err := ephemeral.computeSecret(their.EphemeralKey)
+	if err != nil {
+		return PeerInvalidMessage
+	}
+
+	initiatorKey, receiverKey := deriveSecureKeys(staticSecret, ephemeralSecret, initiatorHandshake, receiverHandshake)
+	if initiator {
+		err = c.(secureCodec).secure(receiverKey, initiatorKey)
+	} else {
+		err = c.(secureCodec).secure(initiatorKey, receiverKey)
+	}
+	if err != nil {
+		return PeerNetworkError
+	}
+
+	return nil
+}
+
 func (h *handshaker) ReceiveHandshake(c Codec) (peer PeerMux, err error) {
-	their, msgId, err := h.readHandshake(c)
+	their, request, msgId, err := h.readHandshake(c)
 	if err != nil {
 		return
 	}
@@ -200,18 +268,25 @@ func (h *handshaker) ReceiveHandshake(c Codec) (peer PeerMux, err error) {
 		}
 	}
 
+	encrypt := h.canEncrypt(c, their)
+	if h.requireEncrypt && !encrypt {
+		return nil, PeerEncryptionRequired
+	}
+
 	peer, err = h.doHandshake(c, Inbound, their)
 	if err != nil {
 		return nil, err
 	}
 
 	response := HandshakeMsg{
-		Version:     int64(h.version),
+		Version:     h.advertisedVersion(),
 		NetID:       int64(h.netId),
 		Name:        h.name,
 		ID:          h.id,
 		Timestamp:   time.Now().Unix(),
 		FileAddress: h.fileAddress,
+		Archive:     h.archive,
+		Caps:        h.subs.caps(),
 	}
 	response.Height, response.Head, response.Genesis = h.protocol.ProtoData()
 	binary.BigEndian.PutUint64(t, uint64(response.Timestamp))
@@ -221,6 +296,13 @@ func (h *handshaker) ReceiveHandshake(c Codec) (peer PeerMux, err error) {
 		response.Key = h.key.PubByte()
 		response.Token = ed25519.Sign(h.key, response.Token)
 	}
+	var ephemeral *ephemeralKey
+	if encrypt {
+		if ephemeral, err = newEphemeralKey(); err != nil {
+			return nil, PeerUnknownReason
+		}
+		response.EphemeralKey = ephemeral.pub[:]
+	}
 	data, err := response.Serialize()
 	if err != nil {
 		return nil, PeerUnmarshalError
@@ -236,17 +318,25 @@ func (h *handshaker) ReceiveHandshake(c Codec) (peer PeerMux, err error) {
 		return nil, PeerNetworkError
 	}
 
+	if encrypt {
+		if err = h.secure(c, ephemeral, their, secret, request, data, false); err != nil {
+			return nil, err
+		}
+	}
+
 	return
 }
 
 func (h *handshaker) InitiateHandshake(c Codec, id vnode.NodeID) (peer PeerMux, err error) {
 	request := HandshakeMsg{
-		Version:     int64(h.version),
+		Version:     h.advertisedVersion(),
 		NetID:       int64(h.netId),
 		Name:        h.name,
 		ID:          h.id,
 		Timestamp:   time.Now().Unix(),
 		FileAddress: h.fileAddress,
+		Archive:     h.archive,
+		Caps:        h.subs.caps(),
 	}
 	request.Height, request.Head, request.Genesis = h.protocol.ProtoData()
 
@@ -265,6 +355,13 @@ func (h *handshaker) InitiateHandshake(c Codec, id vnode.NodeID) (peer PeerMux,
 		request.Key = h.key.PubByte()
 		request.Token = ed25519.Sign(h.key, request.Token)
 	}
+	var ephemeral *ephemeralKey
+	if _, ok := c.(secureCodec); ok && h.encrypt {
+		if ephemeral, err = newEphemeralKey(); err != nil {
+			return nil, PeerUnknownReason
+		}
+		request.EphemeralKey = ephemeral.pub[:]
+	}
 	data, err := request.Serialize()
 	if err != nil {
 		return nil, PeerUnmarshalError
@@ -279,7 +376,7 @@ func (h *handshaker) InitiateHandshake(c Codec, id vnode.NodeID) (peer PeerMux,
 		return nil, PeerNetworkError
 	}
 
-	their, _, err := h.readHandshake(c)
+	their, response, _, err := h.readHandshake(c)
 	if err != nil {
 		return
 	}
@@ -295,7 +392,24 @@ func (h *handshaker) InitiateHandshake(c Codec, id vnode.NodeID) (peer PeerMux,
 		return nil, PeerInvalidToken
 	}
 
-	return h.doHandshake(c, Outbound, their)
+	// the receiver sends ephemeral key only if it has chosen to encrypt
+	encrypt := ephemeral != nil && h.canEncrypt(c, their)
+	if h.requireEncrypt && !encrypt {
+		return nil, PeerEncryptionRequired
+	}
+
+	peer, err = h.doHandshake(c, Outbound, their)
+	if err != nil {
+		return nil, err
+	}
+
+	if encrypt {
+		if err = h.secure(c, ephemeral, their, secret, data, response, true); err != nil {
+			return nil, err
+		}
+	}
+
+	return
 }
 
 func (h *handshaker) doHandshake(c Codec, level Level, their *HandshakeMsg) (peer PeerMux, err error) {
@@ -314,6 +428,21 @@ func (h *handshaker) doHandshake(c Codec, level Level, their *HandshakeMsg) (pee
 		return
 	}
 
+	matched := h.subs.match(their.Caps)
+	for _, name := range h.requiredCaps {
+		var ok bool
+		for _, mc := range matched {
+			if mc.sub.Cap().Name == name {
+				ok = true
+				break
+			}
+		}
+		if !ok {
+			err = PeerMissingCapability
+			return
+		}
+	}
+
 	level2, err := h.protocol.ReceiveHandshake(their)
 	if err != nil {
 		return
@@ -325,6 +454,8 @@ func (h *handshaker) doHandshake(c Codec, level Level, their *HandshakeMsg) (pee
 		level = level2
 	}
 	peer = NewPeer(their.ID, their.Name, their.Height, their.Head, fileAddress, int(their.Version), c, level, h.protocol)
+	peer.setArchive(their.Archive)
+	peer.setCaps(matched)
 
 	return
 }
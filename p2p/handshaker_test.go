@@ -41,6 +41,7 @@ func TestProtoHandshakeMsg_Serialize(t *testing.T) {
 		Key:         []byte{1, 2, 3},
 		Token:       []byte{5, 6, 7},
 		FileAddress: []byte{1, 2},
+		Archive:     true,
 	}
 
 	data, err := msg.Serialize()
@@ -87,6 +88,9 @@ func TestProtoHandshakeMsg_Serialize(t *testing.T) {
 	if false == bytes.Equal(msg.Token, msg2.Token) {
 		t.Errorf("different token: %v %v", msg.Token, msg2.Token)
 	}
+	if msg.Archive != msg2.Archive {
+		t.Errorf("different archive: %v %v", msg.Archive, msg2.Archive)
+	}
 
 }
 
@@ -232,3 +236,108 @@ func TestHandshake(t *testing.T) {
 
 	wg.Wait()
 }
+
+func newTestHandshaker(name string, encrypt, requireEncrypt bool) (hk *handshaker, id vnode.NodeID) {
+	pub, priv, err := ed25519.GenerateKey(nil)
+	if err != nil {
+		panic(err)
+	}
+	id, _ = vnode.Bytes2NodeID(pub)
+
+	return &handshaker{
+		version:        0,
+		netId:          7,
+		name:           name,
+		id:             id,
+		genesis:        types.Hash{4, 5, 6},
+		encrypt:        encrypt,
+		requireEncrypt: requireEncrypt,
+		peerKey:        priv,
+		protocol:       &mockProtocol{},
+	}, id
+}
+
+func pipeCodecs() (c1, c2 Codec) {
+	conn1, conn2 := net.Pipe()
+	return NewTransport(conn1, 100, readMsgTimeout, writeMsgTimeout), NewTransport(conn2, 100, readMsgTimeout, writeMsgTimeout)
+}
+
+func handshakeOverPipe(receiver, initiator *handshaker, receiverId vnode.NodeID) (c1, c2 Codec, err1, err2 error) {
+	c1, c2 = pipeCodecs()
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		if _, err1 = receiver.ReceiveHandshake(c1); err1 != nil {
+			_ = Disconnect(c1, err1)
+		}
+	}()
+
+	_, err2 = initiator.InitiateHandshake(c2, receiverId)
+	if err2 != nil {
+		_ = c2.Close()
+	}
+	wg.Wait()
+
+	return
+}
+
+func TestHandshake_Encrypt(t *testing.T) {
+	receiver, receiverId := newTestHandshaker("receiver", true, false)
+	initiator, _ := newTestHandshaker("initiator", true, false)
+
+	c1, c2, err1, err2 := handshakeOverPipe(receiver, initiator, receiverId)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("failed to handshake: %v %v", err1, err2)
+	}
+	if _, ok := c1.(*transport).Conn.(*secureConn); !ok {
+		t.Fatal("receiver should be encrypted")
+	}
+	if _, ok := c2.(*transport).Conn.(*secureConn); !ok {
+		t.Fatal("initiator should be encrypted")
+	}
+
+	payload := bytes.Repeat([]byte("vite"), secureRecordSize)
+	go func() {
+		_ = c2.WriteMsg(Msg{Code: CodeHeartBeat, Id: 3, Payload: payload})
+		_ = c1.WriteMsg(Msg{Code: CodeHeartBeat, Id: 4, Payload: []byte{1}})
+	}()
+	msg, err := c1.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Code != CodeHeartBeat || msg.Id != 3 || !bytes.Equal(msg.Payload, payload) {
+		t.Fatalf("wrong message: %d %d %d", msg.Code, msg.Id, len(msg.Payload))
+	}
+	msg, err = c2.ReadMsg()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.Id != 4 || !bytes.Equal(msg.Payload, []byte{1}) {
+		t.Fatalf("wrong message: %d %v", msg.Id, msg.Payload)
+	}
+}
+
+func TestHandshake_EncryptCompatible(t *testing.T) {
+	receiver, receiverId := newTestHandshaker("receiver", false, false)
+	initiator, _ := newTestHandshaker("initiator", true, false)
+
+	c1, c2, err1, err2 := handshakeOverPipe(receiver, initiator, receiverId)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("failed to handshake: %v %v", err1, err2)
+	}
+	if _, ok := c1.(*transport).Conn.(*secureConn); ok {
+		t.Fatal("receiver should not be encrypted")
+	}
+	if _, ok := c2.(*transport).Conn.(*secureConn); ok {
+		t.Fatal("initiator should not be encrypted")
+	}
+
+	receiver, receiverId = newTestHandshaker("receiver", true, true)
+	initiator, _ = newTestHandshaker("initiator", false, false)
+	_, _, err1, err2 = handshakeOverPipe(receiver, initiator, receiverId)
+	if err1 != PeerEncryptionRequired || err2 != PeerEncryptionRequired {
+		t.Fatalf("encryption should be required: %v %v", err1, err2)
+	}
+}
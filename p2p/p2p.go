@@ -57,6 +57,7 @@ type NodeInfo struct {
 	Name      string     `json:"name"`
 	NetID     int        `json:"netId"`
 	Version   int        `json:"version"`
+	Caps      []string   `json:"caps"`
 	Address   string     `json:"address"`
 	PeerCount int        `json:"peerCount"`
 	Peers     []PeerInfo `json:"peers"`
@@ -70,8 +71,12 @@ type P2P interface {
 	ConnectNode(node *vnode.Node) error
 	Info() NodeInfo
 	Register(pt Protocol) error
+	// RegisterSub register a sub-protocol, should be invoked before Start
+	RegisterSub(sp SubProtocol) error
 	Discovery() discovery.Discovery
 	Node() *vnode.Node
+	// Reputation score the peers, and ban the peers behave badly
+	Reputation() Reputation
 }
 
 type Handshaker interface {
@@ -92,6 +97,10 @@ type basePeer interface {
 	Head() types.Hash
 	SetHead(head types.Hash, height uint64)
 	FileAddress() string
+	// Archive return true if the peer keeps the full history of ledger
+	Archive() bool
+	// Caps return the sub-protocols running with the peer
+	Caps() []Cap
 	Weight() int64
 	Disconnect(err error)
 }
@@ -104,6 +113,8 @@ type PeerMux interface {
 	basePeer
 	run() error
 	setManager(pm levelManager)
+	setArchive(archive bool)
+	setCaps(caps []matchedCap)
 }
 
 type peerManager interface {
@@ -132,6 +143,8 @@ type p2p struct {
 
 	blackList netool.BlackList
 
+	reputation *reputation
+
 	server Server
 
 	wg sync.WaitGroup
@@ -172,16 +185,20 @@ func New(cfg *Config) P2P {
 	}
 
 	hkr := &handshaker{
-		version:     version,
-		netId:       cfg.NetID,
-		name:        cfg.Name,
-		id:          cfg.Node().ID,
-		genesis:     types.Hash{},
-		fileAddress: cfg.fileAddress,
-		peerKey:     cfg.PrivateKey(),
-		key:         cfg.MineKey,
-		protocol:    nil, // will be set when protocol registered
-		log:         p2pLog.New("module", "handshaker"),
+		version:        version,
+		netId:          cfg.NetID,
+		name:           cfg.Name,
+		id:             cfg.Node().ID,
+		genesis:        types.Hash{},
+		fileAddress:    cfg.fileAddress,
+		archive:        cfg.Archive,
+		encrypt:        cfg.Encrypt || cfg.RequireEncrypt,
+		requireEncrypt: cfg.RequireEncrypt,
+		requiredCaps:   cfg.RequiredCaps,
+		peerKey:        cfg.PrivateKey(),
+		key:            cfg.MineKey,
+		protocol:       nil, // will be set when protocol registered
+		log:            p2pLog.New("module", "handshaker"),
 	}
 
 	codecFactory := &transportFactory{
@@ -208,6 +225,8 @@ func New(cfg *Config) P2P {
 		panic(fmt.Errorf("failed to create database: %v", err))
 	}
 
+	p.reputation = newReputation(p.db, p.onBan)
+
 	if cfg.Discover {
 		p.discv = discovery.New(cfg.Config, p.db)
 	}
@@ -225,12 +244,27 @@ func (p *p2p) Discovery() discovery.Discovery {
 	return p.discv
 }
 
+func (p *p2p) Reputation() Reputation {
+	return p.reputation
+}
+
+// onBan disconnect the peer banned by reputation
+func (p *p2p) onBan(id vnode.NodeID, until time.Time) {
+	if peer := p.peers.get(id); peer != nil {
+		go peer.Close(PeerBanned)
+	}
+}
+
 // add success return true
 func (p *p2p) tryAdd(peer PeerMux) (PeerError, bool) {
 	if peer.ID() == p.node.ID {
 		return PeerConnectSelf, false
 	}
 
+	if p.reputation.Banned(peer.ID()) {
+		return PeerBanned, false
+	}
+
 	return p.peers.add(peer)
 }
 
@@ -290,11 +324,17 @@ func (p *p2p) Connect(node string) error {
 }
 
 func (p *p2p) Info() NodeInfo {
+	var caps []string
+	for _, c := range p.handshaker.subs.caps() {
+		caps = append(caps, c.String())
+	}
+
 	return NodeInfo{
 		ID:        p.cfg.Node().ID.String(),
 		Name:      p.cfg.Name,
 		NetID:     p.cfg.NetID,
 		Version:   version,
+		Caps:      caps,
 		Address:   p.cfg.ListenAddress,
 		PeerCount: p.peers.count(),
 		Peers:     p.peers.info(),
@@ -316,6 +356,18 @@ func (p *p2p) Register(pt Protocol) error {
 	return nil
 }
 
+func (p *p2p) RegisterSub(sp SubProtocol) (err error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if atomic.LoadInt32(&p.running) == 1 {
+		return errP2PAlreadyRunning
+	}
+
+	p.handshaker.subs, err = p.handshaker.subs.add(sp)
+	return
+}
+
 func (p *p2p) Config() Config {
 	return *p.cfg
 }
@@ -387,6 +439,8 @@ func (p *p2p) beatLoop() {
 				Payload: p.protocol.State(),
 			})
 		}
+
+		p.reputation.clean()
 	}
 }
 
@@ -481,13 +535,16 @@ func (p *p2p) ConnectNode(node *vnode.Node) error {
 		return PeerAlreadyConnected
 	}
 
-	if p.blackList.Banned(node.ID.Bytes()) || p.blackList.Banned(node.EndPoint.Host) {
+	if p.blackList.Banned(node.ID.Bytes()) || p.blackList.Banned(node.EndPoint.Host) || p.reputation.Banned(node.ID) {
 		return PeerBanned
 	}
 
 	peer, err := p.dialer.dialNode(node)
 	if err != nil {
 		p.log.Error(fmt.Sprintf("failed to dail %s: %v", node.String(), err))
+		if handshakeMisbehaved(err) {
+			p.reputation.Report(node.ID, BehaviorHandshakeFailed)
+		}
 		return err
 	}
 
@@ -64,15 +64,17 @@ func (p *peerMux) writeDone() {
 }
 
 type PeerInfo struct {
-	ID         string `json:"id"`
-	Name       string `json:"name"`
-	Version    int    `json:"version"`
-	Height     uint64 `json:"height"`
-	Address    string `json:"address"`
-	Level      Level  `json:"level"`
-	CreateAt   string `json:"createAt"`
-	ReadQueue  int    `json:"readQueue"`
-	WriteQueue int    `json:"writeQueue"`
+	ID         string   `json:"id"`
+	Name       string   `json:"name"`
+	Version    int      `json:"version"`
+	Height     uint64   `json:"height"`
+	Address    string   `json:"address"`
+	Level      Level    `json:"level"`
+	CreateAt   string   `json:"createAt"`
+	ReadQueue  int      `json:"readQueue"`
+	WriteQueue int      `json:"writeQueue"`
+	Archive    bool     `json:"archive"`
+	Caps       []string `json:"caps"`
 }
 
 const peerReadMsgBufferSize = 10
@@ -102,6 +104,8 @@ type peerMux struct {
 	log         log15.Logger
 	proto       Protocol
 	fileAddress string
+	archive     bool
+	subs        []*subPeer
 }
 
 func (p *peerMux) Weight() int64 {
@@ -183,6 +187,29 @@ func (p *peerMux) FileAddress() string {
 	return p.fileAddress
 }
 
+func (p *peerMux) Archive() bool {
+	return p.archive
+}
+
+func (p *peerMux) setArchive(archive bool) {
+	p.archive = archive
+}
+
+func (p *peerMux) Caps() (caps []Cap) {
+	for _, sp := range p.subs {
+		caps = append(caps, sp.sub.Cap())
+	}
+	return
+}
+
+// setCaps will be invoked before run by handshaker
+func (p *peerMux) setCaps(caps []matchedCap) {
+	p.subs = make([]*subPeer, len(caps))
+	for i, mc := range caps {
+		p.subs[i] = &subPeer{p, mc}
+	}
+}
+
 // setManager will be invoked before run by module p2p
 func (p *peerMux) setManager(pm levelManager) {
 	p.pm = pm
@@ -272,7 +299,7 @@ func (p *peerMux) handleLoop() (err error) {
 	for msg = range p.readQueue {
 		t1 := time.Now()
 		p.log.Debug(fmt.Sprintf("begin handle msg %d", msg.Code))
-		err = p.proto.Handle(msg)
+		err = p.handle(msg)
 		p.log.Debug(fmt.Sprintf("handle msg %d done[%d][%s]", msg.Code, len(p.readQueue), time.Now().Sub(t1)))
 		if err != nil {
 			return
@@ -282,6 +309,23 @@ func (p *peerMux) handleLoop() (err error) {
 	return nil
 }
 
+// handle dispatch message to the main Protocol or SubProtocol by code
+func (p *peerMux) handle(msg Msg) error {
+	if msg.Code < baseCodeLength {
+		return p.proto.Handle(msg)
+	}
+
+	for _, sp := range p.subs {
+		if sp.contains(msg.Code) {
+			msg.Code -= Code(sp.offset)
+			msg.Sender = sp
+			return sp.sub.Handle(msg)
+		}
+	}
+
+	return PeerUnknownMessage
+}
+
 func (p *peerMux) Close(err error) (err2 error) {
 	if atomic.CompareAndSwapInt32(&p.running, 1, 0) {
 		if pe, ok := err.(PeerError); ok {
@@ -323,12 +367,29 @@ func (p *peerMux) onAdded() (err error) {
 	err = p.proto.OnPeerAdded(p)
 	if err != nil {
 		p.log.Error(fmt.Sprintf("failed to add peer %s: %v", p, err))
+		return
+	}
+
+	for i, sp := range p.subs {
+		if err = sp.sub.OnPeerAdded(sp); err != nil {
+			p.log.Error(fmt.Sprintf("failed to add peer %s to %s: %v", p, sp.sub.Cap(), err))
+			// rollback
+			p.subs = p.subs[:i]
+			p.onRemoved()
+			return
+		}
 	}
 
 	return
 }
 
 func (p *peerMux) onRemoved() {
+	for _, sp := range p.subs {
+		if err := sp.sub.OnPeerRemoved(sp); err != nil {
+			p.log.Error(fmt.Sprintf("failed to remove peer %s from %s: %v", p, sp.sub.Cap(), err))
+		}
+	}
+
 	err := p.proto.OnPeerRemoved(p)
 	if err != nil {
 		p.log.Error(fmt.Sprintf("failed to remove peer %s: %v", p, err))
@@ -338,6 +399,11 @@ func (p *peerMux) onRemoved() {
 }
 
 func (p *peerMux) Info() PeerInfo {
+	var caps []string
+	for _, c := range p.Caps() {
+		caps = append(caps, c.String())
+	}
+
 	return PeerInfo{
 		ID:         p.id.String(),
 		Name:       p.name,
@@ -348,5 +414,7 @@ func (p *peerMux) Info() PeerInfo {
 		CreateAt:   p.createAt.Format("2006-01-02 15:04:05"),
 		ReadQueue:  len(p.readQueue),
 		WriteQueue: len(p.writeQueue),
+		Archive:    p.archive,
+		Caps:       caps,
 	}
 }
@@ -23,6 +23,8 @@ const (
 	PeerInvalidMessage
 	PeerResponseTimeout
 	PeerInvalidToken
+	PeerEncryptionRequired
+	PeerMissingCapability
 	PeerUnknownReason PeerError = 255
 )
 
@@ -47,6 +49,8 @@ var peerErrStr = map[PeerError]string{
 	PeerInvalidMessage:      "invalid message",
 	PeerResponseTimeout:     "response timeout",
 	PeerInvalidToken:        "invalid token",
+	PeerEncryptionRequired:  "encryption required",
+	PeerMissingCapability:   "missing capability",
 	PeerUnknownReason:       "unknown reason",
 }
 
@@ -69,6 +73,18 @@ func (e PeerError) Serialize() ([]byte, error) {
 	}, nil
 }
 
+// handshakeMisbehaved return true if the handshake failed by fault of the remote peer, but not network or capacity
+func handshakeMisbehaved(err error) bool {
+	if pe, ok := err.(PeerError); ok {
+		switch pe {
+		case PeerNotHandshakeMsg, PeerInvalidSignature, PeerUnmarshalError, PeerInvalidMessage, PeerInvalidToken:
+			return true
+		}
+	}
+
+	return false
+}
+
 type Exception byte
 
 const (
@@ -264,3 +264,11 @@ func (s *peers) peers() []PeerMux {
 
 	return ps
 }
+
+// get return nil if the peer is not exist
+func (s *peers) get(id vnode.NodeID) PeerMux {
+	s.rw.RLock()
+	defer s.rw.RUnlock()
+
+	return s.peerMap[id]
+}
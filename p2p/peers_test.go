@@ -43,6 +43,22 @@ func (mp *mockPeer) FileAddress() string {
 	panic("implement me")
 }
 
+func (mp *mockPeer) Archive() bool {
+	return false
+}
+
+func (mp *mockPeer) setArchive(archive bool) {
+	panic("implement me")
+}
+
+func (mp *mockPeer) Caps() []Cap {
+	return nil
+}
+
+func (mp *mockPeer) setCaps(caps []matchedCap) {
+	panic("implement me")
+}
+
 func (mp *mockPeer) weight() int64 {
 	panic("implement me")
 }
@@ -0,0 +1,248 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"fmt"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+)
+
+// Behavior is the conduct of a peer, it changes the reputation of the peer
+type Behavior byte
+
+const (
+	BehaviorInvalidBlock     Behavior = iota // blocks from the peer failed to verify
+	BehaviorSyncTimeout                      // the peer didn`t respond sync request in time
+	BehaviorUselessBroadcast                 // the peer broadcast duplicated or empty blocks
+	BehaviorSlowFile                         // the peer serve ledger files too slow
+	BehaviorHandshakeFailed                  // the peer failed the handshake
+	BehaviorNewBlock                         // the peer broadcast a valid new block
+	BehaviorFileServed                       // the peer served a ledger file
+)
+
+var behaviorScore = map[Behavior]float64{
+	BehaviorInvalidBlock:     -50,
+	BehaviorSyncTimeout:      -10,
+	BehaviorUselessBroadcast: -1,
+	BehaviorSlowFile:         -5,
+	BehaviorHandshakeFailed:  -20,
+	BehaviorNewBlock:         1,
+	BehaviorFileServed:       2,
+}
+
+var behaviorStr = map[Behavior]string{
+	BehaviorInvalidBlock:     "invalid block",
+	BehaviorSyncTimeout:      "sync timeout",
+	BehaviorUselessBroadcast: "useless broadcast",
+	BehaviorSlowFile:         "slow file",
+	BehaviorHandshakeFailed:  "handshake failed",
+	BehaviorNewBlock:         "new block",
+	BehaviorFileServed:       "file served",
+}
+
+func (b Behavior) String() string {
+	str, ok := behaviorStr[b]
+	if ok {
+		return str
+	}
+
+	return "unknown behavior"
+}
+
+const maxReputation = 100
+const banReputation = -100
+
+// the score will be half after reputationHalfLife
+const reputationHalfLife = 10 * time.Minute
+
+// ban duration is doubled every time the peer is banned
+const minBanDuration = 10 * time.Minute
+const maxBanDuration = 24 * time.Hour
+
+// Reputation score peers by their behaviors, the score decays to zero over time.
+// If the score of a peer drops to banReputation, the peer will be disconnected and banned for a while,
+// the bans are persisted in database.
+type Reputation interface {
+	Report(id vnode.NodeID, b Behavior)
+	Score(id vnode.NodeID) int64
+	Banned(id vnode.NodeID) bool
+}
+
+type banStore interface {
+	RetrieveBan(id vnode.NodeID) (until int64, times int64)
+	StoreBan(id vnode.NodeID, until int64, times int64)
+}
+
+type reputationRecord struct {
+	score  float64
+	update time.Time
+	until  time.Time // banned until
+	times  int64     // banned times
+}
+
+// decay the score to now
+func (r *reputationRecord) decay(now time.Time) {
+	if elapse := now.Sub(r.update); elapse > 0 {
+		r.score *= math.Pow(0.5, float64(elapse)/float64(reputationHalfLife))
+		r.update = now
+	}
+}
+
+type reputation struct {
+	mu      sync.Mutex
+	records map[vnode.NodeID]*reputationRecord
+	store   banStore
+
+	// invoked without lock when a peer is banned
+	onBan func(id vnode.NodeID, until time.Time)
+
+	now func() time.Time
+	log log15.Logger
+}
+
+func newReputation(store banStore, onBan func(id vnode.NodeID, until time.Time)) *reputation {
+	return &reputation{
+		records: make(map[vnode.NodeID]*reputationRecord),
+		store:   store,
+		onBan:   onBan,
+		now:     time.Now,
+		log:     p2pLog.New("module", "reputation"),
+	}
+}
+
+// record must be invoked with lock, the ban history is loaded from database at the first time
+func (r *reputation) record(id vnode.NodeID, now time.Time) *reputationRecord {
+	rec, ok := r.records[id]
+	if !ok {
+		rec = &reputationRecord{update: now}
+		if r.store != nil {
+			var until int64
+			if until, rec.times = r.store.RetrieveBan(id); until > 0 {
+				rec.until = time.Unix(until, 0)
+			}
+		}
+		r.records[id] = rec
+	}
+
+	rec.decay(now)
+	return rec
+}
+
+func (r *reputation) Report(id vnode.NodeID, b Behavior) {
+	delta, ok := behaviorScore[b]
+	if !ok {
+		return
+	}
+
+	now := r.now()
+
+	r.mu.Lock()
+	rec := r.record(id, now)
+	if now.Before(rec.until) {
+		r.mu.Unlock()
+		return
+	}
+
+	rec.score += delta
+	if rec.score > maxReputation {
+		rec.score = maxReputation
+	}
+
+	if rec.score > banReputation {
+		r.mu.Unlock()
+		return
+	}
+
+	// banned, the duration is doubled every time
+	duration := maxBanDuration
+	if rec.times < 8 {
+		if duration = minBanDuration << uint(rec.times); duration > maxBanDuration {
+			duration = maxBanDuration
+		}
+	}
+	rec.times++
+	rec.until = now.Add(duration)
+	// on probation after the ban
+	rec.score = banReputation / 2
+	rec.update = rec.until
+
+	if r.store != nil {
+		r.store.StoreBan(id, rec.until.Unix(), rec.times)
+	}
+	until := rec.until
+	r.mu.Unlock()
+
+	r.log.Warn(fmt.Sprintf("ban peer %s until %s for %s", id, until.Format("2006-01-02 15:04:05"), b))
+
+	if r.onBan != nil {
+		r.onBan(id, until)
+	}
+}
+
+// Score is 0 if the peer has no record, the record is not created
+func (r *reputation) Score(id vnode.NodeID) int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if rec, ok := r.records[id]; ok {
+		rec.decay(r.now())
+		return int64(rec.score)
+	}
+	return 0
+}
+
+// Banned doesn't create the record, the ban is read from database if the peer has no record
+func (r *reputation) Banned(id vnode.NodeID) bool {
+	now := r.now()
+
+	r.mu.Lock()
+	rec, ok := r.records[id]
+	if ok {
+		until := rec.until
+		r.mu.Unlock()
+		return now.Before(until)
+	}
+	r.mu.Unlock()
+
+	if r.store == nil {
+		return false
+	}
+	until, _ := r.store.RetrieveBan(id)
+	return until > 0 && now.Before(time.Unix(until, 0))
+}
+
+// clean the records have decayed to zero and not banned
+func (r *reputation) clean() {
+	now := r.now()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, rec := range r.records {
+		rec.decay(now)
+		if math.Abs(rec.score) < 1 && now.After(rec.until) {
+			delete(r.records, id)
+		}
+	}
+}
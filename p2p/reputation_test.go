@@ -0,0 +1,102 @@
+package p2p
+
+import (
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/p2p/vnode"
+)
+
+func TestReputation_Decay(t *testing.T) {
+	now := time.Now()
+	r := newReputation(nil, nil)
+	r.now = func() time.Time {
+		return now
+	}
+
+	id := vnode.RandomNodeID()
+	r.Report(id, BehaviorInvalidBlock)
+	if score := r.Score(id); score != -50 {
+		t.Fatalf("score should be -50, not %d", score)
+	}
+
+	now = now.Add(reputationHalfLife)
+	if score := r.Score(id); score != -25 {
+		t.Fatalf("score should decay to -25, not %d", score)
+	}
+
+	for i := 0; i < 200; i++ {
+		r.Report(id, BehaviorNewBlock)
+	}
+	if score := r.Score(id); score != maxReputation {
+		t.Fatalf("score should not be higher than %d: %d", maxReputation, score)
+	}
+
+	now = now.Add(100 * reputationHalfLife)
+	r.clean()
+	if _, ok := r.records[id]; ok {
+		t.Fatal("record decayed to zero should be cleaned")
+	}
+}
+
+func TestReputation_Ban(t *testing.T) {
+	db, err := newNodeDB("", 1, vnode.ZERO)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	var banned []vnode.NodeID
+	r := newReputation(db, func(id vnode.NodeID, until time.Time) {
+		banned = append(banned, id)
+		if until.Sub(now) != minBanDuration {
+			t.Errorf("should be banned for %s, not %s", minBanDuration, until.Sub(now))
+		}
+	})
+	r.now = func() time.Time {
+		return now
+	}
+
+	id := vnode.RandomNodeID()
+	r.Report(id, BehaviorInvalidBlock)
+	if r.Banned(id) {
+		t.Fatal("should not be banned by one invalid block")
+	}
+	r.Report(id, BehaviorInvalidBlock)
+	if !r.Banned(id) || len(banned) != 1 || banned[0] != id {
+		t.Fatal("should be banned by two invalid blocks")
+	}
+
+	// the ban is persisted, the lookup doesn't create a record
+	r2 := newReputation(db, nil)
+	r2.now = r.now
+	if !r2.Banned(id) {
+		t.Fatal("ban should be loaded from database")
+	}
+	if r2.Banned(vnode.RandomNodeID()) || r2.Score(vnode.RandomNodeID()) != 0 {
+		t.Fatal("peer without record should not be banned")
+	}
+	if len(r2.records) != 0 {
+		t.Fatalf("lookup should not create records, but %d", len(r2.records))
+	}
+
+	now = now.Add(minBanDuration + time.Second)
+	if r2.Banned(id) {
+		t.Fatal("ban should be expired")
+	}
+
+	// the second ban is longer
+	r2.onBan = func(id vnode.NodeID, until time.Time) {
+		if until.Sub(now) != 2*minBanDuration {
+			t.Errorf("should be banned for %s, not %s", 2*minBanDuration, until.Sub(now))
+		}
+	}
+	r2.Report(id, BehaviorInvalidBlock)
+	r2.Report(id, BehaviorInvalidBlock)
+	if !r2.Banned(id) {
+		t.Fatal("should be banned again")
+	}
+	if _, times := db.RetrieveBan(id); times != 2 {
+		t.Fatalf("should be banned 2 times, not %d", times)
+	}
+}
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package p2p
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// versionEncrypted is the first version can negotiate the encrypted transport
+const versionEncrypted = 1
+
+const ephemeralKeyLength = 32
+const secureRecordSize = 1 << 16 // max plaintext bytes of a record
+const secureRecordHead = 3
+
+var errSecureRecordTooLarge = errors.New("secure record is too large")
+var errSecureNonceExhausted = errors.New("secure nonce exhausted")
+var errInvalidEphemeralKey = errors.New("invalid ephemeral key")
+
+var secureInitiatorLabel = []byte("vite p2p initiator")
+var secureReceiverLabel = []byte("vite p2p receiver")
+
+// secureCodec is implemented by the codecs can switch to the encrypted transport after handshake
+type secureCodec interface {
+	secure(readKey, writeKey []byte) error
+}
+
+func (t *transport) secure(readKey, writeKey []byte) (err error) {
+	conn, err := newSecureConn(t.Conn, readKey, writeKey)
+	if err != nil {
+		return
+	}
+	t.Conn = conn
+	return
+}
+
+type ephemeralKey struct {
+	priv, pub [32]byte
+}
+
+func newEphemeralKey() (k *ephemeralKey, err error) {
+	k = new(ephemeralKey)
+	if _, err = io.ReadFull(rand.Reader, k.priv[:]); err != nil {
+		return nil, err
+	}
+	curve25519.ScalarBaseMult(&k.pub, &k.priv)
+	return
+}
+
+func (k *ephemeralKey) computeSecret(peerPub []byte) (secret []byte, err error) {
+	if len(peerPub) != ephemeralKeyLength {
+		return nil, errInvalidEphemeralKey
+	}
+	var pub, sec [32]byte
+	copy(pub[:], peerPub)
+	curve25519.ScalarMult(&sec, &k.priv, &pub)
+
+	// low order points result in zero secret
+	var zero [32]byte
+	if subtle.ConstantTimeCompare(sec[:], zero[:]) == 1 {
+		return nil, errInvalidEphemeralKey
+	}
+	return sec[:], nil
+}
+
+// deriveSecureKeys derive the keys of both directions from the static secret of node keys,
+// the ephemeral secret and the handshake messages, so tampering handshake messages results in wrong keys.
+func deriveSecureKeys(staticSecret, ephemeralSecret, initiatorHandshake, receiverHandshake []byte) (initiatorKey, receiverKey []byte) {
+	transcript := sha256.New()
+	transcript.Write(initiatorHandshake)
+	transcript.Write(receiverHandshake)
+
+	mac := hmac.New(sha256.New, transcript.Sum(nil))
+	mac.Write(staticSecret)
+	mac.Write(ephemeralSecret)
+	prk := mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, prk)
+	mac.Write(secureInitiatorLabel)
+	initiatorKey = mac.Sum(nil)
+
+	mac = hmac.New(sha256.New, prk)
+	mac.Write(secureReceiverLabel)
+	receiverKey = mac.Sum(nil)
+
+	return
+}
+
+/*
+ * secure record structure
+ *  +----------------+--------------------------------------+
+ *  |     Length     |              Ciphertext              |
+ *  |    3 bytes     |   plaintext (0 ~ 64KB) + 16 bytes    |
+ *  +----------------+--------------------------------------+
+ * Ciphertext is sealed by AES-256-GCM, the nonce is the count of records in the same direction,
+ * so a replayed, reordered or dropped record can't be opened.
+ */
+type secureConn struct {
+	net.Conn
+
+	reader     cipher.AEAD
+	readNonce  uint64
+	readHead   [secureRecordHead]byte
+	readBuf    []byte
+	readRemain []byte
+
+	writer     cipher.AEAD
+	writeNonce uint64
+	writeBuf   []byte
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+func newSecureConn(conn net.Conn, readKey, writeKey []byte) (c *secureConn, err error) {
+	c = &secureConn{
+		Conn: conn,
+	}
+	if c.reader, err = newAEAD(readKey); err != nil {
+		return nil, err
+	}
+	if c.writer, err = newAEAD(writeKey); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func putNonce(nonce []byte, n uint64) {
+	for i := 0; i < len(nonce)-8; i++ {
+		nonce[i] = 0
+	}
+	binary.BigEndian.PutUint64(nonce[len(nonce)-8:], n)
+}
+
+// Read is NOT thread-safe
+func (c *secureConn) Read(p []byte) (n int, err error) {
+	if len(c.readRemain) == 0 {
+		if err = c.readRecord(); err != nil {
+			return
+		}
+	}
+
+	n = copy(p, c.readRemain)
+	c.readRemain = c.readRemain[n:]
+	return
+}
+
+func (c *secureConn) readRecord() (err error) {
+	if c.readNonce == math.MaxUint64 {
+		return errSecureNonceExhausted
+	}
+
+	if _, err = io.ReadFull(c.Conn, c.readHead[:]); err != nil {
+		return
+	}
+	length := int(Varint(c.readHead[:]))
+	if length > secureRecordSize+c.reader.Overhead() {
+		return errSecureRecordTooLarge
+	}
+
+	if cap(c.readBuf) < length+c.reader.NonceSize() {
+		c.readBuf = make([]byte, length+c.reader.NonceSize())
+	}
+	nonce := c.readBuf[:c.reader.NonceSize()]
+	record := c.readBuf[len(nonce) : len(nonce)+length]
+	if _, err = io.ReadFull(c.Conn, record); err != nil {
+		return
+	}
+
+	putNonce(nonce, c.readNonce)
+	plain, err := c.reader.Open(record[:0], nonce, record, nil)
+	if err != nil {
+		return fmt.Errorf("failed to open secure record %d: %v", c.readNonce, err)
+	}
+	c.readNonce++
+	c.readRemain = plain
+
+	return nil
+}
+
+// Write is NOT thread-safe
+func (c *secureConn) Write(p []byte) (n int, err error) {
+	for len(p) > 0 {
+		size := len(p)
+		if size > secureRecordSize {
+			size = secureRecordSize
+		}
+		if err = c.writeRecord(p[:size]); err != nil {
+			return
+		}
+		n += size
+		p = p[size:]
+	}
+	return
+}
+
+func (c *secureConn) writeRecord(plain []byte) (err error) {
+	if c.writeNonce == math.MaxUint64 {
+		return errSecureNonceExhausted
+	}
+
+	nonceSize := c.writer.NonceSize()
+	total := nonceSize + secureRecordHead + len(plain) + c.writer.Overhead()
+	if cap(c.writeBuf) < total {
+		c.writeBuf = make([]byte, total)
+	}
+	nonce := c.writeBuf[:nonceSize]
+	putNonce(nonce, c.writeNonce)
+
+	head := c.writeBuf[nonceSize : nonceSize+secureRecordHead]
+	record := c.writer.Seal(c.writeBuf[nonceSize+secureRecordHead:nonceSize+secureRecordHead], nonce, plain, nil)
+	head[0] = byte(len(record) >> 16)
+	head[1] = byte(len(record) >> 8)
+	head[2] = byte(len(record))
+
+	if _, err = c.Conn.Write(c.writeBuf[nonceSize : nonceSize+secureRecordHead+len(record)]); err != nil {
+		return
+	}
+	c.writeNonce++
+
+	return nil
+}
@@ -0,0 +1,82 @@
+package p2p
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+)
+
+// recordConn records the written bytes
+type recordConn struct {
+	net.Conn
+	bytes.Buffer
+}
+
+func (c *recordConn) Write(p []byte) (int, error) {
+	return c.Buffer.Write(p)
+}
+
+func (c *recordConn) Read(p []byte) (int, error) {
+	return c.Buffer.Read(p)
+}
+
+func TestSecureConn(t *testing.T) {
+	initiatorKey, receiverKey := deriveSecureKeys([]byte("static"), []byte("ephemeral"), []byte("request"), []byte("response"))
+
+	wire := new(recordConn)
+	writer, err := newSecureConn(wire, receiverKey, initiatorKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range [][]byte{[]byte("hello"), []byte("vite")} {
+		if _, err = writer.Write(data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	records := wire.Bytes()
+	first := append([]byte{}, records[:secureRecordHead+5+16]...)
+	second := append([]byte{}, records[len(first):]...)
+	if bytes.Contains(records, []byte("hello")) {
+		t.Fatal("record should be encrypted")
+	}
+
+	read := func(data ...[]byte) ([]byte, error) {
+		wire := new(recordConn)
+		for _, d := range data {
+			wire.Buffer.Write(d)
+		}
+		reader, err := newSecureConn(wire, initiatorKey, receiverKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, 9)
+		_, err = io.ReadFull(reader, buf)
+		return buf, err
+	}
+
+	if buf, err := read(first, second); err != nil || string(buf) != "hellovite" {
+		t.Fatalf("failed to read: %s %v", buf, err)
+	}
+	// reorder
+	if _, err := read(second, first); err == nil {
+		t.Fatal("reordered record should be rejected")
+	}
+	// replay
+	if _, err := read(first, first); err == nil {
+		t.Fatal("replayed record should be rejected")
+	}
+	// tamper
+	tampered := append([]byte{}, first...)
+	tampered[secureRecordHead] ^= 1
+	if _, err := read(tampered, second); err == nil {
+		t.Fatal("tampered record should be rejected")
+	}
+	// wrong key
+	wire = new(recordConn)
+	wire.Buffer.Write(records)
+	reader, _ := newSecureConn(wire, receiverKey, initiatorKey)
+	if _, err := io.ReadFull(reader, make([]byte, 9)); err == nil {
+		t.Fatal("record of other direction should be rejected")
+	}
+}
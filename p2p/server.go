@@ -177,6 +177,11 @@ func (srv *server) handle(c Codec) {
 	if err != nil {
 		srv.log.Error(fmt.Sprintf("failed to handshake with peer %s: %v", c.Address().String(), err))
 		_ = Disconnect(c, err)
+
+		// the node id is unknown, ban the address for a while
+		if addr, ok := c.Address().(*net.TCPAddr); ok && handshakeMisbehaved(err) {
+			srv.blackList.Ban(addr.IP)
+		}
 	} else {
 		srv.pm.register(p)
 	}
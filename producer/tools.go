@@ -51,22 +51,29 @@ func (self *tools) generateSnapshot(e *consensus.Event, coinbase *AddressContext
 	}
 
 	block.Hash = block.ComputeHash()
-	manager, err := self.wt.GetEntropyStoreManager(coinbase.EntryPath)
+	signedData, pubkey, err := self.sign(coinbase, block.Hash)
 	if err != nil {
 		return nil, err
 	}
-	_, key, err := manager.DeriveForIndexPath(coinbase.Index)
+	block.Signature = signedData
+	block.PublicKey = pubkey
+	return block, nil
+}
+
+// sign with the key of entropy store if it is configured, otherwise with the signer of wallet
+func (self *tools) sign(coinbase *AddressContext, hash types.Hash) (signedData, pubkey []byte, err error) {
+	if coinbase.EntryPath == "" {
+		return self.wt.SignHash(coinbase.Address, hash)
+	}
+	manager, err := self.wt.GetEntropyStoreManager(coinbase.EntryPath)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	signedData, pubkey, err := key.SignData(block.Hash.Bytes())
-
+	_, key, err := manager.DeriveForIndexPath(coinbase.Index)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
-	block.Signature = signedData
-	block.PublicKey = pubkey
-	return block, nil
+	return key.SignData(hash.Bytes())
 }
 func (self *tools) insertSnapshot(block *ledger.SnapshotBlock) error {
 	defer monitor.LogTime("producer", "snapshotInsert", time.Now())
@@ -0,0 +1,418 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	log "github.com/vitelabs/go-vite/log15"
+)
+
+const (
+	transportHTTP = "http"
+	transportWS   = "ws"
+	transportIPC  = "ipc"
+
+	// the buckets are evicted to rateBucketMax*9/10 once the map reaches rateBucketMax
+	rateBucketMax = 10000
+)
+
+// defaultMethodCost is the cost of the methods reading a lot of blocks, the others cost 1
+var defaultMethodCost = map[string]int{
+	"ledger_getBlocksByAccAddr":       10,
+	"ledger_getBlocksByHash":          10,
+	"ledger_getBlocksByHashInToken":   10,
+	"ledger_getBlocksByHeight":        10,
+	"ledger_getTransactionsByAddress": 10,
+	"ledger_getSnapshotBlocks":        10,
+	"ledger_getBalancesBySnapshot":    10,
+	"ledger_getVmLogListByHash":       5,
+	"ledger_getVmLogList":             5,
+	"subscribe_getLogs":               10,
+}
+
+// AuthToken is a bearer token with the namespaces and methods it can call, "*" in Namespaces means all,
+// a token without namespace and method can call nothing
+type AuthToken struct {
+	Name       string   `json:"Name"`
+	Token      string   `json:"Token"`
+	Namespaces []string `json:"Namespaces"`
+	Methods    []string `json:"Methods"` // full name like ledger_getBlocksByAccAddr
+	RateLimit  float64  `json:"RateLimit"`
+	RateBurst  int      `json:"RateBurst"`
+}
+
+// AuthConfig is the access policy of a Server.
+//
+// Callers send a static token or a HS256 JWT as "Authorization: Bearer <token>", websocket callers
+// can also use the "token" query parameter. A JWT carries "sub", "exp", "namespaces" and "methods" claims,
+// its scope is the same as AuthToken. Callers without token can only call the anonymous namespaces and methods.
+// Callers of ipc are trusted as the socket is only accessible to the owner of node, unless IPCAuth is set,
+// then they are anonymous because ipc can't carry a token.
+type AuthConfig struct {
+	Tokens              []AuthToken `json:"Tokens"`
+	JWTSecret           string      `json:"JWTSecret"`
+	AnonymousNamespaces []string    `json:"AnonymousNamespaces"` // "*" means all
+	AnonymousMethods    []string    `json:"AnonymousMethods"`
+	IPCAuth             bool        `json:"IPCAuth"`
+
+	IPRateLimit    float64        `json:"IPRateLimit"` // cost per second of each ip, 0 means unlimited
+	IPRateBurst    int            `json:"IPRateBurst"`
+	TokenRateLimit float64        `json:"TokenRateLimit"` // cost per second of each token, 0 means unlimited
+	TokenRateBurst int            `json:"TokenRateBurst"`
+	MethodCost     map[string]int `json:"MethodCost"` // overrides the default cost of methods
+
+	MaxParamsSize int  `json:"MaxParamsSize"` // bytes of the params of each request, 0 means unlimited
+	AccessLog     bool `json:"AccessLog"`
+}
+
+// credentials are put into the context by the transports
+type credentials struct {
+	transport string
+	remote    string
+	token     string
+}
+
+type credentialsKey struct{}
+
+func withCredentials(ctx context.Context, transport, remote, token string) context.Context {
+	return context.WithValue(ctx, credentialsKey{}, &credentials{transport: transport, remote: remote, token: token})
+}
+
+func credentialsFromContext(ctx context.Context) *credentials {
+	if c, ok := ctx.Value(credentialsKey{}).(*credentials); ok {
+		return c
+	}
+	return &credentials{}
+}
+
+// bearerToken returns the token of the Authorization header, or the "token" query parameter if allowQuery
+func bearerToken(r *http.Request, allowQuery bool) string {
+	if auth := r.Header.Get("Authorization"); len(auth) > 7 && strings.EqualFold(auth[:7], "bearer ") {
+		return strings.TrimSpace(auth[7:])
+	}
+	if allowQuery {
+		return r.URL.Query().Get("token")
+	}
+	return ""
+}
+
+func remoteIP(remote string) string {
+	if host, _, err := net.SplitHostPort(remote); err == nil {
+		return host
+	}
+	return remote
+}
+
+// principal is the identity of a caller and what it can call, nothing if no namespace and method
+type principal struct {
+	name       string
+	namespaces map[string]struct{}
+	methods    map[string]struct{}
+	limiter    *rateLimiter // nil means the default token limiter is used
+}
+
+func newPrincipal(name string, namespaces, methods []string) *principal {
+	p := &principal{
+		name:       name,
+		namespaces: make(map[string]struct{}, len(namespaces)),
+		methods:    make(map[string]struct{}, len(methods)),
+	}
+	for _, ns := range namespaces {
+		p.namespaces[ns] = struct{}{}
+	}
+	for _, m := range methods {
+		p.methods[m] = struct{}{}
+	}
+	return p
+}
+
+func (p *principal) allowed(namespace, method string) bool {
+	if _, ok := p.namespaces["*"]; ok {
+		return true
+	}
+	if _, ok := p.namespaces[namespace]; ok {
+		return true
+	}
+	_, ok := p.methods[namespace+serviceMethodSeparator+method]
+	return ok
+}
+
+// Auth authenticates, authorizes and rate limits the requests of a Server
+type Auth struct {
+	tokens    map[[sha256.Size]byte]*principal
+	jwtSecret []byte
+	anonymous *principal
+	ipcAuth   bool
+
+	ipLimiter    *rateLimiter
+	tokenLimiter *rateLimiter
+	methodCost   map[string]int
+
+	maxParamsSize int
+	accessLog     bool
+
+	log log.Logger
+}
+
+func NewAuth(cfg *AuthConfig) (*Auth, error) {
+	a := &Auth{
+		tokens:        make(map[[sha256.Size]byte]*principal, len(cfg.Tokens)),
+		jwtSecret:     []byte(cfg.JWTSecret),
+		methodCost:    make(map[string]int, len(defaultMethodCost)+len(cfg.MethodCost)),
+		ipcAuth:       cfg.IPCAuth,
+		maxParamsSize: cfg.MaxParamsSize,
+		accessLog:     cfg.AccessLog,
+		log:           log.New("module", "rpc/auth"),
+	}
+
+	a.anonymous = newPrincipal("anonymous", cfg.AnonymousNamespaces, cfg.AnonymousMethods)
+
+	for i, t := range cfg.Tokens {
+		if len(t.Token) < 16 {
+			return nil, fmt.Errorf("token %d %s is too short, at least 16 characters", i, t.Name)
+		}
+		key := sha256.Sum256([]byte(t.Token))
+		if _, ok := a.tokens[key]; ok {
+			return nil, fmt.Errorf("token %d %s is duplicated", i, t.Name)
+		}
+		name := t.Name
+		if name == "" {
+			name = fmt.Sprintf("token%d", i)
+		}
+		p := newPrincipal(name, t.Namespaces, t.Methods)
+		if t.RateLimit > 0 {
+			p.limiter = newRateLimiter(t.RateLimit, t.RateBurst)
+		}
+		a.tokens[key] = p
+	}
+	if len(a.jwtSecret) > 0 && len(a.jwtSecret) < 32 {
+		return nil, fmt.Errorf("jwt secret is too short, at least 32 bytes")
+	}
+
+	if cfg.IPRateLimit > 0 {
+		a.ipLimiter = newRateLimiter(cfg.IPRateLimit, cfg.IPRateBurst)
+	}
+	if cfg.TokenRateLimit > 0 {
+		a.tokenLimiter = newRateLimiter(cfg.TokenRateLimit, cfg.TokenRateBurst)
+	}
+	for m, c := range defaultMethodCost {
+		a.methodCost[m] = c
+	}
+	for m, c := range cfg.MethodCost {
+		if c <= 0 {
+			return nil, fmt.Errorf("cost of %s should be positive", m)
+		}
+		a.methodCost[m] = c
+	}
+	return a, nil
+}
+
+// authenticate finds the principal of the token, ipc callers are trusted unless ipcAuth
+func (a *Auth) authenticate(c *credentials) (*principal, Error) {
+	if c.transport == transportIPC && !a.ipcAuth {
+		return nil, nil
+	}
+	if c.token == "" {
+		return a.anonymous, nil
+	}
+	if p, ok := a.tokens[sha256.Sum256([]byte(c.token))]; ok {
+		return p, nil
+	}
+	if len(a.jwtSecret) > 0 && strings.Count(c.token, ".") == 2 {
+		p, err := a.parseJWT(c.token)
+		if err != nil {
+			return nil, &unauthorizedError{err.Error()}
+		}
+		return p, nil
+	}
+	return nil, &unauthorizedError{"invalid token"}
+}
+
+type jwtClaims struct {
+	Subject    string   `json:"sub"`
+	Expire     int64    `json:"exp"`
+	NotBefore  int64    `json:"nbf"`
+	Namespaces []string `json:"namespaces"`
+	Methods    []string `json:"methods"`
+}
+
+// parseJWT verifies a HS256 JWT, the "exp" claim is required so a leaked token won't be valid forever
+func (a *Auth) parseJWT(token string) (*principal, error) {
+	parts := strings.Split(token, ".")
+	header, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt header")
+	}
+	var h struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(header, &h); err != nil || h.Alg != "HS256" {
+		return nil, fmt.Errorf("jwt alg should be HS256")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt signature")
+	}
+	mac := hmac.New(sha256.New, a.jwtSecret)
+	mac.Write([]byte(parts[0] + "." + parts[1]))
+	if !hmac.Equal(sig, mac.Sum(nil)) {
+		return nil, fmt.Errorf("invalid jwt signature")
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid jwt payload")
+	}
+	claims := new(jwtClaims)
+	if err := json.Unmarshal(payload, claims); err != nil {
+		return nil, fmt.Errorf("invalid jwt claims")
+	}
+	now := time.Now().Unix()
+	if claims.Expire == 0 || now >= claims.Expire {
+		return nil, fmt.Errorf("jwt expired")
+	}
+	if now < claims.NotBefore {
+		return nil, fmt.Errorf("jwt not valid yet")
+	}
+	return newPrincipal("jwt:"+claims.Subject, claims.Namespaces, claims.Methods), nil
+}
+
+// authorize checks the request before it is executed, the requests of a batch are checked one by one
+func (a *Auth) authorize(ctx context.Context, req *serverRequest) Error {
+	c := credentialsFromContext(ctx)
+	fullName := req.svcname + serviceMethodSeparator + req.method
+
+	p, err := a.authenticate(c)
+	if err == nil && p != nil {
+		if !p.allowed(req.svcname, req.method) {
+			err = &unauthorizedError{fmt.Sprintf("%s is not allowed to call %s", p.name, fullName)}
+		} else {
+			err = a.limit(c, p, fullName)
+		}
+	}
+
+	name := "ipc"
+	if p != nil {
+		name = p.name
+	}
+	if err != nil {
+		a.log.Warn("rpc request denied", "transport", c.transport, "remote", c.remote, "principal", name, "method", fullName, "err", err)
+	} else if a.accessLog {
+		a.log.Info("rpc request", "transport", c.transport, "remote", c.remote, "principal", name, "method", fullName)
+	}
+	return err
+}
+
+func (a *Auth) limit(c *credentials, p *principal, fullName string) Error {
+	cost, ok := a.methodCost[fullName]
+	if !ok {
+		cost = 1
+	}
+	if a.ipLimiter != nil && c.remote != "" && !a.ipLimiter.allow(remoteIP(c.remote), cost) {
+		return &rateLimitedError{fmt.Sprintf("rate limit of %s exceeded", remoteIP(c.remote))}
+	}
+	if p == a.anonymous {
+		return nil
+	}
+	limiter := p.limiter
+	if limiter == nil {
+		limiter = a.tokenLimiter
+	}
+	if limiter != nil && !limiter.allow(p.name, cost) {
+		return &rateLimitedError{fmt.Sprintf("rate limit of %s exceeded", p.name)}
+	}
+	return nil
+}
+
+func (a *Auth) checkParamsSize(params interface{}) Error {
+	if a.maxParamsSize <= 0 {
+		return nil
+	}
+	if raw, ok := params.(json.RawMessage); ok && len(raw) > a.maxParamsSize {
+		return &invalidRequestError{message: fmt.Sprintf("params too large (%d>%d)", len(raw), a.maxParamsSize)}
+	}
+	return nil
+}
+
+// rateLimiter is a token bucket for each key
+type rateLimiter struct {
+	rate  float64
+	burst float64
+
+	mu      sync.Mutex
+	buckets map[string]*rateBucket
+}
+
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+func newRateLimiter(rate float64, burst int) *rateLimiter {
+	if float64(burst) < rate {
+		burst = int(rate) + 1
+	}
+	return &rateLimiter{rate: rate, burst: float64(burst), buckets: make(map[string]*rateBucket)}
+}
+
+func (l *rateLimiter) allow(key string, cost int) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	b, ok := l.buckets[key]
+	if !ok {
+		if len(l.buckets) >= rateBucketMax {
+			l.evict(now)
+		}
+		b = &rateBucket{tokens: l.burst, last: now}
+		l.buckets[key] = b
+	} else {
+		b.tokens += now.Sub(b.last).Seconds() * l.rate
+		if b.tokens > l.burst {
+			b.tokens = l.burst
+		}
+		b.last = now
+	}
+
+	if b.tokens < float64(cost) {
+		return false
+	}
+	b.tokens -= float64(cost)
+	return true
+}
+
+// evict removes the buckets which are full again, they are the same as new ones.
+// If there are still too many, the least recently used ones are removed.
+func (l *rateLimiter) evict(now time.Time) {
+	for key, b := range l.buckets {
+		if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+			delete(l.buckets, key)
+		}
+	}
+
+	low := rateBucketMax - rateBucketMax/10
+	if len(l.buckets) <= low {
+		return
+	}
+	keys := make([]string, 0, len(l.buckets))
+	for key := range l.buckets {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return l.buckets[keys[i]].last.Before(l.buckets[keys[j]].last)
+	})
+	for _, key := range keys[:len(keys)-low] {
+		delete(l.buckets, key)
+	}
+}
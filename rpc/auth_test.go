@@ -0,0 +1,171 @@
+package rpc
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+)
+
+func newTestJWT(secret string, claims *jwtClaims) string {
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256","typ":"JWT"}`))
+	body, _ := json.Marshal(claims)
+	payload := base64.RawURLEncoding.EncodeToString(body)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(header + "." + payload))
+	return header + "." + payload + "." + base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestAuth_Authorize(t *testing.T) {
+	secret := "0123456789abcdef0123456789abcdef"
+	auth, err := NewAuth(&AuthConfig{
+		Tokens: []AuthToken{
+			{Name: "explorer", Token: "explorer-token-0001", Namespaces: []string{"ledger"}},
+			{Name: "wallet", Token: "wallet-token-00001", Methods: []string{"wallet_listEntropyFilesInStandardDir"}},
+		},
+		JWTSecret:        secret,
+		AnonymousMethods: []string{"ledger_getSnapshotChainHeight"},
+		IPRateLimit:      100,
+		IPRateBurst:      100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req := func(svc, method string) *serverRequest {
+		return &serverRequest{svcname: svc, method: method}
+	}
+	call := func(token, remote, svc, method string) Error {
+		return auth.authorize(withCredentials(context.Background(), transportHTTP, remote, token), req(svc, method))
+	}
+
+	if err := call("", "1.1.1.1:1", "ledger", "getSnapshotChainHeight"); err != nil {
+		t.Error(err)
+	}
+	if err := call("", "1.1.1.1:1", "ledger", "getBlocksByAccAddr"); err == nil {
+		t.Error("anonymous should only call the anonymous methods")
+	}
+	if err := call("explorer-token-0001", "1.1.1.1:1", "ledger", "getBlocksByAccAddr"); err != nil {
+		t.Error(err)
+	}
+	if err := call("explorer-token-0001", "1.1.1.1:1", "wallet", "listEntropyFilesInStandardDir"); err == nil {
+		t.Error("explorer should not call wallet")
+	}
+	if err := call("wallet-token-00001", "1.1.1.1:1", "wallet", "listEntropyFilesInStandardDir"); err != nil {
+		t.Error(err)
+	}
+	if err := call("wrong-token-000001", "1.1.1.1:1", "ledger", "getSnapshotChainHeight"); err == nil {
+		t.Error("wrong token should be rejected")
+	}
+	if err := auth.authorize(withCredentials(context.Background(), transportIPC, "", ""), req("wallet", "unlock")); err != nil {
+		t.Error(err)
+	}
+
+	token := newTestJWT(secret, &jwtClaims{Subject: "dapp", Expire: time.Now().Add(time.Hour).Unix(), Namespaces: []string{"contract"}})
+	if err := call(token, "1.1.1.1:1", "contract", "getCreateContractToAddress"); err != nil {
+		t.Error(err)
+	}
+	if err := call(token, "1.1.1.1:1", "ledger", "getBlocksByAccAddr"); err == nil {
+		t.Error("jwt should only call its namespaces")
+	}
+	expired := newTestJWT(secret, &jwtClaims{Subject: "dapp", Expire: time.Now().Add(-time.Hour).Unix()})
+	if err := call(expired, "1.1.1.1:1", "contract", "getCreateContractToAddress"); err == nil {
+		t.Error("expired jwt should be rejected")
+	}
+	unscoped := newTestJWT(secret, &jwtClaims{Subject: "dapp", Expire: time.Now().Add(time.Hour).Unix()})
+	if err := call(unscoped, "1.1.1.1:1", "contract", "getCreateContractToAddress"); err == nil {
+		t.Error("jwt without scope should call nothing")
+	}
+	all := newTestJWT(secret, &jwtClaims{Subject: "admin", Expire: time.Now().Add(time.Hour).Unix(), Namespaces: []string{"*"}})
+	if err := call(all, "1.1.1.1:1", "wallet", "unlock"); err != nil {
+		t.Error(err)
+	}
+	forged := newTestJWT("fedcba9876543210fedcba9876543210", &jwtClaims{Subject: "dapp", Expire: time.Now().Add(time.Hour).Unix()})
+	if err := call(forged, "1.1.1.1:1", "contract", "getCreateContractToAddress"); err == nil {
+		t.Error("jwt of other secret should be rejected")
+	}
+}
+
+func TestAuth_RateLimit(t *testing.T) {
+	auth, err := NewAuth(&AuthConfig{
+		Tokens:              []AuthToken{{Name: "explorer", Token: "explorer-token-0001", Namespaces: []string{"*"}, RateLimit: 1, RateBurst: 20}},
+		AnonymousNamespaces: []string{"*"},
+		IPRateLimit:         1,
+		IPRateBurst:         10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	call := func(token, remote, method string) Error {
+		ctx := withCredentials(context.Background(), transportHTTP, remote, token)
+		return auth.authorize(ctx, &serverRequest{svcname: "ledger", method: method})
+	}
+
+	// the cost of getBlocksByAccAddr is 10
+	if err := call("", "1.1.1.1:1", "getBlocksByAccAddr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("", "1.1.1.1:2", "getSnapshotChainHeight"); err == nil {
+		t.Fatal("rate limit of ip should be exceeded")
+	} else if err.ErrorCode() != (&rateLimitedError{}).ErrorCode() {
+		t.Fatal(err)
+	}
+	if err := call("", "2.2.2.2:1", "getSnapshotChainHeight"); err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if err := call("explorer-token-0001", "3.3.3.3:1", "getSnapshotChainHeight"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := call("explorer-token-0001", "4.4.4.4:1", "getBlocksByAccAddr"); err != nil {
+		t.Fatal(err)
+	}
+	if err := call("explorer-token-0001", "5.5.5.5:1", "getBlocksByAccAddr"); err == nil {
+		t.Fatal("rate limit of token should be exceeded")
+	}
+}
+
+func TestAuth_IPC(t *testing.T) {
+	auth, err := NewAuth(&AuthConfig{AnonymousNamespaces: []string{"ledger"}, IPCAuth: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := withCredentials(context.Background(), transportIPC, "", "")
+	if err := auth.authorize(ctx, &serverRequest{svcname: "ledger", method: "getSnapshotChainHeight"}); err != nil {
+		t.Error(err)
+	}
+	if err := auth.authorize(ctx, &serverRequest{svcname: "wallet", method: "unlock"}); err == nil {
+		t.Error("ipc callers should be anonymous if IPCAuth")
+	}
+}
+
+func TestRateLimiter_Evict(t *testing.T) {
+	l := newRateLimiter(1, 1)
+	for i := 0; i < rateBucketMax*2; i++ {
+		l.allow(fmt.Sprintf("key%d", i), 1)
+		if len(l.buckets) > rateBucketMax {
+			t.Fatalf("too many buckets: %d", len(l.buckets))
+		}
+	}
+	if _, ok := l.buckets[fmt.Sprintf("key%d", rateBucketMax*2-1)]; !ok {
+		t.Error("the latest bucket should be kept")
+	}
+}
+
+func TestAuth_ParamsSize(t *testing.T) {
+	auth, err := NewAuth(&AuthConfig{AnonymousNamespaces: []string{"*"}, MaxParamsSize: 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := auth.checkParamsSize(json.RawMessage(`[1,2]`)); err != nil {
+		t.Error(err)
+	}
+	if err := auth.checkParamsSize(json.RawMessage(`[1,2,3,4,5]`)); err == nil {
+		t.Error("large params should be rejected")
+	}
+}
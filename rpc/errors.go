@@ -39,6 +39,41 @@ func (e *callbackError) ErrorCode() int { return -32002 }
 
 func (e *callbackError) Error() string { return e.message }
 
+// the caller is not authenticated or not allowed to call the method
+type unauthorizedError struct{ message string }
+
+func (e *unauthorizedError) ErrorCode() int { return -32003 }
+
+func (e *unauthorizedError) Error() string { return e.message }
+
+// the caller sends too many requests, retry later
+type rateLimitedError struct{ message string }
+
+func (e *rateLimitedError) ErrorCode() int { return -32004 }
+
+func (e *rateLimitedError) Error() string { return e.message }
+
+// the batch has too many requests
+type batchTooLargeError struct{ message string }
+
+func (e *batchTooLargeError) ErrorCode() int { return -32005 }
+
+func (e *batchTooLargeError) Error() string { return e.message }
+
+// the result of request is too large
+type responseTooLargeError struct{ message string }
+
+func (e *responseTooLargeError) ErrorCode() int { return -32006 }
+
+func (e *responseTooLargeError) Error() string { return e.message }
+
+// the request is not finished before the timeout
+type timeoutError struct{ message string }
+
+func (e *timeoutError) ErrorCode() int { return -32007 }
+
+func (e *timeoutError) Error() string { return e.message }
+
 // received message isn't a valid request
 type invalidRequestError struct {
 	message string
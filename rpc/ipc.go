@@ -25,6 +25,7 @@ import (
 )
 
 // ServeListener accepts connections on l, serving JSON-RPC on them.
+// It serves the ipc endpoint, so the callers are trusted by Auth.
 func (srv *Server) ServeListener(l net.Listener) error {
 	fmt.Println("Vite rpc start success!")
 	for {
@@ -42,7 +43,8 @@ func (srv *Server) ServeListener(l net.Listener) error {
 		if conn != nil {
 			log.Info("Accepted connection", conn.RemoteAddr(), "addr", conn.LocalAddr())
 		}
-		go srv.ServeCodec(NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
+		ctx := withCredentials(context.Background(), transportIPC, "", "")
+		go srv.serveCodec(ctx, NewJSONCodec(conn), OptionMethodInvocation|OptionSubscriptions)
 	}
 }
 
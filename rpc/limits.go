@@ -0,0 +1,122 @@
+package rpc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"runtime"
+	"time"
+
+	log "github.com/vitelabs/go-vite/log15"
+)
+
+// LimitConfig limits the resources used by the requests of a Server
+type LimitConfig struct {
+	MaxBatchLength  int `json:"MaxBatchLength"`  // requests of a batch, 0 means unlimited
+	MaxResponseSize int `json:"MaxResponseSize"` // bytes of the result of each request, 0 means unlimited
+
+	// milliseconds of execution, the key is a namespace like "ledger" or a method like "ledger_getChunks",
+	// the timeout of method overrides the timeout of namespace, 0 means no timeout
+	DefaultTimeout int            `json:"DefaultTimeout"`
+	Timeouts       map[string]int `json:"Timeouts"`
+}
+
+// Limits is the resource limits of a Server.
+// The context of a method is canceled on timeout. Only the methods taking a context.Context stop then, like
+// ledger_getChunks, ledger_getStorageBySnapshot and trace_traceBlock, for the others the timeout only abandons
+// the response and the method keeps running, so the expensive methods must take the context and check it.
+type Limits struct {
+	maxBatchLength  int
+	maxResponseSize int
+	defaultTimeout  time.Duration
+	timeouts        map[string]time.Duration
+}
+
+func NewLimits(cfg *LimitConfig) (*Limits, error) {
+	if cfg.MaxBatchLength < 0 || cfg.MaxResponseSize < 0 || cfg.DefaultTimeout < 0 {
+		return nil, fmt.Errorf("rpc limits should not be negative")
+	}
+	l := &Limits{
+		maxBatchLength:  cfg.MaxBatchLength,
+		maxResponseSize: cfg.MaxResponseSize,
+		defaultTimeout:  time.Duration(cfg.DefaultTimeout) * time.Millisecond,
+		timeouts:        make(map[string]time.Duration, len(cfg.Timeouts)),
+	}
+	for name, t := range cfg.Timeouts {
+		if t < 0 {
+			return nil, fmt.Errorf("timeout of %s should not be negative", name)
+		}
+		l.timeouts[name] = time.Duration(t) * time.Millisecond
+	}
+	return l, nil
+}
+
+func (l *Limits) timeout(namespace, method string) time.Duration {
+	if t, ok := l.timeouts[namespace+serviceMethodSeparator+method]; ok {
+		return t
+	}
+	if t, ok := l.timeouts[namespace]; ok {
+		return t
+	}
+	return l.defaultTimeout
+}
+
+func (l *Limits) checkBatch(reqs []*serverRequest) Error {
+	if l.maxBatchLength > 0 && len(reqs) > l.maxBatchLength {
+		return &batchTooLargeError{fmt.Sprintf("batch too large (%d>%d)", len(reqs), l.maxBatchLength)}
+	}
+	return nil
+}
+
+// checkResponse marshals the result to check its size, it is only done if the limit is set
+func (l *Limits) checkResponse(result interface{}) Error {
+	if l.maxResponseSize <= 0 {
+		return nil
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return &callbackError{err.Error()}
+	}
+	if len(data) > l.maxResponseSize {
+		return &responseTooLargeError{fmt.Sprintf("response too large (%d>%d), please narrow the query", len(data), l.maxResponseSize)}
+	}
+	return nil
+}
+
+// call executes the method in another goroutine if it has a timeout, so the caller gets an error on time.
+// A method without context can't be stopped, it keeps running after the timeout.
+func (l *Limits) call(ctx context.Context, req *serverRequest, arguments []reflect.Value, timeout time.Duration) ([]reflect.Value, Error) {
+	type callResult struct {
+		reply    []reflect.Value
+		panicked bool
+	}
+	done := make(chan callResult, 1)
+	go func() {
+		defer func() {
+			if err := recover(); err != nil {
+				const size = 64 << 10
+				buf := make([]byte, size)
+				buf = buf[:runtime.Stack(buf, false)]
+				log.Error(fmt.Sprintf("%v\n%s", err, buf))
+				done <- callResult{panicked: true}
+			}
+		}()
+		done <- callResult{reply: req.callb.method.Func.Call(arguments)}
+	}()
+
+	select {
+	case r := <-done:
+		if r.panicked {
+			return nil, &executePanicError{}
+		}
+		return r.reply, nil
+	case <-ctx.Done():
+		name := req.svcname + serviceMethodSeparator + req.method
+		if ctx.Err() == context.DeadlineExceeded {
+			log.Warn("rpc request timeout", "method", name, "timeout", timeout)
+			return nil, &timeoutError{fmt.Sprintf("%s timeout after %v", name, timeout)}
+		}
+		return nil, &timeoutError{fmt.Sprintf("%s canceled", name)}
+	}
+}
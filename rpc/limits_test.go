@@ -0,0 +1,77 @@
+package rpc
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newLimitsTestConn(t *testing.T, cfg *LimitConfig) (net.Conn, *json.Decoder, func()) {
+	limits, err := NewLimits(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := NewServer()
+	server.SetLimits(limits)
+	if err := server.RegisterName("test", new(Service)); err != nil {
+		t.Fatal(err)
+	}
+	serverConn, clientConn := net.Pipe()
+	go server.ServeCodec(NewJSONCodec(serverConn), OptionMethodInvocation)
+	clientConn.SetDeadline(time.Now().Add(5 * time.Second))
+	return clientConn, json.NewDecoder(clientConn), func() {
+		clientConn.Close()
+		server.Stop()
+	}
+}
+
+func TestLimits_Timeout(t *testing.T) {
+	conn, dec, stop := newLimitsTestConn(t, &LimitConfig{Timeouts: map[string]int{"test": 50, "test_echo": 0}})
+	defer stop()
+
+	start := time.Now()
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":1,"method":"test_sleep","params":[10000000000]}`))
+	var resp jsonErrResponse
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Code != (&timeoutError{}).ErrorCode() {
+		t.Fatalf("expected timeout error, got %+v", resp.Error)
+	}
+	if time.Since(start) > 2*time.Second {
+		t.Fatal("timeout doesn't work")
+	}
+
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":2,"method":"test_echo","params":["x",1,{"S":"y"}]}`))
+	var ok jsonSuccessResponse
+	if err := dec.Decode(&ok); err != nil {
+		t.Fatal(err)
+	}
+	if ok.Result == nil {
+		t.Fatal("echo should not timeout")
+	}
+}
+
+func TestLimits_BatchAndResponse(t *testing.T) {
+	conn, dec, stop := newLimitsTestConn(t, &LimitConfig{MaxBatchLength: 2, MaxResponseSize: 64})
+	defer stop()
+
+	conn.Write([]byte(`[{"jsonrpc":"2.0","id":1,"method":"test_rets"},{"jsonrpc":"2.0","id":2,"method":"test_rets"},{"jsonrpc":"2.0","id":3,"method":"test_rets"}]`))
+	var resp jsonErrResponse
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Code != (&batchTooLargeError{}).ErrorCode() {
+		t.Fatalf("expected batch error, got %+v", resp.Error)
+	}
+
+	conn.Write([]byte(`{"jsonrpc":"2.0","id":4,"method":"test_echo","params":["` + strings.Repeat("x", 100) + `",1,{"S":"y"}]}`))
+	if err := dec.Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Error.Code != (&responseTooLargeError{}).ErrorCode() {
+		t.Fatalf("expected response error, got %+v", resp.Error)
+	}
+}
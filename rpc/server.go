@@ -25,6 +25,7 @@ import (
 	"strings"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	mapset "github.com/deckarep/golang-set"
 	log "github.com/vitelabs/go-vite/log15"
@@ -117,6 +118,16 @@ func (s *Server) RegisterName(name string, rcvr interface{}) error {
 	return nil
 }
 
+// SetAuth sets the access policy, it should be called before serving
+func (s *Server) SetAuth(auth *Auth) {
+	s.auth = auth
+}
+
+// SetLimits sets the resource limits, it should be called before serving
+func (s *Server) SetLimits(limits *Limits) {
+	s.limits = limits
+}
+
 // serveRequest will reads requests from the codec, calls the RPC callback and
 // writes the response to the given codec.
 //
@@ -189,6 +200,15 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 			}
 			return nil
 		}
+		if batch && s.limits != nil {
+			if err := s.limits.checkBatch(reqs); err != nil {
+				codec.Write(codec.CreateErrorResponse(nil, err))
+				if singleShot {
+					return nil
+				}
+				continue
+			}
+		}
 		// If chain single shot request is executing, run and return immediately
 		if singleShot {
 			if batch {
@@ -217,8 +237,13 @@ func (s *Server) serveRequest(ctx context.Context, codec ServerCodec, singleShot
 // response back using the given codec. It will block until the codec is closed or the server is
 // stopped. In either case the codec is closed.
 func (s *Server) ServeCodec(codec ServerCodec, options CodecOption) error {
+	return s.serveCodec(context.Background(), codec, options)
+}
+
+// serveCodec is ServeCodec with the context holding the credentials of connection
+func (s *Server) serveCodec(ctx context.Context, codec ServerCodec, options CodecOption) error {
 	defer codec.Close()
-	return s.serveRequest(context.Background(), codec, false, options)
+	return s.serveRequest(ctx, codec, false, options)
 }
 
 // ServeSingleRequest reads and processes chain single RPC request from the given codec. It will not
@@ -262,6 +287,13 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, req.err), nil
 	}
 
+	// unsubscribe is always allowed, it can only cancel the subscriptions of the same connection
+	if s.auth != nil && !req.isUnsubscribe {
+		if err := s.auth.authorize(ctx, req); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
+
 	if req.isUnsubscribe { // cancel subscription, first param must be the subscription id
 		if len(req.args) >= 1 && req.args[0].Kind() == reflect.String {
 			notifier, supported := NotifierFromContext(ctx)
@@ -302,6 +334,16 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		return codec.CreateErrorResponse(&req.id, rpcErr), nil
 	}
 
+	var timeout time.Duration
+	if s.limits != nil {
+		timeout = s.limits.timeout(req.svcname, req.method)
+	}
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
 	arguments := []reflect.Value{req.callb.rcvr}
 	if req.callb.hasCtx {
 		arguments = append(arguments, reflect.ValueOf(ctx))
@@ -318,7 +360,15 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 		}
 	}()
 	// execute RPC method and return result
-	reply := req.callb.method.Func.Call(arguments)
+	var reply []reflect.Value
+	if timeout > 0 {
+		var err Error
+		if reply, err = s.limits.call(ctx, req, arguments, timeout); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	} else {
+		reply = req.callb.method.Func.Call(arguments)
+	}
 	if len(reply) == 0 {
 		return codec.CreateResponse(req.id, nil), nil
 	}
@@ -335,6 +385,11 @@ func (s *Server) handle(ctx context.Context, codec ServerCodec, req *serverReque
 			return res, nil
 		}
 	}
+	if s.limits != nil {
+		if err := s.limits.checkResponse(reply[0].Interface()); err != nil {
+			return codec.CreateErrorResponse(&req.id, err), nil
+		}
+	}
 	return codec.CreateResponse(req.id, reply[0].Interface()), nil
 }
 
@@ -418,6 +473,13 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 			continue
 		}
 
+		if s.auth != nil {
+			if err := s.auth.checkParamsSize(r.params); err != nil {
+				requests[i] = &serverRequest{id: r.id, err: err}
+				continue
+			}
+		}
+
 		if svc, ok = s.services[r.service]; !ok { // rpc method isn't available
 			requests[i] = &serverRequest{id: r.id, err: &methodNotFoundError{r.service, r.method, nil}}
 			continue
@@ -425,7 +487,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 
 		if r.isPubSub { // eth_subscribe, r.method contains the subscription method name
 			if callb, ok := svc.subscriptions[r.method]; ok {
-				requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+				requests[i] = &serverRequest{id: r.id, svcname: svc.name, method: r.method, callb: callb}
 				if r.params != nil && len(callb.argTypes) > 0 {
 					argTypes := []reflect.Type{reflect.TypeOf("")}
 					argTypes = append(argTypes, callb.argTypes...)
@@ -442,7 +504,7 @@ func (s *Server) readRequest(codec ServerCodec) ([]*serverRequest, bool, Error)
 		}
 
 		if callb, ok := svc.callbacks[r.method]; ok { // lookup RPC method
-			requests[i] = &serverRequest{id: r.id, svcname: svc.name, callb: callb}
+			requests[i] = &serverRequest{id: r.id, svcname: svc.name, method: r.method, callb: callb}
 			if r.params != nil && len(callb.argTypes) > 0 {
 				if args, err := codec.ParseRequestArguments(callb.argTypes, r.params); err == nil {
 					requests[i].args = args
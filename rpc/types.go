@@ -53,6 +53,7 @@ type service struct {
 type serverRequest struct {
 	id            interface{}
 	svcname       string
+	method        string
 	callb         *callback
 	args          []reflect.Value
 	isUnsubscribe bool
@@ -70,6 +71,9 @@ type Server struct {
 	run      int32
 	codecsMu sync.Mutex
 	codecs   mapset.Set
+
+	auth   *Auth   // nil means all requests are allowed
+	limits *Limits // nil means unlimited
 }
 
 // rpcRequest represents a raw incoming RPC request
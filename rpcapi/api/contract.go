@@ -2,28 +2,34 @@ package api
 
 import (
 	"encoding/hex"
+	"fmt"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/vite"
 	"github.com/vitelabs/go-vite/vm"
 	"github.com/vitelabs/go-vite/vm/abi"
 	"github.com/vitelabs/go-vite/vm/util"
 	"github.com/vitelabs/go-vite/vm_db"
+	"math/big"
 	"strings"
 )
 
 type ContractApi struct {
-	chain chain.Chain
-	log   log15.Logger
+	chain     chain.Chain
+	consensus generator.Consensus
+	log       log15.Logger
 }
 
 func NewContractApi(vite *vite.Vite) *ContractApi {
 	return &ContractApi{
-		chain: vite.Chain(),
-		log:   log15.New("module", "rpc_api/contract_api"),
+		chain:     vite.Chain(),
+		consensus: vite.Consensus(),
+		log:       log15.New("module", "rpc_api/contract_api"),
 	}
 }
 
@@ -187,3 +193,239 @@ func (c *ContractApi) GetContractInfo(addr types.Address) (*ContractInfo, error)
 	}
 	return &ContractInfo{Code: code, Gid: meta.Gid, ConfirmTime: meta.SendConfirmedTimes, QuotaRatio: meta.QuotaRatio}, nil
 }
+
+type SimulateCallParam struct {
+	SelfAddr     types.Address     `json:"selfAddr"`
+	ToAddr       types.Address     `json:"toAddr"`
+	TokenId      types.TokenTypeId `json:"tokenId"`
+	Amount       *string           `json:"amount"`
+	Data         []byte            `json:"data"`
+	Difficulty   *string           `json:"difficulty,omitempty"`
+	SnapshotHash *types.Hash       `json:"snapshotHash,omitempty"`
+}
+
+type SimulateCallResult struct {
+	SendBlockHash    types.Hash                                              `json:"sendBlockHash"`
+	SendQuotaUsed    string                                                  `json:"sendQuotaUsed"`              // uint64
+	ReceiveBlockType byte                                                    `json:"receiveBlockType,omitempty"` // BlockTypeReceive or BlockTypeReceiveError, 0 if toAddr is not a contract
+	ReceiveQuotaUsed string                                                  `json:"receiveQuotaUsed,omitempty"` // uint64
+	ReceiveError     string                                                  `json:"receiveError,omitempty"`
+	IsRetry          bool                                                    `json:"isRetry"` // the contract has no enough quota to receive the send block now
+	StorageDiff      map[string]string                                       `json:"storageDiff,omitempty"`
+	BalanceDiffs     map[types.Address]map[types.TokenTypeId]*RpcBalanceDiff `json:"balanceDiffs"`
+	VmLogList        ledger.VmLogList                                        `json:"vmLogList,omitempty"`
+	SendBlockList    []*RpcTraceSendBlock                                    `json:"sendBlockList,omitempty"`
+}
+
+type RpcBalanceDiff struct {
+	Before *string `json:"before"` // big int
+	After  *string `json:"after"`  // big int
+}
+
+// SimulateCall runs a send call and the receive of the contract on a throwaway vm_db without producing any blocks.
+// The state of the latest snapshot block and the unconfirmed blocks is used if snapshotHash is nil, otherwise the
+// state at the snapshot block is used.
+func (c *ContractApi) SimulateCall(param SimulateCallParam) (*SimulateCallResult, error) {
+	amount := big.NewInt(0)
+	if param.Amount != nil {
+		var ok bool
+		if amount, ok = new(big.Int).SetString(*param.Amount, 10); !ok {
+			return nil, ErrStrToBigInt
+		}
+	}
+	var difficulty *big.Int
+	if param.Difficulty != nil {
+		var ok bool
+		if difficulty, ok = new(big.Int).SetString(*param.Difficulty, 10); !ok {
+			return nil, ErrStrToBigInt
+		}
+	}
+
+	sc, err := newSimulateChain(c.chain, param.SnapshotHash)
+	if err != nil {
+		return nil, err
+	}
+	sb := sc.sb
+
+	result := &SimulateCallResult{
+		BalanceDiffs: make(map[types.Address]map[types.TokenTypeId]*RpcBalanceDiff),
+	}
+
+	// send
+	prevHash, err := getPrevBlockHash(sc, param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	sendGen, err := generator.NewGenerator(sc, c.consensus, param.SelfAddr, &sb.Hash, prevHash)
+	if err != nil {
+		return nil, err
+	}
+	sendResult, err := sendGen.GenerateWithMessage(&generator.IncomingMessage{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: param.SelfAddr,
+		ToAddress:      &param.ToAddr,
+		TokenId:        &param.TokenId,
+		Amount:         amount,
+		Data:           param.Data,
+		Difficulty:     difficulty,
+	}, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := sc.err(); err != nil {
+		return nil, err
+	}
+	if sendResult.Err != nil {
+		return nil, sendResult.Err
+	}
+	if sendResult.VMBlock == nil {
+		return nil, errors.New("generator gen an empty send block")
+	}
+	sendBlock := sendResult.VMBlock.AccountBlock
+	result.SendBlockHash = sendBlock.Hash
+	result.SendQuotaUsed = Uint64ToString(sendBlock.QuotaUsed)
+	if err := c.addBalanceDiffs(result, sc, param.SelfAddr, sendResult.VMBlock.VmDb); err != nil {
+		return nil, err
+	}
+
+	if !types.IsContractAddr(param.ToAddr) {
+		return result, nil
+	}
+
+	// receive
+	receiveResult, err := simulateReceive(sc, c.consensus, sendBlock)
+	if err != nil {
+		return nil, err
+	}
+	if err := sc.err(); err != nil {
+		return nil, err
+	}
+	result.IsRetry = receiveResult.IsRetry
+	if receiveResult.Err != nil {
+		result.ReceiveError = receiveResult.Err.Error()
+	}
+	if receiveResult.VMBlock == nil {
+		return result, nil
+	}
+
+	receiveBlock := receiveResult.VMBlock.AccountBlock
+	result.ReceiveBlockType = receiveBlock.BlockType
+	result.ReceiveQuotaUsed = Uint64ToString(receiveBlock.QuotaUsed)
+	result.VmLogList = receiveResult.VMBlock.VmDb.GetLogList()
+
+	result.StorageDiff = make(map[string]string)
+	for _, kv := range receiveResult.VMBlock.VmDb.GetUnsavedStorage() {
+		result.StorageDiff["0x"+hex.EncodeToString(kv[0])] = "0x" + hex.EncodeToString(kv[1])
+	}
+	if err := c.addBalanceDiffs(result, sc, param.ToAddr, receiveResult.VMBlock.VmDb); err != nil {
+		return nil, err
+	}
+
+	for _, block := range receiveBlock.SendBlockList {
+		result.SendBlockList = append(result.SendBlockList, &RpcTraceSendBlock{
+			Hash:      block.Hash,
+			BlockType: block.BlockType,
+			ToAddress: block.ToAddress,
+			TokenId:   block.TokenId,
+			Amount:    bigIntToString(block.Amount),
+			Fee:       bigIntToString(block.Fee),
+			Data:      block.Data,
+		})
+	}
+	return result, nil
+}
+
+func (c *ContractApi) addBalanceDiffs(result *SimulateCallResult, sc *simulateChain, addr types.Address, db vm_db.VmDb) error {
+	balanceMap := db.GetUnsavedBalanceMap()
+	if len(balanceMap) <= 0 {
+		return nil
+	}
+
+	diffs := make(map[types.TokenTypeId]*RpcBalanceDiff, len(balanceMap))
+	for tokenId, balance := range balanceMap {
+		before, err := sc.GetBalance(addr, tokenId)
+		if err != nil {
+			return err
+		}
+		diffs[tokenId] = &RpcBalanceDiff{
+			Before: bigIntToString(before),
+			After:  bigIntToString(balance),
+		}
+	}
+	result.BalanceDiffs[addr] = diffs
+	return nil
+}
+
+// simulateReceive runs the receive of the contract on a throwaway vm_db
+func simulateReceive(sc *simulateChain, consensus generator.Consensus, sendBlock *ledger.AccountBlock) (*generator.GenResult, error) {
+	sc.sendBlock = sendBlock
+
+	prevHash, err := getPrevBlockHash(sc, sendBlock.ToAddress)
+	if err != nil {
+		return nil, err
+	}
+	gen, err := generator.NewGenerator(sc, consensus, sendBlock.ToAddress, &sc.sb.Hash, prevHash)
+	if err != nil {
+		return nil, err
+	}
+	return gen.GenerateWithBlock(&ledger.AccountBlock{
+		BlockType:      ledger.BlockTypeReceive,
+		AccountAddress: sendBlock.ToAddress,
+		FromBlockHash:  sendBlock.Hash,
+	}, sendBlock)
+}
+
+// simulateChain treats the simulated send block as confirmed by the snapshot block simulated at
+type simulateChain struct {
+	chain.Chain
+
+	sb        *ledger.SnapshotBlock
+	sendBlock *ledger.AccountBlock
+	history   *historyChain
+}
+
+// err return the error of the history reads which can't return an error
+func (sc *simulateChain) err() error {
+	if sc.history == nil {
+		return nil
+	}
+	return sc.history.err
+}
+
+// the state of the latest snapshot block and the unconfirmed blocks is used if snapshotHash is nil
+func newSimulateChain(c chain.Chain, snapshotHash *types.Hash) (*simulateChain, error) {
+	sb := c.GetLatestSnapshotBlock()
+	if snapshotHash == nil || *snapshotHash == sb.Hash {
+		return &simulateChain{Chain: c, sb: sb}, nil
+	}
+
+	sb, err := c.GetSnapshotHeaderByHash(*snapshotHash)
+	if err != nil {
+		return nil, err
+	}
+	if sb == nil {
+		return nil, errors.New(fmt.Sprintf("snapshot block %s is not exist", snapshotHash))
+	}
+	// the prev block of an account is the latest one confirmed at or before sb
+	hc := newHistoryChain(c, sb.Height, types.Address{}, nil, nil)
+	return &simulateChain{
+		Chain:   hc,
+		sb:      sb,
+		history: hc,
+	}, nil
+}
+
+func (sc *simulateChain) GetSnapshotBlockByContractMeta(addr *types.Address, fromHash *types.Hash) (*ledger.SnapshotBlock, error) {
+	if sc.sendBlock == nil || *fromHash != sc.sendBlock.Hash {
+		return sc.Chain.GetSnapshotBlockByContractMeta(addr, fromHash)
+	}
+
+	meta, err := sc.Chain.GetContractMeta(*addr)
+	if err != nil {
+		return nil, err
+	}
+	if meta == nil || meta.SendConfirmedTimes == 0 {
+		return nil, nil
+	}
+	return sc.sb, nil
+}
@@ -0,0 +1,488 @@
+package api
+
+import (
+	"context"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/state"
+	"github.com/vitelabs/go-vite/common/db"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/interfaces"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm_db"
+)
+
+type RpcBlockTrace struct {
+	Hash          types.Hash           `json:"hash"`
+	ReplayedHash  *types.Hash          `json:"replayedHash"` // hash of the block re-executed, differs from hash if the replay diverged
+	BlockType     byte                 `json:"blockType"`
+	QuotaUsed     string               `json:"quotaUsed,omitempty"` // uint64
+	Error         string               `json:"error,omitempty"`
+	ReturnData    string               `json:"returnData,omitempty"`
+	Steps         []*RpcTraceStep      `json:"steps"`
+	SendBlockList []*RpcTraceSendBlock `json:"sendBlockList"`
+	VmLogList     ledger.VmLogList     `json:"vmLogList"`
+}
+
+type RpcTraceStep struct {
+	Depth        int                   `json:"depth"`
+	Address      types.Address         `json:"address"`
+	Pc           string                `json:"pc"` // uint64
+	Op           string                `json:"op"`
+	Cost         string                `json:"cost"`      // uint64
+	QuotaLeft    string                `json:"quotaLeft"` // uint64
+	StackTop     *string               `json:"stackTop,omitempty"`
+	MemoryWrite  *RpcTraceMemoryWrite  `json:"memoryWrite,omitempty"`
+	StorageWrite *RpcTraceStorageWrite `json:"storageWrite,omitempty"`
+}
+
+type RpcTraceMemoryWrite struct {
+	Offset string `json:"offset"` // uint64
+	Data   string `json:"data"`
+}
+
+type RpcTraceStorageWrite struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+}
+
+type RpcTraceSendBlock struct {
+	Hash      types.Hash        `json:"hash"`
+	BlockType byte              `json:"blockType"`
+	ToAddress types.Address     `json:"toAddress"`
+	TokenId   types.TokenTypeId `json:"tokenId"`
+	Amount    *string           `json:"amount"` // big int
+	Fee       *string           `json:"fee"`    // big int
+	Data      []byte            `json:"data"`
+}
+
+// TraceApi re-executes blocks, it's expensive and not in the public apis, enable the "trace" module explicitly
+type TraceApi struct {
+	chain     chain.Chain
+	consensus generator.Consensus
+}
+
+func NewTraceApi(v *vite.Vite) *TraceApi {
+	return &TraceApi{
+		chain:     v.Chain(),
+		consensus: v.Consensus(),
+	}
+}
+
+func (api TraceApi) String() string {
+	return "TraceApi"
+}
+
+// TraceBlock re-executes a confirmed account block and returns the opcode-level trace.
+// The block is re-executed on the state of its account before it, which is rebuilt from the previous
+// snapshot block of its confirming snapshot block and the redo logs of the account blocks ahead of it.
+// It stops when ctx is canceled by the timeout of rpc server, the vm execution itself is bounded by the quota.
+func (api TraceApi) TraceBlock(ctx context.Context, hash types.Hash) (*RpcBlockTrace, error) {
+	c := api.chain
+
+	block, err := c.GetAccountBlockByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if block == nil {
+		return nil, errors.New(fmt.Sprintf("account block %s is not exist", hash))
+	}
+	if block.IsSendBlock() && types.IsContractAddr(block.AccountAddress) {
+		return nil, errors.New("the send block of a contract is generated by a receive block, trace the receive block instead")
+	}
+
+	confirmSb, err := c.GetConfirmSnapshotHeaderByAbHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	if confirmSb == nil {
+		return nil, errors.New("only confirmed account blocks can be traced")
+	}
+	if confirmSb.Height <= 1 {
+		return nil, errors.New("account blocks in the genesis snapshot block can't be traced")
+	}
+	// the blocks ahead of the traced block and the send block may have been pruned
+	if prunedHeight := c.GetPrunedSnapshotHeight(); confirmSb.Height <= prunedHeight {
+		return nil, errors.New(fmt.Sprintf("account blocks confirmed by snapshot block %d have been pruned, the pruned snapshot height is %d",
+			confirmSb.Height, prunedHeight))
+	}
+
+	prevSb, err := c.GetSnapshotHeaderByHeight(confirmSb.Height - 1)
+	if err != nil {
+		return nil, err
+	}
+	if prevSb == nil {
+		return nil, errors.New(fmt.Sprintf("snapshot block %d is not exist", confirmSb.Height-1))
+	}
+
+	snapshotLog, err := c.GetSnapshotStateLog(confirmSb.Height)
+	if err != nil {
+		return nil, err
+	}
+
+	var fromBlock *ledger.AccountBlock
+	if block.IsReceiveBlock() {
+		fromBlock, err = c.GetAccountBlockByHash(block.FromBlockHash)
+		if err != nil {
+			return nil, err
+		}
+		if fromBlock == nil {
+			return nil, errors.New(fmt.Sprintf("send block %s is not exist", block.FromBlockHash))
+		}
+	}
+
+	// the redo logs of the account blocks ahead of the traced block
+	var logList []chain_state.LogItem
+	var aheadBlocks []*ledger.AccountBlock
+	for _, item := range snapshotLog[block.AccountAddress] {
+		if item.Height >= block.Height {
+			continue
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		ahead, err := c.GetAccountBlockByHeight(block.AccountAddress, item.Height)
+		if err != nil {
+			return nil, err
+		}
+		if ahead == nil {
+			return nil, errors.New(fmt.Sprintf("account block %s %d is not exist", block.AccountAddress, item.Height))
+		}
+		logList = append(logList, item)
+		aheadBlocks = append(aheadBlocks, ahead)
+	}
+
+	hc := newHistoryChain(c, prevSb.Height, block.AccountAddress, logList, aheadBlocks)
+	gen, err := generator.NewGenerator(hc, api.consensus, block.AccountAddress, &prevSb.Hash, &block.PrevHash)
+	if err != nil {
+		return nil, err
+	}
+
+	tracer := vm.NewStructLogger()
+	gen.SetTracer(tracer)
+
+	genResult, err := gen.GenerateWithBlock(block, fromBlock)
+	if err != nil {
+		return nil, err
+	}
+	if hc.err != nil {
+		return nil, hc.err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	trace := &RpcBlockTrace{
+		Hash:      block.Hash,
+		BlockType: block.BlockType,
+		Steps:     make([]*RpcTraceStep, 0, len(tracer.StructLogs())),
+	}
+
+	if genResult.Err != nil {
+		trace.Error = genResult.Err.Error()
+	} else if tracer.Error() != nil {
+		trace.Error = tracer.Error().Error()
+	}
+	if ret := tracer.Output(); len(ret) > 0 {
+		trace.ReturnData = "0x" + hex.EncodeToString(ret)
+	}
+
+	for _, log := range tracer.StructLogs() {
+		trace.Steps = append(trace.Steps, structLogToRpc(log))
+	}
+
+	if genResult.VMBlock != nil {
+		vb := genResult.VMBlock.AccountBlock
+		trace.ReplayedHash = &vb.Hash
+		trace.BlockType = vb.BlockType
+		trace.QuotaUsed = Uint64ToString(vb.QuotaUsed)
+		trace.VmLogList = genResult.VMBlock.VmDb.GetLogList()
+
+		sendBlockList := vb.SendBlockList
+		if vb.IsSendBlock() {
+			sendBlockList = []*ledger.AccountBlock{vb}
+		}
+		trace.SendBlockList = make([]*RpcTraceSendBlock, 0, len(sendBlockList))
+		for _, sendBlock := range sendBlockList {
+			trace.SendBlockList = append(trace.SendBlockList, &RpcTraceSendBlock{
+				Hash:      sendBlock.Hash,
+				BlockType: sendBlock.BlockType,
+				ToAddress: sendBlock.ToAddress,
+				TokenId:   sendBlock.TokenId,
+				Amount:    bigIntToString(sendBlock.Amount),
+				Fee:       bigIntToString(sendBlock.Fee),
+				Data:      sendBlock.Data,
+			})
+		}
+	}
+	return trace, nil
+}
+
+func structLogToRpc(log *vm.StructLog) *RpcTraceStep {
+	step := &RpcTraceStep{
+		Depth:     log.Depth,
+		Address:   log.Addr,
+		Pc:        strconv.FormatUint(log.Pc, 10),
+		Op:        log.Op,
+		Cost:      strconv.FormatUint(log.Cost, 10),
+		QuotaLeft: strconv.FormatUint(log.QuotaLeft, 10),
+	}
+	if log.StackTop != nil {
+		stackTop := "0x" + log.StackTop.Text(16)
+		step.StackTop = &stackTop
+	}
+	if log.MemoryWrite != nil {
+		step.MemoryWrite = &RpcTraceMemoryWrite{
+			Offset: strconv.FormatUint(log.MemoryWrite.Offset, 10),
+			Data:   "0x" + hex.EncodeToString(log.MemoryWrite.Data),
+		}
+	}
+	if log.StorageWrite != nil {
+		step.StorageWrite = &RpcTraceStorageWrite{
+			Key:   "0x" + hex.EncodeToString(log.StorageWrite.Key),
+			Value: "0x" + hex.EncodeToString(log.StorageWrite.Value),
+		}
+	}
+	return step
+}
+
+// historyChain reads the state at a snapshot height, the balance, storage, code and contract meta
+// of addr are overlaid by logList, which are the redo logs of the account blocks confirmed by the next
+// snapshot block ahead of the traced block. These blocks are the unconfirmed blocks of addr.
+// The reads without an error in the signature record the first error in err.
+type historyChain struct {
+	chain.Chain
+
+	snapshotHeight uint64
+	addr           types.Address
+	logList        []chain_state.LogItem
+	aheadBlocks    []*ledger.AccountBlock
+
+	err error
+}
+
+// quota used in the latest 74 snapshot blocks and by the unconfirmed blocks, the same as the quota list of chain cache
+const historyQuotaUsedHeight = 75
+
+func newHistoryChain(c chain.Chain, snapshotHeight uint64, addr types.Address, logList []chain_state.LogItem, aheadBlocks []*ledger.AccountBlock) *historyChain {
+	return &historyChain{
+		Chain:          c,
+		snapshotHeight: snapshotHeight,
+		addr:           addr,
+		logList:        logList,
+		aheadBlocks:    aheadBlocks,
+	}
+}
+
+func (hc *historyChain) setErr(err error) {
+	if hc.err == nil {
+		hc.err = err
+	}
+}
+
+func (hc *historyChain) GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error) {
+	if addr == hc.addr {
+		for i := len(hc.logList) - 1; i >= 0; i-- {
+			if balance, ok := hc.logList[i].BalanceMap[tokenId]; ok {
+				return new(big.Int).Set(balance), nil
+			}
+		}
+	}
+	return hc.Chain.GetSnapshotBalance(hc.snapshotHeight, addr, tokenId)
+}
+
+func (hc *historyChain) GetValue(addr types.Address, key []byte) ([]byte, error) {
+	if addr == hc.addr {
+		for i := len(hc.logList) - 1; i >= 0; i-- {
+			for _, kv := range hc.logList[i].Storage {
+				if string(kv[0]) != string(key) {
+					continue
+				}
+				if len(kv[1]) <= 0 {
+					return nil, nil
+				}
+				return kv[1], nil
+			}
+		}
+	}
+	return hc.Chain.GetSnapshotValue(hc.snapshotHeight, addr, key)
+}
+
+func (hc *historyChain) GetStorageIterator(addr types.Address, prefix []byte) (interfaces.StorageIterator, error) {
+	iter, err := hc.Chain.GetSnapshotStorageIterator(hc.snapshotHeight, addr, prefix)
+	if err != nil {
+		return nil, err
+	}
+	if addr != hc.addr || len(hc.logList) <= 0 {
+		return iter, nil
+	}
+
+	unsaved := vm_db.NewUnsaved()
+	for _, item := range hc.logList {
+		for _, kv := range item.Storage {
+			unsaved.SetValue(kv[0], kv[1])
+		}
+	}
+
+	return db.NewMergedIterator([]interfaces.StorageIterator{
+		unsaved.NewStorageIterator(prefix),
+		iter,
+	}, unsaved.IsDelete), nil
+}
+
+func (hc *historyChain) GetContractMeta(addr types.Address) (*ledger.ContractMeta, error) {
+	for i := len(hc.logList) - 1; i >= 0; i-- {
+		if buf, ok := hc.logList[i].ContractMeta[addr]; ok {
+			meta := &ledger.ContractMeta{}
+			if err := meta.Deserialize(buf); err != nil {
+				return nil, err
+			}
+			return meta, nil
+		}
+	}
+
+	// the contract may be created by a send block confirmed together with its first receive block
+	if addr == hc.addr {
+		return hc.Chain.GetContractMetaInSnapshot(addr, hc.snapshotHeight+1)
+	}
+	return hc.Chain.GetContractMetaInSnapshot(addr, hc.snapshotHeight)
+}
+
+func (hc *historyChain) IsContractAccount(addr types.Address) (bool, error) {
+	meta, err := hc.GetContractMeta(addr)
+	if err != nil {
+		return false, err
+	}
+	return meta != nil, nil
+}
+
+// IsContractAccountInSnapshot checks the contracts existed at the height of the history chain
+func (hc *historyChain) IsContractAccountInSnapshot(addr types.Address, snapshotHeight uint64) (bool, error) {
+	return hc.IsContractAccount(addr)
+}
+
+// GetContractCode return the code of the contracts existed at the height, code is never changed after creation
+func (hc *historyChain) GetContractCode(addr types.Address) ([]byte, error) {
+	if addr == hc.addr {
+		for i := len(hc.logList) - 1; i >= 0; i-- {
+			if len(hc.logList[i].Code) > 0 {
+				return hc.logList[i].Code, nil
+			}
+		}
+	}
+
+	meta, err := hc.GetContractMeta(addr)
+	if err != nil || meta == nil {
+		return nil, err
+	}
+	return hc.Chain.GetContractCode(addr)
+}
+
+func (hc *historyChain) GetPledgeBeneficialAmount(addr types.Address) (*big.Int, error) {
+	return abi.GetPledgeBeneficialAmount(&historyStorage{hc: hc, addr: types.AddressPledge}, addr)
+}
+
+func (hc *historyChain) GetUnconfirmedBlocks(addr types.Address) []*ledger.AccountBlock {
+	if addr == hc.addr {
+		return hc.aheadBlocks
+	}
+	return nil
+}
+
+// GetLatestAccountBlock return the latest account block confirmed at or before the height,
+// the blocks ahead of the traced block are the latest ones of addr
+func (hc *historyChain) GetLatestAccountBlock(addr types.Address) (*ledger.AccountBlock, error) {
+	if addr == hc.addr && len(hc.aheadBlocks) > 0 {
+		return hc.aheadBlocks[len(hc.aheadBlocks)-1], nil
+	}
+
+	latestHeight, err := hc.Chain.GetLatestAccountHeight(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	// the confirmed height grows with the account height, search the last one confirmed at or before the height
+	var latest *ledger.AccountBlock
+	low, high := uint64(1), latestHeight
+	for low <= high {
+		mid := low + (high-low)/2
+		block, err := hc.Chain.GetAccountBlockByHeight(addr, mid)
+		if err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, errors.New(fmt.Sprintf("account block %s %d is not exist", addr, mid))
+		}
+
+		confirmSb, err := hc.Chain.GetConfirmSnapshotHeaderByAbHash(block.Hash)
+		if err != nil {
+			return nil, err
+		}
+		if confirmSb != nil && confirmSb.Height <= hc.snapshotHeight {
+			latest = block
+			low = mid + 1
+		} else {
+			high = mid - 1
+		}
+	}
+	return latest, nil
+}
+
+func (hc *historyChain) GetQuotaUsedList(addr types.Address) []types.QuotaInfo {
+	start := uint64(1)
+	if hc.snapshotHeight > historyQuotaUsedHeight {
+		start = hc.snapshotHeight - (historyQuotaUsedHeight - 1)
+	}
+
+	// the first chunk is the start snapshot block itself
+	chunks, err := hc.Chain.GetSubLedger(start, hc.snapshotHeight)
+	if err != nil {
+		hc.setErr(err)
+		return nil
+	}
+
+	usedList := make([]types.QuotaInfo, 0, historyQuotaUsedHeight)
+	for i := 1; i < len(chunks); i++ {
+		usedList = append(usedList, sumQuotaInfo(chunks[i].AccountBlocks, addr))
+	}
+	return append(usedList, sumQuotaInfo(hc.GetUnconfirmedBlocks(addr), addr))
+}
+
+func sumQuotaInfo(blocks []*ledger.AccountBlock, addr types.Address) (info types.QuotaInfo) {
+	for _, block := range blocks {
+		if block.AccountAddress != addr {
+			continue
+		}
+		info.BlockCount++
+		info.QuotaTotal += block.Quota
+		info.QuotaUsedTotal += block.QuotaUsed
+	}
+	return
+}
+
+// historyStorage is the storage of a contract at the height of hc
+type historyStorage struct {
+	hc   *historyChain
+	addr types.Address
+}
+
+func (s *historyStorage) GetValue(key []byte) ([]byte, error) {
+	return s.hc.GetValue(s.addr, key)
+}
+
+func (s *historyStorage) NewStorageIterator(prefix []byte) (interfaces.StorageIterator, error) {
+	return s.hc.GetStorageIterator(s.addr, prefix)
+}
+
+func (s *historyStorage) Address() *types.Address {
+	return &s.addr
+}
@@ -0,0 +1,337 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/test_tools"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/generator"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/quota"
+)
+
+var traceGenesisJson = `{
+  "GenesisAccountAddress": "vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a",
+  "ForkPoints": {},
+  "ConsensusGroupInfo": {
+    "ConsensusGroupInfoMap": {
+      "00000000000000000001": {
+        "NodeCount": 25,
+        "Interval": 1,
+        "PerCount": 3,
+        "RandCount": 2,
+        "RandRank": 100,
+        "Repeat": 1,
+        "CheckLevel": 0,
+        "CountingTokenId": "tti_5649544520544f4b454e6e40",
+        "RegisterConditionId": 1,
+        "RegisterConditionParam": {
+          "PledgeAmount": 100000000000000000000000,
+          "PledgeHeight": 1,
+          "PledgeToken": "tti_5649544520544f4b454e6e40"
+        },
+        "VoteConditionId": 1,
+        "VoteConditionParam": {},
+        "Owner": "vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a",
+        "PledgeAmount": 0,
+        "WithdrawHeight": 1
+      },
+      "00000000000000000002": {
+        "NodeCount": 25,
+        "Interval": 3,
+        "PerCount": 1,
+        "RandCount": 2,
+        "RandRank": 100,
+        "Repeat": 48,
+        "CheckLevel": 1,
+        "CountingTokenId": "tti_5649544520544f4b454e6e40",
+        "RegisterConditionId": 1,
+        "RegisterConditionParam": {
+          "PledgeAmount": 100000000000000000000000,
+          "PledgeHeight": 1,
+          "PledgeToken": "tti_5649544520544f4b454e6e40"
+        },
+        "VoteConditionId": 1,
+        "VoteConditionParam": {},
+        "Owner": "vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a",
+        "PledgeAmount": 0,
+        "WithdrawHeight": 1
+      }
+    },
+    "RegistrationInfoMap": {
+      "00000000000000000001": {
+        "s1": {
+          "NodeAddr": "vite_14edbc9214bd1e5f6082438f707d10bf43463a6d599a4f2d08",
+          "PledgeAddr": "vite_14edbc9214bd1e5f6082438f707d10bf43463a6d599a4f2d08",
+          "Amount": 100000000000000000000000,
+          "WithdrawHeight": 7776000,
+          "RewardTime": 1,
+          "CancelTime": 0,
+          "HisAddrList": [
+            "vite_14edbc9214bd1e5f6082438f707d10bf43463a6d599a4f2d08"
+          ]
+        }
+      }
+    }
+  },
+  "MintageInfo": {
+    "TokenInfoMap": {
+      "tti_5649544520544f4b454e6e40": {
+        "TokenName": "Vite Token",
+        "TokenSymbol": "VITE",
+        "TotalSupply": 1000000000000000000000000000,
+        "Decimals": 18,
+        "Owner": "vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a",
+        "PledgeAmount": 0,
+        "PledgeAddr": "vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a",
+        "WithdrawHeight": 0,
+        "MaxSupply": 115792089237316195423570985008687907853269984665640564039457584007913129639935,
+        "OwnerBurnOnly": false,
+        "IsReIssuable": true
+      }
+    },
+    "LogList": [
+      {
+        "Data": "",
+        "Topics": [
+          "3f9dcc00d5e929040142c3fb2b67a3be1b0e91e98dac18d5bc2b7817a4cfecb6",
+          "000000000000000000000000000000000000000000005649544520544f4b454e"
+        ]
+      }
+    ]
+  },
+  "AccountBalanceMap": {
+    "vite_ab24ef68b84e642c0ddca06beec81c9acb1977bbd7da27a87a": {
+      "tti_5649544520544f4b454e6e40": 899999000000000000000000000
+    }
+  }
+}`
+
+// traceTestChain is a chain in a temp dir, the pruned snapshot height can be set
+type traceTestChain struct {
+	chain.Chain
+
+	dir          string
+	prunedHeight uint64
+}
+
+func newTraceTestChain(t *testing.T, balances map[types.Address]*big.Int) *traceTestChain {
+	// the balance is checked, but the quota is not
+	vm.InitVMConfig(false, true, false, "")
+	quota.InitQuotaConfig(true, true)
+
+	dir, err := ioutil.TempDir("", "trace")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	genesisConfig := &config.Genesis{}
+	if err = json.Unmarshal([]byte(traceGenesisJson), genesisConfig); err != nil {
+		t.Fatal(err)
+	}
+	for addr, balance := range balances {
+		genesisConfig.AccountBalanceMap[addr.String()] = map[string]*big.Int{ledger.ViteTokenId.String(): balance}
+	}
+
+	c := chain.NewChain(dir, &config.Chain{}, genesisConfig)
+	if err = c.Init(); err != nil {
+		t.Fatal(err)
+	}
+	c.SetConsensus(&test_tools.MockConsensus{})
+	if err = c.Start(); err != nil {
+		t.Fatal(err)
+	}
+	return &traceTestChain{Chain: c, dir: dir}
+}
+
+func (c *traceTestChain) close() {
+	c.Chain.Stop()
+	os.RemoveAll(c.dir)
+}
+
+func (c *traceTestChain) GetPrunedSnapshotHeight() uint64 {
+	return c.prunedHeight
+}
+
+// generate generates the block by the message or the send block on the latest snapshot block and inserts it
+func (c *traceTestChain) generate(t *testing.T, key ed25519.PrivateKey, addr types.Address, message *generator.IncomingMessage, sendBlock *ledger.AccountBlock) *ledger.AccountBlock {
+	latestSb := c.GetLatestSnapshotBlock()
+	var prevHash types.Hash
+	prev, err := c.GetLatestAccountBlock(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if prev != nil {
+		prevHash = prev.Hash
+	}
+
+	gen, err := generator.NewGenerator(c, &test_tools.MockConsensus{}, addr, &latestSb.Hash, &prevHash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signFunc := func(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+		return ed25519.Sign(key, data), key.PubByte(), nil
+	}
+
+	var result *generator.GenResult
+	if sendBlock != nil {
+		result, err = gen.GenerateWithOnRoad(sendBlock, &addr, signFunc, nil)
+	} else {
+		result, err = gen.GenerateWithMessage(message, &addr, signFunc)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Err != nil || result.VMBlock == nil {
+		t.Fatalf("generate block failed, err: %v", result.Err)
+	}
+
+	if err = c.InsertAccountBlock(result.VMBlock); err != nil {
+		t.Fatal(err)
+	}
+	return result.VMBlock.AccountBlock
+}
+
+// snapshot confirms all the unconfirmed account blocks
+func (c *traceTestChain) snapshot(t *testing.T) {
+	latestSb := c.GetLatestSnapshotBlock()
+	timestamp := latestSb.Timestamp.Add(time.Second)
+	sb := &ledger.SnapshotBlock{
+		PrevHash:        latestSb.Hash,
+		Height:          latestSb.Height + 1,
+		Timestamp:       &timestamp,
+		SnapshotContent: c.GetContentNeedSnapshot(),
+	}
+	sb.Hash = sb.ComputeHash()
+	if _, err := c.InsertSnapshotBlock(sb); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestTraceApi_TraceBlock(t *testing.T) {
+	_, key, err := ed25519.GenerateKeyFromD([32]byte{1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := types.PubkeyToAddress(key.PubByte())
+	_, toKey, err := ed25519.GenerateKeyFromD([32]byte{2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	toAddr := types.PubkeyToAddress(toKey.PubByte())
+
+	viteAmount := new(big.Int).Exp(big.NewInt(10), big.NewInt(18), nil)
+	c := newTraceTestChain(t, map[types.Address]*big.Int{addr: new(big.Int).Mul(big.NewInt(1e6), viteAmount)})
+	defer c.close()
+	api := &TraceApi{chain: c, consensus: &test_tools.MockConsensus{}}
+
+	tokenId := ledger.ViteTokenId
+	transfer := c.generate(t, key, addr, &generator.IncomingMessage{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: addr,
+		ToAddress:      &toAddr,
+		TokenId:        &tokenId,
+		Amount:         big.NewInt(100),
+	}, nil)
+
+	pledgeData, err := abi.ABIPledge.PackMethod(abi.MethodNamePledge, addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pledge := c.generate(t, key, addr, &generator.IncomingMessage{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: addr,
+		ToAddress:      &types.AddressPledge,
+		TokenId:        &tokenId,
+		Amount:         new(big.Int).Mul(big.NewInt(1000), viteAmount),
+		Data:           pledgeData,
+	}, nil)
+
+	// unconfirmed blocks can't be traced
+	if _, err = api.TraceBlock(context.Background(), transfer.Hash); err == nil || !strings.Contains(err.Error(), "confirmed") {
+		t.Fatalf("unconfirmed block should not be traced, err: %v", err)
+	}
+	c.snapshot(t)
+
+	receive := c.generate(t, toKey, toAddr, nil, transfer)
+	pledgeReceive := c.generate(t, key, types.AddressPledge, nil, pledge)
+	c.snapshot(t)
+
+	// the receive block of cancel pledge emits a refund send block
+	cancelData, err := abi.ABIPledge.PackMethod(abi.MethodNameCancelPledge, addr, new(big.Int).Mul(big.NewInt(500), viteAmount))
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancel := c.generate(t, key, addr, &generator.IncomingMessage{
+		BlockType:      ledger.BlockTypeSendCall,
+		AccountAddress: addr,
+		ToAddress:      &types.AddressPledge,
+		TokenId:        &tokenId,
+		Amount:         big.NewInt(0),
+		Data:           cancelData,
+	}, nil)
+	c.snapshot(t)
+	cancelReceive := c.generate(t, key, types.AddressPledge, nil, cancel)
+	c.snapshot(t)
+	if len(cancelReceive.SendBlockList) != 1 {
+		t.Fatalf("cancel pledge should emit a refund, but %d send blocks", len(cancelReceive.SendBlockList))
+	}
+
+	trace, err := api.TraceBlock(context.Background(), cancelReceive.Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(trace.SendBlockList) != 1 || trace.SendBlockList[0].Hash != cancelReceive.SendBlockList[0].Hash ||
+		trace.SendBlockList[0].ToAddress != addr {
+		t.Fatalf("wrong send blocks of the trace %+v", trace.SendBlockList)
+	}
+
+	// the refund send block is generated by the receive block
+	if _, err = api.TraceBlock(context.Background(), cancelReceive.SendBlockList[0].Hash); err == nil {
+		t.Fatal("send block of a contract should not be traced")
+	}
+
+	// the send blocks and the receive blocks are replayed to the same blocks, the second send block
+	// is replayed on the state after the first one
+	for _, block := range []*ledger.AccountBlock{transfer, pledge, receive, pledgeReceive, cancel, cancelReceive} {
+		trace, err := api.TraceBlock(context.Background(), block.Hash)
+		if err != nil {
+			t.Fatalf("trace block %s failed, err: %v", block.Hash, err)
+		}
+		if trace.Error != "" {
+			t.Fatalf("trace block %s has an error %s", block.Hash, trace.Error)
+		}
+		if trace.ReplayedHash == nil || *trace.ReplayedHash != block.Hash {
+			t.Fatalf("block %s is replayed to %v", block.Hash, trace.ReplayedHash)
+		}
+		if block.IsSendBlock() && (len(trace.SendBlockList) != 1 || trace.SendBlockList[0].Hash != block.Hash) {
+			t.Fatalf("send block %s should be in the send block list", block.Hash)
+		}
+	}
+
+	// the block is not exist
+	if _, err = api.TraceBlock(context.Background(), types.Hash{1}); err == nil || !strings.Contains(err.Error(), "not exist") {
+		t.Fatalf("missing block should not be traced, err: %v", err)
+	}
+
+	// the blocks confirmed by the pruned snapshot blocks
+	c.prunedHeight = 2
+	if _, err = api.TraceBlock(context.Background(), transfer.Hash); err == nil || !strings.Contains(err.Error(), "pruned") {
+		t.Fatalf("pruned block should not be traced, err: %v", err)
+	}
+	if _, err = api.TraceBlock(context.Background(), receive.Hash); err != nil {
+		t.Fatalf("block after the pruned height should be traced, err: %v", err)
+	}
+}
@@ -48,10 +48,11 @@ type SnapshotChainEvent struct {
 }
 
 type ChainSubscribe struct {
-	vite                   *vite.Vite
-	es                     *EventSystem
-	listenIdList           []uint64
-	preDeleteAccountBlocks []*AccountChainEvent
+	vite                    *vite.Vite
+	es                      *EventSystem
+	listenIdList            []uint64
+	preDeleteAccountBlocks  []*AccountChainEvent
+	preDeleteSnapshotBlocks []*SnapshotChainEvent
 }
 
 func NewChainSubscribe(v *vite.Vite, e *EventSystem) *ChainSubscribe {
@@ -73,7 +74,7 @@ func (c *ChainSubscribe) InsertAccountBlocks(blocks []*vm_db.VmAccountBlock) err
 	for i, b := range blocks {
 		acEvents[i] = NewAccountChainEvent(b.AccountBlock, b.VmDb.GetLogList())
 	}
-	c.es.acCh <- acEvents
+	c.es.eventCh <- &chainEvent{ac: acEvents}
 	return nil
 }
 
@@ -85,21 +86,14 @@ func (c *ChainSubscribe) InsertSnapshotBlocks(chunks []*ledger.SnapshotChunk) er
 	for _, chunk := range chunks {
 		sbEvents = append(sbEvents, &SnapshotChainEvent{chunk.SnapshotBlock.Hash, chunk.SnapshotBlock.Height})
 	}
-	c.es.sbCh <- sbEvents
+	c.es.eventCh <- &chainEvent{sb: sbEvents}
 	return nil
 }
 func (c *ChainSubscribe) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
-	acEvents := make([]*AccountChainEvent, 0)
-	for _, b := range blocks {
-		if b.LogHash != nil {
-			logList, err := c.vite.Chain().GetVmLogList(b.LogHash)
-			if err != nil {
-				c.es.log.Error("get log list failed when preDeleteAccountBlocks", "addr", b.AccountAddress, "hash", b.Hash, "height", b.Height, "err", err)
-			}
-			acEvents = append(acEvents, NewAccountChainEvent(b, logList))
-		} else {
-			acEvents = append(acEvents, NewAccountChainEvent(b, nil))
-		}
+	// the removed blocks are notified in the reverse order
+	acEvents := make([]*AccountChainEvent, 0, len(blocks))
+	for i := len(blocks) - 1; i >= 0; i-- {
+		acEvents = append(acEvents, c.newDeletedAccountChainEvent(blocks[i], "preDeleteAccountBlocks"))
 	}
 	c.preDeleteAccountBlocks = append(c.preDeleteAccountBlocks, acEvents...)
 	return nil
@@ -107,40 +101,49 @@ func (c *ChainSubscribe) PrepareDeleteAccountBlocks(blocks []*ledger.AccountBloc
 func (c *ChainSubscribe) DeleteAccountBlocks(blocks []*ledger.AccountBlock) error {
 	deletedBlocks := c.preDeleteAccountBlocks
 	c.preDeleteAccountBlocks = nil
-	c.es.acDelCh <- deletedBlocks
+	c.es.eventCh <- &chainEvent{removed: true, ac: deletedBlocks}
 	return nil
 }
 func (c *ChainSubscribe) PrepareDeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
+	// the removed blocks are notified in the reverse order
+	sbEvents := make([]*SnapshotChainEvent, 0, len(chunks))
 	acEvents := make([]*AccountChainEvent, 0)
-	for _, chunk := range chunks {
-		for _, b := range chunk.AccountBlocks {
-			if b.LogHash != nil {
-				logList, err := c.vite.Chain().GetVmLogList(b.LogHash)
-				if err != nil {
-					c.es.log.Error("get log list failed when preDeleteSnapshotBlocks", "addr", b.AccountAddress, "hash", b.Hash, "height", b.Height, "err", err)
-				}
-				acEvents = append(acEvents, NewAccountChainEvent(b, logList))
-			} else {
-				acEvents = append(acEvents, NewAccountChainEvent(b, nil))
-			}
+	for i := len(chunks) - 1; i >= 0; i-- {
+		chunk := chunks[i]
+		if chunk.SnapshotBlock != nil {
+			sbEvents = append(sbEvents, &SnapshotChainEvent{chunk.SnapshotBlock.Hash, chunk.SnapshotBlock.Height})
+		}
+		for j := len(chunk.AccountBlocks) - 1; j >= 0; j-- {
+			acEvents = append(acEvents, c.newDeletedAccountChainEvent(chunk.AccountBlocks[j], "preDeleteSnapshotBlocks"))
 		}
 	}
+	c.preDeleteSnapshotBlocks = append(c.preDeleteSnapshotBlocks, sbEvents...)
 	c.preDeleteAccountBlocks = append(c.preDeleteAccountBlocks, acEvents...)
 	return nil
 }
 func (c *ChainSubscribe) DeleteSnapshotBlocks(chunks []*ledger.SnapshotChunk) error {
-	sbEvents := make([]*SnapshotChainEvent, 0, len(chunks))
-	for _, b := range chunks {
-		if b.SnapshotBlock != nil {
-			sbEvents = append(sbEvents, &SnapshotChainEvent{b.SnapshotBlock.Hash, b.SnapshotBlock.Height})
-		}
+	e := &chainEvent{
+		removed: true,
+		sb:      c.preDeleteSnapshotBlocks,
+		ac:      c.preDeleteAccountBlocks,
 	}
-	c.es.sbDelCh <- sbEvents
+	c.preDeleteSnapshotBlocks = nil
+	c.preDeleteAccountBlocks = nil
 
-	if len(c.preDeleteAccountBlocks) > 0 {
-		deletedBlocks := c.preDeleteAccountBlocks
-		c.preDeleteAccountBlocks = nil
-		c.es.acDelCh <- deletedBlocks
+	if len(e.sb) > 0 || len(e.ac) > 0 {
+		c.es.eventCh <- e
 	}
 	return nil
 }
+
+// the logs are read before the block is deleted
+func (c *ChainSubscribe) newDeletedAccountChainEvent(b *ledger.AccountBlock, method string) *AccountChainEvent {
+	if b.LogHash == nil {
+		return NewAccountChainEvent(b, nil)
+	}
+	logList, err := c.vite.Chain().GetVmLogList(b.LogHash)
+	if err != nil {
+		c.es.log.Error("get log list failed when "+method, "addr", b.AccountAddress, "hash", b.Hash, "height", b.Height, "err", err)
+	}
+	return NewAccountChainEvent(b, logList)
+}
@@ -45,26 +45,31 @@ type subscription struct {
 	accountBlockCh           chan []*AccountBlock
 	accountBlockWithHeightCh chan []*AccountBlockWithHeight
 	logsCh                   chan []*Logs
+	replay                   *replayState // not nil if the history is replayed before the live events
+}
+
+// chainEvent is an insertion or a deletion of the chain, the removed snapshot blocks are handled before
+// the removed account blocks, and both of them are in the reverse order of insertion.
+type chainEvent struct {
+	removed bool
+	sb      []*SnapshotChainEvent
+	ac      []*AccountChainEvent
 }
 
 type EventSystem struct {
 	vite      *vite.Vite
+	ledger    replayChain
 	chain     *ChainSubscribe
-	install   chan *subscription        // install filter
-	uninstall chan *subscription        // remove filter
-	acCh      chan []*AccountChainEvent // Channel to receive new account chain event
-	acDelCh   chan []*AccountChainEvent // Channel to receive new account chain delete event when account chain fork
-	sbCh      chan []*SnapshotChainEvent
-	sbDelCh   chan []*SnapshotChainEvent
+	install   chan *subscription // install filter
+	uninstall chan *subscription // remove filter
+	activate  chan *subscription // finish the replay of subscription
+	eventCh   chan *chainEvent   // Channel to receive chain events in order, so the removed blocks are notified before the blocks of new fork
 	stop      chan struct{}
 	log       log15.Logger
 }
 
 const (
-	acChanSize    = 100
-	acDelChanSize = 10
-	sbChanSize    = 10
-	sbDelChanSize = 10
+	eventChanSize = 100
 	installSize   = 10
 	uninstallSize = 10
 )
@@ -72,15 +77,16 @@ const (
 func NewEventSystem(v *vite.Vite) *EventSystem {
 	es := &EventSystem{
 		vite:      v,
-		acCh:      make(chan []*AccountChainEvent, acChanSize),
-		acDelCh:   make(chan []*AccountChainEvent, acDelChanSize),
-		sbCh:      make(chan []*SnapshotChainEvent, sbChanSize),
-		sbDelCh:   make(chan []*SnapshotChainEvent, sbDelChanSize),
+		eventCh:   make(chan *chainEvent, eventChanSize),
 		install:   make(chan *subscription, installSize),
 		uninstall: make(chan *subscription, uninstallSize),
+		activate:  make(chan *subscription),
 		stop:      make(chan struct{}),
 		log:       log15.New("module", "rpc_api/event_system"),
 	}
+	if v != nil {
+		es.ledger = v.Chain()
+	}
 	return es
 }
 
@@ -100,24 +106,56 @@ func (es *EventSystem) eventLoop() {
 	for i := LogsSubscription; i <= SnapshotBlocksSubscription; i++ {
 		index[i] = make(map[rpc.ID]*subscription)
 	}
+	// the live events of replaying subscriptions are kept until the replay is finished
+	replaying := make(map[rpc.ID]*subscription)
+
+	var latestHeight uint64
+	if es.ledger != nil {
+		latestHeight = es.ledger.GetLatestSnapshotBlock().Height
+	}
 
 	for {
 		select {
-		case acEvent := <-es.acCh:
-			es.handleAcEvent(index, acEvent, false)
-		case acDelEvent := <-es.acDelCh:
-			es.handleAcEvent(index, acDelEvent, true)
-		case sbEvent := <-es.sbCh:
-			es.handleSbEvent(index, sbEvent, false)
-		case sbDelEvent := <-es.sbDelCh:
-			es.handleSbEvent(index, sbDelEvent, true)
+		case e := <-es.eventCh:
+			for id, s := range replaying {
+				if len(s.replay.pending) >= maxReplayPendingEvents {
+					es.log.Warn("too many pending events, uninstall ", "id", id)
+					delete(replaying, id)
+					close(s.err)
+					continue
+				}
+				s.replay.pending = append(s.replay.pending, e)
+			}
+			latestHeight = e.latestHeight(latestHeight)
+			es.handleSbEvent(index, e.sb, e.removed)
+			es.handleAcEvent(index, e.ac, e.removed, latestHeight+1)
 		case i := <-es.install:
 			es.log.Info("install ", "id", i.id)
-			index[i.typ][i.id] = i
+			if i.replay != nil {
+				replaying[i.id] = i
+			} else {
+				index[i.typ][i.id] = i
+			}
 			close(i.installed)
+		case a := <-es.activate:
+			// the subscription is activated if there are no pending events
+			pending := a.replay.pending
+			a.replay.pending = nil
+			if _, ok := replaying[a.id]; ok && len(pending) == 0 {
+				es.log.Info("activate ", "id", a.id)
+				delete(replaying, a.id)
+				index[a.typ][a.id] = a
+			}
+			a.replay.pendingCh <- pending
 		case u := <-es.uninstall:
+			// a replaying subscription may be uninstalled by itself before the client unsubscribes
+			_, installed := index[u.typ][u.id]
+			if _, ok := replaying[u.id]; !installed && !ok {
+				continue
+			}
 			es.log.Info("uninstall ", "id", u.id)
 			delete(index[u.typ], u.id)
+			delete(replaying, u.id)
 			close(u.err)
 
 		// system stopped
@@ -127,6 +165,9 @@ func (es *EventSystem) eventLoop() {
 					close(s.err)
 				}
 			}
+			for _, s := range replaying {
+				close(s.err)
+			}
 			index = nil
 			es.log.Info("stop event loop")
 			return
@@ -134,93 +175,135 @@ func (es *EventSystem) eventLoop() {
 	}
 }
 
+// latestHeight returns the latest snapshot height after the event
+func (e *chainEvent) latestHeight(latest uint64) uint64 {
+	if len(e.sb) == 0 {
+		return latest
+	}
+	last := e.sb[len(e.sb)-1]
+	if e.removed {
+		return last.Height - 1
+	}
+	return last.Height
+}
+
+// the cursor of an inserted snapshot block is the next height, all the blocks confirmed by it have been notified.
+// The cursor of a removed snapshot block is its height.
 func (es *EventSystem) handleSbEvent(filters map[FilterType]map[rpc.ID]*subscription, sbEvent []*SnapshotChainEvent, removed bool) {
 	if len(sbEvent) == 0 {
 		return
 	}
 	blocks := make([]*SnapshotBlock, len(sbEvent))
 	for i, e := range sbEvent {
-		blocks[i] = &SnapshotBlock{Hash: e.Hash, Height: e.Height, HeightStr: api.Uint64ToString(e.Height), Removed: removed}
+		cursor := e.Height + 1
+		if removed {
+			cursor = e.Height
+		}
+		blocks[i] = &SnapshotBlock{Hash: e.Hash, Height: e.Height, HeightStr: api.Uint64ToString(e.Height), Removed: removed, Cursor: api.Uint64ToString(cursor)}
 	}
 	for _, f := range filters[SnapshotBlocksSubscription] {
-		f.snapshotBlockCh <- blocks
+		select {
+		case f.snapshotBlockCh <- blocks:
+		case <-f.err:
+		}
 	}
 }
 
-func (es *EventSystem) handleAcEvent(filters map[FilterType]map[rpc.ID]*subscription, acEvent []*AccountChainEvent, removed bool) {
+// cursor is the snapshot height to resume the subscription from without missing the account blocks
+func (es *EventSystem) handleAcEvent(filters map[FilterType]map[rpc.ID]*subscription, acEvent []*AccountChainEvent, removed bool, cursor uint64) {
 	if len(acEvent) == 0 {
 		return
 	}
+	cursorStr := api.Uint64ToString(cursor)
 	msgs := make([]*AccountBlock, len(acEvent))
 	heightMsgs := make(map[types.Address][]*AccountBlockWithHeight)
 	onroadMsgs := make(map[types.Address][]*AccountBlock)
 	deletedSendBlockHash := make(map[types.Hash]interface{})
+	if removed {
+		// the removed blocks are in the reverse order, a receive block is handled before its send block
+		for _, e := range acEvent {
+			if ledger.IsSendBlock(e.BlockType) {
+				deletedSendBlockHash[e.Hash] = struct{}{}
+			}
+		}
+	}
 	for i, e := range acEvent {
-		msgs[i] = &AccountBlock{Hash: e.Hash, Removed: removed}
+		msgs[i] = &AccountBlock{Hash: e.Hash, Removed: removed, Cursor: cursorStr}
 		if _, ok := heightMsgs[e.Addr]; !ok {
 			heightMsgs[e.Addr] = make([]*AccountBlockWithHeight, 0)
 		}
-		heightMsgs[e.Addr] = append(heightMsgs[e.Addr], &AccountBlockWithHeight{Height: e.Height, HeightStr: api.Uint64ToString(e.Height), Hash: e.Hash, Removed: removed})
+		heightMsgs[e.Addr] = append(heightMsgs[e.Addr], &AccountBlockWithHeight{Height: e.Height, HeightStr: api.Uint64ToString(e.Height), Hash: e.Hash, Removed: removed, Cursor: cursorStr})
 
 		if removed {
+			// the removed send block is not onroad any more
 			if ledger.IsSendBlock(e.BlockType) {
-				deletedSendBlockHash[e.Hash] = struct{}{}
-			} else {
-				if _, ok := deletedSendBlockHash[e.FromBlockHash]; !ok {
-					onroadMsgs = appendOnroadMsg(onroadMsgs, e, removed)
-				}
+				onroadMsgs = appendOnroadMsg(onroadMsgs, e, removed, cursorStr)
+			} else if _, ok := deletedSendBlockHash[e.FromBlockHash]; !ok {
+				onroadMsgs = appendOnroadMsg(onroadMsgs, e, removed, cursorStr)
 			}
 		} else {
-			onroadMsgs = appendOnroadMsg(onroadMsgs, e, removed)
+			onroadMsgs = appendOnroadMsg(onroadMsgs, e, removed, cursorStr)
 		}
 	}
 	// handle account blocks
 	for _, f := range filters[AccountBlocksSubscription] {
-		f.accountBlockCh <- msgs
+		select {
+		case f.accountBlockCh <- msgs:
+		case <-f.err:
+		}
 	}
 	// handle accountBlocksWithHeight
 	for _, f := range filters[AccountBlocksWithHeightSubscription] {
 		if hashHeightMsgs, ok := heightMsgs[f.addr]; ok {
-			f.accountBlockWithHeightCh <- hashHeightMsgs
+			select {
+			case f.accountBlockWithHeightCh <- hashHeightMsgs:
+			case <-f.err:
+			}
 		}
 	}
 	// handle onroad blocks
 	for _, f := range filters[OnroadBlocksSubscription] {
 		if onroadMsgs, ok := onroadMsgs[f.addr]; ok {
-			f.accountBlockCh <- onroadMsgs
+			select {
+			case f.accountBlockCh <- onroadMsgs:
+			case <-f.err:
+			}
 		}
 	}
 	// handle logs
 	for _, f := range filters[LogsSubscription] {
 		var logs []*Logs
 		for _, e := range acEvent {
-			if matchedLogs := filterLogs(e, f.param, removed); len(matchedLogs) > 0 {
+			if matchedLogs := filterLogs(e, f.param, removed, cursorStr); len(matchedLogs) > 0 {
 				logs = append(logs, matchedLogs...)
 			}
 		}
 		if len(logs) > 0 {
-			f.logsCh <- logs
+			select {
+			case f.logsCh <- logs:
+			case <-f.err:
+			}
 		}
 	}
 }
 
-func appendOnroadMsg(onroadMsgs map[types.Address][]*AccountBlock, e *AccountChainEvent, removed bool) map[types.Address][]*AccountBlock {
+func appendOnroadMsg(onroadMsgs map[types.Address][]*AccountBlock, e *AccountChainEvent, removed bool, cursor string) map[types.Address][]*AccountBlock {
 	if _, ok := onroadMsgs[e.ToAddr]; !ok {
 		onroadMsgs[e.ToAddr] = make([]*AccountBlock, 0)
 	}
-	onroadMsgs[e.ToAddr] = append(onroadMsgs[e.ToAddr], &AccountBlock{Hash: e.Hash, Removed: removed})
+	onroadMsgs[e.ToAddr] = append(onroadMsgs[e.ToAddr], &AccountBlock{Hash: e.Hash, Removed: removed, Cursor: cursor})
 	if len(e.SendBlockList) > 0 {
 		for _, sendBlock := range e.SendBlockList {
 			if _, ok := onroadMsgs[sendBlock.ToAddr]; !ok {
 				onroadMsgs[sendBlock.ToAddr] = make([]*AccountBlock, 0)
 			}
-			onroadMsgs[sendBlock.ToAddr] = append(onroadMsgs[sendBlock.ToAddr], &AccountBlock{Hash: sendBlock.Hash, Removed: removed})
+			onroadMsgs[sendBlock.ToAddr] = append(onroadMsgs[sendBlock.ToAddr], &AccountBlock{Hash: sendBlock.Hash, Removed: removed, Cursor: cursor})
 		}
 	}
 	return onroadMsgs
 }
 
-func filterLogs(e *AccountChainEvent, filter *filterParam, removed bool) []*Logs {
+func filterLogs(e *AccountChainEvent, filter *filterParam, removed bool, cursor string) []*Logs {
 	if len(e.Logs) == 0 {
 		return nil
 	}
@@ -234,7 +317,7 @@ func filterLogs(e *AccountChainEvent, filter *filterParam, removed bool) []*Logs
 	}
 	for _, l := range e.Logs {
 		if filterLog(filter, l) {
-			logs = append(logs, &Logs{l, e.Hash, &e.Addr, removed})
+			logs = append(logs, &Logs{l, e.Hash, &e.Addr, removed, cursor})
 		}
 	}
 	return logs
@@ -290,7 +373,7 @@ func (s *RpcSubscription) Unsubscribe() {
 	})
 }
 
-func (es *EventSystem) SubscribeAccountBlocks(ch chan []*AccountBlock) *RpcSubscription {
+func (es *EventSystem) SubscribeAccountBlocks(ch chan []*AccountBlock, fromHeight uint64) *RpcSubscription {
 	sub := &subscription{
 		id:                       rpc.NewID(),
 		typ:                      AccountBlocksSubscription,
@@ -302,10 +385,10 @@ func (es *EventSystem) SubscribeAccountBlocks(ch chan []*AccountBlock) *RpcSubsc
 		accountBlockWithHeightCh: make(chan []*AccountBlockWithHeight),
 		logsCh:                   make(chan []*Logs),
 	}
-	return es.subscribe(sub)
+	return es.subscribe(sub, fromHeight)
 }
 
-func (es *EventSystem) SubscribeAccountBlocksByAddr(addr types.Address, ch chan []*AccountBlockWithHeight) *RpcSubscription {
+func (es *EventSystem) SubscribeAccountBlocksByAddr(addr types.Address, ch chan []*AccountBlockWithHeight, fromHeight uint64) *RpcSubscription {
 	sub := &subscription{
 		id:                       rpc.NewID(),
 		typ:                      AccountBlocksWithHeightSubscription,
@@ -318,10 +401,10 @@ func (es *EventSystem) SubscribeAccountBlocksByAddr(addr types.Address, ch chan
 		accountBlockWithHeightCh: ch,
 		logsCh:                   make(chan []*Logs),
 	}
-	return es.subscribe(sub)
+	return es.subscribe(sub, fromHeight)
 }
 
-func (es *EventSystem) SubscribeOnroadBlocksByAddr(addr types.Address, ch chan []*AccountBlock) *RpcSubscription {
+func (es *EventSystem) SubscribeOnroadBlocksByAddr(addr types.Address, ch chan []*AccountBlock, fromHeight uint64) *RpcSubscription {
 	sub := &subscription{
 		id:                       rpc.NewID(),
 		typ:                      OnroadBlocksSubscription,
@@ -334,10 +417,10 @@ func (es *EventSystem) SubscribeOnroadBlocksByAddr(addr types.Address, ch chan [
 		accountBlockWithHeightCh: make(chan []*AccountBlockWithHeight),
 		logsCh:                   make(chan []*Logs),
 	}
-	return es.subscribe(sub)
+	return es.subscribe(sub, fromHeight)
 }
 
-func (es *EventSystem) SubscribeSnapshotBlocks(ch chan []*SnapshotBlock) *RpcSubscription {
+func (es *EventSystem) SubscribeSnapshotBlocks(ch chan []*SnapshotBlock, fromHeight uint64) *RpcSubscription {
 	sub := &subscription{
 		id:                       rpc.NewID(),
 		typ:                      SnapshotBlocksSubscription,
@@ -349,10 +432,10 @@ func (es *EventSystem) SubscribeSnapshotBlocks(ch chan []*SnapshotBlock) *RpcSub
 		accountBlockWithHeightCh: make(chan []*AccountBlockWithHeight),
 		logsCh:                   make(chan []*Logs),
 	}
-	return es.subscribe(sub)
+	return es.subscribe(sub, fromHeight)
 }
 
-func (es *EventSystem) SubscribeLogs(p *filterParam, ch chan []*Logs) *RpcSubscription {
+func (es *EventSystem) SubscribeLogs(p *filterParam, ch chan []*Logs, fromHeight uint64) *RpcSubscription {
 	sub := &subscription{
 		id:                       rpc.NewID(),
 		typ:                      LogsSubscription,
@@ -365,11 +448,22 @@ func (es *EventSystem) SubscribeLogs(p *filterParam, ch chan []*Logs) *RpcSubscr
 		accountBlockWithHeightCh: make(chan []*AccountBlockWithHeight),
 		logsCh:                   ch,
 	}
-	return es.subscribe(sub)
+	return es.subscribe(sub, fromHeight)
 }
 
-func (es *EventSystem) subscribe(s *subscription) *RpcSubscription {
+// subscribe replays the history from the snapshot height before the live events if fromHeight is not 0,
+// fromHeight should be checked by checkReplayFrom.
+func (es *EventSystem) subscribe(s *subscription, fromHeight uint64) *RpcSubscription {
+	if fromHeight == 0 {
+		es.install <- s
+		<-s.installed
+		return &RpcSubscription{ID: s.id, sub: s, es: es}
+	}
+
+	oldHead := es.ledger.GetLatestSnapshotBlock().Height
+	s.replay = &replayState{from: fromHeight, pendingCh: make(chan []*chainEvent, 1)}
 	es.install <- s
 	<-s.installed
+	go es.replay(s, oldHead)
 	return &RpcSubscription{ID: s.id, sub: s, es: es}
 }
@@ -0,0 +1,228 @@
+package filters
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestEventSystem_Removed(t *testing.T) {
+	es := NewEventSystem(nil)
+	go es.eventLoop()
+	defer close(es.stop)
+
+	c := &ChainSubscribe{es: es}
+
+	sbCh := make(chan []*SnapshotBlock, 10)
+	sbSub := es.SubscribeSnapshotBlocks(sbCh, 0)
+	defer sbSub.Unsubscribe()
+
+	toAddr := types.Address{1}
+	onroadCh := make(chan []*AccountBlock, 10)
+	onroadSub := es.SubscribeOnroadBlocksByAddr(toAddr, onroadCh, 0)
+	defer onroadSub.Unsubscribe()
+
+	sb := &ledger.SnapshotBlock{Hash: types.Hash{1}, Height: 2}
+	send := &ledger.AccountBlock{BlockType: ledger.BlockTypeSendCall, Hash: types.Hash{2}, ToAddress: toAddr}
+	chunks := []*ledger.SnapshotChunk{{SnapshotBlock: sb, AccountBlocks: []*ledger.AccountBlock{send}}}
+
+	c.InsertSnapshotBlocks(chunks)
+	c.PrepareDeleteSnapshotBlocks(chunks)
+	c.DeleteSnapshotBlocks(chunks)
+
+	fork := &ledger.SnapshotBlock{Hash: types.Hash{3}, Height: 2}
+	c.InsertSnapshotBlocks([]*ledger.SnapshotChunk{{SnapshotBlock: fork}})
+
+	for i, expected := range []SnapshotBlock{{Hash: sb.Hash}, {Hash: sb.Hash, Removed: true}, {Hash: fork.Hash}} {
+		select {
+		case blocks := <-sbCh:
+			if len(blocks) != 1 {
+				t.Fatalf("wrong snapshot blocks %d: %v", i, blocks)
+			}
+			if blocks[0].Hash != expected.Hash || blocks[0].Removed != expected.Removed {
+				t.Errorf("wrong snapshot blocks %d: %v", i, blocks[0])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("missing snapshot blocks %d", i)
+		}
+	}
+
+	select {
+	case blocks := <-onroadCh:
+		if len(blocks) != 1 {
+			t.Fatalf("wrong onroad blocks: %v", blocks)
+		}
+		if blocks[0].Hash != send.Hash || !blocks[0].Removed {
+			t.Errorf("wrong onroad blocks: %v", blocks[0])
+		}
+	case <-time.After(time.Second):
+		t.Fatal("missing removed onroad blocks")
+	}
+}
+
+type mockReplayChain struct {
+	mu          sync.Mutex
+	chunks      []*ledger.SnapshotChunk
+	unconfirmed []*ledger.AccountBlock
+	onReplay    func()
+}
+
+func (c *mockReplayChain) GetLatestSnapshotBlock() *ledger.SnapshotBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.chunks[len(c.chunks)-1].SnapshotBlock
+}
+
+func (c *mockReplayChain) GetPrunedSnapshotHeight() uint64 {
+	return 1
+}
+
+func (c *mockReplayChain) GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error) {
+	if c.onReplay != nil {
+		c.onReplay()
+		c.onReplay = nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if startHeight == 0 {
+		startHeight = 1
+	}
+	if endHeight > uint64(len(c.chunks)) {
+		endHeight = uint64(len(c.chunks))
+	}
+	return c.chunks[startHeight-1 : endHeight], nil
+}
+
+func (c *mockReplayChain) GetAllUnconfirmedBlocks() []*ledger.AccountBlock {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.unconfirmed
+}
+
+func (c *mockReplayChain) GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error) {
+	return nil, nil
+}
+
+func TestEventSystem_Replay(t *testing.T) {
+	c := &mockReplayChain{}
+	for i := byte(1); i <= 3; i++ {
+		c.chunks = append(c.chunks, &ledger.SnapshotChunk{
+			SnapshotBlock: &ledger.SnapshotBlock{Hash: types.Hash{i}, Height: uint64(i)},
+			AccountBlocks: []*ledger.AccountBlock{{Hash: types.Hash{i, 1}}},
+		})
+	}
+
+	es := NewEventSystem(nil)
+	es.ledger = c
+	go es.eventLoop()
+	defer close(es.stop)
+
+	if err := es.checkReplayFrom(1); err == nil {
+		t.Error("pruned height should not be replayed")
+	}
+	if err := es.checkReplayFrom(5); err == nil {
+		t.Error("future height should not be replayed")
+	}
+
+	// an account block and a snapshot block are inserted while the history is replayed
+	unconfirmed := &ledger.AccountBlock{Hash: types.Hash{4, 1}}
+	c.onReplay = func() {
+		c.mu.Lock()
+		c.unconfirmed = []*ledger.AccountBlock{unconfirmed}
+		c.mu.Unlock()
+		es.eventCh <- &chainEvent{ac: []*AccountChainEvent{NewAccountChainEvent(unconfirmed, nil)}}
+		es.eventCh <- &chainEvent{sb: []*SnapshotChainEvent{{types.Hash{4}, 4}}}
+		es.eventCh <- &chainEvent{ac: []*AccountChainEvent{{Hash: types.Hash{5, 1}}}}
+	}
+
+	acCh := make(chan []*AccountBlock, 10)
+	acSub := es.SubscribeAccountBlocks(acCh, 2)
+	defer acSub.Unsubscribe()
+
+	for i, expected := range []AccountBlock{
+		{Hash: types.Hash{2, 1}, Cursor: "2"},
+		{Hash: types.Hash{3, 1}, Cursor: "3"},
+		{Hash: unconfirmed.Hash, Cursor: "4"},
+		{Hash: types.Hash{5, 1}, Cursor: "5"},
+	} {
+		select {
+		case blocks := <-acCh:
+			if len(blocks) != 1 {
+				t.Fatalf("wrong account blocks %d: %v", i, blocks)
+			}
+			if blocks[0].Hash != expected.Hash || blocks[0].Cursor != expected.Cursor {
+				t.Errorf("wrong account blocks %d: %v", i, blocks[0])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("missing account blocks %d", i)
+		}
+	}
+	select {
+	case blocks := <-acCh:
+		t.Errorf("duplicated account blocks: %v", blocks)
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	sbCh := make(chan []*SnapshotBlock, 10)
+	sbSub := es.SubscribeSnapshotBlocks(sbCh, 3)
+	defer sbSub.Unsubscribe()
+	es.eventCh <- &chainEvent{removed: true, sb: []*SnapshotChainEvent{{types.Hash{3}, 3}}}
+
+	for i, expected := range []SnapshotBlock{
+		{Hash: types.Hash{3}, Cursor: "4"},
+		{Hash: types.Hash{3}, Removed: true, Cursor: "3"},
+	} {
+		select {
+		case blocks := <-sbCh:
+			if len(blocks) != 1 {
+				t.Fatalf("wrong snapshot blocks %d: %v", i, blocks)
+			}
+			if blocks[0].Hash != expected.Hash || blocks[0].Removed != expected.Removed || blocks[0].Cursor != expected.Cursor {
+				t.Errorf("wrong snapshot blocks %d: %v", i, blocks[0])
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("missing snapshot blocks %d", i)
+		}
+	}
+}
+
+func TestEventSystem_ReplayOverflow(t *testing.T) {
+	defer func(max int) { maxReplayPendingEvents = max }(maxReplayPendingEvents)
+	maxReplayPendingEvents = 2
+
+	c := &mockReplayChain{}
+	for i := byte(1); i <= 3; i++ {
+		c.chunks = append(c.chunks, &ledger.SnapshotChunk{
+			SnapshotBlock: &ledger.SnapshotBlock{Hash: types.Hash{i}, Height: uint64(i)},
+		})
+	}
+
+	es := NewEventSystem(nil)
+	es.ledger = c
+	go es.eventLoop()
+	defer close(es.stop)
+
+	// more live events than the limit are received while the history is replayed
+	c.onReplay = func() {
+		for i := byte(1); i <= 3; i++ {
+			es.eventCh <- &chainEvent{ac: []*AccountChainEvent{{Hash: types.Hash{4, i}}}}
+		}
+		// the events are received by the event loop before the replay is finished
+		for len(es.eventCh) > 0 {
+			time.Sleep(time.Millisecond)
+		}
+	}
+
+	acCh := make(chan []*AccountBlock, 10)
+	acSub := es.SubscribeAccountBlocks(acCh, 2)
+	defer acSub.Unsubscribe()
+
+	select {
+	case <-acSub.Err():
+	case <-time.After(time.Second):
+		t.Fatal("the subscription should fail if the pending events overflow")
+	}
+}
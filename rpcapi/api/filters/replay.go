@@ -0,0 +1,164 @@
+package filters
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/rpc"
+)
+
+const (
+	maxReplaySnapshotHeights = 24 * 3600
+	replayBatchHeights       = 100
+)
+
+// the live events kept for a replaying subscription, the subscription fails if the replay can't catch up with them
+var maxReplayPendingEvents = 10000
+
+type replayChain interface {
+	GetLatestSnapshotBlock() *ledger.SnapshotBlock
+	GetPrunedSnapshotHeight() uint64
+	GetSubLedger(startHeight, endHeight uint64) ([]*ledger.SnapshotChunk, error)
+	GetAllUnconfirmedBlocks() []*ledger.AccountBlock
+	GetVmLogList(logListHash *types.Hash) (ledger.VmLogList, error)
+}
+
+// replayState keeps the live events received by the event loop while the history is replayed
+type replayState struct {
+	from      uint64
+	pending   []*chainEvent
+	pendingCh chan []*chainEvent
+}
+
+// checkReplayFrom checks whether the history from the snapshot height can be replayed
+func (es *EventSystem) checkReplayFrom(from uint64) error {
+	if es.ledger == nil {
+		return errors.New("replay is not supported")
+	}
+	latest := es.ledger.GetLatestSnapshotBlock().Height
+	if from > latest+1 {
+		return errors.New(fmt.Sprintf("fromSnapshotHeight %d is higher than the next snapshot height %d", from, latest+1))
+	}
+	if pruned := es.ledger.GetPrunedSnapshotHeight(); from <= pruned {
+		return errors.New(fmt.Sprintf("fromSnapshotHeight %d is pruned, should be higher than %d", from, pruned))
+	}
+	if latest+1-from > maxReplaySnapshotHeights {
+		return errors.New(fmt.Sprintf("fromSnapshotHeight %d is too low, at most %d snapshot heights can be replayed", from, maxReplaySnapshotHeights))
+	}
+	return nil
+}
+
+// replay notifies the history from the snapshot height, then the live events kept during the replay,
+// the subscription is switched to live events once there are no more pending events.
+// oldHead is the latest snapshot height before the subscription is installed, the account blocks
+// confirmed after it may be received as live events too, so they are skipped when the pending events are notified.
+func (es *EventSystem) replay(sub *subscription, oldHead uint64) {
+	filters := map[FilterType]map[rpc.ID]*subscription{sub.typ: {sub.id: sub}}
+	seen := make(map[types.Hash]struct{})
+
+	head := es.ledger.GetLatestSnapshotBlock().Height
+	for start := sub.replay.from; start <= head; start += replayBatchHeights {
+		end := start + replayBatchHeights - 1
+		if end > head {
+			end = head
+		}
+		chunks, err := es.ledger.GetSubLedger(start-1, end)
+		if err != nil {
+			es.log.Error(fmt.Sprintf("replay snapshot height %d to %d failed, err: %v", start, end, err), "method", "replay")
+			es.uninstallReplay(sub)
+			return
+		}
+		for _, chunk := range chunks {
+			if chunk.SnapshotBlock == nil || chunk.SnapshotBlock.Height < start || chunk.SnapshotBlock.Height > end {
+				continue
+			}
+			height := chunk.SnapshotBlock.Height
+			es.handleAcEvent(filters, es.replayAccountEvents(sub, chunk.AccountBlocks, height > oldHead, seen), false, height)
+			es.handleSbEvent(filters, []*SnapshotChainEvent{{chunk.SnapshotBlock.Hash, height}}, false)
+		}
+		if isClosed(sub.err) {
+			return
+		}
+	}
+	es.handleAcEvent(filters, es.replayAccountEvents(sub, es.ledger.GetAllUnconfirmedBlocks(), true, seen), false, head+1)
+
+	latest := head
+	for {
+		select {
+		case es.activate <- sub:
+		case <-sub.err:
+			return
+		}
+		pending := <-sub.replay.pendingCh
+		if len(pending) == 0 {
+			return
+		}
+		for _, e := range pending {
+			sb, ac := e.sb, e.ac
+			if !e.removed {
+				sb = skipReplayedSnapshotEvents(sb, head)
+				ac = skipReplayedAccountEvents(ac, seen)
+			}
+			latest = e.latestHeight(latest)
+			es.handleSbEvent(filters, sb, e.removed)
+			es.handleAcEvent(filters, ac, e.removed, latest+1)
+		}
+	}
+}
+
+// uninstallReplay closes the subscription, the client will receive the error and resubscribe
+func (es *EventSystem) uninstallReplay(sub *subscription) {
+	select {
+	case es.uninstall <- sub:
+	case <-sub.err:
+	}
+}
+
+func (es *EventSystem) replayAccountEvents(sub *subscription, blocks []*ledger.AccountBlock, record bool, seen map[types.Hash]struct{}) []*AccountChainEvent {
+	events := make([]*AccountChainEvent, 0, len(blocks))
+	for _, block := range blocks {
+		if record {
+			seen[block.Hash] = struct{}{}
+		}
+		var logs ledger.VmLogList
+		if sub.typ == LogsSubscription && block.LogHash != nil {
+			var err error
+			if logs, err = es.ledger.GetVmLogList(block.LogHash); err != nil {
+				es.log.Error(fmt.Sprintf("get vm logs of %s failed, err: %v", block.Hash, err), "method", "replay")
+			}
+		}
+		events = append(events, NewAccountChainEvent(block, logs))
+	}
+	return events
+}
+
+func skipReplayedSnapshotEvents(events []*SnapshotChainEvent, head uint64) []*SnapshotChainEvent {
+	result := make([]*SnapshotChainEvent, 0, len(events))
+	for _, e := range events {
+		if e.Height > head {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func skipReplayedAccountEvents(events []*AccountChainEvent, seen map[types.Hash]struct{}) []*AccountChainEvent {
+	result := make([]*AccountChainEvent, 0, len(events))
+	for _, e := range events {
+		if _, ok := seen[e.Hash]; !ok {
+			result = append(result, e)
+		}
+	}
+	return result
+}
+
+func isClosed(ch chan error) bool {
+	select {
+	case <-ch:
+		return true
+	default:
+		return false
+	}
+}
@@ -3,6 +3,8 @@ package filters
 import (
 	"context"
 	"errors"
+	"fmt"
+	"github.com/vitelabs/go-vite/chain/plugins"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/log15"
@@ -96,6 +98,9 @@ func (r *Range) toHeightRange() (*heightRange, error) {
 type RpcFilterParam struct {
 	AddrRange map[string]*Range `json:"addrRange"`
 	Topics    [][]types.Hash    `json:"topics"`
+
+	// only used by GetLogs, query logs confirmed by the snapshot blocks in the range through the vm log index plugin
+	SnapshotRange *Range `json:"snapshotRange"`
 }
 
 func (p *RpcFilterParam) toFilterParam() (*filterParam, error) {
@@ -125,9 +130,12 @@ func (p *RpcFilterParam) toFilterParam() (*filterParam, error) {
 	return target, nil
 }
 
+// Cursor is the snapshot height to resume the subscription from, the events after it won't be missed,
+// but some of the events before it may be notified again.
 type AccountBlock struct {
 	Hash    types.Hash `json:"hash"`
 	Removed bool       `json:"removed"`
+	Cursor  string     `json:"cursor,omitempty"`
 }
 
 type AccountBlockWithHeight struct {
@@ -135,6 +143,7 @@ type AccountBlockWithHeight struct {
 	Height    uint64     `json:"height"`
 	HeightStr string     `json:"heightStr"`
 	Removed   bool       `json:"removed"`
+	Cursor    string     `json:"cursor,omitempty"`
 }
 
 type SnapshotBlock struct {
@@ -142,6 +151,7 @@ type SnapshotBlock struct {
 	Height    uint64     `json:"height"`
 	HeightStr string     `json:"heightStr"`
 	Removed   bool       `json:"removed"`
+	Cursor    string     `json:"cursor,omitempty"`
 }
 
 type Logs struct {
@@ -149,13 +159,18 @@ type Logs struct {
 	AccountBlockHash types.Hash     `json:"accountBlockHash"`
 	Addr             *types.Address `json:"addr"`
 	Removed          bool           `json:"removed"`
+	Cursor           string         `json:"cursor,omitempty"`
 }
 
-func (s *SubscribeApi) NewSnapshotBlocksFilter() (rpc.ID, error) {
+func (s *SubscribeApi) NewSnapshotBlocksFilter(fromSnapshotHeight *string) (rpc.ID, error) {
 	s.log.Info("NewSnapshotBlocksFilter")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return "", err
+	}
 	var (
 		sbCh  = make(chan []*SnapshotBlock)
-		sbSub = s.eventSystem.SubscribeSnapshotBlocks(sbCh)
+		sbSub = s.eventSystem.SubscribeSnapshotBlocks(sbCh, from)
 	)
 
 	s.filterMapMu.Lock()
@@ -183,11 +198,15 @@ func (s *SubscribeApi) NewSnapshotBlocksFilter() (rpc.ID, error) {
 	return sbSub.ID, nil
 }
 
-func (s *SubscribeApi) NewAccountBlocksFilter() (rpc.ID, error) {
+func (s *SubscribeApi) NewAccountBlocksFilter(fromSnapshotHeight *string) (rpc.ID, error) {
 	s.log.Info("NewAccountBlocksFilter")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return "", err
+	}
 	var (
 		acCh  = make(chan []*AccountBlock)
-		acSub = s.eventSystem.SubscribeAccountBlocks(acCh)
+		acSub = s.eventSystem.SubscribeAccountBlocks(acCh, from)
 	)
 
 	s.filterMapMu.Lock()
@@ -215,11 +234,15 @@ func (s *SubscribeApi) NewAccountBlocksFilter() (rpc.ID, error) {
 	return acSub.ID, nil
 }
 
-func (s *SubscribeApi) NewAccountBlocksByAddrFilter(addr types.Address) (rpc.ID, error) {
+func (s *SubscribeApi) NewAccountBlocksByAddrFilter(addr types.Address, fromSnapshotHeight *string) (rpc.ID, error) {
 	s.log.Info("NewAccountBlocksByAddrFilter")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return "", err
+	}
 	var (
 		acCh  = make(chan []*AccountBlockWithHeight)
-		acSub = s.eventSystem.SubscribeAccountBlocksByAddr(addr, acCh)
+		acSub = s.eventSystem.SubscribeAccountBlocksByAddr(addr, acCh, from)
 	)
 
 	s.filterMapMu.Lock()
@@ -247,11 +270,15 @@ func (s *SubscribeApi) NewAccountBlocksByAddrFilter(addr types.Address) (rpc.ID,
 	return acSub.ID, nil
 }
 
-func (s *SubscribeApi) NewOnroadBlocksByAddrFilter(addr types.Address) (rpc.ID, error) {
+func (s *SubscribeApi) NewOnroadBlocksByAddrFilter(addr types.Address, fromSnapshotHeight *string) (rpc.ID, error) {
 	s.log.Info("NewOnroadBlocksByAddrFilter")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return "", err
+	}
 	var (
 		acCh  = make(chan []*AccountBlock)
-		acSub = s.eventSystem.SubscribeOnroadBlocksByAddr(addr, acCh)
+		acSub = s.eventSystem.SubscribeOnroadBlocksByAddr(addr, acCh, from)
 	)
 
 	s.filterMapMu.Lock()
@@ -279,15 +306,19 @@ func (s *SubscribeApi) NewOnroadBlocksByAddrFilter(addr types.Address) (rpc.ID,
 	return acSub.ID, nil
 }
 
-func (s *SubscribeApi) NewLogsFilter(param RpcFilterParam) (rpc.ID, error) {
+func (s *SubscribeApi) NewLogsFilter(param RpcFilterParam, fromSnapshotHeight *string) (rpc.ID, error) {
 	s.log.Info("NewLogsFilter")
 	p, err := param.toFilterParam()
 	if err != nil {
 		return "", err
 	}
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return "", err
+	}
 	var (
 		logsCh  = make(chan []*Logs)
-		logsSub = s.eventSystem.SubscribeLogs(p, logsCh)
+		logsSub = s.eventSystem.SubscribeLogs(p, logsCh, from)
 	)
 
 	s.filterMapMu.Lock()
@@ -315,6 +346,24 @@ func (s *SubscribeApi) NewLogsFilter(param RpcFilterParam) (rpc.ID, error) {
 	return logsSub.ID, nil
 }
 
+// parseFromSnapshotHeight returns 0 if fromSnapshotHeight is not set, the subscription receives the live events only
+func (s *SubscribeApi) parseFromSnapshotHeight(fromSnapshotHeight *string) (uint64, error) {
+	if fromSnapshotHeight == nil {
+		return 0, nil
+	}
+	from, err := api.StringToUint64(*fromSnapshotHeight)
+	if err != nil {
+		return 0, err
+	}
+	if from == 0 {
+		return 0, errors.New("fromSnapshotHeight should be higher than 0")
+	}
+	if err := s.eventSystem.checkReplayFrom(from); err != nil {
+		return 0, err
+	}
+	return from, nil
+}
+
 func (s *SubscribeApi) UninstallFilter(id rpc.ID) bool {
 	s.log.Info("UninstallFilter")
 	s.filterMapMu.Lock()
@@ -387,8 +436,13 @@ func (s *SubscribeApi) GetFilterChanges(id rpc.ID) (interface{}, error) {
 	return nil, errors.New("filter not found")
 }
 
-func (s *SubscribeApi) NewSnapshotBlocks(ctx context.Context) (*rpc.Subscription, error) {
+func (s *SubscribeApi) NewSnapshotBlocks(ctx context.Context, fromSnapshotHeight *string) (*rpc.Subscription, error) {
 	s.log.Info("NewSnapshotBlocks")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -397,7 +451,7 @@ func (s *SubscribeApi) NewSnapshotBlocks(ctx context.Context) (*rpc.Subscription
 
 	go func() {
 		snapshotBlockHashChan := make(chan []*SnapshotBlock, 128)
-		sbSub := s.eventSystem.SubscribeSnapshotBlocks(snapshotBlockHashChan)
+		sbSub := s.eventSystem.SubscribeSnapshotBlocks(snapshotBlockHashChan, from)
 		for {
 			select {
 			case h := <-snapshotBlockHashChan:
@@ -415,8 +469,13 @@ func (s *SubscribeApi) NewSnapshotBlocks(ctx context.Context) (*rpc.Subscription
 	return rpcSub, nil
 }
 
-func (s *SubscribeApi) NewAccountBlocks(ctx context.Context) (*rpc.Subscription, error) {
+func (s *SubscribeApi) NewAccountBlocks(ctx context.Context, fromSnapshotHeight *string) (*rpc.Subscription, error) {
 	s.log.Info("NewAccountBlocks")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -425,7 +484,7 @@ func (s *SubscribeApi) NewAccountBlocks(ctx context.Context) (*rpc.Subscription,
 
 	go func() {
 		accountBlockHashCh := make(chan []*AccountBlock, 128)
-		acSub := s.eventSystem.SubscribeAccountBlocks(accountBlockHashCh)
+		acSub := s.eventSystem.SubscribeAccountBlocks(accountBlockHashCh, from)
 		for {
 			select {
 			case h := <-accountBlockHashCh:
@@ -443,8 +502,13 @@ func (s *SubscribeApi) NewAccountBlocks(ctx context.Context) (*rpc.Subscription,
 	return rpcSub, nil
 }
 
-func (s *SubscribeApi) NewAccountBlocksByAddr(ctx context.Context, addr types.Address) (*rpc.Subscription, error) {
+func (s *SubscribeApi) NewAccountBlocksByAddr(ctx context.Context, addr types.Address, fromSnapshotHeight *string) (*rpc.Subscription, error) {
 	s.log.Info("NewAccountBlocksByAddr")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -453,7 +517,7 @@ func (s *SubscribeApi) NewAccountBlocksByAddr(ctx context.Context, addr types.Ad
 
 	go func() {
 		accountBlockCh := make(chan []*AccountBlockWithHeight, 128)
-		acSub := s.eventSystem.SubscribeAccountBlocksByAddr(addr, accountBlockCh)
+		acSub := s.eventSystem.SubscribeAccountBlocksByAddr(addr, accountBlockCh, from)
 		for {
 			select {
 			case h := <-accountBlockCh:
@@ -471,8 +535,13 @@ func (s *SubscribeApi) NewAccountBlocksByAddr(ctx context.Context, addr types.Ad
 	return rpcSub, nil
 }
 
-func (s *SubscribeApi) NewOnroadBlocksByAddr(ctx context.Context, addr types.Address) (*rpc.Subscription, error) {
+func (s *SubscribeApi) NewOnroadBlocksByAddr(ctx context.Context, addr types.Address, fromSnapshotHeight *string) (*rpc.Subscription, error) {
 	s.log.Info("NewOnroadBlocks")
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
+
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
 		return &rpc.Subscription{}, rpc.ErrNotificationsUnsupported
@@ -481,7 +550,7 @@ func (s *SubscribeApi) NewOnroadBlocksByAddr(ctx context.Context, addr types.Add
 
 	go func() {
 		accountBlockHashCh := make(chan []*AccountBlock, 128)
-		acSub := s.eventSystem.SubscribeOnroadBlocksByAddr(addr, accountBlockHashCh)
+		acSub := s.eventSystem.SubscribeOnroadBlocksByAddr(addr, accountBlockHashCh, from)
 		for {
 			select {
 			case h := <-accountBlockHashCh:
@@ -499,12 +568,16 @@ func (s *SubscribeApi) NewOnroadBlocksByAddr(ctx context.Context, addr types.Add
 	return rpcSub, nil
 }
 
-func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.Subscription, error) {
+func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam, fromSnapshotHeight *string) (*rpc.Subscription, error) {
 	s.log.Info("NewLogs")
 	p, err := param.toFilterParam()
 	if err != nil {
 		return nil, err
 	}
+	from, err := s.parseFromSnapshotHeight(fromSnapshotHeight)
+	if err != nil {
+		return nil, err
+	}
 
 	notifier, supported := rpc.NotifierFromContext(ctx)
 	if !supported {
@@ -514,7 +587,7 @@ func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.
 
 	go func() {
 		logsMsg := make(chan []*Logs, 128)
-		sub := s.eventSystem.SubscribeLogs(p, logsMsg)
+		sub := s.eventSystem.SubscribeLogs(p, logsMsg, from)
 
 		for {
 			select {
@@ -534,7 +607,26 @@ func (s *SubscribeApi) NewLogs(ctx context.Context, param RpcFilterParam) (*rpc.
 
 var getAccountBlocksCount uint64 = 100
 
-func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*Logs, error) {
+// limits of GetLogs by snapshot range, an error is returned if they are exceeded, the query should be narrowed then
+var (
+	getLogsMaxSnapshotRange uint64 = 10000
+	getLogsMaxCount                = 10000
+)
+
+// GetLogs stops when ctx is canceled by the timeout of rpc server
+func (s *SubscribeApi) GetLogs(ctx context.Context, param RpcFilterParam) ([]*Logs, error) {
+	if param.SnapshotRange != nil {
+		// topic-only query is allowed
+		filterParam := &filterParam{topics: param.Topics}
+		if len(param.AddrRange) > 0 {
+			var err error
+			if filterParam, err = param.toFilterParam(); err != nil {
+				return nil, err
+			}
+		}
+		return s.getLogsBySnapshotRange(ctx, filterParam, param.SnapshotRange)
+	}
+
 	filterParam, err := param.toFilterParam()
 	if err != nil {
 		return nil, err
@@ -554,6 +646,9 @@ func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*Logs, error) {
 			endHeight = acc.Height
 		}
 		for {
+			if err := ctx.Err(); err != nil {
+				return nil, err
+			}
 			end, count, finish := getHeightPage(startHeight, endHeight, getAccountBlocksCount)
 			if count == 0 {
 				break
@@ -570,7 +665,7 @@ func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*Logs, error) {
 					}
 					for _, l := range list {
 						if filterLog(filterParam, l) {
-							logs = append(logs, &Logs{l, b.Hash, &addr, false})
+							logs = append(logs, &Logs{l, b.Hash, &addr, false, ""})
 						}
 					}
 				}
@@ -584,6 +679,70 @@ func (s *SubscribeApi) GetLogs(param RpcFilterParam) ([]*Logs, error) {
 	return logs, nil
 }
 
+func (s *SubscribeApi) getLogsBySnapshotRange(ctx context.Context, filterParam *filterParam, snapshotRange *Range) ([]*Logs, error) {
+	hr, err := snapshotRange.toHeightRange()
+	if err != nil {
+		return nil, err
+	}
+	latestHeight := s.vite.Chain().GetLatestSnapshotBlock().Height
+	if hr.toHeight == 0 || hr.toHeight > latestHeight {
+		hr.toHeight = latestHeight
+	}
+	if hr.fromHeight > hr.toHeight {
+		return nil, nil
+	}
+	if hr.toHeight-hr.fromHeight >= getLogsMaxSnapshotRange {
+		return nil, errors.New(fmt.Sprintf("snapshot range %d-%d is too large, at most %d snapshot blocks can be queried",
+			hr.fromHeight, hr.toHeight, getLogsMaxSnapshotRange))
+	}
+
+	plugins := s.vite.Chain().Plugins()
+	if plugins == nil {
+		return nil, errors.New("config.OpenPlugins is false, api can't work")
+	}
+	plugin := plugins.GetPlugin("vmLogIndex").(*chain_plugins.VmLogIndex)
+
+	addrList := make([]types.Address, 0, len(filterParam.addrRange))
+	for addr := range filterParam.addrRange {
+		addrList = append(addrList, addr)
+	}
+
+	// every indexed block has logs, so the blocks are limited as the logs
+	blocks, err := plugin.GetBlocks(ctx, addrList, filterParam.topics, hr.fromHeight, hr.toHeight, getLogsMaxCount)
+	if err != nil {
+		return nil, err
+	}
+
+	var logs []*Logs
+	for _, b := range blocks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if filterParam.addrRange != nil {
+			if ar, ok := filterParam.addrRange[b.AccountAddress]; !ok {
+				continue
+			} else if (ar.fromHeight > 0 && ar.fromHeight > b.Height) || (ar.toHeight > 0 && ar.toHeight < b.Height) {
+				continue
+			}
+		}
+
+		list, err := s.vite.Chain().GetVmLogList(b.LogHash)
+		if err != nil {
+			return nil, err
+		}
+		addr := b.AccountAddress
+		for _, l := range list {
+			if filterLog(filterParam, l) {
+				logs = append(logs, &Logs{l, b.Hash, &addr, false, ""})
+			}
+		}
+		if len(logs) > getLogsMaxCount {
+			return nil, errors.New(fmt.Sprintf("more than %d logs are matched, please narrow the query", getLogsMaxCount))
+		}
+	}
+	return logs, nil
+}
+
 func getHeightPage(start uint64, end uint64, count uint64) (uint64, uint64, bool) {
 	if end < count || end-count <= start {
 		return end, end - start, true
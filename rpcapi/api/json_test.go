@@ -19,19 +19,18 @@ func TestJson(t *testing.T) {
 	m := make(map[types.TokenTypeId]*RpcTokenBalanceInfo)
 	id := types.CreateTokenTypeId([]byte{1, 3, 4})
 	totalSupply := "10000"
-	pledgeAmount := "10000"
+	maxSupply := "10000"
 	number := "10000"
 	addresses, _, _ := types.CreateAddress()
 
 	m[id] = &RpcTokenBalanceInfo{
 		TokenInfo: &RpcTokenInfo{
-			TokenName:      "as",
-			TokenSymbol:    "aa",
-			TotalSupply:    &totalSupply,
-			Decimals:       19,
-			Owner:          addresses,
-			PledgeAmount:   &pledgeAmount,
-			WithdrawHeight: "12",
+			TokenName:   "as",
+			TokenSymbol: "aa",
+			TotalSupply: &totalSupply,
+			Decimals:    19,
+			Owner:       addresses,
+			MaxSupply:   &maxSupply,
 		},
 		TotalAmount: "132",
 		Number:      &number,
@@ -1,6 +1,8 @@
 package api
 
 import (
+	"context"
+	"encoding/hex"
 	"fmt"
 	"github.com/pkg/errors"
 	"github.com/vitelabs/go-vite/chain"
@@ -13,6 +15,9 @@ import (
 	"strconv"
 )
 
+// the maximum count of the account blocks returned by GetTransactionsByAddress
+const getTransactionsMaxCount = 1000
+
 func NewLedgerApi(vite *vite.Vite) *LedgerApi {
 	api := &LedgerApi{
 		chain: vite.Chain(),
@@ -175,6 +180,42 @@ func (l *LedgerApi) GetBlocksByHashInToken(addr types.Address, originBlockHash *
 	return l.ledgerBlocksToRpcBlocks(blocks)
 }
 
+// the account blocks touching addr, which are confirmed by the snapshot blocks between startHeight and endHeight, high to low
+func (l *LedgerApi) GetTransactionsByAddress(addr types.Address, startHeight interface{}, endHeight interface{}, index int, count int) ([]*AccountBlock, error) {
+	if index < 0 || count <= 0 {
+		return nil, nil
+	}
+	if count > getTransactionsMaxCount {
+		return nil, errors.New(fmt.Sprintf("maximum number per page allowed is %d", getTransactionsMaxCount))
+	}
+
+	startHeightUint64, err := parseHeight(startHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	endHeightUint64, err := parseHeight(endHeight)
+	if err != nil {
+		return nil, err
+	}
+
+	plugins := l.chain.Plugins()
+	if plugins == nil {
+		err := errors.New("config.OpenPlugins is false, api can't work")
+		return nil, err
+	}
+
+	plugin := plugins.GetPlugin("addressTx").(*chain_plugins.AddressTx)
+
+	blocks, err := plugin.GetBlocks(addr, startHeightUint64, endHeightUint64, uint64(index), uint64(count))
+	if err != nil {
+		l.log.Error("GetBlocks failed, error is "+err.Error(), "method", "GetTransactionsByAddress")
+		return nil, err
+	}
+
+	return l.ledgerBlocksToRpcBlocks(blocks)
+}
+
 func (l *LedgerApi) GetVmLogListByHash(logHash types.Hash) (ledger.VmLogList, error) {
 	logList, err := l.chain.GetVmLogList(&logHash)
 	if err != nil {
@@ -327,7 +368,10 @@ func (l *LedgerApi) GetSnapshotBlocks(height interface{}, count int) ([]*Snapsho
 	return l.ledgerSnapshotBlocksToRpcBlocks(blocks)
 }
 
-func (l *LedgerApi) GetChunks(startHeight interface{}, endHeight interface{}) ([]*SnapshotChunk, error) {
+var getChunksPageSize uint64 = 100
+
+// GetChunks reads the chunks page by page, and stops when ctx is canceled by the timeout of rpc server
+func (l *LedgerApi) GetChunks(ctx context.Context, startHeight interface{}, endHeight interface{}) ([]*SnapshotChunk, error) {
 	startHeightUint64, err := parseHeight(startHeight)
 	if err != nil {
 		return nil, err
@@ -337,15 +381,29 @@ func (l *LedgerApi) GetChunks(startHeight interface{}, endHeight interface{}) ([
 	if err != nil {
 		return nil, err
 	}
-
-	chunks, err := l.chain.GetSubLedger(startHeightUint64-1, endHeightUint64)
-	if err != nil {
-		return nil, err
+	if latestHeight := l.chain.GetLatestSnapshotBlock().Height; endHeightUint64 > latestHeight {
+		endHeightUint64 = latestHeight
 	}
-	if len(chunks) > 0 {
-		if chunks[0].SnapshotBlock == nil || chunks[0].SnapshotBlock.Height == startHeightUint64-1 {
-			chunks = chunks[1:]
+
+	var chunks []*ledger.SnapshotChunk
+	for pageStart := startHeightUint64; pageStart <= endHeightUint64; pageStart += getChunksPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
+		pageEnd := endHeightUint64
+		if endHeightUint64-pageStart >= getChunksPageSize {
+			pageEnd = pageStart + getChunksPageSize - 1
+		}
+		page, err := l.chain.GetSubLedger(pageStart-1, pageEnd)
+		if err != nil {
+			return nil, err
+		}
+		if len(page) > 0 {
+			if page[0].SnapshotBlock == nil || page[0].SnapshotBlock.Height == pageStart-1 {
+				page = page[1:]
+			}
+		}
+		chunks = append(chunks, page...)
 	}
 
 	return l.ledgerChunksToRpcChunks(chunks)
@@ -409,6 +467,168 @@ func (l *LedgerApi) GetUnconfirmedBlocks(addr types.Address) []*ledger.AccountBl
 	return l.chain.GetUnconfirmedBlocks(addr)
 }
 
+func (l *LedgerApi) GetBalanceBySnapshot(addr types.Address, tokenTypeId types.TokenTypeId, snapshot interface{}) (string, error) {
+	sb, err := parseSnapshotHeader(l.chain, snapshot)
+	if err != nil {
+		return "", err
+	}
+
+	balance, err := l.chain.GetSnapshotBalance(sb.Height, addr, tokenTypeId)
+	if err != nil {
+		l.log.Error("GetSnapshotBalance failed, error is "+err.Error(), "method", "GetBalanceBySnapshot")
+		return "", err
+	}
+	return balance.String(), nil
+}
+
+// GetBalancesBySnapshot stops when ctx is canceled by the timeout of rpc server
+func (l *LedgerApi) GetBalancesBySnapshot(ctx context.Context, addrList []types.Address, tokenTypeId types.TokenTypeId, snapshot interface{}) (map[types.Address]string, error) {
+	sb, err := parseSnapshotHeader(l.chain, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	balances := make(map[types.Address]string, len(addrList))
+	for _, addr := range addrList {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		balance, err := l.chain.GetSnapshotBalance(sb.Height, addr, tokenTypeId)
+		if err != nil {
+			l.log.Error("GetSnapshotBalance failed, error is "+err.Error(), "method", "GetBalancesBySnapshot")
+			return nil, err
+		}
+		balances[addr] = balance.String()
+	}
+	return balances, nil
+}
+
+func (l *LedgerApi) GetAccountBySnapshot(addr types.Address, snapshot interface{}) (*RpcSnapshotAccountInfo, error) {
+	sb, err := parseSnapshotHeader(l.chain, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	balanceMap, err := l.chain.GetSnapshotBalanceMap(sb.Height, addr)
+	if err != nil {
+		l.log.Error("GetSnapshotBalanceMap failed, error is "+err.Error(), "method", "GetAccountBySnapshot")
+		return nil, err
+	}
+
+	tokenBalanceInfoMap := make(map[types.TokenTypeId]*RpcTokenBalanceInfo)
+	for tokenId, amount := range balanceMap {
+		token, _ := l.chain.GetTokenInfoById(tokenId)
+		if token == nil {
+			continue
+		}
+		tokenBalanceInfoMap[tokenId] = &RpcTokenBalanceInfo{
+			TokenInfo:   RawTokenInfoToRpc(token, tokenId),
+			TotalAmount: amount.String(),
+			Number:      nil,
+		}
+	}
+
+	return &RpcSnapshotAccountInfo{
+		AccountAddress:      addr,
+		SnapshotHeight:      strconv.FormatUint(sb.Height, 10),
+		SnapshotHash:        sb.Hash,
+		TokenBalanceInfoMap: tokenBalanceInfoMap,
+	}, nil
+}
+
+// GetStorageBySnapshot stops when ctx is canceled by the timeout of rpc server
+func (l *LedgerApi) GetStorageBySnapshot(ctx context.Context, addr types.Address, prefix string, snapshot interface{}) (map[string]string, error) {
+	sb, err := parseSnapshotHeader(l.chain, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	var prefixBytes []byte
+	if len(prefix) > 0 {
+		prefixBytes, err = hex.DecodeString(prefix)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	iter, err := l.chain.GetSnapshotStorageIterator(sb.Height, addr, prefixBytes)
+	if err != nil {
+		l.log.Error("GetSnapshotStorageIterator failed, error is "+err.Error(), "method", "GetStorageBySnapshot")
+		return nil, err
+	}
+	defer iter.Release()
+
+	m := make(map[string]string)
+	for iter.Next() {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if len(iter.Key()) > 0 && len(iter.Value()) > 0 {
+			m["0x"+hex.EncodeToString(iter.Key())] = "0x" + hex.EncodeToString(iter.Value())
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (l *LedgerApi) GetContractMetaBySnapshot(addr types.Address, snapshot interface{}) (*ledger.ContractMeta, error) {
+	sb, err := parseSnapshotHeader(l.chain, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := l.chain.GetContractMetaInSnapshot(addr, sb.Height)
+	if err != nil {
+		l.log.Error("GetContractMetaInSnapshot failed, error is "+err.Error(), "method", "GetContractMetaBySnapshot")
+		return nil, err
+	}
+	return meta, nil
+}
+
+func (l *LedgerApi) GetStateChangesBySnapshot(snapshot interface{}) (*RpcSnapshotStateChanges, error) {
+	sb, err := parseSnapshotHeader(l.chain, snapshot)
+	if err != nil {
+		return nil, err
+	}
+
+	snapshotLog, err := l.chain.GetSnapshotStateLog(sb.Height)
+	if err != nil {
+		l.log.Error("GetSnapshotStateLog failed, error is "+err.Error(), "method", "GetStateChangesBySnapshot")
+		return nil, err
+	}
+
+	return snapshotLogToRpc(sb, snapshotLog), nil
+}
+
+// snapshot can be a snapshot height or a snapshot hash
+func parseSnapshotHeader(c chain.Chain, snapshot interface{}) (*ledger.SnapshotBlock, error) {
+	var sb *ledger.SnapshotBlock
+	if hashStr, ok := snapshot.(string); ok && len(hashStr) == 2*types.HashSize {
+		hash, err := types.HexToHash(hashStr)
+		if err != nil {
+			return nil, err
+		}
+		if sb, err = c.GetSnapshotHeaderByHash(hash); err != nil {
+			return nil, err
+		}
+	} else {
+		height, err := parseHeight(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		if sb, err = c.GetSnapshotHeaderByHeight(height); err != nil {
+			return nil, err
+		}
+	}
+
+	if sb == nil {
+		return nil, errors.New(fmt.Sprintf("snapshot block %v is not existed", snapshot))
+	}
+	return sb, nil
+}
+
 func parseHeight(height interface{}) (uint64, error) {
 	var heightUint64 uint64
 	switch height.(type) {
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"encoding/hex"
 	"errors"
 	"math/big"
 	"strconv"
 
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/state"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 )
@@ -320,6 +322,53 @@ type RpcTokenBalanceInfo struct {
 	Number      *string       `json:"number,omitempty"` // uint64
 }
 
+type RpcSnapshotAccountInfo struct {
+	AccountAddress      types.Address                              `json:"accountAddress"`
+	SnapshotHeight      string                                     `json:"snapshotHeight"` // uint64
+	SnapshotHash        types.Hash                                 `json:"snapshotHash"`
+	TokenBalanceInfoMap map[types.TokenTypeId]*RpcTokenBalanceInfo `json:"tokenBalanceInfoMap,omitempty"`
+}
+
+type RpcSnapshotStateChanges struct {
+	SnapshotHeight string                                   `json:"snapshotHeight"` // uint64
+	SnapshotHash   types.Hash                               `json:"snapshotHash"`
+	Accounts       map[types.Address]*RpcAccountStateChange `json:"accounts"`
+}
+
+type RpcAccountStateChange struct {
+	Heights    []string                     `json:"heights"`              // uint64, account block heights confirmed by the snapshot block
+	BalanceMap map[types.TokenTypeId]string `json:"balanceMap,omitempty"` // big int
+	Storage    map[string]string            `json:"storage,omitempty"`
+}
+
+func snapshotLogToRpc(sb *ledger.SnapshotBlock, snapshotLog chain_state.SnapshotLog) *RpcSnapshotStateChanges {
+	changes := &RpcSnapshotStateChanges{
+		SnapshotHeight: strconv.FormatUint(sb.Height, 10),
+		SnapshotHash:   sb.Hash,
+		Accounts:       make(map[types.Address]*RpcAccountStateChange, len(snapshotLog)),
+	}
+
+	for addr, logList := range snapshotLog {
+		change := &RpcAccountStateChange{
+			Heights:    make([]string, 0, len(logList)),
+			BalanceMap: make(map[types.TokenTypeId]string),
+			Storage:    make(map[string]string),
+		}
+		// later log items overwrite earlier ones
+		for _, item := range logList {
+			change.Heights = append(change.Heights, strconv.FormatUint(item.Height, 10))
+			for tokenId, balance := range item.BalanceMap {
+				change.BalanceMap[tokenId] = balance.String()
+			}
+			for _, kv := range item.Storage {
+				change.Storage["0x"+hex.EncodeToString(kv[0])] = "0x" + hex.EncodeToString(kv[1])
+			}
+		}
+		changes.Accounts[addr] = change
+	}
+	return changes
+}
+
 type RpcTokenInfo struct {
 	TokenName     string            `json:"tokenName"`
 	TokenSymbol   string            `json:"tokenSymbol"`
@@ -0,0 +1,78 @@
+package api
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite/net"
+)
+
+var errLightSnapshot = errors.New("only the latest snapshot is supported in light mode")
+
+// LightLedgerApi answer the subset of ledger apis can be answered by the light client
+type LightLedgerApi struct {
+	client *net.LightClient
+	log    log15.Logger
+}
+
+func NewLightLedgerApi(client *net.LightClient) *LightLedgerApi {
+	return &LightLedgerApi{
+		client: client,
+		log:    log15.New("module", "rpc_api/light_ledger_api"),
+	}
+}
+
+func (l LightLedgerApi) String() string {
+	return "LightLedgerApi"
+}
+
+func (l *LightLedgerApi) GetSnapshotChainHeight() string {
+	return strconv.FormatUint(l.client.Latest().Height, 10)
+}
+
+func (l *LightLedgerApi) GetLatestSnapshotChainHash() *types.Hash {
+	return &l.client.Latest().Hash
+}
+
+// GetSnapshotBlockByHeight return nil if the header is too old to be kept
+func (l *LightLedgerApi) GetSnapshotBlockByHeight(height interface{}) (*SnapshotBlock, error) {
+	heightUint64, err := parseHeight(height)
+	if err != nil {
+		return nil, err
+	}
+
+	return ledgerSnapshotBlockToRpcBlock(l.client.GetSnapshotBlockByHeight(heightUint64))
+}
+
+func (l *LightLedgerApi) GetSnapshotBlockByHash(hash types.Hash) (*SnapshotBlock, error) {
+	return ledgerSnapshotBlockToRpcBlock(l.client.GetSnapshotBlockByHash(hash))
+}
+
+func (l *LightLedgerApi) GetRawBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error) {
+	block, err := l.client.GetAccountBlockByHash(blockHash)
+	if err != nil {
+		l.log.Error("GetAccountBlockByHash failed, error is "+err.Error(), "method", "GetRawBlockByHash")
+		return nil, err
+	}
+	return block, nil
+}
+
+// GetBalanceBySnapshot only support the balance of the latest confirmed head, snapshot should be empty.
+// The balance is not proved, it is trusted if a quorum of light servers respond the same.
+func (l *LightLedgerApi) GetBalanceBySnapshot(addr types.Address, tokenTypeId types.TokenTypeId, snapshot interface{}) (string, error) {
+	if snapshot != nil {
+		if hashStr, ok := snapshot.(string); !ok || hashStr != l.client.Latest().Hash.String() {
+			return "", errLightSnapshot
+		}
+	}
+
+	balance, _, err := l.client.GetQuorumBalance(addr, tokenTypeId)
+	if err != nil {
+		l.log.Error("GetQuorumBalance failed, error is "+err.Error(), "method", "GetBalanceBySnapshot")
+		return "", err
+	}
+	return balance.String(), nil
+}
@@ -0,0 +1,119 @@
+package api
+
+import (
+	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+)
+
+type MultiSigApi struct {
+	chain chain.Chain
+	log   log15.Logger
+}
+
+func NewMultiSigApi(vite *vite.Vite) *MultiSigApi {
+	return &MultiSigApi{
+		chain: vite.Chain(),
+		log:   log15.New("module", "rpc_api/multisig_api"),
+	}
+}
+
+func (m MultiSigApi) String() string {
+	return "MultiSigApi"
+}
+
+type CreateMultiSigWalletParam struct {
+	Owners    []types.Address `json:"owners"`
+	Threshold uint8           `json:"threshold"`
+}
+
+// GetCreateWalletData returns the data of creating a wallet, the hash of send block is the wallet id
+func (m *MultiSigApi) GetCreateWalletData(param CreateMultiSigWalletParam) ([]byte, error) {
+	return abi.ABIMultiSig.PackMethod(abi.MethodNameCreateMultiSigWallet, param.Owners, param.Threshold)
+}
+
+func (m *MultiSigApi) GetDepositData(walletId types.Hash) ([]byte, error) {
+	return abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigDeposit, walletId)
+}
+
+type MultiSigProposeParam struct {
+	WalletId types.Hash        `json:"walletId"`
+	To       types.Address     `json:"to"`
+	TokenId  types.TokenTypeId `json:"tokenId"`
+	Amount   string            `json:"amount"`
+}
+
+// GetProposeData returns the data of proposing a transfer, the hash of send block is the proposal id
+func (m *MultiSigApi) GetProposeData(param MultiSigProposeParam) ([]byte, error) {
+	if bAmount, err := stringToBigInt(&param.Amount); err == nil {
+		return abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigPropose, param.WalletId, param.To, param.TokenId, bAmount)
+	} else {
+		return nil, err
+	}
+}
+
+func (m *MultiSigApi) GetApproveData(walletId types.Hash, proposalId types.Hash) ([]byte, error) {
+	return abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigApprove, walletId, proposalId)
+}
+
+// GetCancelProposalData returns the data of cancelling a proposal, only the proposer can cancel it before expiration
+func (m *MultiSigApi) GetCancelProposalData(walletId types.Hash, proposalId types.Hash) ([]byte, error) {
+	return abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigCancel, walletId, proposalId)
+}
+
+type MultiSigWalletInfo struct {
+	WalletId   types.Hash                   `json:"walletId"`
+	Owners     []types.Address              `json:"owners"`
+	Threshold  uint8                        `json:"threshold"`
+	BalanceMap map[types.TokenTypeId]string `json:"balanceMap"`
+}
+
+func (m *MultiSigApi) GetWallet(walletId types.Hash) (*MultiSigWalletInfo, error) {
+	db, err := getVmDb(m.chain, types.AddressMultiSig)
+	if err != nil {
+		return nil, err
+	}
+	wallet, err := abi.GetMultiSigWallet(db, walletId)
+	if err != nil || wallet == nil {
+		return nil, err
+	}
+	balanceMap, err := abi.GetMultiSigBalanceMap(db, walletId)
+	if err != nil {
+		return nil, err
+	}
+	info := &MultiSigWalletInfo{walletId, wallet.Owners, wallet.Threshold, make(map[types.TokenTypeId]string, len(balanceMap))}
+	for tokenId, amount := range balanceMap {
+		info.BalanceMap[tokenId] = *bigIntToString(amount)
+	}
+	return info, nil
+}
+
+type MultiSigProposalInfo struct {
+	ProposalId       types.Hash        `json:"proposalId"`
+	To               types.Address     `json:"to"`
+	TokenId          types.TokenTypeId `json:"tokenId"`
+	Amount           string            `json:"amount"`
+	Approvals        []types.Address   `json:"approvals"`
+	Proposer         types.Address     `json:"proposer"`
+	ExpirationHeight string            `json:"expirationHeight"`
+}
+
+// GetProposalList returns the proposals waiting for approvals, executed and cancelled proposals are deleted.
+// Proposals are not approvable after expirationHeight, but stay in the list until cancelled.
+func (m *MultiSigApi) GetProposalList(walletId types.Hash) ([]*MultiSigProposalInfo, error) {
+	db, err := getVmDb(m.chain, types.AddressMultiSig)
+	if err != nil {
+		return nil, err
+	}
+	proposalList, err := abi.GetMultiSigProposalList(db, walletId)
+	if err != nil {
+		return nil, err
+	}
+	infoList := make([]*MultiSigProposalInfo, len(proposalList))
+	for i, p := range proposalList {
+		infoList[i] = &MultiSigProposalInfo{p.Id, p.To, p.TokenId, *bigIntToString(p.Amount), p.Approvals, p.Proposer, Uint64ToString(p.ExpirationHeight)}
+	}
+	return infoList, nil
+}
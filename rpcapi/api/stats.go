@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"errors"
 	"time"
 
@@ -121,7 +122,10 @@ func (c StatsApi) GetPeriodSBPStats(startIdx uint64, endIdx uint64) ([]*PeriodSt
 	return result, nil
 }
 
-func (c StatsApi) GetDaySBPStats(startIdx uint64, endIdx uint64) ([]map[string]interface{}, error) {
+var getDayStatsPageSize uint64 = 10
+
+// GetDaySBPStats reads the stats page by page, and stops when ctx is canceled by the timeout of rpc server
+func (c StatsApi) GetDaySBPStats(ctx context.Context, startIdx uint64, endIdx uint64) ([]map[string]interface{}, error) {
 	var result []map[string]interface{}
 	reader := c.cs.SBPReader()
 	timeIndex := reader.GetDayTimeIndex()
@@ -129,20 +133,32 @@ func (c StatsApi) GetDaySBPStats(startIdx uint64, endIdx uint64) ([]map[string]i
 		startIdx, endIdx = c.reIndex(timeIndex)
 	}
 	// day
-	stats, err := reader.DayStats(startIdx, endIdx)
-	if err != nil {
-		return nil, err
-	}
-
-	for _, v := range stats {
-		r := make(map[string]interface{})
-		stime, etime := timeIndex.Index2Time(v.Index)
-
-		r["stime"] = stime.String()
-		r["etime"] = etime.String()
-		r["stat"] = v
-
-		result = append(result, r)
+	for pageStart := startIdx; pageStart <= endIdx; pageStart += getDayStatsPageSize {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		pageEnd := endIdx
+		if endIdx-pageStart >= getDayStatsPageSize {
+			pageEnd = pageStart + getDayStatsPageSize - 1
+		}
+		stats, err := reader.DayStats(pageStart, pageEnd)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, v := range stats {
+			r := make(map[string]interface{})
+			stime, etime := timeIndex.Index2Time(v.Index)
+
+			r["stime"] = stime.String()
+			r["etime"] = etime.String()
+			r["stat"] = v
+
+			result = append(result, r)
+		}
+		if pageEnd == endIdx {
+			break
+		}
 	}
 	return result, nil
 }
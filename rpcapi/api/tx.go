@@ -251,6 +251,137 @@ func (t Tx) CalcPoWDifficulty(param CalcPoWDifficultyParam) (result *CalcPoWDiff
 	return &CalcPoWDifficultyResult{quotaRequired, d.String()}, nil
 }
 
+type EstimateQuotaParam struct {
+	SelfAddr     types.Address      `json:"selfAddr"`
+	BlockType    byte               `json:"blockType"`
+	ToAddr       *types.Address     `json:"toAddr"`
+	TokenId      *types.TokenTypeId `json:"tokenId"`
+	Amount       *string            `json:"amount"`
+	Data         []byte             `json:"data"`
+	SnapshotHash *types.Hash        `json:"snapshotHash,omitempty"`
+}
+
+type EstimateQuotaResult struct {
+	SendQuota    string  `json:"sendQuota"`              // uint64
+	ReceiveQuota *string `json:"receiveQuota,omitempty"` // uint64, quota used by the receive of a user contract
+	ReceiveError string  `json:"receiveError,omitempty"`
+	CurrentQuota string  `json:"currentQuota"` // uint64, quota available from pledge
+	Difficulty   string  `json:"difficulty"`   // PoW difficulty to cover the shortfall of the send quota, empty if the current quota is enough
+	CanPoW       bool    `json:"canPoW"`
+}
+
+// EstimateQuota returns the quota required by a send block, the quota used by the receive of a user contract
+// which is estimated by simulating the receive, the current pledge quota and the PoW difficulty needed
+func (t Tx) EstimateQuota(param EstimateQuotaParam) (*EstimateQuotaResult, error) {
+	c := t.vite.Chain()
+
+	blockType := param.BlockType
+	if blockType == 0 {
+		blockType = ledger.BlockTypeSendCall
+	}
+	if blockType != ledger.BlockTypeSendCall && blockType != ledger.BlockTypeSendCreate {
+		return nil, util.ErrBlockTypeNotSupported
+	}
+	if param.ToAddr == nil && blockType == ledger.BlockTypeSendCall {
+		return nil, errors.New("toAddr is nil")
+	}
+
+	sc, err := newSimulateChain(c, param.SnapshotHash)
+	if err != nil {
+		return nil, err
+	}
+
+	// the prev block, pledge and quota are read at the same snapshot block as the state
+	prevBlock, err := sc.GetLatestAccountBlock(param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	block := &ledger.AccountBlock{
+		BlockType:      blockType,
+		AccountAddress: param.SelfAddr,
+		Amount:         big.NewInt(0),
+		Fee:            big.NewInt(0),
+		Data:           param.Data,
+	}
+	if prevBlock != nil {
+		block.PrevHash = prevBlock.Hash
+		block.Height = prevBlock.Height + 1
+	} else {
+		block.Height = 1
+	}
+	if param.ToAddr != nil {
+		block.ToAddress = *param.ToAddr
+	}
+	if param.TokenId != nil {
+		block.TokenId = *param.TokenId
+	}
+	if param.Amount != nil {
+		amount, ok := new(big.Int).SetString(*param.Amount, 10)
+		if !ok {
+			return nil, ErrStrToBigInt
+		}
+		block.Amount = amount
+	}
+	block.Hash = block.ComputeHash()
+
+	db, err := vm_db.NewVmDb(sc, &param.SelfAddr, &sc.sb.Hash, &block.PrevHash)
+	if err != nil {
+		return nil, err
+	}
+	sendQuota, err := vm.GasRequiredForBlock(db, block)
+	if err != nil {
+		return nil, err
+	}
+
+	pledgeAmount, err := sc.GetPledgeBeneficialAmount(param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	q, err := quota.GetPledgeQuota(db, param.SelfAddr, pledgeAmount)
+	if err != nil {
+		return nil, err
+	}
+	canPoW, err := quota.CanPoW(db, param.SelfAddr)
+	if err != nil {
+		return nil, err
+	}
+	if err := sc.err(); err != nil {
+		return nil, err
+	}
+
+	result := &EstimateQuotaResult{
+		SendQuota:    Uint64ToString(sendQuota),
+		CurrentQuota: Uint64ToString(q.Current()),
+		CanPoW:       canPoW,
+	}
+	if q.Current() < sendQuota {
+		d, err := quota.CalcPoWDifficulty(sendQuota, q)
+		if err != nil {
+			return nil, err
+		}
+		result.Difficulty = d.String()
+	}
+
+	// the receive quota of built-in contracts is not paid by anyone
+	if blockType == ledger.BlockTypeSendCall && types.IsContractAddr(block.ToAddress) && !types.IsBuiltinContractAddr(block.ToAddress) {
+		genResult, err := simulateReceive(sc, t.vite.Consensus(), block)
+		if err != nil {
+			return nil, err
+		}
+		if err := sc.err(); err != nil {
+			return nil, err
+		}
+		if genResult.Err != nil {
+			result.ReceiveError = genResult.Err.Error()
+		}
+		if genResult.VMBlock != nil {
+			receiveQuota := Uint64ToString(genResult.VMBlock.AccountBlock.QuotaUsed)
+			result.ReceiveQuota = &receiveQuota
+		}
+	}
+	return result, nil
+}
+
 func (tx Tx) autoSend() {
 	if !tx.autoTx {
 		return
@@ -93,7 +93,7 @@ func (v *VmDebugApi) NewAccount() (*AccountInfo, error) {
 		return nil, err
 	}
 	// unlock user account
-	err = v.wallet.Unlock(response.Filename, defaultPassphrase)
+	err = v.wallet.Unlock(response.Filename, defaultPassphrase, nil, nil)
 	if err != nil {
 		return nil, err
 	}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"time"
 
 	"github.com/vitelabs/go-vite/chain"
 	"github.com/vitelabs/go-vite/common/types"
@@ -14,6 +15,7 @@ import (
 	"github.com/vitelabs/go-vite/vite"
 	"github.com/vitelabs/go-vite/wallet"
 	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/keystore"
 )
 
 type HexSignedTuple struct {
@@ -50,6 +52,21 @@ type CreateTransferTxParms struct {
 	Difficulty       *string           `json:"difficulty,omitempty"`
 }
 
+type CreateTxParms struct {
+	SelfAddr    types.Address     `json:"selfAddr"`
+	ToAddr      types.Address     `json:"toAddr"`
+	TokenTypeId types.TokenTypeId `json:"tokenTypeId"`
+	Amount      string            `json:"amount"`
+	Data        []byte            `json:"data,omitempty"`
+	Difficulty  *string           `json:"difficulty,omitempty"`
+}
+
+type KeyStoreInfo struct {
+	Address  types.Address `json:"address"`
+	Filename string        `json:"filename"`
+	Unlocked bool          `json:"unlocked"`
+}
+
 type IsMayValidKeystoreFileResponse struct {
 	Maybe      bool
 	MayAddress types.Address
@@ -197,16 +214,31 @@ func (m WalletApi) ExtractMnemonic(entropyStore string, passphrase string) (stri
 	return m.wallet.ExtractMnemonic(entropyStore, passphrase)
 }
 
-func (m WalletApi) Unlock(entropyStore string, passphrase string) error {
-	manager, e := m.wallet.GetEntropyStoreManager(entropyStore)
-	if e != nil {
-		return e
+// maxUnlockTimeout is the longest unlock timeout in seconds, use 0 to keep it unlocked until locked
+const maxUnlockTimeout = 30 * 24 * 3600
+
+func unlockDuration(timeout *int64) (time.Duration, error) {
+	if timeout == nil {
+		return 0, nil
+	}
+	if *timeout < 0 || *timeout > maxUnlockTimeout {
+		return 0, errors.New(fmt.Sprintf("timeout should be between 0 and %d seconds", maxUnlockTimeout))
 	}
-	err := manager.Unlock(passphrase)
+	return time.Duration(*timeout) * time.Second, nil
+}
+
+// Unlock keeps the entropy store unlocked for timeout seconds, until Lock is called if timeout is nil or 0.
+// Only the addresses of the indexes can be used if indexes is not nil.
+func (m WalletApi) Unlock(entropyStore string, passphrase string, timeout *int64, indexes *[]uint32) error {
+	duration, err := unlockDuration(timeout)
 	if err != nil {
 		return err
 	}
-	return nil
+	var indexList []uint32
+	if indexes != nil {
+		indexList = *indexes
+	}
+	return m.wallet.UnlockWithScope(entropyStore, passphrase, duration, indexList)
 }
 
 func (m WalletApi) Lock(entropyStore string) error {
@@ -218,6 +250,20 @@ func (m WalletApi) Lock(entropyStore string) error {
 	return nil
 }
 
+// ChangePassphrase encrypts the entropy store file again in place, kdfProfile is one of ListKDFProfiles,
+// the profile of node config is used if it is nil
+func (m WalletApi) ChangePassphrase(entropyStore string, oldPassphrase string, newPassphrase string, kdfProfile *string) error {
+	profile := ""
+	if kdfProfile != nil {
+		profile = *kdfProfile
+	}
+	return m.wallet.ChangePassphrase(entropyStore, oldPassphrase, newPassphrase, profile)
+}
+
+func (m WalletApi) ListKDFProfiles() []string {
+	return entropystore.KDFProfileNames()
+}
+
 func (m WalletApi) FindAddrWithPassphrase(entropyStore string, passphrase string, addr types.Address) (findResult *FindAddrResult, e error) {
 	manager, e := m.wallet.GetEntropyStoreManager(entropyStore)
 	if e != nil {
@@ -275,12 +321,7 @@ func (m WalletApi) SignData(addr types.Address, hexMsg string) (*HexSignedTuple,
 	if err != nil {
 		return nil, err
 	}
-	_, key, _, e := m.wallet.GlobalFindAddr(addr)
-	if e != nil {
-		return nil, e
-	}
-
-	signedData, pubkey, err := key.SignData(msgbytes)
+	signedData, pubkey, err := m.wallet.SignData(addr, msgbytes)
 	if err != nil {
 		return nil, err
 	}
@@ -295,13 +336,33 @@ func (m WalletApi) SignData(addr types.Address, hexMsg string) (*HexSignedTuple,
 }
 
 func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.Hash, error) {
-	amount, ok := new(big.Int).SetString(params.Amount, 10)
+	return m.createTx(params.SelfAddr, params.ToAddr, params.TokenTypeId, params.Amount, params.Data, params.Difficulty,
+		func(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+			if params.EntropystoreFile != nil {
+				manager, e := m.wallet.GetEntropyStoreManager(*params.EntropystoreFile)
+				if e != nil {
+					return nil, nil, e
+				}
+				return manager.SignDataWithPassphrase(addr, params.Passphrase, data)
+			}
+
+			return m.wallet.SignDataWithPassphrase(addr, params.Passphrase, data)
+		})
+}
+
+// CreateTx sign the block by the wallet signer, the account must be unlocked or served by the external signer
+func (m WalletApi) CreateTx(params CreateTxParms) (*types.Hash, error) {
+	return m.createTx(params.SelfAddr, params.ToAddr, params.TokenTypeId, params.Amount, params.Data, params.Difficulty, m.wallet.SignData)
+}
+
+func (m WalletApi) createTx(selfAddr, toAddr types.Address, tokenId types.TokenTypeId, amountStr string, data []byte, difficultyStr *string, sign generator.SignFunc) (*types.Hash, error) {
+	amount, ok := new(big.Int).SetString(amountStr, 10)
 	if !ok {
 		return nil, ErrStrToBigInt
 	}
 	var difficulty *big.Int = nil
-	if params.Difficulty != nil {
-		difficulty, ok = new(big.Int).SetString(*params.Difficulty, 10)
+	if difficultyStr != nil {
+		difficulty, ok = new(big.Int).SetString(*difficultyStr, 10)
 		if !ok {
 			return nil, ErrStrToBigInt
 		}
@@ -309,13 +370,13 @@ func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.
 
 	msg := &generator.IncomingMessage{
 		BlockType:      ledger.BlockTypeSendCall,
-		AccountAddress: params.SelfAddr,
-		ToAddress:      &params.ToAddr,
-		TokenId:        &params.TokenTypeId,
+		AccountAddress: selfAddr,
+		ToAddress:      &toAddr,
+		TokenId:        &tokenId,
 		Amount:         amount,
 		Fee:            nil,
 		Difficulty:     difficulty,
-		Data:           params.Data,
+		Data:           data,
 	}
 
 	addrState, err := generator.GetAddressStateForGenerator(m.chain, &msg.AccountAddress)
@@ -326,21 +387,7 @@ func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.
 	if e != nil {
 		return nil, e
 	}
-	result, e := g.GenerateWithMessage(msg, &msg.AccountAddress, func(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
-		if params.EntropystoreFile != nil {
-			manager, e := m.wallet.GetEntropyStoreManager(*params.EntropystoreFile)
-			if e != nil {
-				return nil, nil, e
-			}
-			return manager.SignDataWithPassphrase(addr, params.Passphrase, data)
-		}
-
-		_, key, _, e := m.wallet.GlobalFindAddrWithPassphrase(addr, params.Passphrase)
-		if e != nil {
-			return nil, nil, e
-		}
-		return key.SignData(data)
-	})
+	result, e := g.GenerateWithMessage(msg, &msg.AccountAddress, sign)
 
 	if e != nil {
 		return nil, e
@@ -349,7 +396,7 @@ func (m WalletApi) CreateTxWithPassphrase(params CreateTransferTxParms) (*types.
 		return nil, result.Err
 	}
 	if result.VMBlock != nil {
-		return &result.VMBlock.AccountBlock.Hash, m.pool.AddDirectAccountBlock(params.SelfAddr, result.VMBlock)
+		return &result.VMBlock.AccountBlock.Hash, m.pool.AddDirectAccountBlock(selfAddr, result.VMBlock)
 	} else {
 		return nil, errors.New("generator gen an empty block")
 	}
@@ -362,11 +409,7 @@ func (m WalletApi) SignDataWithPassphrase(addr types.Address, hexMsg string, pas
 	if err != nil {
 		return nil, err
 	}
-	_, key, _, e := m.wallet.GlobalFindAddrWithPassphrase(addr, passphrase)
-	if e != nil {
-		return nil, e
-	}
-	signedData, pubkey, err := key.SignData(msgbytes)
+	signedData, pubkey, err := m.wallet.SignDataWithPassphrase(addr, passphrase, msgbytes)
 	if err != nil {
 		return nil, err
 	}
@@ -395,3 +438,80 @@ func (m WalletApi) IsMayValidKeystoreFile(path string) IsMayValidKeystoreFileRes
 func (m WalletApi) GetDataDir() string {
 	return m.wallet.GetDataDir()
 }
+
+func newKeyStoreInfo(km *keystore.Manager) *KeyStoreInfo {
+	return &KeyStoreInfo{
+		Address:  km.GetAddr(),
+		Filename: km.GetKeyStoreFile(),
+		Unlocked: km.IsUnlocked(),
+	}
+}
+
+// ImportPrivateKey encrypts the hex of a 64 bytes ed25519 private key or its 32 bytes seed into a key store
+func (m WalletApi) ImportPrivateKey(hexPrivateKey string, passphrase string) (*KeyStoreInfo, error) {
+	key, err := keystore.ParsePrivateKey(hexPrivateKey)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Clear()
+	km, err := m.wallet.ImportPrivateKey(key, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyStoreInfo(km), nil
+}
+
+// ImportKeyStore imports the key store json exported by ExportKeyStore
+func (m WalletApi) ImportKeyStore(keyJson string, passphrase string) (*KeyStoreInfo, error) {
+	km, err := m.wallet.ImportKeyJSON([]byte(keyJson), passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return newKeyStoreInfo(km), nil
+}
+
+// ExportKeyStore exports the key of the key stores or the entropy stores as a key store json,
+// it's encrypted by newPassphrase if given, otherwise by passphrase
+func (m WalletApi) ExportKeyStore(addr types.Address, passphrase string, newPassphrase *string) (string, error) {
+	pass := passphrase
+	if newPassphrase != nil {
+		pass = *newPassphrase
+	}
+	keyjson, err := m.wallet.ExportKeyJSON(addr, passphrase, pass)
+	if err != nil {
+		return "", err
+	}
+	return string(keyjson), nil
+}
+
+// ExportPrivateKey returns the hex of the 64 bytes ed25519 private key
+func (m WalletApi) ExportPrivateKey(addr types.Address, passphrase string) (string, error) {
+	key, err := m.wallet.ExtractPrivateKey(addr, passphrase)
+	if err != nil {
+		return "", err
+	}
+	defer key.Clear()
+	return key.Hex(), nil
+}
+
+func (m WalletApi) ListKeyStores() []*KeyStoreInfo {
+	list := m.wallet.ListKeyStores()
+	infoList := make([]*KeyStoreInfo, len(list))
+	for i, km := range list {
+		infoList[i] = newKeyStoreInfo(km)
+	}
+	return infoList
+}
+
+// UnlockKeyStore keeps the key store unlocked for timeout seconds, until LockKeyStore is called if timeout is nil or 0
+func (m WalletApi) UnlockKeyStore(addr types.Address, passphrase string, timeout *int64) error {
+	duration, err := unlockDuration(timeout)
+	if err != nil {
+		return err
+	}
+	return m.wallet.UnlockKeyStore(addr, passphrase, duration)
+}
+
+func (m WalletApi) LockKeyStore(addr types.Address) error {
+	return m.wallet.LockKeyStore(addr)
+}
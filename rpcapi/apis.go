@@ -5,6 +5,7 @@ import (
 	"github.com/vitelabs/go-vite/rpcapi/api"
 	"github.com/vitelabs/go-vite/rpcapi/api/filters"
 	"github.com/vitelabs/go-vite/vite"
+	"github.com/vitelabs/go-vite/vite/net"
 )
 
 func Init(dir, lvl string, testApi_prikey, testApi_tti string, netId uint) {
@@ -96,6 +97,13 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewPledgeApi(vite),
 			Public:    true,
 		}
+	case "multisig":
+		return rpc.API{
+			Namespace: "multisig",
+			Version:   "1.0",
+			Service:   api.NewMultiSigApi(vite),
+			Public:    true,
+		}
 	case "consensusGroup":
 		return rpc.API{
 			Namespace: "consensusGroup",
@@ -125,6 +133,13 @@ func GetApi(vite *vite.Vite, apiModule string) rpc.API {
 			Service:   api.NewDebugApi(vite),
 			Public:    true,
 		}
+	case "trace":
+		return rpc.API{
+			Namespace: "trace",
+			Version:   "1.0",
+			Service:   api.NewTraceApi(vite),
+			Public:    false,
+		}
 	case "dashboard":
 		return rpc.API{
 			Namespace: "dashboard",
@@ -167,9 +182,21 @@ func GetApis(vite *vite.Vite, apiModule ...string) []rpc.API {
 }
 
 func GetPublicApis(vite *vite.Vite) []rpc.API {
-	return GetApis(vite, "ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard")
+	return GetApis(vite, "ledger", "public_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "multisig", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard")
+}
+
+// GetLightApis return the apis answered by the light client
+func GetLightApis(client *net.LightClient) []rpc.API {
+	return []rpc.API{
+		{
+			Namespace: "ledger",
+			Version:   "1.0",
+			Service:   api.NewLightLedgerApi(client),
+			Public:    true,
+		},
+	}
 }
 
 func GetAllApis(vite *vite.Vite) []rpc.API {
-	return GetApis(vite, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "consensusGroup", "testapi", "pow", "tx", "debug", "dashboard", "vmdebug", "subscribe")
+	return GetApis(vite, "ledger", "wallet", "private_onroad", "net", "contract", "pledge", "register", "vote", "mintage", "multisig", "consensusGroup", "testapi", "pow", "tx", "debug", "trace", "dashboard", "vmdebug", "subscribe")
 }
@@ -43,10 +43,10 @@ func (v *AccountVerifier) InitOnRoadPool(manager *onroad.Manager) {
 	v.orManager = manager
 }
 
-func (v *AccountVerifier) verifyReferred(block *ledger.AccountBlock) (VerifyResult, *AccBlockPendingTask, error) {
+func (v *AccountVerifier) verifyReferred(block *ledger.AccountBlock, snapshotHash *types.Hash) (VerifyResult, *AccBlockPendingTask, error) {
 	pendingTask := &AccBlockPendingTask{}
 
-	if err := v.verifySelf(block); err != nil {
+	if err := v.verifySelf(block, snapshotHash); err != nil {
 		return FAIL, pendingTask, err
 	}
 
@@ -81,8 +81,8 @@ func (v *AccountVerifier) verifyConfirmedTimes(recvBlock *ledger.AccountBlock) e
 	return nil
 }
 
-func (v *AccountVerifier) verifySelf(block *ledger.AccountBlock) error {
-	if err := v.checkAccountAddress(block); err != nil {
+func (v *AccountVerifier) verifySelf(block *ledger.AccountBlock, snapshotHash *types.Hash) error {
+	if err := v.checkAccountAddress(block, snapshotHash); err != nil {
 		return err
 	}
 	if block.IsSendBlock() {
@@ -103,13 +103,26 @@ func (v *AccountVerifier) verifySelf(block *ledger.AccountBlock) error {
 	return nil
 }
 
-func (v *AccountVerifier) checkAccountAddress(block *ledger.AccountBlock) error {
+// checkAccountAddress checks the contract exists at the snapshot block the block is executed with,
+// the builtin contracts added by forks are in use since the fork point
+func (v *AccountVerifier) checkAccountAddress(block *ledger.AccountBlock, snapshotHash *types.Hash) error {
 	if types.IsContractAddr(block.AccountAddress) {
-		meta, err := v.chain.GetContractMeta(block.AccountAddress)
+		sb := v.chain.GetLatestSnapshotBlock()
+		if snapshotHash != nil {
+			var err error
+			if sb, err = v.chain.GetSnapshotHeaderByHash(*snapshotHash); err != nil {
+				return err
+			}
+			if sb == nil {
+				return errors.New(fmt.Sprintf("snapshot block %s is not exist", snapshotHash))
+			}
+		}
+
+		isContract, err := v.chain.IsContractAccountInSnapshot(block.AccountAddress, sb.Height)
 		if err != nil {
 			return err
 		}
-		if meta == nil {
+		if !isContract {
 			return errors.New("contract address's meta is nil")
 		}
 	}
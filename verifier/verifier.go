@@ -76,7 +76,7 @@ func (v *verifier) VerifyPoolAccBlock(block *ledger.AccountBlock, snapshotHash *
 		detail += fmt.Sprintf("fromHash=%v;", block.FromBlockHash)
 	}
 
-	verifyResult, task, err := v.Av.verifyReferred(block)
+	verifyResult, task, err := v.Av.verifyReferred(block, snapshotHash)
 	if err != nil {
 		eLog.Error(err.Error(), "d", detail)
 	}
@@ -103,7 +103,7 @@ func (v *verifier) VerifyRPCAccBlock(block *ledger.AccountBlock, snapshotHash *t
 		detail += fmt.Sprintf(",fromH:%v", block.FromBlockHash)
 	}
 
-	if verifyResult, task, err := v.Av.verifyReferred(block); verifyResult != SUCCESS {
+	if verifyResult, task, err := v.Av.verifyReferred(block, snapshotHash); verifyResult != SUCCESS {
 		if err != nil {
 			log.Error(err.Error(), "d", detail)
 			return nil, err
@@ -365,6 +365,7 @@ func (b *broadcaster) handle(msg p2p.Msg, sender Peer) (err error) {
 		msg.Recycle()
 
 		if nb.Block == nil {
+			sender.report(p2p.BehaviorUselessBroadcast)
 			return errMissingBroadcastBlock
 		}
 
@@ -372,6 +373,10 @@ func (b *broadcaster) handle(msg p2p.Msg, sender Peer) (err error) {
 		b.log.Debug(fmt.Sprintf("unmarshal new snapshotblock %s/%d from %s [%s]", nb.Block.Hash, nb.Block.Height, sender, unmarshalAt.Sub(start)))
 
 		block := nb.Block
+		// only the block broadcast by the sender twice is useless, we may have sent it to the sender
+		if sender.receiveBlock(block.Hash) {
+			sender.report(p2p.BehaviorUselessBroadcast)
+		}
 		sender.seeBlock(block.Hash)
 
 		if b.listener != nil {
@@ -399,8 +404,13 @@ func (b *broadcaster) handle(msg p2p.Msg, sender Peer) (err error) {
 
 		if err = b.verifier.VerifyNetSb(block); err != nil {
 			b.log.Error(fmt.Sprintf("verify new snapshotblock %s/%d from %s error: %v", hash, block.Height, sender, err))
+			// the blocked hashes are our local policy, not a fault of the sender
+			if err != errHashBlocked {
+				sender.report(p2p.BehaviorInvalidBlock)
+			}
 			return err
 		}
+		sender.report(p2p.BehaviorNewBlock)
 		verifyAt := time.Now()
 		b.log.Debug(fmt.Sprintf("verify new snapshotblock %s/%d from %s [%s]", hash, block.Height, sender, verifyAt.Sub(recordAt)))
 
@@ -428,6 +438,7 @@ func (b *broadcaster) handle(msg p2p.Msg, sender Peer) (err error) {
 		msg.Recycle()
 
 		if nb.Block == nil {
+			sender.report(p2p.BehaviorUselessBroadcast)
 			return errMissingBroadcastBlock
 		}
 
@@ -435,6 +446,10 @@ func (b *broadcaster) handle(msg p2p.Msg, sender Peer) (err error) {
 		b.log.Debug(fmt.Sprintf("unmarshal new accountblock %s from %s [%s]", nb.Block.Hash, sender, unmarshalAt.Sub(start)))
 
 		block := nb.Block
+		// only the block broadcast by the sender twice is useless, we may have sent it to the sender
+		if sender.receiveBlock(block.Hash) {
+			sender.report(p2p.BehaviorUselessBroadcast)
+		}
 		sender.seeBlock(block.Hash)
 
 		if b.listener != nil {
@@ -462,8 +477,12 @@ func (b *broadcaster) handle(msg p2p.Msg, sender Peer) (err error) {
 
 		if err = b.verifier.VerifyNetAb(block); err != nil {
 			b.log.Error(fmt.Sprintf("verify new accountblock %s from %s error: %v", hash, sender, err))
+			if err != errHashBlocked {
+				sender.report(p2p.BehaviorInvalidBlock)
+			}
 			return err
 		}
+		sender.report(p2p.BehaviorNewBlock)
 
 		verifyAt := time.Now()
 		b.log.Debug(fmt.Sprintf("verify new accountblock %s from %s [%s]", hash, sender, verifyAt.Sub(recordAt)))
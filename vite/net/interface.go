@@ -36,6 +36,10 @@ type chainReader interface {
 	GetGenesisSnapshotBlock() *ledger.SnapshotBlock
 }
 
+type prunedReader interface {
+	GetPrunedSnapshotHeight() uint64
+}
+
 type syncCacher interface {
 	GetSyncCache() interfaces.SyncCache
 }
@@ -52,6 +56,7 @@ type Chain interface {
 	chainReader
 	ledgerReader
 	syncCacher
+	prunedReader
 }
 
 type IrreversibleReader interface {
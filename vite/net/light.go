@@ -0,0 +1,232 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/consensus/core"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/p2p"
+)
+
+/*
+ * The light protocol is a sub-protocol runs beside the vite protocol, full nodes serve it to light clients.
+ *
+ * Snapshot headers are the whole snapshot blocks, because the hash of a snapshot block is computed from
+ * all the items of SnapshotContent, there is no root can prove an item alone. A light client follows the
+ * headers from a trusted checkpoint, checks the hash and the signature of every header, and checks the
+ * producer against the plans of consensus/core.
+ *
+ * The light client runs in a quorum-trust mode, it doesn't prove who the producers are. The members of
+ * a round are decided by the votes in the contract state, which can't be proved without a state root, so
+ * they are given by servers and trusted if Quorum servers respond the same. Only the members of the round
+ * contains the checkpoint can be configured as trusted. A quorum of dishonest servers can feed a fake chain.
+ *
+ * An account block is proved by the account blocks from it to a confirmed head, they are linked by PrevHash.
+ * A balance is not proved, it is a claim of servers bound to the head, and trusted if Quorum servers respond the same.
+ */
+
+const LightProtocolName = "light"
+const lightProtocolVersion = 1
+
+// message codes of the light protocol, relative to the offset negotiated in handshake
+const (
+	lightGetHeadersCode p2p.Code = iota
+	lightHeadersCode
+	lightGetAccountProofCode
+	lightAccountProofCode
+	lightGetRoundMembersCode
+	lightRoundMembersCode
+	lightExceptionCode
+	lightCodeLength
+)
+
+const maxLightHeaders = 100
+const maxLightProofBlocks = 200
+const lightMaxFutureTime = 10 * time.Second
+const lightRoundsKept = 16
+
+var errLightBrokenHeaders = errors.New("snapshot headers are not continuous")
+var errLightInvalidHeader = errors.New("invalid snapshot header")
+var errLightInvalidProducer = errors.New("snapshot header is not produced as planned")
+var errLightUnknownRound = errors.New("members of round is unknown")
+var errLightInvalidMembers = errors.New("invalid round members")
+var errLightInvalidProof = errors.New("invalid account proof")
+
+// verifyLightHeader check the header links to prev, and it`s hash and signature, but not the producer
+func verifyLightHeader(prev, block *ledger.SnapshotBlock, now time.Time) error {
+	if block.Height != prev.Height+1 || block.PrevHash != prev.Hash {
+		return errLightBrokenHeaders
+	}
+	if block.Timestamp == nil || !block.Timestamp.After(*prev.Timestamp) || block.Timestamp.After(now.Add(lightMaxFutureTime)) {
+		return fmt.Errorf("%v: wrong timestamp of %s/%d", errLightInvalidHeader, block.Hash, block.Height)
+	}
+	if block.ComputeHash() != block.Hash {
+		return fmt.Errorf("%v: wrong hash of %s/%d", errLightInvalidHeader, block.Hash, block.Height)
+	}
+	if !block.VerifySignature() {
+		return fmt.Errorf("%v: wrong signature of %s/%d", errLightInvalidHeader, block.Hash, block.Height)
+	}
+	return nil
+}
+
+// verifyAccountProof check blocks are from low to high, link target to the confirmed head.
+// Zero target means only the head is required.
+func verifyAccountProof(blocks []*ledger.AccountBlock, addr types.Address, head ledger.HashHeight, target types.Hash) error {
+	if len(blocks) == 0 || len(blocks) > maxLightProofBlocks {
+		return errLightInvalidProof
+	}
+
+	last := blocks[len(blocks)-1]
+	if last.Hash != head.Hash || last.Height != head.Height {
+		return fmt.Errorf("%v: proof ends at %s/%d, not %s/%d", errLightInvalidProof, last.Hash, last.Height, head.Hash, head.Height)
+	}
+	if target != types.ZERO_HASH && blocks[0].Hash != target {
+		return fmt.Errorf("%v: proof starts at %s, not %s", errLightInvalidProof, blocks[0].Hash, target)
+	}
+
+	for i, block := range blocks {
+		if block.AccountAddress != addr {
+			return fmt.Errorf("%v: block %s is not belong to %s", errLightInvalidProof, block.Hash, addr)
+		}
+		if err := verifyLightAccountBlock(block); err != nil {
+			return err
+		}
+		if i > 0 && (block.Height != blocks[i-1].Height+1 || block.PrevHash != blocks[i-1].Hash) {
+			return fmt.Errorf("%v: block %s is not linked to %s", errLightInvalidProof, block.Hash, blocks[i-1].Hash)
+		}
+	}
+
+	return nil
+}
+
+// verifyLightAccountBlock check the hash of block and it`s send blocks. The genesis blocks are not signed,
+// the hash links them to the confirmed head already, so the signature is only checked if it exists.
+func verifyLightAccountBlock(block *ledger.AccountBlock) error {
+	if block.ComputeHash() != block.Hash {
+		return fmt.Errorf("%v: wrong hash of %s", errLightInvalidProof, block.Hash)
+	}
+	if (len(block.Signature) != 0 || len(block.PublicKey) != 0) && !block.VerifySignature() {
+		return fmt.Errorf("%v: wrong signature of %s", errLightInvalidProof, block.Hash)
+	}
+	for i, send := range block.SendBlockList {
+		if send.ComputeSendHash(block, uint8(i)) != send.Hash {
+			return fmt.Errorf("%v: wrong hash of send block %s in %s", errLightInvalidProof, send.Hash, block.Hash)
+		}
+	}
+	return nil
+}
+
+// lightSchedule check the producers of snapshot headers by the plans of consensus/core.
+// The light client can`t count the votes, the members of a round are trusted from a quorum of servers,
+// see acceptQuorum, so the producers are not proved.
+type lightSchedule struct {
+	info        *core.GroupInfo
+	rounds      map[uint64][]types.Address
+	last        uint64
+	lastMembers []types.Address
+}
+
+func newLightSchedule(genesisTime time.Time, group types.ConsensusGroupInfo) *lightSchedule {
+	return &lightSchedule{
+		info:   core.NewGroupInfo(genesisTime, group),
+		rounds: make(map[uint64][]types.Address),
+	}
+}
+
+func (s *lightSchedule) index(t time.Time) uint64 {
+	return s.info.Time2Index(t)
+}
+
+func (s *lightSchedule) members(index uint64) (members []types.Address, ok bool) {
+	members, ok = s.rounds[index]
+	return
+}
+
+// trust set the members without check, it is used by the trusted members of checkpoint
+func (s *lightSchedule) trust(index uint64, members []types.Address) {
+	s.rounds[index] = members
+	s.last = index
+	s.lastMembers = members
+}
+
+// acceptQuorum set the members responded by a quorum of servers, it`s trust, not a proof. As a sanity check,
+// most of them should be members of the last accepted round.
+func (s *lightSchedule) acceptQuorum(index uint64, members []types.Address) error {
+	if len(members) == 0 || len(members) > int(s.info.NodeCount) {
+		return errLightInvalidMembers
+	}
+
+	var set = make(map[types.Address]struct{}, len(members))
+	for _, addr := range members {
+		if _, ok := set[addr]; ok {
+			return fmt.Errorf("%v: duplicated member %s", errLightInvalidMembers, addr)
+		}
+		set[addr] = struct{}{}
+	}
+
+	if s.lastMembers == nil {
+		s.trust(index, members)
+		return nil
+	}
+
+	if index <= s.last {
+		return fmt.Errorf("%v: round %d is not after %d", errLightInvalidMembers, index, s.last)
+	}
+
+	var overlap int
+	for _, addr := range s.lastMembers {
+		if _, ok := set[addr]; ok {
+			overlap++
+		}
+	}
+	if overlap*3 < len(s.lastMembers)*2 {
+		return fmt.Errorf("%v: only %d of %d members are in round %d", errLightInvalidMembers, overlap, len(s.lastMembers), s.last)
+	}
+
+	s.trust(index, members)
+	for i := range s.rounds {
+		if i+lightRoundsKept < index {
+			delete(s.rounds, i)
+		}
+	}
+
+	return nil
+}
+
+func (s *lightSchedule) verifyProducer(block *ledger.SnapshotBlock) error {
+	index := s.index(*block.Timestamp)
+	members, ok := s.rounds[index]
+	if !ok {
+		return errLightUnknownRound
+	}
+
+	producer := block.Producer()
+	for _, plan := range s.info.GenPlanByAddress(index, members) {
+		if plan.Member == producer && plan.STime.Equal(*block.Timestamp) {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%v: %s/%d by %s at %s", errLightInvalidProducer, block.Hash, block.Height, producer, block.Timestamp)
+}
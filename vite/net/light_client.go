@@ -0,0 +1,889 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package net
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+	"github.com/vitelabs/go-vite/vite/net/message"
+	"github.com/vitelabs/go-vite/vite/net/protos"
+)
+
+const lightRequestTimeout = 10 * time.Second
+const lightFollowInterval = time.Second
+const lightHeaderWindow = 3600
+const defaultLightQuorum = 2
+
+var errLightClientRunning = errors.New("light client is already running")
+var errLightClientStopped = errors.New("light client stopped")
+var errLightNotReady = errors.New("light client has not reached the checkpoint")
+var errLightNoPeer = errors.New("no light server")
+var errLightNoQuorum = errors.New("not enough light servers to reach the quorum")
+var errLightBalanceMismatch = errors.New("balances from light servers are different")
+var errLightUnknownAccount = errors.New("account has not been confirmed since the checkpoint")
+var errLightUnconfirmed = errors.New("account block has not been confirmed")
+var errLightProofTooLong = errors.New("account block is too far from the confirmed head")
+var errLightForkShorter = errors.New("fork is not longer than the followed headers")
+var errLightForkInvalid = errors.New("fork is invalid")
+var errLightForkTooDeep = errors.New("common ancestor of the fork is lower than the kept headers")
+
+type LightConfig struct {
+	// Genesis is the genesis snapshot block of the network
+	Genesis *ledger.SnapshotBlock
+	// Group is the snapshot consensus group
+	Group types.ConsensusGroupInfo
+	// Checkpoint is a trusted snapshot block to follow from, follow from genesis if it is nil.
+	// Only the accounts confirmed since the checkpoint are known by light client.
+	Checkpoint *ledger.HashHeight
+	// Members are the trusted producers of the round contains the checkpoint,
+	// the members of other rounds are trusted if Quorum servers respond the same.
+	Members []types.Address
+	// Quorum is the count of servers should respond the same unproved data, the round members and balances, default 2.
+	// The light client is only as safe as the assumption that Quorum servers are not all dishonest.
+	Quorum int
+}
+
+type lightHead struct {
+	ledger.HashHeight
+	snapshot types.Hash // the snapshot block confirmed the head
+}
+
+type deserializable interface {
+	Deserialize(buf []byte) error
+}
+
+type lightRequest struct {
+	peer vnode.NodeID
+	ch   chan p2p.Msg
+}
+
+// LightClient follows the snapshot headers and verifies the ledger data by the light protocol.
+// It is the main Protocol of a light node, which has no chain, and Sub() should be registered to p2p too.
+// The verified headers and account heads are kept in memory, follow from the checkpoint after restart.
+type LightClient struct {
+	LightConfig
+	schedule *lightSchedule
+
+	mu      sync.RWMutex
+	base    *ledger.SnapshotBlock // the checkpoint, nil before verified
+	latest  *ledger.SnapshotBlock
+	headers map[uint64]*ledger.SnapshotBlock
+	hashes  map[types.Hash]uint64
+	heads   map[types.Address]*lightHead
+
+	peerMu sync.RWMutex
+	peers  map[vnode.NodeID]p2p.Peer
+
+	id        uint32
+	pendingMu sync.Mutex
+	pending   map[p2p.MsgId]*lightRequest
+
+	wake    chan struct{}
+	term    chan struct{}
+	wg      sync.WaitGroup
+	running int32
+	log     log15.Logger
+}
+
+func NewLightClient(cfg LightConfig) (*LightClient, error) {
+	if cfg.Genesis == nil || cfg.Genesis.Timestamp == nil {
+		return nil, errors.New("missing genesis snapshot block")
+	}
+	if cfg.Group.NodeCount == 0 || cfg.Group.Interval <= 0 || cfg.Group.PerCount <= 0 || cfg.Group.Repeat == 0 {
+		return nil, errors.New("invalid snapshot consensus group")
+	}
+	if cfg.Quorum <= 0 {
+		cfg.Quorum = defaultLightQuorum
+	}
+
+	c := &LightClient{
+		LightConfig: cfg,
+		schedule:    newLightSchedule(*cfg.Genesis.Timestamp, cfg.Group),
+		headers:     make(map[uint64]*ledger.SnapshotBlock),
+		hashes:      make(map[types.Hash]uint64),
+		heads:       make(map[types.Address]*lightHead),
+		peers:       make(map[vnode.NodeID]p2p.Peer),
+		pending:     make(map[p2p.MsgId]*lightRequest),
+		wake:        make(chan struct{}, 1),
+		log:         netLog.New("module", "light_client"),
+	}
+
+	if cfg.Checkpoint == nil {
+		c.setBase(cfg.Genesis)
+	}
+
+	return c, nil
+}
+
+func (c *LightClient) setBase(block *ledger.SnapshotBlock) {
+	c.mu.Lock()
+	c.base = block
+	c.mu.Unlock()
+
+	c.apply(block)
+
+	if len(c.Members) > 0 {
+		c.schedule.trust(c.schedule.index(*block.Timestamp), c.Members)
+	}
+}
+
+func (c *LightClient) apply(block *ledger.SnapshotBlock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.latest = block
+	c.headers[block.Height] = block
+	c.hashes[block.Hash] = block.Height
+	if block.Height > lightHeaderWindow {
+		if old, ok := c.headers[block.Height-lightHeaderWindow]; ok {
+			delete(c.headers, old.Height)
+			delete(c.hashes, old.Hash)
+		}
+	}
+
+	for addr, hh := range block.SnapshotContent {
+		c.heads[addr] = &lightHead{
+			HashHeight: *hh,
+			snapshot:   block.Hash,
+		}
+	}
+}
+
+func (c *LightClient) Start() error {
+	if !atomic.CompareAndSwapInt32(&c.running, 0, 1) {
+		return errLightClientRunning
+	}
+
+	c.term = make(chan struct{})
+	c.wg.Add(1)
+	go c.loop()
+
+	return nil
+}
+
+func (c *LightClient) Stop() error {
+	if atomic.CompareAndSwapInt32(&c.running, 1, 0) {
+		close(c.term)
+		c.wg.Wait()
+	}
+	return nil
+}
+
+// Sub return the SubProtocol of LightClient, it requests the light servers
+func (c *LightClient) Sub() p2p.SubProtocol {
+	return (*lightClientSub)(c)
+}
+
+// @section main Protocol, the light client has no blocks, so peers never sync from it
+
+func (c *LightClient) ProtoData() (height uint64, head types.Hash, genesis types.Hash) {
+	return c.Genesis.Height, c.Genesis.Hash, c.Genesis.Hash
+}
+
+func (c *LightClient) ReceiveHandshake(msg *p2p.HandshakeMsg) (level p2p.Level, err error) {
+	return p2p.Inbound, nil
+}
+
+func (c *LightClient) Handle(msg p2p.Msg) error {
+	if msg.Code != p2p.CodeHeartBeat {
+		return nil
+	}
+
+	var heartbeat = &protos.State{}
+	if err := proto.Unmarshal(msg.Payload, heartbeat); err != nil {
+		return err
+	}
+	head, err := types.BytesToHash(heartbeat.Head)
+	if err != nil {
+		return err
+	}
+	msg.Sender.SetHead(head, heartbeat.Height)
+
+	return nil
+}
+
+func (c *LightClient) State() []byte {
+	data, _ := proto.Marshal(&protos.State{
+		Head:      c.Genesis.Hash.Bytes(),
+		Height:    c.Genesis.Height,
+		Timestamp: time.Now().Unix(),
+	})
+	return data
+}
+
+func (c *LightClient) OnPeerAdded(peer p2p.Peer) error {
+	return nil
+}
+
+func (c *LightClient) OnPeerRemoved(peer p2p.Peer) error {
+	return nil
+}
+
+// @section SubProtocol
+
+type lightClientSub LightClient
+
+func (s *lightClientSub) Cap() p2p.Cap {
+	return p2p.Cap{Name: LightProtocolName, Version: lightProtocolVersion}
+}
+
+func (s *lightClientSub) CodeLength() int {
+	return int(lightCodeLength)
+}
+
+func (s *lightClientSub) Handle(msg p2p.Msg) error {
+	switch msg.Code {
+	case lightHeadersCode, lightAccountProofCode, lightRoundMembersCode, lightExceptionCode:
+		s.pendingMu.Lock()
+		req, ok := s.pending[msg.Id]
+		if ok && req.peer == msg.Sender.ID() {
+			delete(s.pending, msg.Id)
+		} else {
+			ok = false
+		}
+		s.pendingMu.Unlock()
+
+		// late response
+		if ok {
+			req.ch <- msg
+		}
+		return nil
+	case lightGetHeadersCode, lightGetAccountProofCode, lightGetRoundMembersCode:
+		// light client don`t serve
+		return nil
+	default:
+		return p2p.PeerUnknownMessage
+	}
+}
+
+func (s *lightClientSub) OnPeerAdded(peer p2p.Peer) error {
+	s.peerMu.Lock()
+	s.peers[peer.ID()] = peer
+	s.peerMu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return nil
+}
+
+func (s *lightClientSub) OnPeerRemoved(peer p2p.Peer) error {
+	s.peerMu.Lock()
+	delete(s.peers, peer.ID())
+	s.peerMu.Unlock()
+
+	return nil
+}
+
+// @section request
+
+// pickPeers return at most count peers, the peers higher than height first
+func (c *LightClient) pickPeers(count int, height uint64) (peers []p2p.Peer) {
+	c.peerMu.RLock()
+	defer c.peerMu.RUnlock()
+
+	var lower []p2p.Peer
+	for _, p := range c.peers {
+		if p.Height() >= height {
+			peers = append(peers, p)
+		} else {
+			lower = append(lower, p)
+		}
+	}
+	peers = append(peers, lower...)
+
+	if len(peers) > count {
+		peers = peers[:count]
+	}
+	return
+}
+
+func (c *LightClient) bestPeer() (best p2p.Peer) {
+	c.peerMu.RLock()
+	defer c.peerMu.RUnlock()
+
+	for _, p := range c.peers {
+		if best == nil || p.Height() > best.Height() {
+			best = p
+		}
+	}
+	return
+}
+
+func (c *LightClient) request(peer p2p.Peer, code p2p.Code, data p2p.Serializable, resp p2p.Code, ret deserializable) (err error) {
+	payload, err := data.Serialize()
+	if err != nil {
+		return
+	}
+
+	id := atomic.AddUint32(&c.id, 1)
+	req := &lightRequest{
+		peer: peer.ID(),
+		ch:   make(chan p2p.Msg, 1),
+	}
+	c.pendingMu.Lock()
+	c.pending[id] = req
+	c.pendingMu.Unlock()
+
+	defer func() {
+		c.pendingMu.Lock()
+		delete(c.pending, id)
+		c.pendingMu.Unlock()
+	}()
+
+	if err = peer.WriteMsg(p2p.Msg{Code: code, Id: id, Payload: payload}); err != nil {
+		return
+	}
+
+	var timer = time.NewTimer(lightRequestTimeout)
+	defer timer.Stop()
+
+	select {
+	case msg := <-req.ch:
+		if msg.Code == lightExceptionCode {
+			return p2p.ExpMissing
+		}
+		if msg.Code != resp {
+			return p2p.PeerInvalidMessage
+		}
+		if err = ret.Deserialize(msg.Payload); err != nil {
+			return p2p.PeerUnmarshalError
+		}
+		return nil
+	case <-timer.C:
+		return p2p.PeerResponseTimeout
+	case <-c.term:
+		return errLightClientStopped
+	}
+}
+
+// @section follow
+
+func (c *LightClient) loop() {
+	defer c.wg.Done()
+
+	var ticker = time.NewTicker(lightFollowInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.term:
+			return
+		case <-ticker.C:
+		case <-c.wake:
+		}
+
+		c.follow()
+	}
+}
+
+func (c *LightClient) follow() {
+	if c.Checkpoint != nil && c.base == nil {
+		if err := c.fetchCheckpoint(); err != nil {
+			c.log.Warn(fmt.Sprintf("failed to fetch checkpoint %s/%d: %v", c.Checkpoint.Hash, c.Checkpoint.Height, err))
+			return
+		}
+	}
+
+	for {
+		select {
+		case <-c.term:
+			return
+		default:
+		}
+
+		latest := c.Latest()
+		peer := c.bestPeer()
+		if peer == nil || peer.Height() <= latest.Height {
+			return
+		}
+
+		count := peer.Height() - latest.Height
+		if count > maxLightHeaders {
+			count = maxLightHeaders
+		}
+
+		var blocks = new(message.SnapshotBlocks)
+		err := c.request(peer, lightGetHeadersCode, &message.GetSnapshotBlocks{
+			From:    ledger.HashHeight{Height: latest.Height + 1},
+			Count:   count,
+			Forward: true,
+		}, lightHeadersCode, blocks)
+		if err != nil {
+			c.log.Warn(fmt.Sprintf("failed to get headers from %s: %v", peer, err))
+			return
+		}
+
+		// the peer is on another fork, it is not invalid until the headers from the common ancestor are verified
+		if len(blocks.Blocks) > 0 && blocks.Blocks[0].Height == latest.Height+1 && blocks.Blocks[0].PrevHash != latest.Hash {
+			if err = c.reorg(peer, latest); err != nil {
+				c.log.Warn(fmt.Sprintf("failed to switch to the fork of %s: %v", peer, err))
+				if err == errLightForkInvalid {
+					_ = peer.Close(p2p.PeerInvalidBlock)
+				}
+				return
+			}
+			continue
+		}
+
+		if err = c.insert(peer, blocks.Blocks); err != nil {
+			c.log.Error(fmt.Sprintf("failed to verify headers from %s: %v", peer, err))
+			if err != errLightClientStopped {
+				_ = peer.Close(p2p.PeerInvalidBlock)
+			}
+			return
+		}
+	}
+}
+
+func (c *LightClient) fetchCheckpoint() (err error) {
+	peer := c.bestPeer()
+	if peer == nil {
+		return errLightNoPeer
+	}
+
+	var blocks = new(message.SnapshotBlocks)
+	err = c.request(peer, lightGetHeadersCode, &message.GetSnapshotBlocks{
+		From:    *c.Checkpoint,
+		Count:   1,
+		Forward: true,
+	}, lightHeadersCode, blocks)
+	if err != nil {
+		return
+	}
+
+	if len(blocks.Blocks) != 1 {
+		return errLightInvalidHeader
+	}
+	block := blocks.Blocks[0]
+	if block.Hash != c.Checkpoint.Hash || block.Height != c.Checkpoint.Height || block.ComputeHash() != block.Hash || block.Timestamp == nil {
+		_ = peer.Close(p2p.PeerInvalidBlock)
+		return errLightInvalidHeader
+	}
+
+	c.setBase(block)
+	c.log.Info(fmt.Sprintf("reach checkpoint %s/%d", block.Hash, block.Height))
+
+	return nil
+}
+
+// insert verify the headers and append them to the latest one
+func (c *LightClient) insert(peer p2p.Peer, blocks []*ledger.SnapshotBlock) (err error) {
+	if len(blocks) == 0 {
+		return errLightBrokenHeaders
+	}
+
+	prev := c.Latest()
+	now := time.Now()
+	for _, block := range blocks {
+		if err = c.verifyHeader(peer, prev, block, now); err != nil {
+			return
+		}
+
+		c.apply(block)
+		prev = block
+	}
+
+	return nil
+}
+
+// verifyHeader verify the header links to prev and is produced by the planned member, the unknown members are requested from peer
+func (c *LightClient) verifyHeader(peer p2p.Peer, prev, block *ledger.SnapshotBlock, now time.Time) (err error) {
+	if err = verifyLightHeader(prev, block, now); err != nil {
+		return
+	}
+
+	index := c.schedule.index(*block.Timestamp)
+	if _, ok := c.schedule.members(index); !ok {
+		if err = c.fetchMembers(peer, index); err != nil {
+			return
+		}
+	}
+
+	return c.schedule.verifyProducer(block)
+}
+
+// reorg find the common ancestor with the fork of peer, roll back the headers above it and follow the fork.
+// The headers of the fork are verified before the rollback, and Quorum servers should respond the same fork,
+// the ancestor should not be lower than the checkpoint and the headers kept.
+func (c *LightClient) reorg(peer p2p.Peer, latest *ledger.SnapshotBlock) (err error) {
+	ancestor, err := c.findAncestor(peer, latest)
+	if err != nil {
+		return
+	}
+
+	count := peer.Height() - ancestor.Height
+	if count > maxLightHeaders {
+		count = maxLightHeaders
+	}
+	var blocks = new(message.SnapshotBlocks)
+	err = c.request(peer, lightGetHeadersCode, &message.GetSnapshotBlocks{
+		From:    ledger.HashHeight{Height: ancestor.Height + 1},
+		Count:   count,
+		Forward: true,
+	}, lightHeadersCode, blocks)
+	if err != nil {
+		return
+	}
+
+	// only a longer fork replaces the unconfirmed tail
+	if len(blocks.Blocks) == 0 || ancestor.Height+uint64(len(blocks.Blocks)) <= latest.Height {
+		return errLightForkShorter
+	}
+	prev, now := ancestor, time.Now()
+	for _, block := range blocks.Blocks {
+		if err = c.verifyHeader(peer, prev, block, now); err != nil {
+			if err == errLightClientStopped {
+				return
+			}
+			c.log.Warn(fmt.Sprintf("invalid fork from %s: %v", peer, err))
+			return errLightForkInvalid
+		}
+		prev = block
+	}
+	if err = c.agreeFork(peer, blocks.Blocks[0]); err != nil {
+		return
+	}
+
+	c.rollback(ancestor)
+	c.log.Warn(fmt.Sprintf("roll back from %s/%d to %s/%d, follow the fork of %s", latest.Hash, latest.Height, ancestor.Hash, ancestor.Height, peer))
+
+	return c.insert(peer, blocks.Blocks)
+}
+
+// findAncestor return the highest kept header which is the same with the header of peer
+func (c *LightClient) findAncestor(peer p2p.Peer, latest *ledger.SnapshotBlock) (ancestor *ledger.SnapshotBlock, err error) {
+	c.mu.RLock()
+	checkpoint := c.base.Height
+	low := checkpoint
+	if latest.Height > lightHeaderWindow && latest.Height-lightHeaderWindow+1 > low {
+		low = latest.Height - lightHeaderWindow + 1
+	}
+	c.mu.RUnlock()
+
+	for high := latest.Height; high >= low; {
+		from := low
+		if high-low+1 > maxLightHeaders {
+			from = high - maxLightHeaders + 1
+		}
+
+		var blocks = new(message.SnapshotBlocks)
+		err = c.request(peer, lightGetHeadersCode, &message.GetSnapshotBlocks{
+			From:    ledger.HashHeight{Height: from},
+			Count:   high - from + 1,
+			Forward: true,
+		}, lightHeadersCode, blocks)
+		if err != nil {
+			return
+		}
+
+		for i := len(blocks.Blocks) - 1; i >= 0; i-- {
+			block := blocks.Blocks[i]
+			if kept := c.GetSnapshotBlockByHeight(block.Height); kept != nil && kept.Hash == block.Hash {
+				return kept, nil
+			}
+		}
+
+		if from == low {
+			break
+		}
+		high = from - 1
+	}
+
+	// the checkpoint is trusted, a fork below it is invalid
+	if low == checkpoint {
+		return nil, errLightForkInvalid
+	}
+	return nil, errLightForkTooDeep
+}
+
+// agreeFork check the first header of the fork is responded by Quorum servers include peer
+func (c *LightClient) agreeFork(peer p2p.Peer, block *ledger.SnapshotBlock) (err error) {
+	var peers []p2p.Peer
+	for _, p := range c.pickPeers(c.Quorum, block.Height) {
+		if len(peers)+1 >= c.Quorum {
+			break
+		}
+		if p.ID() != peer.ID() {
+			peers = append(peers, p)
+		}
+	}
+	if len(peers)+1 < c.Quorum {
+		return errLightNoQuorum
+	}
+
+	// the server responds exception if the header of the height is not the hash
+	for _, p := range peers {
+		var ret = new(message.SnapshotBlocks)
+		err = c.request(p, lightGetHeadersCode, &message.GetSnapshotBlocks{
+			From:    ledger.HashHeight{Height: block.Height, Hash: block.Hash},
+			Count:   1,
+			Forward: true,
+		}, lightHeadersCode, ret)
+		if err != nil {
+			return fmt.Errorf("%s don`t agree the fork %s/%d: %v", p, block.Hash, block.Height, err)
+		}
+	}
+
+	return nil
+}
+
+// rollback remove the headers higher than ancestor, the account heads confirmed by them are restored
+// to the kept headers, or unknown if they are not confirmed by the kept headers.
+func (c *LightClient) rollback(ancestor *ledger.SnapshotBlock) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var reverted = make(map[types.Address]struct{})
+	for height := ancestor.Height + 1; height <= c.latest.Height; height++ {
+		block, ok := c.headers[height]
+		if !ok {
+			continue
+		}
+		for addr := range block.SnapshotContent {
+			reverted[addr] = struct{}{}
+		}
+		delete(c.headers, height)
+		delete(c.hashes, block.Hash)
+	}
+	c.latest = ancestor
+
+	for addr := range reverted {
+		delete(c.heads, addr)
+	}
+	for height := ancestor.Height; height > 0 && len(reverted) > 0; height-- {
+		block, ok := c.headers[height]
+		if !ok {
+			break
+		}
+		for addr, hh := range block.SnapshotContent {
+			if _, ok = reverted[addr]; ok {
+				c.heads[addr] = &lightHead{
+					HashHeight: *hh,
+					snapshot:   block.Hash,
+				}
+				delete(reverted, addr)
+			}
+		}
+	}
+}
+
+// fetchMembers request the members of round, they should be responded the same by Quorum servers
+// include the peer of headers. The members can`t be proved, see acceptQuorum.
+func (c *LightClient) fetchMembers(peer p2p.Peer, index uint64) (err error) {
+	var peers = []p2p.Peer{peer}
+	for _, p := range c.pickPeers(c.Quorum, 0) {
+		if len(peers) >= c.Quorum {
+			break
+		}
+		if p.ID() != peer.ID() {
+			peers = append(peers, p)
+		}
+	}
+	if len(peers) < c.Quorum {
+		return errLightNoQuorum
+	}
+
+	var members []types.Address
+	for _, p := range peers {
+		var ret = new(message.RoundMembers)
+		if err = c.request(p, lightGetRoundMembersCode, &message.RoundMembers{Index: index}, lightRoundMembersCode, ret); err != nil {
+			return
+		}
+		if ret.Index != index {
+			return fmt.Errorf("%v: round %d is not %d", errLightInvalidMembers, ret.Index, index)
+		}
+
+		if members == nil {
+			members = ret.Members
+		} else if !sameAddresses(members, ret.Members) {
+			return fmt.Errorf("%v: servers respond different members of round %d", errLightInvalidMembers, index)
+		}
+	}
+
+	return c.schedule.acceptQuorum(index, members)
+}
+
+func sameAddresses(a, b []types.Address) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// @section query, the blocks are verified by the followed headers
+
+// Latest return the latest verified snapshot header
+func (c *LightClient) Latest() *ledger.SnapshotBlock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.latest == nil {
+		return c.Genesis
+	}
+	return c.latest
+}
+
+// GetSnapshotBlockByHeight return the verified header, only the recent headers are kept
+func (c *LightClient) GetSnapshotBlockByHeight(height uint64) *ledger.SnapshotBlock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	return c.headers[height]
+}
+
+func (c *LightClient) GetSnapshotBlockByHash(hash types.Hash) *ledger.SnapshotBlock {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if height, ok := c.hashes[hash]; ok {
+		return c.headers[height]
+	}
+	return nil
+}
+
+// GetAccountHead return the latest confirmed block of addr, and the snapshot block confirmed it
+func (c *LightClient) GetAccountHead(addr types.Address) (head ledger.HashHeight, snapshot types.Hash, err error) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	if c.base == nil {
+		err = errLightNotReady
+		return
+	}
+
+	h, ok := c.heads[addr]
+	if !ok {
+		err = errLightUnknownAccount
+		return
+	}
+
+	return h.HashHeight, h.snapshot, nil
+}
+
+// GetAccountBlockByHash return the confirmed account block, it is proved by the blocks link it to the confirmed head
+func (c *LightClient) GetAccountBlockByHash(hash types.Hash) (block *ledger.AccountBlock, err error) {
+	peers := c.pickPeers(1, c.Latest().Height)
+	if len(peers) == 0 {
+		return nil, errLightNoPeer
+	}
+	peer := peers[0]
+
+	var ret = new(message.AccountProof)
+	if err = c.request(peer, lightGetAccountProofCode, &message.GetAccountProof{Hash: hash}, lightAccountProofCode, ret); err != nil {
+		return
+	}
+	if len(ret.Blocks) != 1 || ret.Blocks[0].Hash != hash {
+		return nil, errLightInvalidProof
+	}
+	if err = verifyLightAccountBlock(ret.Blocks[0]); err != nil {
+		return
+	}
+	block = ret.Blocks[0]
+
+	head, _, err := c.GetAccountHead(block.AccountAddress)
+	if err != nil {
+		return
+	}
+	if head.Height < block.Height {
+		return nil, errLightUnconfirmed
+	}
+	if head.Height-block.Height+1 > maxLightProofBlocks {
+		return nil, errLightProofTooLong
+	}
+
+	ret = new(message.AccountProof)
+	err = c.request(peer, lightGetAccountProofCode, &message.GetAccountProof{
+		Address: block.AccountAddress,
+		Head:    head,
+		Hash:    hash,
+	}, lightAccountProofCode, ret)
+	if err != nil {
+		return
+	}
+	if err = verifyAccountProof(ret.Blocks, block.AccountAddress, head, hash); err != nil {
+		_ = peer.Close(p2p.PeerInvalidBlock)
+		return nil, err
+	}
+
+	return ret.Blocks[0], nil
+}
+
+// GetQuorumBalance return the balance of the latest confirmed head of addr.
+// The head is proved, but the balance is not, it is trusted if Quorum servers respond the same.
+func (c *LightClient) GetQuorumBalance(addr types.Address, tokenId types.TokenTypeId) (balance *big.Int, head ledger.HashHeight, err error) {
+	head, snapshot, err := c.GetAccountHead(addr)
+	if err != nil {
+		return
+	}
+
+	peers := c.pickPeers(c.Quorum, c.Latest().Height)
+	if len(peers) < c.Quorum {
+		err = errLightNoQuorum
+		return
+	}
+
+	for _, peer := range peers {
+		var ret = new(message.AccountProof)
+		err = c.request(peer, lightGetAccountProofCode, &message.GetAccountProof{
+			Address:  addr,
+			Head:     head,
+			TokenId:  tokenId,
+			Snapshot: snapshot,
+		}, lightAccountProofCode, ret)
+		if err != nil {
+			return
+		}
+		if err = verifyAccountProof(ret.Blocks, addr, head, types.ZERO_HASH); err != nil {
+			_ = peer.Close(p2p.PeerInvalidBlock)
+			return
+		}
+		if ret.Balance == nil {
+			err = errLightInvalidProof
+			return
+		}
+
+		if balance == nil {
+			balance = ret.Balance
+		} else if balance.Cmp(ret.Balance) != 0 {
+			err = errLightBalanceMismatch
+			return
+		}
+	}
+
+	return
+}
@@ -0,0 +1,224 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package net
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/vite/net/message"
+)
+
+type lightServerChain interface {
+	GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) ([]*ledger.SnapshotBlock, error)
+	GetAccountBlockByHash(blockHash types.Hash) (*ledger.AccountBlock, error)
+	GetAccountBlocksByHeight(addr types.Address, height uint64, count uint64) ([]*ledger.AccountBlock, error)
+	GetConfirmedBalanceList(addrList []types.Address, tokenId types.TokenTypeId, sbHash types.Hash) (map[types.Address]*big.Int, error)
+}
+
+type lightServerConsensus interface {
+	ReadByIndex(gid types.Gid, index uint64) ([]*consensus.Event, uint64, error)
+}
+
+// lightServer serve the light protocol for light clients
+type lightServer struct {
+	chain     lightServerChain
+	consensus lightServerConsensus
+	log       log15.Logger
+}
+
+// NewLightServer create the SubProtocol serves snapshot headers, account proofs and round members to light clients,
+// it should be registered to p2p before start.
+func NewLightServer(chain lightServerChain, cs lightServerConsensus) p2p.SubProtocol {
+	return &lightServer{
+		chain:     chain,
+		consensus: cs,
+		log:       netLog.New("module", "light_server"),
+	}
+}
+
+func (s *lightServer) Cap() p2p.Cap {
+	return p2p.Cap{Name: LightProtocolName, Version: lightProtocolVersion}
+}
+
+func (s *lightServer) CodeLength() int {
+	return int(lightCodeLength)
+}
+
+func (s *lightServer) OnPeerAdded(peer p2p.Peer) error {
+	return nil
+}
+
+func (s *lightServer) OnPeerRemoved(peer p2p.Peer) error {
+	return nil
+}
+
+func (s *lightServer) Handle(msg p2p.Msg) (err error) {
+	var data p2p.Serializable
+	var code p2p.Code
+
+	switch msg.Code {
+	case lightGetHeadersCode:
+		req := new(message.GetSnapshotBlocks)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return p2p.PeerUnmarshalError
+		}
+		code = lightHeadersCode
+		data, err = s.getHeaders(req)
+
+	case lightGetAccountProofCode:
+		req := new(message.GetAccountProof)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return p2p.PeerUnmarshalError
+		}
+		code = lightAccountProofCode
+		data, err = s.getAccountProof(req)
+
+	case lightGetRoundMembersCode:
+		req := new(message.RoundMembers)
+		if err = req.Deserialize(msg.Payload); err != nil {
+			return p2p.PeerUnmarshalError
+		}
+		code = lightRoundMembersCode
+		data, err = s.getRoundMembers(req.Index)
+
+	case lightHeadersCode, lightAccountProofCode, lightRoundMembersCode, lightExceptionCode:
+		// server don`t request
+		return nil
+
+	default:
+		return p2p.PeerUnknownMessage
+	}
+
+	if err != nil {
+		s.log.Warn(fmt.Sprintf("failed to handle light message %d from %s: %v", msg.Code, msg.Sender, err))
+		code, data = lightExceptionCode, p2p.ExpMissing
+	}
+
+	payload, err := data.Serialize()
+	if err != nil {
+		return err
+	}
+
+	return msg.Sender.WriteMsg(p2p.Msg{
+		Code:    code,
+		Id:      msg.Id,
+		Payload: payload,
+	})
+}
+
+func (s *lightServer) getHeaders(req *message.GetSnapshotBlocks) (*message.SnapshotBlocks, error) {
+	if !req.Forward || req.Count == 0 || req.Count > maxLightHeaders {
+		return nil, fmt.Errorf("wrong headers request %s", req)
+	}
+
+	blocks, err := s.chain.GetSnapshotBlocksByHeight(req.From.Height, true, req.Count)
+	if err != nil {
+		return nil, err
+	}
+	if len(blocks) == 0 {
+		return nil, fmt.Errorf("missing headers from %d", req.From.Height)
+	}
+	if req.From.Hash != types.ZERO_HASH && blocks[0].Hash != req.From.Hash {
+		return nil, fmt.Errorf("header %d is not %s", req.From.Height, req.From.Hash)
+	}
+
+	return &message.SnapshotBlocks{Blocks: blocks}, nil
+}
+
+func (s *lightServer) getAccountProof(req *message.GetAccountProof) (proof *message.AccountProof, err error) {
+	proof = new(message.AccountProof)
+
+	// only the block of hash
+	if req.Head.Height == 0 {
+		var block *ledger.AccountBlock
+		if block, err = s.chain.GetAccountBlockByHash(req.Hash); err != nil {
+			return nil, err
+		}
+		if block == nil {
+			return nil, fmt.Errorf("missing account block %s", req.Hash)
+		}
+		proof.Blocks = []*ledger.AccountBlock{block}
+		return proof, nil
+	}
+
+	var count uint64 = 1
+	if req.Hash != types.ZERO_HASH && req.Hash != req.Head.Hash {
+		var block *ledger.AccountBlock
+		if block, err = s.chain.GetAccountBlockByHash(req.Hash); err != nil {
+			return nil, err
+		}
+		if block == nil || block.AccountAddress != req.Address || block.Height > req.Head.Height {
+			return nil, fmt.Errorf("account block %s is not before %s/%d", req.Hash, req.Head.Hash, req.Head.Height)
+		}
+		count = req.Head.Height - block.Height + 1
+		if count > maxLightProofBlocks {
+			return nil, fmt.Errorf("account proof of %s is too long: %d", req.Hash, count)
+		}
+	}
+
+	// high to low
+	blocks, err := s.chain.GetAccountBlocksByHeight(req.Address, req.Head.Height, count)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(blocks)) != count || blocks[0].Hash != req.Head.Hash {
+		return nil, fmt.Errorf("missing account blocks of %s before %s/%d", req.Address, req.Head.Hash, req.Head.Height)
+	}
+	proof.Blocks = make([]*ledger.AccountBlock, len(blocks))
+	for i, block := range blocks {
+		proof.Blocks[len(blocks)-1-i] = block
+	}
+
+	if req.TokenId != types.ZERO_TOKENID {
+		var balances map[types.Address]*big.Int
+		if balances, err = s.chain.GetConfirmedBalanceList([]types.Address{req.Address}, req.TokenId, req.Snapshot); err != nil {
+			return nil, err
+		}
+		if proof.Balance = balances[req.Address]; proof.Balance == nil {
+			proof.Balance = big.NewInt(0)
+		}
+	}
+
+	return proof, nil
+}
+
+func (s *lightServer) getRoundMembers(index uint64) (*message.RoundMembers, error) {
+	events, index, err := s.consensus.ReadByIndex(types.SNAPSHOT_GID, index)
+	if err != nil {
+		return nil, err
+	}
+
+	// events are the plans, members are in order of the first plan
+	members := &message.RoundMembers{Index: index}
+	var set = make(map[types.Address]struct{})
+	for _, e := range events {
+		if _, ok := set[e.Address]; !ok {
+			set[e.Address] = struct{}{}
+			members.Members = append(members.Members, e.Address)
+		}
+	}
+
+	return members, nil
+}
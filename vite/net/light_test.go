@@ -0,0 +1,345 @@
+package net
+
+import (
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/consensus"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/p2p"
+	"github.com/vitelabs/go-vite/p2p/vnode"
+)
+
+type lightTestChain struct {
+	snapshots []*ledger.SnapshotBlock // index is height-1
+	accounts  map[types.Address][]*ledger.AccountBlock
+	members   []types.Address
+	keys      []ed25519.PrivateKey // keys of members
+}
+
+// produce append a snapshot block one second after the latest, it is signed by the planned producer
+func (c *lightTestChain) produce(content ledger.SnapshotContent) *ledger.SnapshotBlock {
+	prev := c.snapshots[len(c.snapshots)-1]
+	timestamp := prev.Timestamp.Add(time.Second)
+	block := &ledger.SnapshotBlock{
+		PrevHash:        prev.Hash,
+		Height:          prev.Height + 1,
+		Timestamp:       &timestamp,
+		SnapshotContent: content,
+	}
+	key := c.keys[len(c.snapshots)%len(c.keys)]
+	block.PublicKey = key.PubByte()
+	block.Hash = block.ComputeHash()
+	block.Signature = ed25519.Sign(key, block.Hash.Bytes())
+	c.snapshots = append(c.snapshots, block)
+	return block
+}
+
+func (c *lightTestChain) GetSnapshotBlocksByHeight(height uint64, higher bool, count uint64) (blocks []*ledger.SnapshotBlock, err error) {
+	for h := height; h < height+count && h <= uint64(len(c.snapshots)); h++ {
+		blocks = append(blocks, c.snapshots[h-1])
+	}
+	return
+}
+
+func (c *lightTestChain) GetAccountBlockByHash(hash types.Hash) (*ledger.AccountBlock, error) {
+	for _, blocks := range c.accounts {
+		for _, block := range blocks {
+			if block.Hash == hash {
+				return block, nil
+			}
+		}
+	}
+	return nil, nil
+}
+
+func (c *lightTestChain) GetAccountBlocksByHeight(addr types.Address, height uint64, count uint64) (blocks []*ledger.AccountBlock, err error) {
+	for h := height; h > 0 && height-h < count; h-- {
+		blocks = append(blocks, c.accounts[addr][h-1])
+	}
+	return
+}
+
+func (c *lightTestChain) GetConfirmedBalanceList(addrList []types.Address, tokenId types.TokenTypeId, sbHash types.Hash) (map[types.Address]*big.Int, error) {
+	return map[types.Address]*big.Int{addrList[0]: big.NewInt(100)}, nil
+}
+
+func (c *lightTestChain) ReadByIndex(gid types.Gid, index uint64) ([]*consensus.Event, uint64, error) {
+	var events []*consensus.Event
+	for _, addr := range c.members {
+		events = append(events, &consensus.Event{Gid: gid, Address: addr})
+	}
+	return events, index, nil
+}
+
+// lightTestPeer deliver the messages to the SubProtocol of the other side
+type lightTestPeer struct {
+	*mockPeer
+	remote p2p.SubProtocol
+	back   *lightTestPeer
+	closed error
+}
+
+func (p *lightTestPeer) WriteMsg(msg p2p.Msg) error {
+	msg.Sender = p.back
+	go p.remote.Handle(msg)
+	return nil
+}
+
+func (p *lightTestPeer) Close(err error) error {
+	p.closed = err
+	return nil
+}
+
+func newLightTestChain(t *testing.T) (*lightTestChain, types.ConsensusGroupInfo, types.Address) {
+	group := types.ConsensusGroupInfo{
+		Gid:       types.SNAPSHOT_GID,
+		NodeCount: 3,
+		Interval:  1,
+		PerCount:  1,
+		Repeat:    1,
+	}
+
+	chain := &lightTestChain{
+		accounts: make(map[types.Address][]*ledger.AccountBlock),
+	}
+	for i := 0; i < 3; i++ {
+		addr, key, err := types.CreateAddress()
+		if err != nil {
+			t.Fatal(err)
+		}
+		chain.keys = append(chain.keys, key)
+		chain.members = append(chain.members, addr)
+	}
+
+	// account chain, the genesis block is not signed
+	addr, key, _ := types.CreateAddress()
+	var prev types.Hash
+	for h := uint64(1); h <= 3; h++ {
+		block := &ledger.AccountBlock{
+			BlockType:      ledger.BlockTypeReceive,
+			PrevHash:       prev,
+			Height:         h,
+			AccountAddress: addr,
+		}
+		block.FromBlockHash[0] = byte(h)
+		block.Hash = block.ComputeHash()
+		if h > 1 {
+			block.PublicKey = key.PubByte()
+			block.Signature = ed25519.Sign(key, block.Hash.Bytes())
+		}
+		chain.accounts[addr] = append(chain.accounts[addr], block)
+		prev = block.Hash
+	}
+
+	genesisTime := time.Unix(1558411200, 0)
+	genesis := &ledger.SnapshotBlock{
+		Height:    1,
+		Timestamp: &genesisTime,
+		SnapshotContent: ledger.SnapshotContent{
+			addr: {Height: 1, Hash: chain.accounts[addr][0].Hash},
+		},
+	}
+	genesis.Hash = genesis.ComputeHash()
+	chain.snapshots = append(chain.snapshots, genesis)
+
+	// one block per second, the producer is planned by consensus/core
+	for i := 1; i <= 6; i++ {
+		content := ledger.SnapshotContent{}
+		if i == 3 {
+			content[addr] = &ledger.HashHeight{Height: 3, Hash: chain.accounts[addr][2].Hash}
+		}
+		chain.produce(content)
+	}
+
+	return chain, group, addr
+}
+
+func newLightTestPair(t *testing.T, chain *lightTestChain, group types.ConsensusGroupInfo) (*LightClient, *lightTestPeer) {
+	client, err := NewLightClient(LightConfig{
+		Genesis: chain.snapshots[0],
+		Group:   group,
+		Quorum:  1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var serverID, clientID vnode.NodeID
+	serverID[0], clientID[0] = 1, 2
+	toServer := &lightTestPeer{mockPeer: newMockPeer(serverID, uint64(len(chain.snapshots))), remote: NewLightServer(chain, chain)}
+	toClient := &lightTestPeer{mockPeer: newMockPeer(clientID, 1), remote: client.Sub()}
+	toServer.back, toClient.back = toClient, toServer
+
+	if err = client.Sub().OnPeerAdded(toServer); err != nil {
+		t.Fatal(err)
+	}
+
+	return client, toServer
+}
+
+func TestLightClient(t *testing.T) {
+	chain, group, addr := newLightTestChain(t)
+	client, server := newLightTestPair(t, chain, group)
+
+	client.follow()
+	if server.closed != nil {
+		t.Fatalf("server should not be closed: %v", server.closed)
+	}
+	if latest := client.Latest(); latest.Hash != chain.snapshots[6].Hash {
+		t.Fatalf("should follow to %d, not %d", chain.snapshots[6].Height, latest.Height)
+	}
+
+	head, snapshot, err := client.GetAccountHead(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Height != 3 || snapshot != chain.snapshots[3].Hash {
+		t.Fatalf("wrong head %d confirmed by %s", head.Height, snapshot)
+	}
+
+	block, err := client.GetAccountBlockByHash(chain.accounts[addr][0].Hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if block.Hash != chain.accounts[addr][0].Hash {
+		t.Fatalf("wrong block %s", block.Hash)
+	}
+
+	balance, _, err := client.GetQuorumBalance(addr, ledger.ViteTokenId)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance.Cmp(big.NewInt(100)) != 0 {
+		t.Fatalf("wrong balance %s", balance)
+	}
+
+	// a block is not linked to the confirmed head
+	chain.accounts[addr][1].PrevHash[0]++
+	if _, err = client.GetAccountBlockByHash(chain.accounts[addr][0].Hash); err == nil {
+		t.Fatal("broken proof should be rejected")
+	}
+	if server.closed == nil {
+		t.Fatal("server responds invalid proof should be closed")
+	}
+}
+
+func TestLightClient_Reorg(t *testing.T) {
+	chain, group, addr := newLightTestChain(t)
+	client, server := newLightTestPair(t, chain, group)
+
+	client.follow()
+	if latest := client.Latest(); latest.Hash != chain.snapshots[6].Hash {
+		t.Fatalf("should follow to %d, not %d", chain.snapshots[6].Height, latest.Height)
+	}
+
+	// the server switches to a longer fork from height 4, which doesn`t confirm the account
+	chain.snapshots = chain.snapshots[:3]
+	for i := 0; i < 5; i++ {
+		chain.produce(ledger.SnapshotContent{})
+	}
+	server.height = uint64(len(chain.snapshots))
+
+	client.follow()
+	if server.closed != nil {
+		t.Fatalf("server on a valid fork should not be closed: %v", server.closed)
+	}
+	if latest := client.Latest(); latest.Hash != chain.snapshots[7].Hash {
+		t.Fatalf("should follow the fork to %d, not %d", chain.snapshots[7].Height, latest.Height)
+	}
+	for _, block := range chain.snapshots {
+		if client.GetSnapshotBlockByHeight(block.Height).Hash != block.Hash {
+			t.Fatalf("header %d is not the one of the fork", block.Height)
+		}
+	}
+
+	// the head confirmed by the rolled back header is restored to the genesis
+	head, snapshot, err := client.GetAccountHead(addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if head.Height != 1 || snapshot != chain.snapshots[0].Hash {
+		t.Fatalf("wrong head %d confirmed by %s", head.Height, snapshot)
+	}
+
+	// the fork from height 6 is signed by a key out of the plan, the headers followed are kept
+	chain.snapshots = chain.snapshots[:5]
+	chain.produce(ledger.SnapshotContent{addr: &ledger.HashHeight{Height: 1, Hash: chain.accounts[addr][0].Hash}})
+	block := chain.produce(ledger.SnapshotContent{})
+	chain.produce(ledger.SnapshotContent{})
+	chain.produce(ledger.SnapshotContent{})
+	_, key, _ := types.CreateAddress()
+	block.PublicKey = key.PubByte()
+	block.Signature = ed25519.Sign(key, block.Hash.Bytes())
+	server.height = uint64(len(chain.snapshots))
+	latest := client.Latest()
+
+	client.follow()
+	if server.closed != p2p.PeerInvalidBlock {
+		t.Fatalf("server on an invalid fork should be closed by invalid block, not %v", server.closed)
+	}
+	if client.Latest().Hash != latest.Hash {
+		t.Fatalf("the headers should not be rolled back for an invalid fork")
+	}
+}
+
+func TestLightClient_InvalidProducer(t *testing.T) {
+	chain, group, _ := newLightTestChain(t)
+
+	// the block of height 5 is signed by a key out of the plan
+	block := chain.snapshots[4]
+	_, key, _ := types.CreateAddress()
+	block.PublicKey = key.PubByte()
+	block.Signature = ed25519.Sign(key, block.Hash.Bytes())
+
+	client, server := newLightTestPair(t, chain, group)
+	client.follow()
+
+	if server.closed != p2p.PeerInvalidBlock {
+		t.Fatalf("server should be closed by invalid block, not %v", server.closed)
+	}
+	if latest := client.Latest(); latest.Height != 4 {
+		t.Fatalf("only the headers before the invalid one should be accepted, but latest is %d", latest.Height)
+	}
+}
+
+func TestLightClient_NoQuorum(t *testing.T) {
+	chain, group, _ := newLightTestChain(t)
+	client, _ := newLightTestPair(t, chain, group)
+
+	// the members of every round should be responded by 2 servers
+	client.Quorum = 2
+	client.follow()
+	if latest := client.Latest(); latest.Height != 1 {
+		t.Fatalf("should not follow without a quorum, but latest is %d", latest.Height)
+	}
+}
+
+func TestLightSchedule_AcceptQuorum(t *testing.T) {
+	s := newLightSchedule(time.Unix(1558411200, 0), types.ConsensusGroupInfo{NodeCount: 3, Interval: 1, PerCount: 1, Repeat: 1})
+
+	var members []types.Address
+	for i := 0; i < 4; i++ {
+		addr, _, _ := types.CreateAddress()
+		members = append(members, addr)
+	}
+
+	if err := s.acceptQuorum(1, []types.Address{members[0], members[0]}); err == nil {
+		t.Error("duplicated members should be rejected")
+	}
+	if err := s.acceptQuorum(1, members); err == nil {
+		t.Error("members more than NodeCount should be rejected")
+	}
+	if err := s.acceptQuorum(1, members[:3]); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.acceptQuorum(2, []types.Address{members[0], members[3]}); err == nil {
+		t.Error("members changed too much should be rejected")
+	}
+	if err := s.acceptQuorum(2, []types.Address{members[0], members[1], members[3]}); err != nil {
+		t.Error(err)
+	}
+}
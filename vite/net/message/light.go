@@ -0,0 +1,168 @@
+package message
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vitepb"
+)
+
+// @section GetAccountProof
+
+// GetAccountProof request the account blocks link the block of Hash to the account head,
+// if Head is empty, only the block of Hash will be responded.
+// If TokenId and Snapshot is not empty, the balance confirmed by Snapshot will be responded too.
+type GetAccountProof struct {
+	Address  types.Address
+	Head     ledger.HashHeight
+	Hash     types.Hash
+	TokenId  types.TokenTypeId
+	Snapshot types.Hash
+}
+
+func (b *GetAccountProof) String() string {
+	return "GetAccountProof<" + b.Address.String() + "/" + b.Head.Hash.String() + "/" + strconv.FormatUint(b.Head.Height, 10) + "/" + b.Hash.String() + ">"
+}
+
+func (b *GetAccountProof) Serialize() ([]byte, error) {
+	pb := &vitepb.GetAccountProof{
+		Address: b.Address.Bytes(),
+		Head:    b.Head.Proto(),
+		Hash:    b.Hash.Bytes(),
+	}
+	if b.TokenId != types.ZERO_TOKENID {
+		pb.TokenId = b.TokenId.Bytes()
+		pb.Snapshot = b.Snapshot.Bytes()
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (b *GetAccountProof) Deserialize(buf []byte) (err error) {
+	pb := new(vitepb.GetAccountProof)
+
+	if err = proto.Unmarshal(buf, pb); err != nil {
+		return
+	}
+
+	if pb.Head == nil {
+		return errDeserialize
+	}
+
+	if b.Address, err = types.BytesToAddress(pb.Address); err != nil {
+		return
+	}
+	if err = b.Head.DeProto(pb.Head); err != nil {
+		return
+	}
+	if b.Hash, err = types.BytesToHash(pb.Hash); err != nil {
+		return
+	}
+	if len(pb.TokenId) != 0 {
+		if b.TokenId, err = types.BytesToTokenTypeId(pb.TokenId); err != nil {
+			return
+		}
+		if b.Snapshot, err = types.BytesToHash(pb.Snapshot); err != nil {
+			return
+		}
+	}
+
+	return nil
+}
+
+// AccountProof is the account blocks from low to high, and the balance if requested.
+// The blocks prove themselves by the confirmed head, the balance is not proved.
+type AccountProof struct {
+	Blocks  []*ledger.AccountBlock
+	Balance *big.Int
+}
+
+func (a *AccountProof) String() string {
+	return "AccountProof<" + strconv.FormatInt(int64(len(a.Blocks)), 10) + ">"
+}
+
+func (a *AccountProof) Serialize() ([]byte, error) {
+	pb := new(vitepb.AccountProof)
+
+	pb.Blocks = make([]*vitepb.AccountBlock, len(a.Blocks))
+	for i, block := range a.Blocks {
+		pb.Blocks[i] = block.Proto()
+	}
+
+	if a.Balance != nil {
+		pb.Balance = a.Balance.Bytes()
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (a *AccountProof) Deserialize(buf []byte) error {
+	pb := new(vitepb.AccountProof)
+
+	err := proto.Unmarshal(buf, pb)
+	if err != nil {
+		return err
+	}
+
+	a.Blocks = make([]*ledger.AccountBlock, len(pb.Blocks))
+	for i, bp := range pb.Blocks {
+		if bp == nil {
+			return errDeserialize
+		}
+
+		block := new(ledger.AccountBlock)
+		if err = block.DeProto(bp); err != nil {
+			return err
+		}
+		a.Blocks[i] = block
+	}
+
+	if pb.Balance != nil {
+		a.Balance = new(big.Int).SetBytes(pb.Balance)
+	}
+
+	return nil
+}
+
+// RoundMembers is the snapshot producers of a consensus round in order, request with the Index only
+type RoundMembers struct {
+	Index   uint64
+	Members []types.Address
+}
+
+func (r *RoundMembers) String() string {
+	return "RoundMembers<" + strconv.FormatUint(r.Index, 10) + "/" + strconv.Itoa(len(r.Members)) + ">"
+}
+
+func (r *RoundMembers) Serialize() ([]byte, error) {
+	pb := &vitepb.RoundMembers{
+		Index:   r.Index,
+		Members: make([][]byte, len(r.Members)),
+	}
+	for i, addr := range r.Members {
+		pb.Members[i] = addr.Bytes()
+	}
+
+	return proto.Marshal(pb)
+}
+
+func (r *RoundMembers) Deserialize(buf []byte) (err error) {
+	pb := new(vitepb.RoundMembers)
+
+	if err = proto.Unmarshal(buf, pb); err != nil {
+		return
+	}
+
+	r.Index = pb.Index
+	r.Members = make([]types.Address, len(pb.Members))
+	for i, addr := range pb.Members {
+		if r.Members[i], err = types.BytesToAddress(addr); err != nil {
+			return
+		}
+	}
+
+	return nil
+}
@@ -519,6 +519,7 @@ func (s stateHandler) handle(msg p2p.Msg, sender Peer) (err error) {
 	}
 
 	sender.SetHead(head, heartbeat.Height)
+	sender.setPrunedHeight(heartbeat.PrunedHeight)
 
 	if p := s.peers.get(sender.ID()); p != nil {
 		// max 100 neighbors
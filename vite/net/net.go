@@ -32,6 +32,11 @@ type Config struct {
 	AccessDenyKeys     []string `json:"AccessDenyKeys"`
 	BlackBlockHashList []string
 
+	// Archive node keeps the full history of ledger, and serves the old chunks to pruned peers
+	Archive bool
+	// ArchiveRateLimit is the max bytes per second sent to every peer in archive mode
+	ArchiveRateLimit int64
+
 	MinePrivateKey ed25519.PrivateKey
 	P2PPrivateKey  ed25519.PrivateKey
 	Chain
@@ -40,6 +45,7 @@ type Config struct {
 
 const DefaultForwardStrategy = "cross"
 const DefaultFilePort = 8484
+const DefaultArchiveRateLimit = 4 * 1024 * 1024
 const ID = 1
 const maxNeighbors = 100
 
@@ -53,16 +59,17 @@ type net struct {
 	reader     *cacheReader
 	downloader syncDownloader
 	BlockSubscriber
-	server    *syncServer
-	handlers  *msgHandlers
-	query     *queryHandler
-	hb        *heartBeater
-	running   int32
-	log       log15.Logger
-	tracer    Tracer
-	sn        *sbpn
-	consensus Consensus
-	p2p       p2p.P2P
+	server     *syncServer
+	handlers   *msgHandlers
+	query      *queryHandler
+	hb         *heartBeater
+	running    int32
+	log        log15.Logger
+	tracer     Tracer
+	sn         *sbpn
+	consensus  Consensus
+	p2p        p2p.P2P
+	reputation p2p.Reputation
 }
 
 func (n *net) ProtoData() (height uint64, head types.Hash, genesis types.Hash) {
@@ -138,6 +145,7 @@ func (n *net) SetState(state []byte, peer p2p.Peer) {
 		}
 
 		p.SetHead(head, heartbeat.Height)
+		p.setPrunedHeight(heartbeat.PrunedHeight)
 
 		// max 100 neighbors
 		var count = len(heartbeat.Peers)
@@ -157,7 +165,7 @@ func (n *net) SetState(state []byte, peer p2p.Peer) {
 }
 
 func (n *net) OnPeerAdded(peer p2p.Peer) (err error) {
-	p := newPeer(peer, netLog.New("peer", peer.ID()))
+	p := newPeer(peer, n.reputation, netLog.New("peer", peer.ID()))
 
 	err = n.peers.add(p)
 	if err != nil {
@@ -213,7 +221,10 @@ func New(cfg Config) Net {
 		mineKey: cfg.MinePrivateKey,
 	}
 	downloader := newExecutor(50, 10, peers, syncConnFac)
-	syncServer := newSyncServer(cfg.FileListenAddress, cfg.Chain, syncConnFac)
+	if cfg.Archive && cfg.ArchiveRateLimit == 0 {
+		cfg.ArchiveRateLimit = DefaultArchiveRateLimit
+	}
+	syncServer := newSyncServer(cfg.FileListenAddress, cfg.Chain, syncConnFac, cfg.Archive, cfg.ArchiveRateLimit)
 
 	reader := newCacheReader(cfg.Chain, cfg.Verifier, downloader)
 	reader.setBlackHashList(cfg.BlackBlockHashList)
@@ -290,14 +301,19 @@ func New(cfg Config) Net {
 	return n
 }
 
+type heartBeaterChain interface {
+	chainReader
+	prunedReader
+}
+
 type heartBeater struct {
-	chain     chainReader
+	chain     heartBeaterChain
 	last      time.Time
 	lastPeers map[peerId]struct{}
 	ps        *peerSet
 }
 
-func newHeartBeater(ps *peerSet, chain chainReader) *heartBeater {
+func newHeartBeater(ps *peerSet, chain heartBeaterChain) *heartBeater {
 	return &heartBeater{
 		chain:     chain,
 		lastPeers: make(map[peerId]struct{}),
@@ -309,11 +325,12 @@ func (h *heartBeater) state() []byte {
 	current := h.chain.GetLatestSnapshotBlock()
 
 	var heartBeat = &protos.State{
-		Peers:     nil,
-		Patch:     true,
-		Head:      current.Hash.Bytes(),
-		Height:    current.Height,
-		Timestamp: time.Now().Unix(),
+		Peers:        nil,
+		Patch:        true,
+		Head:         current.Hash.Bytes(),
+		Height:       current.Height,
+		PrunedHeight: h.chain.GetPrunedSnapshotHeight(),
+		Timestamp:    time.Now().Unix(),
 	}
 
 	idMap := h.ps.idMap()
@@ -367,6 +384,7 @@ func (n *net) Start(svr p2p.P2P) (err error) {
 	if atomic.CompareAndSwapInt32(&n.running, 0, 1) {
 		n.nodeID = svr.Config().Node().ID
 		n.p2p = svr
+		n.reputation = svr.Reputation()
 
 		if err = n.server.start(); err != nil {
 			return
@@ -4,7 +4,9 @@ import (
 	"errors"
 	"sort"
 	"sync"
+	"sync/atomic"
 
+	"github.com/hashicorp/golang-lru"
 	"github.com/vitelabs/go-vite/vite/net/message"
 
 	"github.com/vitelabs/go-vite/p2p/vnode"
@@ -15,6 +17,9 @@ import (
 	"github.com/vitelabs/go-vite/p2p"
 )
 
+// the recent blocks broadcast by a peer are remembered exactly, to find the duplicated broadcasts
+const receivedBlocksCap = 10000
+
 var errPeerExisted = errors.New("peer has existed")
 var errPeerNotExist = errors.New("peer not exist")
 
@@ -31,17 +36,26 @@ type Peer interface {
 	setPeers(ps []peerConn, patch bool)
 	peers() map[peerId]struct{}
 	seeBlock(hash types.Hash) bool
+	// receiveBlock record the block broadcast by the peer, return true if the peer has broadcast it before
+	receiveBlock(hash types.Hash) bool
 	catch(err error)
 	send(c p2p.Code, id p2p.MsgId, data p2p.Serializable) error
 	sendSnapshotBlocks(bs []*ledger.SnapshotBlock, msgId p2p.MsgId) error
 	sendAccountBlocks(bs []*ledger.AccountBlock, msgId p2p.MsgId) error
 	info() PeerInfo
+	// the account blocks confirmed by the snapshot blocks not higher than prunedHeight are pruned by the peer
+	setPrunedHeight(height uint64)
+	prunedHeight() uint64
+	// report the behavior of peer to the reputation of p2p, the peer maybe banned
+	report(b p2p.Behavior)
+	score() int64
 }
 
 // PeerInfo is for api
 type PeerInfo struct {
 	p2p.PeerInfo
 	Peers []string `json:"peers"`
+	Score int64    `json:"score"`
 }
 
 type peer struct {
@@ -49,12 +63,17 @@ type peer struct {
 
 	_head types.Hash
 
+	_prunedHeight uint64
+
 	m  map[peerId]struct{}
 	m2 map[peerId]struct{} // MUST NOT write m2, only read, for cross peers
 
-	knownBlocks blockFilter
-	errChan     chan error
-	once        sync.Once
+	knownBlocks    blockFilter // sent to or received from the peer, has false positives
+	receivedBlocks *lru.Cache  // received from the peer
+	errChan        chan error
+	once           sync.Once
+
+	reputation p2p.Reputation
 
 	log log15.Logger
 }
@@ -79,17 +98,43 @@ func (p *peer) info() PeerInfo {
 	return PeerInfo{
 		PeerInfo: p.Info(),
 		Peers:    ps,
+		Score:    p.score(),
 	}
 }
 
-func newPeer(p p2p.Peer, log log15.Logger) Peer {
+func newPeer(p p2p.Peer, reputation p2p.Reputation, log log15.Logger) Peer {
+	receivedBlocks, _ := lru.New(receivedBlocksCap)
+
 	return &peer{
-		Peer:        p,
-		knownBlocks: newBlockFilter(filterCap),
-		m:           make(map[peerId]struct{}),
-		log:         log,
-		errChan:     make(chan error, 1),
+		Peer:           p,
+		knownBlocks:    newBlockFilter(filterCap),
+		receivedBlocks: receivedBlocks,
+		m:              make(map[peerId]struct{}),
+		reputation:     reputation,
+		log:            log,
+		errChan:        make(chan error, 1),
+	}
+}
+
+func (p *peer) report(b p2p.Behavior) {
+	if p.reputation != nil {
+		p.reputation.Report(p.ID(), b)
+	}
+}
+
+func (p *peer) score() int64 {
+	if p.reputation != nil {
+		return p.reputation.Score(p.ID())
 	}
+	return 0
+}
+
+func (p *peer) setPrunedHeight(height uint64) {
+	atomic.StoreUint64(&p._prunedHeight, height)
+}
+
+func (p *peer) prunedHeight() uint64 {
+	return atomic.LoadUint64(&p._prunedHeight)
 }
 
 func (p *peer) catch(err error) {
@@ -135,6 +180,11 @@ func (p *peer) seeBlock(hash types.Hash) (existed bool) {
 	return p.knownBlocks.lookAndRecord(hash[:])
 }
 
+func (p *peer) receiveBlock(hash types.Hash) (received bool) {
+	received, _ = p.receivedBlocks.ContainsOrAdd(hash, struct{}{})
+	return
+}
+
 func (p *peer) send(c p2p.Code, id p2p.MsgId, data p2p.Serializable) error {
 	buf, err := data.Serialize()
 	if err != nil {
@@ -186,15 +236,15 @@ type peerSet struct {
 	subs []chan<- peerEvent
 }
 
-// pickDownloadPeers implement downloadPeerSet
-func (m *peerSet) pickDownloadPeers(height uint64) (m2 map[peerId]Peer) {
+// pickDownloadPeers implement downloadPeerSet, the peers have all blocks in [from, to]
+func (m *peerSet) pickDownloadPeers(from, to uint64) (m2 map[peerId]Peer) {
 	m2 = make(map[peerId]Peer)
 
 	m.prw.RLock()
 	defer m.prw.RUnlock()
 
 	for id, p := range m.m {
-		if p.Height() >= height {
+		if p.Height() >= to && p.prunedHeight() < from {
 			m2[id] = p
 		}
 	}
@@ -202,6 +252,20 @@ func (m *peerSet) pickDownloadPeers(height uint64) (m2 map[peerId]Peer) {
 	return
 }
 
+// maxPrunedHeight return the highest pruned height of all peers
+func (m *peerSet) maxPrunedHeight() (height uint64) {
+	m.prw.RLock()
+	defer m.prw.RUnlock()
+
+	for _, p := range m.m {
+		if pruned := p.prunedHeight(); pruned > height {
+			height = pruned
+		}
+	}
+
+	return
+}
+
 func newPeerSet() *peerSet {
 	return &peerSet{
 		m: make(map[peerId]Peer),
@@ -342,18 +406,21 @@ func (m *peerSet) sortPeers() (l peers) {
 	return
 }
 
-// broadcastPeers return all peers unsorted.
+// broadcastPeers return all peers sort by score from high to low, so the forward strategy prefer the good peers.
 // for broadcaster, use interface `[]broadcastPeer` other than `[]Peer` let easier to mock.
 func (m *peerSet) broadcastPeers() (l []broadcastPeer) {
 	m.prw.RLock()
-	defer m.prw.RUnlock()
+	ps := make(peersByScore, 0, len(m.m))
+	for _, p := range m.m {
+		ps = append(ps, scoredPeer{p, p.score()})
+	}
+	m.prw.RUnlock()
 
-	l = make([]broadcastPeer, len(m.m))
+	sort.Sort(ps)
 
-	i := 0
-	for _, p := range m.m {
-		l[i] = p
-		i++
+	l = make([]broadcastPeer, len(ps))
+	for i, sp := range ps {
+		l[i] = sp.Peer
 	}
 
 	return
@@ -441,3 +508,23 @@ func (s peers) delete(id peerId) peers {
 
 	return s
 }
+
+type scoredPeer struct {
+	Peer
+	_score int64
+}
+
+// peersByScore can be sort by score, from high to low
+type peersByScore []scoredPeer
+
+func (s peersByScore) Len() int {
+	return len(s)
+}
+
+func (s peersByScore) Less(i, j int) bool {
+	return s[i]._score > s[j]._score
+}
+
+func (s peersByScore) Swap(i, j int) {
+	s[i], s[j] = s[j], s[i]
+}
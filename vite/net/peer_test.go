@@ -17,7 +17,11 @@ import (
 type mockPeer struct {
 	id      vnode.NodeID
 	height  uint64
+	pruned  uint64
+	archive bool
 	peerMap map[vnode.NodeID]struct{}
+	reports []p2p.Behavior
+	_score  int64
 }
 
 func (mp *mockPeer) Disconnect(err error) {
@@ -48,6 +52,14 @@ func (mp *mockPeer) FileAddress() string {
 	panic("implement me")
 }
 
+func (mp *mockPeer) Archive() bool {
+	return mp.archive
+}
+
+func (mp *mockPeer) Caps() []p2p.Cap {
+	return nil
+}
+
 func (mp *mockPeer) send(c p2p.Code, id p2p.MsgId, data p2p.Serializable) error {
 	return nil
 }
@@ -131,6 +143,18 @@ func (mp *mockPeer) seeBlock(hash types.Hash) bool {
 	panic("implement me")
 }
 
+func (mp *mockPeer) receiveBlock(hash types.Hash) bool {
+	panic("implement me")
+}
+
+func (mp *mockPeer) report(b p2p.Behavior) {
+	mp.reports = append(mp.reports, b)
+}
+
+func (mp *mockPeer) score() int64 {
+	return mp._score
+}
+
 func (mp *mockPeer) catch(err error) {
 	panic("implement me")
 }
@@ -147,6 +171,14 @@ func (mp *mockPeer) info() PeerInfo {
 	panic("implement me")
 }
 
+func (mp *mockPeer) setPrunedHeight(height uint64) {
+	mp.pruned = height
+}
+
+func (mp *mockPeer) prunedHeight() uint64 {
+	return mp.pruned
+}
+
 func TestPeerSet_Add(t *testing.T) {
 	var m = newPeerSet()
 	var p = newMockPeer(vnode.RandomNodeID(), 1)
@@ -255,6 +287,93 @@ func TestPeerSet_Pick(t *testing.T) {
 	}
 }
 
+func TestPeerSet_PickDownloadPeers(t *testing.T) {
+	var m = newPeerSet()
+
+	full := newMockPeer(vnode.RandomNodeID(), 100)
+	pruned := newMockPeer(vnode.RandomNodeID(), 100)
+	pruned.setPrunedHeight(50)
+	low := newMockPeer(vnode.RandomNodeID(), 10)
+
+	for _, p := range []*mockPeer{full, pruned, low} {
+		if m.add(p) != nil {
+			t.Fail()
+		}
+	}
+
+	ps := m.pickDownloadPeers(20, 80)
+	if len(ps) != 1 {
+		t.Errorf("wrong peer number: %d", len(ps))
+	}
+	if _, ok := ps[full.ID()]; !ok {
+		t.Errorf("full peer should be picked")
+	}
+
+	ps = m.pickDownloadPeers(51, 80)
+	if len(ps) != 2 {
+		t.Errorf("wrong peer number: %d", len(ps))
+	}
+	if _, ok := ps[low.ID()]; ok {
+		t.Errorf("low peer should not be picked")
+	}
+}
+
+func TestPeerSet_BroadcastPeers(t *testing.T) {
+	var m = newPeerSet()
+
+	for _, score := range []int64{-10, 30, 0, 20} {
+		p := newMockPeer(vnode.RandomNodeID(), 1)
+		p._score = score
+		if m.add(p) != nil {
+			t.Fail()
+		}
+	}
+
+	var scores []int64
+	for _, p := range m.broadcastPeers() {
+		scores = append(scores, p.(*mockPeer)._score)
+	}
+	if fmt.Sprint(scores) != "[30 20 0 -10]" {
+		t.Errorf("peers should be sorted by score: %v", scores)
+	}
+}
+
+func TestBestScoredPeer(t *testing.T) {
+	var peerMap = make(map[peerId]Peer)
+	if bestScoredPeer(peerMap) != nil {
+		t.Error("no peers")
+	}
+
+	bad := newMockPeer(vnode.RandomNodeID(), 1)
+	bad._score = -20
+	peerMap[bad.ID()] = bad
+	if bestScoredPeer(peerMap) != bad {
+		t.Error("the only peer should be chosen")
+	}
+
+	good := newMockPeer(vnode.RandomNodeID(), 1)
+	peerMap[good.ID()] = good
+	if bestScoredPeer(peerMap) != good {
+		t.Error("the peer has higher score should be chosen")
+	}
+}
+
+func TestPeer_ReceiveBlock(t *testing.T) {
+	p := newPeer(newMockPeer(vnode.RandomNodeID(), 1), nil, netLog)
+
+	var hash types.Hash
+	hash[0] = 1
+
+	// sent to the peer, but not received from it
+	p.seeBlock(hash)
+	if p.receiveBlock(hash) {
+		t.Error("the block sent to the peer should not be a duplicated broadcast")
+	}
+	if !p.receiveBlock(hash) {
+		t.Error("the block broadcast twice should be a duplicated broadcast")
+	}
+}
+
 func ExamplePeerSet_Get() {
 	var m1 = newPeerSet()
 	var p1 = m1.get(vnode.ZERO)
@@ -50,6 +50,7 @@ type State struct {
 	Patch                bool          `protobuf:"varint,2,opt,name=Patch,proto3" json:"Patch,omitempty"`
 	Head                 []byte        `protobuf:"bytes,3,opt,name=Head,proto3" json:"Head,omitempty"`
 	Height               uint64        `protobuf:"varint,4,opt,name=Height,proto3" json:"Height,omitempty"`
+	PrunedHeight         uint64        `protobuf:"varint,5,opt,name=PrunedHeight,proto3" json:"PrunedHeight,omitempty"`
 	Timestamp            int64         `protobuf:"varint,10,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
 	XXX_NoUnkeyedLiteral struct{}      `json:"-"`
 	XXX_unrecognized     []byte        `json:"-"`
@@ -109,6 +110,13 @@ func (m *State) GetHeight() uint64 {
 	return 0
 }
 
+func (m *State) GetPrunedHeight() uint64 {
+	if m != nil {
+		return m.PrunedHeight
+	}
+	return 0
+}
+
 func (m *State) GetTimestamp() int64 {
 	if m != nil {
 		return m.Timestamp
@@ -172,20 +180,20 @@ func init() {
 func init() { proto.RegisterFile("vite_state.proto", fileDescriptor_9f340c7429a8059a) }
 
 var fileDescriptor_9f340c7429a8059a = []byte{
-	// 228 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x64, 0x90, 0x31, 0x4f, 0xc3, 0x30,
-	0x14, 0x84, 0x71, 0xe2, 0x44, 0xf4, 0x51, 0xaa, 0xe8, 0x09, 0x21, 0x0b, 0x31, 0x58, 0x9d, 0xbc,
-	0x10, 0xa1, 0xf2, 0x13, 0xc8, 0x90, 0x6e, 0x95, 0x61, 0x47, 0x26, 0x7d, 0xa2, 0x1e, 0xda, 0x54,
-	0xf5, 0x83, 0x1f, 0xc1, 0xaf, 0x46, 0xb6, 0x2b, 0x32, 0x74, 0xb2, 0xef, 0xbb, 0x93, 0xef, 0x64,
-	0x68, 0x7e, 0x3c, 0xd3, 0x47, 0x60, 0xc7, 0xd4, 0x1e, 0x4f, 0x23, 0x8f, 0x58, 0xa7, 0x23, 0x2c,
-	0x7f, 0x0b, 0xa8, 0xde, 0x22, 0x47, 0x03, 0xd5, 0x86, 0xe8, 0x14, 0x94, 0xd0, 0xa5, 0xb9, 0x59,
-	0x61, 0x0e, 0x86, 0x36, 0xb9, 0x6d, 0xb4, 0x6c, 0x0e, 0xe0, 0x1d, 0x54, 0x1b, 0xc7, 0xc3, 0x4e,
-	0x15, 0x5a, 0x98, 0x6b, 0x9b, 0x05, 0x22, 0xc8, 0x9e, 0xdc, 0x56, 0x95, 0x5a, 0x98, 0xb9, 0x4d,
-	0x77, 0xbc, 0x87, 0xba, 0x27, 0xff, 0xb5, 0x63, 0x25, 0xb5, 0x30, 0xd2, 0x9e, 0x15, 0x3e, 0xc2,
-	0xec, 0xdd, 0xef, 0x29, 0xb0, 0xdb, 0x1f, 0x15, 0x68, 0x61, 0x4a, 0x3b, 0x81, 0x87, 0x1e, 0x64,
-	0x2c, 0xc2, 0x05, 0x14, 0xeb, 0x4e, 0x89, 0xf4, 0x5e, 0xb1, 0xee, 0xf0, 0x19, 0xea, 0x38, 0xe6,
-	0x3b, 0xa4, 0x8e, 0xc5, 0x4a, 0x5d, 0x4e, 0xcc, 0xbe, 0x3d, 0xe7, 0x96, 0x4f, 0x00, 0x13, 0xc5,
-	0x5b, 0x98, 0xbd, 0x8e, 0x87, 0x03, 0x0d, 0x4c, 0xdb, 0xe6, 0x0a, 0x1b, 0x98, 0x77, 0x3e, 0x0c,
-	0xff, 0x44, 0x7c, 0xe6, 0x4f, 0x79, 0xf9, 0x0b, 0x00, 0x00, 0xff, 0xff, 0x3d, 0xe3, 0xcc, 0x65,
-	0x2f, 0x01, 0x00, 0x00,
+	// 233 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x65, 0x90, 0xcb, 0x8a, 0xc2, 0x40,
+	0x10, 0x45, 0xcd, 0x13, 0xad, 0x89, 0x12, 0x8a, 0x41, 0x1a, 0x71, 0x21, 0x59, 0x65, 0x33, 0x41,
+	0xf4, 0x13, 0x74, 0x91, 0xd9, 0x85, 0xd6, 0xfd, 0x90, 0x49, 0x8a, 0x31, 0x0b, 0x13, 0x49, 0xb7,
+	0xf3, 0x59, 0x7e, 0xa3, 0xfd, 0x08, 0x8a, 0xb8, 0xaa, 0xaa, 0x73, 0x6f, 0xd1, 0xb7, 0x0b, 0xe2,
+	0xff, 0x46, 0xd2, 0x8f, 0x90, 0xa5, 0xa4, 0xec, 0xd2, 0x77, 0xb2, 0xc3, 0xd0, 0x14, 0x91, 0xdc,
+	0x5c, 0x08, 0x0e, 0x9a, 0x63, 0x0a, 0x41, 0x41, 0xd4, 0x0b, 0xe6, 0xac, 0xbc, 0xf4, 0x63, 0x83,
+	0xd6, 0x28, 0x32, 0xa3, 0x66, 0x5a, 0xe2, 0xd6, 0x80, 0x9f, 0xca, 0x59, 0xca, 0xea, 0xc4, 0xdc,
+	0x95, 0x93, 0x8e, 0xb9, 0x1d, 0x10, 0xc1, 0xcf, 0xa9, 0xac, 0x99, 0xa7, 0x60, 0xc4, 0x4d, 0x8f,
+	0x73, 0x08, 0x73, 0x6a, 0xfe, 0x4e, 0x92, 0xf9, 0x8a, 0xfa, 0x7c, 0x98, 0x70, 0x09, 0x93, 0x63,
+	0x73, 0x26, 0x15, 0xe8, 0x7c, 0x61, 0xa0, 0x24, 0x8f, 0x3f, 0x01, 0x26, 0x10, 0x15, 0xfd, 0xb5,
+	0xa5, 0x7a, 0xd8, 0x0d, 0xcc, 0xee, 0x0b, 0x5b, 0xe4, 0xe0, 0xeb, 0x30, 0x38, 0x03, 0xf7, 0x7b,
+	0xaf, 0x22, 0xeb, 0x37, 0x55, 0x87, 0x6b, 0x08, 0x75, 0xe0, 0xab, 0x30, 0x39, 0x66, 0x1b, 0xf6,
+	0xfe, 0x0d, 0xab, 0xf3, 0xc1, 0x97, 0x7c, 0x01, 0x3c, 0x29, 0x4e, 0x61, 0xb2, 0xeb, 0xda, 0x96,
+	0x2a, 0x49, 0x75, 0x3c, 0xc2, 0x18, 0xa2, 0x7d, 0x23, 0xaa, 0x07, 0x71, 0x7e, 0xed, 0xe1, 0xb6,
+	0x77, 0x21, 0xcf, 0x88, 0xfc, 0x53, 0x01, 0x00, 0x00,
 }
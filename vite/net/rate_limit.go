@@ -0,0 +1,87 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package net
+
+import (
+	"io"
+	"time"
+)
+
+// rateLimitWriter limit the bytes written to w per second
+type rateLimitWriter struct {
+	w    io.Writer
+	rate int64 // bytes per second
+
+	start   time.Time
+	written int64
+}
+
+func newRateLimitWriter(w io.Writer, rate int64) *rateLimitWriter {
+	return &rateLimitWriter{
+		w:    w,
+		rate: rate,
+	}
+}
+
+func (r *rateLimitWriter) Write(p []byte) (n int, err error) {
+	// write at most 1/10 rate every time, so the traffic is smooth
+	batch := int(r.rate / 10)
+	if batch == 0 {
+		batch = 1
+	}
+
+	var nw int
+	for len(p) > 0 {
+		count := len(p)
+		if count > batch {
+			count = batch
+		}
+
+		r.wait(count)
+
+		nw, err = r.w.Write(p[:count])
+		n += nw
+		r.written += int64(nw)
+		if err != nil {
+			return
+		}
+
+		p = p[nw:]
+	}
+
+	return
+}
+
+// wait until count bytes can be written
+func (r *rateLimitWriter) wait(count int) {
+	now := time.Now()
+	elapse := now.Sub(r.start)
+	expect := time.Duration((r.written + int64(count)) * int64(time.Second) / r.rate)
+
+	// idle for a while, don`t burst
+	if elapse > expect+time.Second {
+		r.start = now
+		r.written = 0
+		return
+	}
+
+	if expect > elapse {
+		time.Sleep(expect - elapse)
+	}
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2019 The go-vite Authors
+ * This file is part of the go-vite library.
+ *
+ * The go-vite library is free software: you can redistribute it and/or modify
+ * it under the terms of the GNU Lesser General Public License as published by
+ * the Free Software Foundation, either version 3 of the License, or
+ * (at your option) any later version.
+ *
+ * The go-vite library is distributed in the hope that it will be useful,
+ * but WITHOUT ANY WARRANTY; without even the implied warranty of
+ * MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+ * GNU Lesser General Public License for more details.
+ *
+ * You should have received a copy of the GNU Lesser General Public License
+ * along with the go-vite library. If not, see <http://www.gnu.org/licenses/>.
+ */
+
+package net
+
+import (
+	"bytes"
+	"testing"
+	"time"
+)
+
+func TestRateLimitWriter(t *testing.T) {
+	const rate = 100 * 1024
+
+	var buf bytes.Buffer
+	w := newRateLimitWriter(&buf, rate)
+
+	data := make([]byte, rate/2)
+
+	start := time.Now()
+	for i := 0; i < 3; i++ {
+		n, err := w.Write(data)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n != len(data) {
+			t.Fatalf("write %d/%d bytes", n, len(data))
+		}
+	}
+	elapse := time.Now().Sub(start)
+
+	if buf.Len() != 3*len(data) {
+		t.Errorf("wrong length: %d", buf.Len())
+	}
+	// 1.5 rate, the first batch is written without waiting
+	if elapse < time.Second || elapse > 2*time.Second {
+		t.Errorf("wrong elapse: %s", elapse)
+	}
+}
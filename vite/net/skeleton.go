@@ -213,11 +213,15 @@ func (sk *skeleton) receiveHashList(msg p2p.Msg, sender Peer) {
 }
 
 func (sk *skeleton) getHashListFailed(id p2p.MsgId, sender Peer, err error) {
-	sk.removePending(id)
+	// the response maybe received before timeout
+	if sk.removePending(id) && err == errTimeout {
+		sender.report(p2p.BehaviorSyncTimeout)
+	}
 	netLog.Warn(fmt.Sprintf("failed to get HashHeight list from %s: %v", sender, err))
 }
 
-func (sk *skeleton) removePending(id p2p.MsgId) {
+// removePending return false if the request is not pending
+func (sk *skeleton) removePending(id p2p.MsgId) bool {
 	sk.mu.Lock()
 	if _, ok := sk.pending[id]; ok {
 		delete(sk.pending, id)
@@ -225,9 +229,11 @@ func (sk *skeleton) removePending(id p2p.MsgId) {
 		sk.wg.Done()
 
 		// todo handle response error
-	} else {
-		sk.mu.Unlock()
+		return true
 	}
+
+	sk.mu.Unlock()
+	return false
 }
 
 func (sk *skeleton) reset() {
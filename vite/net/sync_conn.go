@@ -46,6 +46,7 @@ var errWriteTooShort = errors.New("write too short")
 var errHandshakeError = errors.New("sync handshake error")
 var errServerNotReady = errors.New("server not ready")
 var errIncompleteChunk = errors.New("incomplete chunk")
+var errChunkMissing = errors.New("chunk missing")
 
 type syncHandshake struct {
 	id    peerId
@@ -429,6 +430,11 @@ func (f *syncConn) download(t *syncTask) (fatal bool, err error) {
 	}
 
 	if msg.Code != p2p.CodeSyncReady {
+		// the chunk has been pruned by the peer, it`s not fault of the connection
+		if msg.Code == p2p.CodeException && len(msg.Payload) > 0 && p2p.Exception(msg.Payload[0]) == p2p.ExpMissing {
+			return false, errChunkMissing
+		}
+
 		fatal = f.fail()
 		return fatal, errServerNotReady
 	}
@@ -561,7 +567,12 @@ func newPool(peers *peerSet) *connPoolImpl {
 	}
 }
 
+// blockPeer is invoked when the chunk downloaded from the peer is invalid
 func (fp *connPoolImpl) blockPeer(id peerId) {
+	if p := fp.peers.get(id); p != nil {
+		p.report(p2p.BehaviorInvalidBlock)
+	}
+
 	fp.mu.Lock()
 	defer fp.mu.Unlock()
 	fp.blackList[id] = time.Now().Unix()
@@ -634,12 +645,30 @@ func (fp *connPoolImpl) sortLocked() {
 
 // choose the fast fileConn, or create new conn randomly
 func (fp *connPoolImpl) chooseSource(t *syncTask) (Peer, *syncConn, error) {
-	peerMap := fp.peers.pickDownloadPeers(t.Bound[1])
+	peerMap := fp.peers.pickDownloadPeers(t.Bound[0], t.Bound[1])
+	for id := range t.lacks {
+		delete(peerMap, id)
+	}
 
 	if len(peerMap) == 0 {
 		return nil, nil, errNoSuitablePeer
 	}
 
+	// the chunk has been pruned by some peers, prefer archive peers
+	var archiveOnly bool
+	if t.Bound[0] <= fp.peers.maxPrunedHeight() {
+		archives := make(map[peerId]Peer)
+		for id, p := range peerMap {
+			if p.Archive() {
+				archives[id] = p
+			}
+		}
+		if len(archives) > 0 {
+			peerMap = archives
+			archiveOnly = true
+		}
+	}
+
 	fp.mu.Lock()
 	defer fp.mu.Unlock()
 
@@ -648,11 +677,13 @@ func (fp *connPoolImpl) chooseSource(t *syncTask) (Peer, *syncConn, error) {
 		delete(peerMap, c.peer.ID())
 	}
 
-	// is in blackList
+	// is in blackList, or behave badly recently
 	now := time.Now().Unix()
 	for k, p := range peerMap {
 		if tt, ok := fp.blackList[p.ID()]; ok && now-tt < 60 {
 			delete(peerMap, k)
+		} else if p.score() < minSyncScore {
+			delete(peerMap, k)
 		}
 	}
 
@@ -663,7 +694,10 @@ func (fp *connPoolImpl) chooseSource(t *syncTask) (Peer, *syncConn, error) {
 
 	fp.sortLocked()
 	for i, c := range fp.l {
-		if c.isBusy() || c.peer.Height() < t.Bound[1] {
+		if c.isBusy() || c.peer.Height() < t.Bound[1] || c.peer.prunedHeight() >= t.Bound[0] {
+			continue
+		}
+		if t.isLacked(c.peer.ID()) || (archiveOnly && !c.peer.Archive()) || c.peer.score() < minSyncScore {
 			continue
 		}
 
@@ -673,7 +707,7 @@ func (fp *connPoolImpl) chooseSource(t *syncTask) (Peer, *syncConn, error) {
 		}
 
 		if createNew {
-			for _, p := range peerMap {
+			if p := bestScoredPeer(peerMap); p != nil {
 				return p, nil, nil
 			}
 		} else {
@@ -681,11 +715,19 @@ func (fp *connPoolImpl) chooseSource(t *syncTask) (Peer, *syncConn, error) {
 		}
 	}
 
+	return bestScoredPeer(peerMap), nil, nil
+}
+
+// bestScoredPeer return the peer has the highest score
+func bestScoredPeer(peerMap map[peerId]Peer) (best Peer) {
+	var bestScore int64
 	for _, p := range peerMap {
-		return p, nil, nil
+		if score := p.score(); best == nil || score > bestScore {
+			best, bestScore = p, score
+		}
 	}
 
-	return nil, nil, nil
+	return
 }
 
 func (fp *connPoolImpl) reset() {
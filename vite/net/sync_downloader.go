@@ -29,8 +29,16 @@ import (
 	"github.com/vitelabs/go-vite/interfaces"
 
 	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/p2p"
 )
 
+// the chunk is served too slow if it took more than slowFileElapse and the speed is lower than minFileSpeed
+const slowFileElapse = 10 * time.Second
+const minFileSpeed = 32 * 1024 // byte/s
+
+// peers scored lower than minSyncScore will not be chosen to download chunks, until the score recover
+const minSyncScore = -30
+
 type reqState = int32
 
 const (
@@ -54,6 +62,7 @@ type syncTask struct {
 	st     reqState
 	doneAt time.Time
 	source peerId
+	lacks  map[peerId]struct{} // peers don`t have the chunk
 }
 
 func (t *syncTask) String() string {
@@ -87,6 +96,18 @@ func (t *syncTask) error() {
 	}
 }
 
+func (t *syncTask) lack(id peerId) {
+	if t.lacks == nil {
+		t.lacks = make(map[peerId]struct{})
+	}
+	t.lacks[id] = struct{}{}
+}
+
+func (t *syncTask) isLacked(id peerId) bool {
+	_, ok := t.lacks[id]
+	return ok
+}
+
 func (t *syncTask) equal(t2 *syncTask) bool {
 	return t.Segment == t2.Segment
 }
@@ -568,6 +589,17 @@ func (e *executor) doJob(c *syncConn, t *syncTask) error {
 	if fatal, err := c.download(t); err != nil {
 		e.log.Warn(fmt.Sprintf("failed to download chunk %s from %s: %v", t, c.address(), err))
 
+		// try other peers
+		if err == errChunkMissing {
+			t.lack(c.peer.ID())
+		}
+
+		if err == errIncompleteChunk {
+			c.peer.report(p2p.BehaviorSlowFile)
+		} else if fatal {
+			c.peer.report(p2p.BehaviorSyncTimeout)
+		}
+
 		if fatal {
 			e.pool.delConn(c)
 			e.log.Warn(fmt.Sprintf("delete sync connection %s: %v", c.address(), err))
@@ -576,7 +608,14 @@ func (e *executor) doJob(c *syncConn, t *syncTask) error {
 		return err
 	}
 
-	e.log.Info(fmt.Sprintf("download chunk %s from %s elapse %s", t, c.address(), time.Now().Sub(start)))
+	elapse := time.Now().Sub(start)
+	e.log.Info(fmt.Sprintf("download chunk %s from %s elapse %s", t, c.address(), elapse))
+
+	if elapse > slowFileElapse && c.speed() < minFileSpeed {
+		c.peer.report(p2p.BehaviorSlowFile)
+	} else {
+		c.peer.report(p2p.BehaviorFileServed)
+	}
 
 	return nil
 }
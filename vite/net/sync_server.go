@@ -37,6 +37,11 @@ const fileTimeout = 5 * time.Minute
 var errSyncServerIsRunning = errors.New("sync server is running")
 var errSyncServerNotRunning = errors.New("sync server is not running")
 
+type syncServerChain interface {
+	ledgerReader
+	prunedReader
+}
+
 type FileServerStatus struct {
 	Connections []SyncConnectionStatus `json:"connections"`
 }
@@ -46,19 +51,23 @@ type syncServer struct {
 	ln       net2.Listener
 	mu       sync.Mutex
 	sconnMap map[peerId]*syncConn // key is addr
-	chain    ledgerReader
+	chain    syncServerChain
 	factory  syncConnReceiver
+	archive  bool
+	rate     int64 // bytes per second to every peer in archive mode
 	running  int32
 	wg       sync.WaitGroup
 	log      log15.Logger
 }
 
-func newSyncServer(addr string, chain ledgerReader, factory syncConnReceiver) *syncServer {
+func newSyncServer(addr string, chain syncServerChain, factory syncConnReceiver, archive bool, rate int64) *syncServer {
 	return &syncServer{
 		addr:     addr,
 		sconnMap: make(map[peerId]*syncConn),
 		chain:    chain,
 		factory:  factory,
+		archive:  archive,
+		rate:     rate,
 		log:      log15.New("module", "server"),
 	}
 }
@@ -165,6 +174,11 @@ func (s *syncServer) handleConn(conn net2.Conn) {
 	s.addConn(sconn)
 	defer s.deleteConn(sconn)
 
+	var writer io.Writer = conn
+	if s.archive && s.rate > 0 {
+		writer = newRateLimitWriter(conn, s.rate)
+	}
+
 	var msg p2p.Msg
 	for {
 		msg, err = sconn.c.ReadMsg()
@@ -187,6 +201,19 @@ func (s *syncServer) handleConn(conn net2.Conn) {
 			return
 		}
 
+		// the chunk has been pruned
+		if request.from <= s.chain.GetPrunedSnapshotHeight() {
+			s.log.Warn(fmt.Sprintf("chunk<%d-%d> requested by %s has been pruned", request.from, request.to, conn.RemoteAddr()))
+
+			_ = sconn.c.WriteMsg(p2p.Msg{
+				Code:    p2p.CodeException,
+				Id:      msg.Id,
+				Payload: []byte{byte(p2p.ExpMissing)},
+			})
+
+			continue
+		}
+
 		var reader interfaces.LedgerReader
 		reader, err = s.chain.GetLedgerReaderByHeight(request.from, request.to)
 		if err != nil {
@@ -245,7 +272,7 @@ func (s *syncServer) handleConn(conn net2.Conn) {
 
 		var wn int64
 		_ = conn.SetWriteDeadline(time.Now().Add(fileTimeout))
-		wn, err = io.Copy(conn, reader)
+		wn, err = io.Copy(writer, reader)
 		_ = reader.Close()
 
 		if wn != int64(reader.Size()) {
@@ -3,10 +3,12 @@ package vite
 import (
 	"errors"
 	"fmt"
+	"path/filepath"
 	"strconv"
 	"strings"
 
 	"github.com/vitelabs/go-vite/chain"
+	"github.com/vitelabs/go-vite/chain/stream"
 	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/config"
@@ -38,6 +40,7 @@ type Vite struct {
 	consensus       consensus.Consensus
 	onRoad          *onroad.Manager
 	p2p             p2p.P2P
+	streamSinks     []*chain_stream.Sink
 }
 
 func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err error) {
@@ -51,6 +54,12 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 	if err != nil {
 		return nil, err
 	}
+	// stream
+	sinks, err := newStreamSinks(cfg, chain)
+	if err != nil {
+		return nil, err
+	}
+
 	// pool
 	pl, err := pool.NewPool(chain)
 	if err != nil {
@@ -74,6 +83,8 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 		AccessAllowKeys:    cfg.AccessAllowKeys,
 		AccessDenyKeys:     cfg.AccessDenyKeys,
 		BlackBlockHashList: cfg.BlackBlockHashList,
+		Archive:            cfg.Archive,
+		ArchiveRateLimit:   cfg.ArchiveRateLimit,
 		MinePrivateKey:     cfg.MinePrivateKey,
 		P2PPrivateKey:      cfg.P2PPrivateKey,
 		Chain:              chain,
@@ -90,6 +101,7 @@ func New(cfg *config.Config, walletManager *wallet.Manager) (vite *Vite, err err
 		pool:            pl,
 		consensus:       cs,
 		accountVerifier: verifier,
+		streamSinks:     sinks,
 	}
 
 	// producer
@@ -147,6 +159,12 @@ func (v *Vite) Start(p2p p2p.P2P) (err error) {
 
 	v.chain.Start()
 
+	for _, sink := range v.streamSinks {
+		if err = sink.Start(); err != nil {
+			return err
+		}
+	}
+
 	err = v.consensus.Init()
 	if err != nil {
 		return err
@@ -184,6 +202,9 @@ func (v *Vite) Stop() (err error) {
 		}
 	}
 	v.consensus.Stop()
+	for _, sink := range v.streamSinks {
+		sink.Stop()
+	}
 	v.chain.Stop()
 	v.onRoad.Stop()
 	return nil
@@ -225,6 +246,34 @@ func (v *Vite) P2P() p2p.P2P {
 	return v.p2p
 }
 
+// newStreamSinks creates a sink for every kafka producer, the checkpoint of sink is saved by the topic
+func newStreamSinks(cfg *config.Config, c chain.Chain) ([]*chain_stream.Sink, error) {
+	if cfg.Stream == nil {
+		return nil, nil
+	}
+
+	var sinks []*chain_stream.Sink
+	topics := make(map[string]struct{})
+	for _, spec := range cfg.Stream.KafkaProducers {
+		if strings.TrimSpace(spec) == "" {
+			continue
+		}
+
+		brokers, topic, err := chain_stream.ParseKafkaProducer(spec)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := topics[topic]; ok {
+			return nil, errors.New(fmt.Sprintf("duplicated topic %s of kafka producers", topic))
+		}
+		topics[topic] = struct{}{}
+
+		producer := chain_stream.NewKafkaProducer(brokers, topic)
+		sinks = append(sinks, chain_stream.NewSink(c, producer, filepath.Join(cfg.DataDir, "ledger_stream", topic+".json")))
+	}
+	return sinks, nil
+}
+
 func parseCoinbase(coinbaseCfg string) (*types.Address, uint32, error) {
 	splits := strings.Split(coinbaseCfg, ":")
 	if len(splits) != 2 {
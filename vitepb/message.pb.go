@@ -32,6 +32,9 @@ type Handshake struct {
 	FileAddress          []byte   `protobuf:"bytes,9,opt,name=FileAddress,proto3" json:"FileAddress,omitempty"`
 	Key                  []byte   `protobuf:"bytes,10,opt,name=Key,proto3" json:"Key,omitempty"`
 	Token                []byte   `protobuf:"bytes,11,opt,name=Token,proto3" json:"Token,omitempty"`
+	Archive              bool     `protobuf:"varint,12,opt,name=Archive,proto3" json:"Archive,omitempty"`
+	EphemeralKey         []byte   `protobuf:"bytes,13,opt,name=EphemeralKey,proto3" json:"EphemeralKey,omitempty"`
+	Caps                 []string `protobuf:"bytes,14,rep,name=Caps,proto3" json:"Caps,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -139,6 +142,27 @@ func (m *Handshake) GetToken() []byte {
 	return nil
 }
 
+func (m *Handshake) GetArchive() bool {
+	if m != nil {
+		return m.Archive
+	}
+	return false
+}
+
+func (m *Handshake) GetEphemeralKey() []byte {
+	if m != nil {
+		return m.EphemeralKey
+	}
+	return nil
+}
+
+func (m *Handshake) GetCaps() []string {
+	if m != nil {
+		return m.Caps
+	}
+	return nil
+}
+
 type SyncConnHandshake struct {
 	ID                   []byte   `protobuf:"bytes,1,opt,name=ID,proto3" json:"ID,omitempty"`
 	Timestamp            int64    `protobuf:"varint,2,opt,name=Timestamp,proto3" json:"Timestamp,omitempty"`
@@ -1034,6 +1058,171 @@ func (m *Trace) GetTTL() uint32 {
 	return 0
 }
 
+type GetAccountProof struct {
+	Address              []byte      `protobuf:"bytes,1,opt,name=Address,proto3" json:"Address,omitempty"`
+	Head                 *HashHeight `protobuf:"bytes,2,opt,name=Head,proto3" json:"Head,omitempty"`
+	Hash                 []byte      `protobuf:"bytes,3,opt,name=Hash,proto3" json:"Hash,omitempty"`
+	TokenId              []byte      `protobuf:"bytes,4,opt,name=TokenId,proto3" json:"TokenId,omitempty"`
+	Snapshot             []byte      `protobuf:"bytes,5,opt,name=Snapshot,proto3" json:"Snapshot,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}    `json:"-"`
+	XXX_unrecognized     []byte      `json:"-"`
+	XXX_sizecache        int32       `json:"-"`
+}
+
+func (m *GetAccountProof) Reset()         { *m = GetAccountProof{} }
+func (m *GetAccountProof) String() string { return proto.CompactTextString(m) }
+func (*GetAccountProof) ProtoMessage()    {}
+func (*GetAccountProof) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{17}
+}
+
+func (m *GetAccountProof) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_GetAccountProof.Unmarshal(m, b)
+}
+func (m *GetAccountProof) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_GetAccountProof.Marshal(b, m, deterministic)
+}
+func (m *GetAccountProof) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_GetAccountProof.Merge(m, src)
+}
+func (m *GetAccountProof) XXX_Size() int {
+	return xxx_messageInfo_GetAccountProof.Size(m)
+}
+func (m *GetAccountProof) XXX_DiscardUnknown() {
+	xxx_messageInfo_GetAccountProof.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_GetAccountProof proto.InternalMessageInfo
+
+func (m *GetAccountProof) GetAddress() []byte {
+	if m != nil {
+		return m.Address
+	}
+	return nil
+}
+
+func (m *GetAccountProof) GetHead() *HashHeight {
+	if m != nil {
+		return m.Head
+	}
+	return nil
+}
+
+func (m *GetAccountProof) GetHash() []byte {
+	if m != nil {
+		return m.Hash
+	}
+	return nil
+}
+
+func (m *GetAccountProof) GetTokenId() []byte {
+	if m != nil {
+		return m.TokenId
+	}
+	return nil
+}
+
+func (m *GetAccountProof) GetSnapshot() []byte {
+	if m != nil {
+		return m.Snapshot
+	}
+	return nil
+}
+
+type AccountProof struct {
+	Blocks               []*AccountBlock `protobuf:"bytes,1,rep,name=Blocks,proto3" json:"Blocks,omitempty"`
+	Balance              []byte          `protobuf:"bytes,2,opt,name=Balance,proto3" json:"Balance,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}        `json:"-"`
+	XXX_unrecognized     []byte          `json:"-"`
+	XXX_sizecache        int32           `json:"-"`
+}
+
+func (m *AccountProof) Reset()         { *m = AccountProof{} }
+func (m *AccountProof) String() string { return proto.CompactTextString(m) }
+func (*AccountProof) ProtoMessage()    {}
+func (*AccountProof) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{18}
+}
+
+func (m *AccountProof) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_AccountProof.Unmarshal(m, b)
+}
+func (m *AccountProof) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_AccountProof.Marshal(b, m, deterministic)
+}
+func (m *AccountProof) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_AccountProof.Merge(m, src)
+}
+func (m *AccountProof) XXX_Size() int {
+	return xxx_messageInfo_AccountProof.Size(m)
+}
+func (m *AccountProof) XXX_DiscardUnknown() {
+	xxx_messageInfo_AccountProof.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_AccountProof proto.InternalMessageInfo
+
+func (m *AccountProof) GetBlocks() []*AccountBlock {
+	if m != nil {
+		return m.Blocks
+	}
+	return nil
+}
+
+func (m *AccountProof) GetBalance() []byte {
+	if m != nil {
+		return m.Balance
+	}
+	return nil
+}
+
+type RoundMembers struct {
+	Index                uint64   `protobuf:"varint,1,opt,name=Index,proto3" json:"Index,omitempty"`
+	Members              [][]byte `protobuf:"bytes,2,rep,name=Members,proto3" json:"Members,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RoundMembers) Reset()         { *m = RoundMembers{} }
+func (m *RoundMembers) String() string { return proto.CompactTextString(m) }
+func (*RoundMembers) ProtoMessage()    {}
+func (*RoundMembers) Descriptor() ([]byte, []int) {
+	return fileDescriptor_2a6a8486deb9ab39, []int{19}
+}
+
+func (m *RoundMembers) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RoundMembers.Unmarshal(m, b)
+}
+func (m *RoundMembers) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RoundMembers.Marshal(b, m, deterministic)
+}
+func (m *RoundMembers) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RoundMembers.Merge(m, src)
+}
+func (m *RoundMembers) XXX_Size() int {
+	return xxx_messageInfo_RoundMembers.Size(m)
+}
+func (m *RoundMembers) XXX_DiscardUnknown() {
+	xxx_messageInfo_RoundMembers.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RoundMembers proto.InternalMessageInfo
+
+func (m *RoundMembers) GetIndex() uint64 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *RoundMembers) GetMembers() [][]byte {
+	if m != nil {
+		return m.Members
+	}
+	return nil
+}
+
 func init() {
 	proto.RegisterType((*Handshake)(nil), "vitepb.Handshake")
 	proto.RegisterType((*SyncConnHandshake)(nil), "vitepb.SyncConnHandshake")
@@ -1053,57 +1242,67 @@ func init() {
 	proto.RegisterType((*NewAccountBlock)(nil), "vitepb.NewAccountBlock")
 	proto.RegisterType((*NewAccountBlockBytes)(nil), "vitepb.NewAccountBlockBytes")
 	proto.RegisterType((*Trace)(nil), "vitepb.Trace")
+	proto.RegisterType((*GetAccountProof)(nil), "vitepb.GetAccountProof")
+	proto.RegisterType((*AccountProof)(nil), "vitepb.AccountProof")
+	proto.RegisterType((*RoundMembers)(nil), "vitepb.RoundMembers")
 }
 
 func init() { proto.RegisterFile("vitepb/message.proto", fileDescriptor_2a6a8486deb9ab39) }
 
 var fileDescriptor_2a6a8486deb9ab39 = []byte{
-	// 739 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb4, 0x55, 0x49, 0x6f, 0x13, 0x4b,
-	0x10, 0xd6, 0x6c, 0x8e, 0x5d, 0x59, 0x9e, 0xd3, 0xf2, 0x7b, 0x6f, 0x64, 0x38, 0x58, 0x73, 0x40,
-	0x16, 0x8b, 0x23, 0xc1, 0x85, 0x0b, 0x20, 0x27, 0x21, 0x71, 0x44, 0x64, 0x4c, 0xdb, 0xe2, 0x1a,
-	0x4d, 0x3c, 0xa5, 0x78, 0xe4, 0x78, 0xda, 0x4c, 0x77, 0x12, 0x05, 0x89, 0x1b, 0x57, 0x7e, 0x11,
-	0x7f, 0x0e, 0xf5, 0x32, 0x9b, 0x63, 0x23, 0x2e, 0xdc, 0xaa, 0xba, 0xaa, 0xeb, 0xab, 0xaf, 0xea,
-	0x9b, 0x1e, 0x68, 0xdd, 0xc6, 0x02, 0x97, 0x97, 0x07, 0x0b, 0xe4, 0x3c, 0xbc, 0xc2, 0xde, 0x32,
-	0x65, 0x82, 0x91, 0x9a, 0x3e, 0x6d, 0xb7, 0x4d, 0x34, 0x9c, 0x4e, 0xd9, 0x4d, 0x22, 0x2e, 0x2e,
-	0xaf, 0xd9, 0x74, 0xae, 0x73, 0xda, 0x8f, 0x4c, 0x8c, 0x27, 0xe1, 0x92, 0xcf, 0x58, 0x25, 0x18,
-	0xfc, 0xb0, 0xa1, 0x31, 0x08, 0x93, 0x88, 0xcf, 0xc2, 0x39, 0x12, 0x1f, 0xb6, 0x3e, 0x63, 0xca,
-	0x63, 0x96, 0xf8, 0x56, 0xc7, 0xea, 0x3a, 0x34, 0x73, 0x49, 0x0b, 0xbc, 0x21, 0x8a, 0xb3, 0xc8,
-	0xb7, 0xd5, 0xb9, 0x76, 0x08, 0x01, 0x77, 0x18, 0x2e, 0xd0, 0x77, 0x3a, 0x56, 0xb7, 0x41, 0x95,
-	0x4d, 0xf6, 0xc0, 0x3e, 0x3b, 0xf6, 0xdd, 0x8e, 0xd5, 0xdd, 0xa1, 0xf6, 0xd9, 0x31, 0x79, 0x0c,
-	0x8d, 0x49, 0xbc, 0x40, 0x2e, 0xc2, 0xc5, 0xd2, 0xf7, 0xd4, 0xed, 0xe2, 0x40, 0x22, 0x9e, 0x62,
-	0x82, 0x3c, 0xe6, 0x7e, 0x4d, 0x5d, 0xc9, 0x5c, 0xf2, 0x1f, 0xd4, 0x06, 0x18, 0x5f, 0xcd, 0x84,
-	0xbf, 0xd5, 0xb1, 0xba, 0x2e, 0x35, 0x9e, 0xc4, 0x1c, 0x60, 0x18, 0xf9, 0x75, 0x95, 0xae, 0x6c,
-	0xd2, 0x81, 0xed, 0x93, 0xf8, 0x1a, 0xfb, 0x51, 0x94, 0x22, 0xe7, 0x7e, 0x43, 0x85, 0xca, 0x47,
-	0xa4, 0x09, 0xce, 0x07, 0xbc, 0xf7, 0x41, 0x45, 0xa4, 0x29, 0x19, 0x4d, 0xd8, 0x1c, 0x13, 0x7f,
-	0x5b, 0x9d, 0x69, 0x27, 0x88, 0x61, 0x7f, 0x7c, 0x9f, 0x4c, 0x8f, 0x58, 0x92, 0x14, 0x63, 0xd1,
-	0x94, 0xac, 0xf5, 0x94, 0xec, 0x55, 0x4a, 0x06, 0xca, 0x59, 0x03, 0xe5, 0x96, 0xa1, 0x66, 0xb0,
-	0x73, 0x34, 0xbb, 0x49, 0xe6, 0x14, 0xbf, 0xdc, 0x20, 0x57, 0xc4, 0x4e, 0x52, 0xb6, 0x50, 0x38,
-	0x2e, 0x55, 0xb6, 0x44, 0x9e, 0x30, 0x05, 0xe1, 0x52, 0x7b, 0xc2, 0x48, 0x1b, 0xea, 0xa3, 0x14,
-	0x6f, 0x07, 0x21, 0x9f, 0x19, 0x80, 0xdc, 0x97, 0xa3, 0x7c, 0x9f, 0x44, 0x2a, 0xa4, 0x71, 0x32,
-	0x37, 0xf8, 0x06, 0xbb, 0x06, 0x89, 0x2f, 0x59, 0xc2, 0xf1, 0xef, 0x41, 0xc9, 0xca, 0xe3, 0xf8,
-	0x2b, 0xaa, 0x45, 0xbb, 0x54, 0xd9, 0xc1, 0x4f, 0x0b, 0xbc, 0xb1, 0x08, 0x05, 0x92, 0x2e, 0x78,
-	0x23, 0xc4, 0x94, 0xfb, 0x56, 0xc7, 0xe9, 0x6e, 0xbf, 0x24, 0x3d, 0x2d, 0xcd, 0x9e, 0x8a, 0xf6,
-	0x64, 0x88, 0xea, 0x04, 0x39, 0xb2, 0x51, 0x28, 0xa6, 0x33, 0xd5, 0x50, 0x9d, 0x6a, 0x27, 0xdf,
-	0xbd, 0x53, 0xda, 0x7d, 0xa1, 0x13, 0xb7, 0xa2, 0x93, 0xca, 0x92, 0x60, 0x65, 0x49, 0xed, 0x2e,
-	0xb8, 0x12, 0xe8, 0xc1, 0x6a, 0x9b, 0xe0, 0xf4, 0xa3, 0xc8, 0xa0, 0x4a, 0x33, 0x78, 0x0d, 0x20,
-	0x99, 0x95, 0xd4, 0x27, 0x69, 0x5b, 0xa6, 0x03, 0xc9, 0xb9, 0xe8, 0xc0, 0x2e, 0x77, 0x10, 0x7c,
-	0x84, 0x7f, 0x8a, 0x9b, 0x23, 0x16, 0x27, 0x42, 0x0d, 0x40, 0x1a, 0xea, 0x7e, 0x69, 0x00, 0x45,
-	0x1e, 0xd5, 0x09, 0xf9, 0x20, 0xed, 0xd2, 0x20, 0xfb, 0xb0, 0x57, 0x24, 0x9e, 0xc7, 0x5c, 0x90,
-	0x03, 0xa8, 0xa9, 0xf4, 0x6c, 0xa2, 0xff, 0x3f, 0x2c, 0xa8, 0xe2, 0xd4, 0xa4, 0x05, 0x17, 0xb0,
-	0x7f, 0x8a, 0x62, 0xa5, 0xca, 0x93, 0x5c, 0x0e, 0xce, 0x86, 0xa6, 0xb4, 0x44, 0x64, 0x4f, 0x02,
-	0x97, 0x79, 0x4f, 0x02, 0x97, 0x46, 0x36, 0x4e, 0x26, 0x9b, 0x60, 0xae, 0x00, 0xc6, 0xe6, 0xad,
-	0x39, 0x94, 0x4f, 0x0d, 0x2f, 0x01, 0x58, 0xbf, 0x05, 0x68, 0x81, 0x77, 0x24, 0xdf, 0x2f, 0x83,
-	0xa0, 0x1d, 0xa9, 0xb6, 0x13, 0x96, 0xde, 0x85, 0xa9, 0x5e, 0x7c, 0x9d, 0x66, 0x6e, 0xf0, 0x0e,
-	0xf6, 0x56, 0x90, 0x5e, 0x40, 0x4d, 0x5b, 0x86, 0xcc, 0xbf, 0xb9, 0xc4, 0xca, 0x79, 0xd4, 0x24,
-	0x05, 0xdf, 0x2d, 0x68, 0x9e, 0xa2, 0xe8, 0xeb, 0x67, 0xd3, 0xd4, 0xf0, 0x61, 0x2b, 0x7b, 0x49,
-	0xf4, 0x9a, 0x33, 0x37, 0xe7, 0x61, 0xff, 0x29, 0x0f, 0x67, 0x03, 0x0f, 0xb7, 0xca, 0xe3, 0x0d,
-	0xec, 0x56, 0x5b, 0x78, 0xbe, 0x42, 0xa3, 0x95, 0x41, 0x95, 0xd3, 0x72, 0x16, 0x9f, 0xa0, 0x39,
-	0xc4, 0xbb, 0x0a, 0x43, 0xf2, 0x0c, 0x3c, 0x65, 0x98, 0x99, 0x6f, 0x98, 0x83, 0xce, 0x91, 0xaa,
-	0x9f, 0x4c, 0xce, 0x15, 0x2d, 0x8f, 0x4a, 0x53, 0x6a, 0x77, 0x88, 0x77, 0x65, 0x34, 0xf2, 0xb4,
-	0x5a, 0x71, 0x7d, 0x4b, 0x1b, 0x0b, 0xbe, 0x85, 0xd6, 0x4a, 0xc1, 0xc3, 0x7b, 0x81, 0xea, 0x43,
-	0x2f, 0xaa, 0xee, 0x6c, 0xbe, 0xdf, 0x07, 0x6f, 0x92, 0x86, 0x53, 0x5c, 0xfb, 0x05, 0x12, 0x70,
-	0x47, 0xa1, 0x90, 0x8f, 0x85, 0x23, 0xcf, 0xa4, 0x9d, 0x95, 0x90, 0x1b, 0xd8, 0x55, 0x25, 0x2e,
-	0x6b, 0xea, 0x97, 0xf7, 0xea, 0x57, 0x00, 0x00, 0x00, 0xff, 0xff, 0x6d, 0x8a, 0x8c, 0xed, 0x4b,
-	0x07, 0x00, 0x00,
+	// 864 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xb5, 0x56, 0xcb, 0x6e, 0xd3, 0x40,
+	0x14, 0x95, 0x63, 0x27, 0x4d, 0x6e, 0xd3, 0xb4, 0x1d, 0x05, 0xb0, 0x02, 0x8b, 0xca, 0x0b, 0x14,
+	0xf1, 0x48, 0x25, 0xd8, 0xb0, 0x01, 0x94, 0x86, 0xb6, 0x89, 0x28, 0x25, 0x38, 0x51, 0xb7, 0x95,
+	0x63, 0x0f, 0xb5, 0x95, 0xc4, 0x0e, 0xb6, 0xdb, 0x52, 0x24, 0x76, 0xfc, 0x06, 0x5f, 0xc2, 0xc7,
+	0xf0, 0x2b, 0xcc, 0x9d, 0x19, 0xbf, 0xd2, 0xa4, 0x82, 0x05, 0xbb, 0xfb, 0x9a, 0x7b, 0xce, 0x7d,
+	0xf8, 0xca, 0xd0, 0xbc, 0xf2, 0x62, 0xba, 0x98, 0xec, 0xcf, 0x69, 0x14, 0x59, 0x17, 0xb4, 0xb3,
+	0x08, 0x83, 0x38, 0x20, 0x15, 0x61, 0x6d, 0xb5, 0xa4, 0xd7, 0xb2, 0xed, 0xe0, 0xd2, 0x8f, 0xcf,
+	0x27, 0xb3, 0xc0, 0x9e, 0x8a, 0x98, 0xd6, 0x43, 0xe9, 0x8b, 0x7c, 0x6b, 0x11, 0xb9, 0x41, 0xc1,
+	0x69, 0xfc, 0x2e, 0x41, 0xad, 0x6f, 0xf9, 0x4e, 0xe4, 0x5a, 0x53, 0x4a, 0x74, 0xd8, 0x38, 0xa3,
+	0x61, 0xe4, 0x05, 0xbe, 0xae, 0xec, 0x29, 0x6d, 0xd5, 0x4c, 0x54, 0xd2, 0x84, 0xf2, 0x29, 0x8d,
+	0x07, 0x8e, 0x5e, 0xe2, 0x76, 0xa1, 0x10, 0x02, 0xda, 0xa9, 0x35, 0xa7, 0xba, 0xca, 0x8c, 0x35,
+	0x93, 0xcb, 0xa4, 0x01, 0xa5, 0xc1, 0x3b, 0x5d, 0x63, 0x96, 0xba, 0xc9, 0x24, 0xf2, 0x08, 0x6a,
+	0x63, 0x8f, 0xb1, 0x8e, 0xad, 0xf9, 0x42, 0x2f, 0xf3, 0xd7, 0x99, 0x01, 0x11, 0x8f, 0xa9, 0x4f,
+	0x23, 0x2f, 0xd2, 0x2b, 0xfc, 0x49, 0xa2, 0x92, 0xfb, 0x50, 0xe9, 0x53, 0xef, 0xc2, 0x8d, 0xf5,
+	0x0d, 0xe6, 0xd0, 0x4c, 0xa9, 0x21, 0x66, 0x9f, 0x5a, 0x8e, 0x5e, 0xe5, 0xe1, 0x5c, 0x26, 0x7b,
+	0xb0, 0x79, 0xe4, 0xcd, 0x68, 0xd7, 0x71, 0x42, 0xd6, 0x1e, 0xbd, 0xc6, 0x5d, 0x79, 0x13, 0xd9,
+	0x01, 0xf5, 0x3d, 0xbd, 0xd1, 0x81, 0x7b, 0x50, 0xc4, 0x8a, 0xc6, 0xc1, 0x94, 0xfa, 0xfa, 0x26,
+	0xb7, 0x09, 0x05, 0xf9, 0x74, 0x43, 0xdb, 0xf5, 0xae, 0xa8, 0x5e, 0x67, 0xf6, 0xaa, 0x99, 0xa8,
+	0xc4, 0x80, 0xfa, 0xe1, 0xc2, 0xa5, 0x73, 0x1a, 0x5a, 0x33, 0x4c, 0xb5, 0xc5, 0x9f, 0x15, 0x6c,
+	0xc8, 0xad, 0xc7, 0x9a, 0xac, 0x37, 0xf6, 0x54, 0xec, 0x07, 0xca, 0x86, 0x07, 0xbb, 0xa3, 0x1b,
+	0xdf, 0xee, 0x05, 0xbe, 0x9f, 0x35, 0x5a, 0x34, 0x49, 0x59, 0xdd, 0xa4, 0xd2, 0x72, 0x93, 0x24,
+	0x79, 0x75, 0x05, 0x79, 0x2d, 0x47, 0xde, 0x70, 0xa1, 0xde, 0x73, 0x2f, 0xfd, 0xa9, 0x49, 0xbf,
+	0x5c, 0xb2, 0xa7, 0x48, 0xe7, 0x28, 0x0c, 0xe6, 0x1c, 0x47, 0x33, 0xb9, 0x8c, 0xc8, 0xe3, 0x80,
+	0x43, 0x68, 0x26, 0x93, 0x48, 0x0b, 0xaa, 0xc3, 0x90, 0x5e, 0xf5, 0xad, 0xc8, 0x95, 0x00, 0xa9,
+	0x8e, 0xcd, 0x38, 0xf4, 0x1d, 0xee, 0x12, 0x38, 0x89, 0x6a, 0x7c, 0x87, 0x2d, 0x89, 0x14, 0x2d,
+	0x02, 0x3f, 0xa2, 0xff, 0x0f, 0x0a, 0x33, 0x8f, 0xbc, 0x6f, 0x94, 0xaf, 0x0e, 0xcb, 0x8c, 0xb2,
+	0xf1, 0x4b, 0x81, 0xf2, 0x28, 0xb6, 0x62, 0x4a, 0xda, 0x50, 0x1e, 0x52, 0xb6, 0xa3, 0x0c, 0x58,
+	0x6d, 0x6f, 0xbe, 0x20, 0x1d, 0xb1, 0xec, 0x1d, 0xee, 0xed, 0xa0, 0xcb, 0x14, 0x01, 0xd8, 0xb2,
+	0xa1, 0x15, 0xdb, 0x2e, 0x27, 0x54, 0x35, 0x85, 0x92, 0x6e, 0x93, 0x9a, 0xdb, 0xa6, 0x6c, 0xf3,
+	0xb4, 0xc2, 0xe6, 0x15, 0x86, 0x04, 0x4b, 0x43, 0x6a, 0xb5, 0x41, 0x43, 0xa0, 0x5b, 0xa3, 0x65,
+	0xc3, 0x63, 0x4b, 0x28, 0x51, 0x51, 0x34, 0x5e, 0x01, 0x60, 0x65, 0xb9, 0x7d, 0xc6, 0xb2, 0x15,
+	0xc9, 0x00, 0x6b, 0xce, 0x18, 0x94, 0xf2, 0x0c, 0x8c, 0x8f, 0xb0, 0x9d, 0xbd, 0x1c, 0x06, 0x9e,
+	0x1f, 0xf3, 0x06, 0xa0, 0xc0, 0xdf, 0xe7, 0x1a, 0x90, 0xc5, 0x99, 0x22, 0x20, 0x6d, 0x64, 0x29,
+	0xd7, 0xc8, 0x2e, 0x34, 0xb2, 0xc0, 0x13, 0x8f, 0xed, 0xcc, 0x3e, 0x54, 0x78, 0x78, 0xd2, 0xd1,
+	0x07, 0xb7, 0x13, 0x72, 0xbf, 0x29, 0xc3, 0x8c, 0x73, 0xd8, 0x3d, 0xa6, 0xf1, 0x52, 0x96, 0xc7,
+	0xe9, 0x3a, 0xa8, 0x6b, 0x48, 0x89, 0x15, 0x41, 0x4e, 0xcc, 0x93, 0x72, 0x62, 0xb2, 0x5c, 0x1b,
+	0x35, 0x59, 0x1b, 0x63, 0xca, 0x01, 0x46, 0xf2, 0x7a, 0x1d, 0xe0, 0xf1, 0x8a, 0x72, 0x00, 0xca,
+	0x9d, 0x00, 0x6c, 0xea, 0x3d, 0xbc, 0x88, 0x12, 0x41, 0x28, 0xb8, 0x6d, 0x47, 0x41, 0x78, 0x6d,
+	0x85, 0x62, 0xf0, 0xec, 0x2b, 0x97, 0xaa, 0xf1, 0x16, 0x1a, 0x4b, 0x48, 0xcf, 0xa1, 0x22, 0x24,
+	0x59, 0xcc, 0xbd, 0x74, 0xc5, 0xf2, 0x71, 0xa6, 0x0c, 0x32, 0x7e, 0x28, 0xb0, 0xc3, 0xe8, 0x76,
+	0xc5, 0x21, 0x96, 0x39, 0xf0, 0xaa, 0xc8, 0xdb, 0x24, 0xc6, 0x9c, 0xa8, 0x69, 0x1d, 0xa5, 0xbf,
+	0xad, 0x43, 0x5d, 0x53, 0x87, 0x56, 0xac, 0xe3, 0x35, 0x6c, 0x15, 0x29, 0x3c, 0x5b, 0x2a, 0xa3,
+	0x99, 0x40, 0xe5, 0xc3, 0xd2, 0x2a, 0x3e, 0xc1, 0xce, 0x29, 0xbd, 0x2e, 0x54, 0x48, 0x9e, 0x42,
+	0x99, 0x0b, 0xb2, 0xe7, 0x6b, 0xfa, 0x20, 0x62, 0x70, 0xeb, 0xc7, 0xe3, 0x13, 0x5e, 0x56, 0xd9,
+	0x44, 0x11, 0x77, 0x97, 0xa5, 0xcc, 0xa3, 0x91, 0x27, 0xc5, 0x8c, 0xab, 0x29, 0xad, 0x4d, 0xf8,
+	0x06, 0x9a, 0x4b, 0x09, 0x0f, 0x6e, 0x62, 0xca, 0x3f, 0xf4, 0x2c, 0x6b, 0x7d, 0xfd, 0xfb, 0x2e,
+	0xbb, 0xa1, 0xa1, 0x65, 0xd3, 0x95, 0x5f, 0x20, 0xb3, 0xb1, 0x03, 0x81, 0xc7, 0x42, 0x45, 0x1b,
+	0xca, 0x49, 0x0a, 0x9c, 0xc0, 0x96, 0x48, 0xf1, 0x53, 0x81, 0xed, 0x6c, 0xd8, 0xc3, 0x30, 0x08,
+	0x3e, 0xdf, 0x3d, 0x6b, 0x7e, 0x6b, 0xee, 0x98, 0x35, 0xbf, 0x3f, 0x09, 0x1f, 0x35, 0xc7, 0x87,
+	0x65, 0xe5, 0x37, 0x7e, 0xe0, 0x24, 0xf7, 0x51, 0xaa, 0x78, 0x55, 0x93, 0x09, 0xf0, 0x1b, 0xc9,
+	0xae, 0x6a, 0xa2, 0x1b, 0x67, 0x50, 0x2f, 0x70, 0xfb, 0xa7, 0x25, 0x40, 0xcc, 0x03, 0x6b, 0x66,
+	0xf9, 0xb6, 0xb8, 0x19, 0x0c, 0x53, 0xaa, 0xac, 0xf5, 0x75, 0x93, 0xc5, 0x3b, 0x1f, 0xe8, 0x7c,
+	0x22, 0x6f, 0xeb, 0xc0, 0x77, 0xe8, 0x57, 0x79, 0xfe, 0x85, 0x82, 0xef, 0x65, 0x80, 0x6c, 0x63,
+	0xa2, 0x4e, 0x2a, 0xfc, 0xe7, 0xe3, 0xe5, 0x1f, 0xd8, 0xb8, 0xc0, 0x61, 0xd5, 0x08, 0x00, 0x00,
 }
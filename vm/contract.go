@@ -58,5 +58,12 @@ func (c *contract) run(vm *VM) (ret []byte, err error) {
 		c.intPool = nil
 	}()
 
+	if vm.tracer != nil {
+		vm.tracer.CaptureStart(c.codeAddr, c.data, c.quotaLeft)
+		defer func() {
+			vm.tracer.CaptureEnd(ret, c.quotaLeft, err)
+		}()
+	}
+
 	return vm.i.runLoop(vm, c)
 }
@@ -0,0 +1,237 @@
+package abi
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/vm/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"math/big"
+	"strings"
+)
+
+const (
+	jsonMultiSig = `
+	[
+		{"type":"function","name":"CreateWallet","inputs":[{"name":"owners","type":"address[]"},{"name":"threshold","type":"uint8"}]},
+		{"type":"function","name":"Deposit","inputs":[{"name":"walletId","type":"bytes32"}]},
+		{"type":"function","name":"Propose","inputs":[{"name":"walletId","type":"bytes32"},{"name":"to","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"}]},
+		{"type":"function","name":"Approve","inputs":[{"name":"walletId","type":"bytes32"},{"name":"proposalId","type":"bytes32"}]},
+		{"type":"function","name":"CancelProposal","inputs":[{"name":"walletId","type":"bytes32"},{"name":"proposalId","type":"bytes32"}]},
+		{"type":"variable","name":"multiSigWallet","inputs":[{"name":"owners","type":"address[]"},{"name":"threshold","type":"uint8"}]},
+		{"type":"variable","name":"multiSigBalance","inputs":[{"name":"amount","type":"uint256"}]},
+		{"type":"variable","name":"multiSigProposal","inputs":[{"name":"to","type":"address"},{"name":"tokenId","type":"tokenId"},{"name":"amount","type":"uint256"},{"name":"approvals","type":"address[]"},{"name":"proposer","type":"address"},{"name":"expirationHeight","type":"uint64"}]},
+		{"type":"event","name":"createWallet","inputs":[{"name":"walletId","type":"bytes32","indexed":true}]},
+		{"type":"event","name":"propose","inputs":[{"name":"walletId","type":"bytes32","indexed":true},{"name":"proposalId","type":"bytes32"}]},
+		{"type":"event","name":"approve","inputs":[{"name":"walletId","type":"bytes32","indexed":true},{"name":"proposalId","type":"bytes32"},{"name":"owner","type":"address"}]},
+		{"type":"event","name":"execute","inputs":[{"name":"walletId","type":"bytes32","indexed":true},{"name":"proposalId","type":"bytes32"}]},
+		{"type":"event","name":"cancelProposal","inputs":[{"name":"walletId","type":"bytes32","indexed":true},{"name":"proposalId","type":"bytes32"}]}
+	]`
+
+	MethodNameCreateMultiSigWallet = "CreateWallet"
+	MethodNameMultiSigDeposit      = "Deposit"
+	MethodNameMultiSigPropose      = "Propose"
+	MethodNameMultiSigApprove      = "Approve"
+	MethodNameMultiSigCancel       = "CancelProposal"
+	VariableNameMultiSigWallet     = "multiSigWallet"
+	VariableNameMultiSigBalance    = "multiSigBalance"
+	VariableNameMultiSigProposal   = "multiSigProposal"
+	EventNameCreateMultiSigWallet  = "createWallet"
+	EventNameMultiSigPropose       = "propose"
+	EventNameMultiSigApprove       = "approve"
+	EventNameMultiSigExecute       = "execute"
+	EventNameMultiSigCancel        = "cancelProposal"
+)
+
+var (
+	ABIMultiSig, _ = abi.JSONToABIContract(strings.NewReader(jsonMultiSig))
+
+	// wallet key is wallet id, balance key is wallet id + token id, proposal key is wallet id + proposal id
+	multiSigBalanceKeySize  = types.HashSize + types.TokenTypeIdSize
+	multiSigProposalKeySize = types.HashSize + types.HashSize
+)
+
+type ParamCreateMultiSigWallet struct {
+	Owners    []types.Address
+	Threshold uint8
+}
+type ParamMultiSigPropose struct {
+	WalletId types.Hash
+	To       types.Address
+	TokenId  types.TokenTypeId
+	Amount   *big.Int
+}
+type ParamMultiSigApprove struct {
+	WalletId   types.Hash
+	ProposalId types.Hash
+}
+
+type MultiSigWallet struct {
+	Owners    []types.Address
+	Threshold uint8
+}
+
+func (w *MultiSigWallet) IsOwner(addr types.Address) bool {
+	for _, owner := range w.Owners {
+		if owner == addr {
+			return true
+		}
+	}
+	return false
+}
+
+type VariableMultiSigBalance struct {
+	Amount *big.Int
+}
+
+// MultiSigProposal can not be approved after expiration height, then it can be cancelled by any owner
+type MultiSigProposal struct {
+	Id               types.Hash
+	To               types.Address
+	TokenId          types.TokenTypeId
+	Amount           *big.Int
+	Approvals        []types.Address
+	Proposer         types.Address
+	ExpirationHeight uint64
+}
+
+func (p *MultiSigProposal) IsExpired(snapshotHeight uint64) bool {
+	return snapshotHeight > p.ExpirationHeight
+}
+
+func (p *MultiSigProposal) IsApproved(addr types.Address) bool {
+	for _, approval := range p.Approvals {
+		if approval == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func GetMultiSigWalletKey(walletId types.Hash) []byte {
+	return walletId.Bytes()
+}
+func GetMultiSigBalanceKey(walletId types.Hash, tokenId types.TokenTypeId) []byte {
+	return append(walletId.Bytes(), tokenId.Bytes()...)
+}
+func GetMultiSigProposalKey(walletId types.Hash, proposalId types.Hash) []byte {
+	return append(walletId.Bytes(), proposalId.Bytes()...)
+}
+func IsMultiSigBalanceKey(key []byte) bool {
+	return len(key) == multiSigBalanceKeySize
+}
+func IsMultiSigProposalKey(key []byte) bool {
+	return len(key) == multiSigProposalKeySize
+}
+
+func GetMultiSigWallet(db StorageDatabase, walletId types.Hash) (*MultiSigWallet, error) {
+	if *db.Address() != types.AddressMultiSig {
+		return nil, util.ErrAddressNotMatch
+	}
+	v, err := db.GetValue(GetMultiSigWalletKey(walletId))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, nil
+	}
+	wallet := new(MultiSigWallet)
+	if err := ABIMultiSig.UnpackVariable(wallet, VariableNameMultiSigWallet, v); err != nil {
+		return nil, err
+	}
+	return wallet, nil
+}
+
+func GetMultiSigBalance(db StorageDatabase, walletId types.Hash, tokenId types.TokenTypeId) (*big.Int, error) {
+	if *db.Address() != types.AddressMultiSig {
+		return nil, util.ErrAddressNotMatch
+	}
+	v, err := db.GetValue(GetMultiSigBalanceKey(walletId, tokenId))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return big.NewInt(0), nil
+	}
+	balance := new(VariableMultiSigBalance)
+	if err := ABIMultiSig.UnpackVariable(balance, VariableNameMultiSigBalance, v); err != nil {
+		return nil, err
+	}
+	return balance.Amount, nil
+}
+
+func GetMultiSigBalanceMap(db StorageDatabase, walletId types.Hash) (map[types.TokenTypeId]*big.Int, error) {
+	if *db.Address() != types.AddressMultiSig {
+		return nil, util.ErrAddressNotMatch
+	}
+	iterator, err := db.NewStorageIterator(walletId.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Release()
+	balanceMap := make(map[types.TokenTypeId]*big.Int)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		if !filterKeyValue(iterator.Key(), iterator.Value(), IsMultiSigBalanceKey) {
+			continue
+		}
+		balance := new(VariableMultiSigBalance)
+		if err := ABIMultiSig.UnpackVariable(balance, VariableNameMultiSigBalance, iterator.Value()); err == nil {
+			tokenId, _ := types.BytesToTokenTypeId(iterator.Key()[types.HashSize:])
+			balanceMap[tokenId] = balance.Amount
+		}
+	}
+	return balanceMap, nil
+}
+
+func GetMultiSigProposal(db StorageDatabase, walletId types.Hash, proposalId types.Hash) (*MultiSigProposal, error) {
+	if *db.Address() != types.AddressMultiSig {
+		return nil, util.ErrAddressNotMatch
+	}
+	v, err := db.GetValue(GetMultiSigProposalKey(walletId, proposalId))
+	if err != nil {
+		return nil, err
+	}
+	if len(v) == 0 {
+		return nil, nil
+	}
+	proposal := new(MultiSigProposal)
+	if err := ABIMultiSig.UnpackVariable(proposal, VariableNameMultiSigProposal, v); err != nil {
+		return nil, err
+	}
+	proposal.Id = proposalId
+	return proposal, nil
+}
+
+// GetMultiSigProposalList returns the proposals waiting for approvals
+func GetMultiSigProposalList(db StorageDatabase, walletId types.Hash) ([]*MultiSigProposal, error) {
+	if *db.Address() != types.AddressMultiSig {
+		return nil, util.ErrAddressNotMatch
+	}
+	iterator, err := db.NewStorageIterator(walletId.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	defer iterator.Release()
+	proposalList := make([]*MultiSigProposal, 0)
+	for {
+		if !iterator.Next() {
+			if iterator.Error() != nil {
+				return nil, iterator.Error()
+			}
+			break
+		}
+		if !filterKeyValue(iterator.Key(), iterator.Value(), IsMultiSigProposalKey) {
+			continue
+		}
+		proposal := new(MultiSigProposal)
+		if err := ABIMultiSig.UnpackVariable(proposal, VariableNameMultiSigProposal, iterator.Value()); err == nil {
+			proposal.Id, _ = types.BytesToHash(iterator.Key()[types.HashSize:])
+			proposalList = append(proposalList, proposal)
+		}
+	}
+	return proposalList, nil
+}
@@ -5,13 +5,14 @@ import (
 	"fmt"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/vm/abi"
+	"math/big"
 	"strconv"
 	"strings"
 	"testing"
 )
 
 func TestContractsABIInit(t *testing.T) {
-	tests := []string{jsonPledge, jsonConsensusGroup, jsonMintage}
+	tests := []string{jsonPledge, jsonConsensusGroup, jsonMintage, jsonMultiSig}
 	for _, data := range tests {
 		if _, err := abi.JSONToABIContract(strings.NewReader(data)); err != nil {
 			t.Fatalf("json to abi failed, %v, %v", data, err)
@@ -67,3 +68,31 @@ func TestABIContract_MethodById(t *testing.T) {
 		fmt.Printf("%v: %v\n", e.Name, result)
 	}
 }
+
+func TestABIMultiSig_PackMethod(t *testing.T) {
+	walletId := types.Hash{1, 2, 3}
+	data, err := ABIMultiSig.PackMethod(MethodNameMultiSigPropose, walletId, types.Address{4}, t1, big.NewInt(5))
+	if err != nil {
+		t.Fatal(err)
+	}
+	param := new(ParamMultiSigPropose)
+	if err := ABIMultiSig.UnpackMethod(param, MethodNameMultiSigPropose, data); err != nil {
+		t.Fatal(err)
+	}
+	if param.WalletId != walletId || param.To != (types.Address{4}) || param.TokenId != t1 || param.Amount.Cmp(big.NewInt(5)) != 0 {
+		t.Fatalf("unpack propose failed, %v", param)
+	}
+
+	value, err := ABIMultiSig.PackVariable(VariableNameMultiSigProposal, types.Address{4}, t1, big.NewInt(5), []types.Address{{6}, {7}}, types.Address{6}, uint64(100))
+	if err != nil {
+		t.Fatal(err)
+	}
+	proposal := new(MultiSigProposal)
+	if err := ABIMultiSig.UnpackVariable(proposal, VariableNameMultiSigProposal, value); err != nil {
+		t.Fatal(err)
+	}
+	if !proposal.IsApproved(types.Address{7}) || proposal.IsApproved(types.Address{4}) ||
+		proposal.Proposer != (types.Address{6}) || proposal.IsExpired(100) || !proposal.IsExpired(101) {
+		t.Fatalf("unpack proposal failed, %v", proposal)
+	}
+}
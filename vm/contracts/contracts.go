@@ -1,6 +1,7 @@
 package contracts
 
 import (
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
 	"github.com/vitelabs/go-vite/vm/abi"
@@ -81,9 +82,23 @@ var simpleContracts = map[types.Address]*builtinContract{
 		},
 		cabi.ABIMintage,
 	},
+	types.AddressMultiSig: {
+		map[string]BuiltinContractMethod{
+			cabi.MethodNameCreateMultiSigWallet: &MethodCreateMultiSigWallet{},
+			cabi.MethodNameMultiSigDeposit:      &MethodMultiSigDeposit{},
+			cabi.MethodNameMultiSigPropose:      &MethodMultiSigPropose{},
+			cabi.MethodNameMultiSigApprove:      &MethodMultiSigApprove{},
+			cabi.MethodNameMultiSigCancel:       &MethodMultiSigCancel{},
+		},
+		cabi.ABIMultiSig,
+	},
 }
 
-func GetBuiltinContractMethod(addr types.Address, methodSelector []byte) (BuiltinContractMethod, bool, error) {
+// GetBuiltinContractMethod returns false if the contract is not in use at the snapshot height
+func GetBuiltinContractMethod(addr types.Address, methodSelector []byte, sbHeight uint64) (BuiltinContractMethod, bool, error) {
+	if !fork.IsBuiltinContractAddrInUse(addr, sbHeight) {
+		return nil, false, nil
+	}
 	p, ok := simpleContracts[addr]
 	if ok {
 		if method, err := p.abi.MethodById(methodSelector); err == nil {
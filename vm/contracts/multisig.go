@@ -0,0 +1,267 @@
+package contracts
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+	"github.com/vitelabs/go-vite/vm/contracts/abi"
+	"github.com/vitelabs/go-vite/vm/util"
+	"github.com/vitelabs/go-vite/vm_db"
+	"math/big"
+)
+
+type MethodCreateMultiSigWallet struct{}
+
+func (p *MethodCreateMultiSigWallet) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodCreateMultiSigWallet) GetRefundData() ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodCreateMultiSigWallet) GetSendQuota(data []byte) (uint64, error) {
+	return CreateMultiSigWalletGas, nil
+}
+func (p *MethodCreateMultiSigWallet) DoSend(db vm_db.VmDb, block *ledger.AccountBlock) error {
+	param := new(abi.ParamCreateMultiSigWallet)
+	if err := abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameCreateMultiSigWallet, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if len(param.Owners) == 0 || len(param.Owners) > multiSigOwnerCountMax ||
+		param.Threshold == 0 || int(param.Threshold) > len(param.Owners) {
+		return util.ErrInvalidMethodParam
+	}
+	ownerSet := make(map[types.Address]struct{}, len(param.Owners))
+	for _, owner := range param.Owners {
+		if _, ok := ownerSet[owner]; ok {
+			return util.ErrInvalidMethodParam
+		}
+		ownerSet[owner] = struct{}{}
+	}
+	block.Data, _ = abi.ABIMultiSig.PackMethod(abi.MethodNameCreateMultiSigWallet, param.Owners, param.Threshold)
+	return nil
+}
+
+// DoReceive creates the wallet with the send block hash as wallet id, the amount of send block is deposited to the wallet
+func (p *MethodCreateMultiSigWallet) DoReceive(db vm_db.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamCreateMultiSigWallet)
+	abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameCreateMultiSigWallet, sendBlock.Data)
+	walletId := sendBlock.Hash
+	walletInfo, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigWallet, param.Owners, param.Threshold)
+	util.SetValue(db, abi.GetMultiSigWalletKey(walletId), walletInfo)
+	if sendBlock.Amount.Sign() > 0 {
+		addMultiSigBalance(db, walletId, sendBlock.TokenId, sendBlock.Amount)
+	}
+	db.AddLog(util.NewLog(abi.ABIMultiSig, abi.EventNameCreateMultiSigWallet, walletId))
+	return nil, nil
+}
+
+type MethodMultiSigDeposit struct{}
+
+func (p *MethodMultiSigDeposit) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodMultiSigDeposit) GetRefundData() ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodMultiSigDeposit) GetSendQuota(data []byte) (uint64, error) {
+	return MultiSigDepositGas, nil
+}
+func (p *MethodMultiSigDeposit) DoSend(db vm_db.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() <= 0 {
+		return util.ErrInvalidMethodParam
+	}
+	walletId := new(types.Hash)
+	if err := abi.ABIMultiSig.UnpackMethod(walletId, abi.MethodNameMultiSigDeposit, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigDeposit, *walletId)
+	return nil
+}
+func (p *MethodMultiSigDeposit) DoReceive(db vm_db.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	walletId := new(types.Hash)
+	abi.ABIMultiSig.UnpackMethod(walletId, abi.MethodNameMultiSigDeposit, sendBlock.Data)
+	wallet, err := abi.GetMultiSigWallet(db, *walletId)
+	util.DealWithErr(err)
+	if wallet == nil {
+		return nil, util.ErrInvalidMethodParam
+	}
+	addMultiSigBalance(db, *walletId, sendBlock.TokenId, sendBlock.Amount)
+	return nil, nil
+}
+
+type MethodMultiSigPropose struct{}
+
+func (p *MethodMultiSigPropose) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodMultiSigPropose) GetRefundData() ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodMultiSigPropose) GetSendQuota(data []byte) (uint64, error) {
+	return MultiSigProposeGas, nil
+}
+func (p *MethodMultiSigPropose) DoSend(db vm_db.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamMultiSigPropose)
+	if err := abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameMultiSigPropose, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	if param.Amount.Sign() <= 0 {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigPropose, param.WalletId, param.To, param.TokenId, param.Amount)
+	return nil
+}
+
+// DoReceive saves the transfer with the send block hash as proposal id, the proposer approves it at the same time
+func (p *MethodMultiSigPropose) DoReceive(db vm_db.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamMultiSigPropose)
+	abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameMultiSigPropose, sendBlock.Data)
+	wallet, err := abi.GetMultiSigWallet(db, param.WalletId)
+	util.DealWithErr(err)
+	if wallet == nil || !wallet.IsOwner(sendBlock.AccountAddress) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	proposal := &abi.MultiSigProposal{
+		Id:               sendBlock.Hash,
+		To:               param.To,
+		TokenId:          param.TokenId,
+		Amount:           param.Amount,
+		Approvals:        []types.Address{sendBlock.AccountAddress},
+		Proposer:         sendBlock.AccountAddress,
+		ExpirationHeight: vm.GlobalStatus().SnapshotBlock().Height + nodeConfig.params.MultiSigProposalExpirationHeight,
+	}
+	db.AddLog(util.NewLog(abi.ABIMultiSig, abi.EventNameMultiSigPropose, param.WalletId, proposal.Id))
+	return approveMultiSigProposal(db, block, param.WalletId, wallet, proposal)
+}
+
+type MethodMultiSigApprove struct{}
+
+func (p *MethodMultiSigApprove) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodMultiSigApprove) GetRefundData() ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodMultiSigApprove) GetSendQuota(data []byte) (uint64, error) {
+	return MultiSigApproveGas, nil
+}
+func (p *MethodMultiSigApprove) DoSend(db vm_db.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamMultiSigApprove)
+	if err := abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameMultiSigApprove, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigApprove, param.WalletId, param.ProposalId)
+	return nil
+}
+func (p *MethodMultiSigApprove) DoReceive(db vm_db.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamMultiSigApprove)
+	abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameMultiSigApprove, sendBlock.Data)
+	wallet, err := abi.GetMultiSigWallet(db, param.WalletId)
+	util.DealWithErr(err)
+	if wallet == nil || !wallet.IsOwner(sendBlock.AccountAddress) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	proposal, err := abi.GetMultiSigProposal(db, param.WalletId, param.ProposalId)
+	util.DealWithErr(err)
+	if proposal == nil || proposal.IsApproved(sendBlock.AccountAddress) ||
+		proposal.IsExpired(vm.GlobalStatus().SnapshotBlock().Height) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	proposal.Approvals = append(proposal.Approvals, sendBlock.AccountAddress)
+	db.AddLog(util.NewLog(abi.ABIMultiSig, abi.EventNameMultiSigApprove, param.WalletId, proposal.Id, sendBlock.AccountAddress))
+	return approveMultiSigProposal(db, block, param.WalletId, wallet, proposal)
+}
+
+type MethodMultiSigCancel struct{}
+
+func (p *MethodMultiSigCancel) GetFee(block *ledger.AccountBlock) (*big.Int, error) {
+	return big.NewInt(0), nil
+}
+func (p *MethodMultiSigCancel) GetRefundData() ([]byte, bool) {
+	return []byte{}, false
+}
+func (p *MethodMultiSigCancel) GetSendQuota(data []byte) (uint64, error) {
+	return MultiSigCancelGas, nil
+}
+func (p *MethodMultiSigCancel) DoSend(db vm_db.VmDb, block *ledger.AccountBlock) error {
+	if block.Amount.Sign() > 0 {
+		return util.ErrInvalidMethodParam
+	}
+	param := new(abi.ParamMultiSigApprove)
+	if err := abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameMultiSigCancel, block.Data); err != nil {
+		return util.ErrInvalidMethodParam
+	}
+	block.Data, _ = abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigCancel, param.WalletId, param.ProposalId)
+	return nil
+}
+
+// DoReceive deletes the proposal, the proposer can cancel it at any time, other owners can cancel it after expiration
+func (p *MethodMultiSigCancel) DoReceive(db vm_db.VmDb, block *ledger.AccountBlock, sendBlock *ledger.AccountBlock, vm vmEnvironment) ([]*ledger.AccountBlock, error) {
+	param := new(abi.ParamMultiSigApprove)
+	abi.ABIMultiSig.UnpackMethod(param, abi.MethodNameMultiSigCancel, sendBlock.Data)
+	wallet, err := abi.GetMultiSigWallet(db, param.WalletId)
+	util.DealWithErr(err)
+	if wallet == nil || !wallet.IsOwner(sendBlock.AccountAddress) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	proposal, err := abi.GetMultiSigProposal(db, param.WalletId, param.ProposalId)
+	util.DealWithErr(err)
+	if proposal == nil ||
+		(proposal.Proposer != sendBlock.AccountAddress && !proposal.IsExpired(vm.GlobalStatus().SnapshotBlock().Height)) {
+		return nil, util.ErrInvalidMethodParam
+	}
+	util.SetValue(db, abi.GetMultiSigProposalKey(param.WalletId, proposal.Id), nil)
+	db.AddLog(util.NewLog(abi.ABIMultiSig, abi.EventNameMultiSigCancel, param.WalletId, proposal.Id))
+	return nil, nil
+}
+
+// approveMultiSigProposal executes the transfer once the approvals reach the threshold, otherwise saves the approvals.
+// The approval fails if the balance of wallet is not enough, so it can be approved again after deposit.
+func approveMultiSigProposal(db vm_db.VmDb, block *ledger.AccountBlock, walletId types.Hash, wallet *abi.MultiSigWallet, proposal *abi.MultiSigProposal) ([]*ledger.AccountBlock, error) {
+	proposalKey := abi.GetMultiSigProposalKey(walletId, proposal.Id)
+	if len(proposal.Approvals) < int(wallet.Threshold) {
+		proposalInfo, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigProposal, proposal.To, proposal.TokenId, proposal.Amount, proposal.Approvals, proposal.Proposer, proposal.ExpirationHeight)
+		util.SetValue(db, proposalKey, proposalInfo)
+		return nil, nil
+	}
+
+	balance, err := abi.GetMultiSigBalance(db, walletId, proposal.TokenId)
+	util.DealWithErr(err)
+	if balance.Cmp(proposal.Amount) < 0 {
+		return nil, util.ErrInsufficientBalance
+	}
+	setMultiSigBalance(db, walletId, proposal.TokenId, balance.Sub(balance, proposal.Amount))
+	util.SetValue(db, proposalKey, nil)
+	db.AddLog(util.NewLog(abi.ABIMultiSig, abi.EventNameMultiSigExecute, walletId, proposal.Id))
+	return []*ledger.AccountBlock{
+		{
+			AccountAddress: block.AccountAddress,
+			ToAddress:      proposal.To,
+			BlockType:      ledger.BlockTypeSendCall,
+			Amount:         proposal.Amount,
+			TokenId:        proposal.TokenId,
+			Data:           []byte{},
+		},
+	}, nil
+}
+
+func addMultiSigBalance(db vm_db.VmDb, walletId types.Hash, tokenId types.TokenTypeId, amount *big.Int) {
+	balance, err := abi.GetMultiSigBalance(db, walletId, tokenId)
+	util.DealWithErr(err)
+	setMultiSigBalance(db, walletId, tokenId, balance.Add(balance, amount))
+}
+
+func setMultiSigBalance(db vm_db.VmDb, walletId types.Hash, tokenId types.TokenTypeId, amount *big.Int) {
+	balanceKey := abi.GetMultiSigBalanceKey(walletId, tokenId)
+	if amount.Sign() == 0 {
+		util.SetValue(db, balanceKey, nil)
+		return
+	}
+	balance, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigBalance, amount)
+	util.SetValue(db, balanceKey, balance)
+}
@@ -26,6 +26,11 @@ const (
 	TransferOwnerGas          uint64 = 58981
 	ChangeTokenTypeGas        uint64 = 63125
 	GetTokenInfoGas           uint64 = 63200
+	CreateMultiSigWalletGas   uint64 = 62200
+	MultiSigDepositGas        uint64 = 48837
+	MultiSigProposeGas        uint64 = 62200
+	MultiSigApproveGas        uint64 = 62200
+	MultiSigCancelGas         uint64 = 62200
 
 	cgNodeCountMin   uint8 = 3       // Minimum node count of consensus group
 	cgNodeCountMax   uint8 = 101     // Maximum node count of consensus group
@@ -41,8 +46,9 @@ const (
 	tokenNameLengthMax   int = 40 // Maximum length of a token name(include)
 	tokenSymbolLengthMax int = 10 // Maximum length of a token symbol(include)
 
-	tokenNameIndexMax  uint16 = 1000
-	GetRewardTimeLimit int64  = 3600 // Cannot get snapshot block reward of current few blocks, for latest snapshot block could be reverted
+	tokenNameIndexMax     uint16 = 1000
+	multiSigOwnerCountMax int    = 20   // Maximum owner count of a multi-signature wallet
+	GetRewardTimeLimit    int64  = 3600 // Cannot get snapshot block reward of current few blocks, for latest snapshot block could be reverted
 )
 
 var (
@@ -58,6 +64,7 @@ type ContractsParams struct {
 	PledgeHeight                     uint64 // pledge height for stake
 	CreateConsensusGroupPledgeHeight uint64 // Pledge height for registering to be a super node of snapshot group and common delegate group
 	MintPledgeHeight                 uint64 // Pledge height for mintage if choose to pledge instead of destroy vite token
+	MultiSigProposalExpirationHeight uint64 // Proposal of multi-signature wallet can be approved in expiration height
 }
 
 var (
@@ -66,11 +73,13 @@ var (
 		PledgeHeight:                     1,
 		CreateConsensusGroupPledgeHeight: 1,
 		MintPledgeHeight:                 1,
+		MultiSigProposalExpirationHeight: 10,
 	}
 	ContractsParamsMainNet = ContractsParams{
 		RegisterMinPledgeHeight:          3600 * 24 * 3,
 		PledgeHeight:                     3600 * 24 * 3,
 		CreateConsensusGroupPledgeHeight: 3600 * 24 * 3,
 		MintPledgeHeight:                 3600 * 24 * 30 * 3,
+		MultiSigProposalExpirationHeight: 3600 * 24 * 7,
 	}
 )
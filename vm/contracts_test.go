@@ -4,8 +4,10 @@ import (
 	"bytes"
 	"encoding/hex"
 	"fmt"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/config"
 	"github.com/vitelabs/go-vite/consensus/core"
 	"github.com/vitelabs/go-vite/crypto/ed25519"
 	"github.com/vitelabs/go-vite/ledger"
@@ -919,6 +921,270 @@ func TestContractsPledge(t *testing.T) {
 	db.accountBlockMap[addr1][hash18] = receiveCancelPledgeRefundBlock2.AccountBlock
 }
 
+func TestContractsMultiSig(t *testing.T) {
+	// prepare db
+	viteTotalSupply := new(big.Int).Mul(big.NewInt(2e6), big.NewInt(1e18))
+	db, addr1, _, hash12, snapshot2, _ := prepareDb(viteTotalSupply)
+	balance1 := new(big.Int).Set(viteTotalSupply)
+	addr2, _, _ := types.CreateAddress()
+	addr3, _, _ := types.CreateAddress()
+	addr5 := types.AddressMultiSig
+	depositAmount := new(big.Int).Mul(big.NewInt(100), util.AttovPerVite)
+	transferAmount := new(big.Int).Mul(big.NewInt(60), util.AttovPerVite)
+
+	// not in use before fork point
+	block13Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameCreateMultiSigWallet, []types.Address{addr1, addr2}, uint8(2))
+	fork.SetForkPoints(&config.ForkPoints{MultiSigFork: &config.ForkPoint{Height: snapshot2.Height + 1}})
+	defer initFork()
+	if _, ok, _ := contracts.GetBuiltinContractMethod(addr5, block13Data, snapshot2.Height); ok {
+		t.Fatalf("multi-signature contract should not be in use before fork point")
+	}
+	fork.SetForkPoints(&config.ForkPoints{MultiSigFork: &config.ForkPoint{Height: snapshot2.Height}})
+
+	// create wallet with initial deposit
+	hash13 := types.DataHash([]byte{1, 3})
+	block13 := &ledger.AccountBlock{
+		Height:         3,
+		ToAddress:      addr5,
+		AccountAddress: addr1,
+		Amount:         depositAmount,
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash12,
+		Data:           block13Data,
+		Hash:           hash13,
+	}
+	vm := NewVM(nil)
+	db.addr = addr1
+	sendCreateBlock, isRetry, err := vm.RunV2(db, block13, nil, nil)
+	balance1.Sub(balance1, depositAmount)
+	if sendCreateBlock == nil ||
+		len(sendCreateBlock.AccountBlock.SendBlockList) != 0 || isRetry || err != nil ||
+		db.balanceMap[addr1][ledger.ViteTokenId].Cmp(balance1) != 0 ||
+		!bytes.Equal(sendCreateBlock.AccountBlock.Data, block13Data) ||
+		sendCreateBlock.AccountBlock.Quota != contracts.CreateMultiSigWalletGas {
+		t.Fatalf("send create multi-signature wallet transaction error")
+	}
+	db.accountBlockMap[addr1][hash13] = sendCreateBlock.AccountBlock
+
+	hash51 := types.DataHash([]byte{5, 1})
+	block51 := &ledger.AccountBlock{
+		Height:         1,
+		AccountAddress: addr5,
+		BlockType:      ledger.BlockTypeReceive,
+		FromBlockHash:  hash13,
+		Hash:           hash51,
+	}
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveCreateBlock, isRetry, err := vm.RunV2(db, block51, sendCreateBlock.AccountBlock, NewTestGlobalStatus(0, snapshot2))
+	walletId := hash13
+	walletData, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigWallet, []types.Address{addr1, addr2}, uint8(2))
+	if receiveCreateBlock == nil ||
+		len(receiveCreateBlock.AccountBlock.SendBlockList) != 0 || isRetry || err != nil ||
+		!bytes.Equal(db.storageMap[addr5][ToKey(abi.GetMultiSigWalletKey(walletId))], walletData) ||
+		db.balanceMap[addr5][ledger.ViteTokenId].Cmp(depositAmount) != 0 {
+		t.Fatalf("receive create multi-signature wallet transaction error")
+	}
+	db.accountBlockMap[addr5] = make(map[types.Hash]*ledger.AccountBlock)
+	db.accountBlockMap[addr5][hash51] = receiveCreateBlock.AccountBlock
+
+	// propose a transfer, wait for approval of addr2
+	block14Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigPropose, walletId, addr3, ledger.ViteTokenId, transferAmount)
+	hash14 := types.DataHash([]byte{1, 4})
+	block14 := &ledger.AccountBlock{
+		Height:         4,
+		ToAddress:      addr5,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash13,
+		Data:           block14Data,
+		Hash:           hash14,
+	}
+	vm = NewVM(nil)
+	db.addr = addr1
+	sendProposeBlock, isRetry, err := vm.RunV2(db, block14, nil, nil)
+	if sendProposeBlock == nil ||
+		len(sendProposeBlock.AccountBlock.SendBlockList) != 0 || isRetry || err != nil ||
+		sendProposeBlock.AccountBlock.Quota != contracts.MultiSigProposeGas {
+		t.Fatalf("send propose transaction error")
+	}
+	db.accountBlockMap[addr1][hash14] = sendProposeBlock.AccountBlock
+
+	hash52 := types.DataHash([]byte{5, 2})
+	block52 := &ledger.AccountBlock{
+		Height:         2,
+		AccountAddress: addr5,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash51,
+		FromBlockHash:  hash14,
+		Hash:           hash52,
+	}
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveProposeBlock, isRetry, err := vm.RunV2(db, block52, sendProposeBlock.AccountBlock, NewTestGlobalStatus(0, snapshot2))
+	proposalId := hash14
+	expirationHeight := snapshot2.Height + contracts.ContractsParamsMainNet.MultiSigProposalExpirationHeight
+	proposalData, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigProposal, addr3, ledger.ViteTokenId, transferAmount, []types.Address{addr1}, addr1, expirationHeight)
+	if receiveProposeBlock == nil ||
+		len(receiveProposeBlock.AccountBlock.SendBlockList) != 0 || isRetry || err != nil ||
+		!bytes.Equal(db.storageMap[addr5][ToKey(abi.GetMultiSigProposalKey(walletId, proposalId))], proposalData) {
+		t.Fatalf("receive propose transaction error")
+	}
+	db.accountBlockMap[addr5][hash52] = receiveProposeBlock.AccountBlock
+
+	// approve by addr2, transfer to addr3
+	block21Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigApprove, walletId, proposalId)
+	hash21 := types.DataHash([]byte{2, 1})
+	block21 := &ledger.AccountBlock{
+		Height:         1,
+		ToAddress:      addr5,
+		AccountAddress: addr2,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		Data:           block21Data,
+		Hash:           hash21,
+	}
+	hash53 := types.DataHash([]byte{5, 3})
+	block53 := &ledger.AccountBlock{
+		Height:         3,
+		AccountAddress: addr5,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash52,
+		FromBlockHash:  hash21,
+		Hash:           hash53,
+	}
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveApproveBlock, isRetry, err := vm.RunV2(db, block53, block21, NewTestGlobalStatus(0, snapshot2))
+	balance5 := new(big.Int).Sub(depositAmount, transferAmount)
+	balanceData, _ := abi.ABIMultiSig.PackVariable(abi.VariableNameMultiSigBalance, balance5)
+	if receiveApproveBlock == nil ||
+		len(receiveApproveBlock.AccountBlock.SendBlockList) != 1 || isRetry || err != nil ||
+		receiveApproveBlock.AccountBlock.SendBlockList[0].ToAddress != addr3 ||
+		receiveApproveBlock.AccountBlock.SendBlockList[0].Amount.Cmp(transferAmount) != 0 ||
+		len(db.storageMap[addr5][ToKey(abi.GetMultiSigProposalKey(walletId, proposalId))]) != 0 ||
+		!bytes.Equal(db.storageMap[addr5][ToKey(abi.GetMultiSigBalanceKey(walletId, ledger.ViteTokenId))], balanceData) ||
+		db.balanceMap[addr5][ledger.ViteTokenId].Cmp(balance5) != 0 {
+		t.Fatalf("receive approve transaction error")
+	}
+	db.accountBlockMap[addr5][hash53] = receiveApproveBlock.AccountBlock
+
+	// approve an executed proposal
+	hash54 := types.DataHash([]byte{5, 4})
+	block54 := &ledger.AccountBlock{
+		Height:         4,
+		AccountAddress: addr5,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash53,
+		FromBlockHash:  hash21,
+		Hash:           hash54,
+	}
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveApproveBlock2, isRetry, err := vm.RunV2(db, block54, block21, NewTestGlobalStatus(0, snapshot2))
+	if receiveApproveBlock2 == nil || isRetry ||
+		err == nil || err.Error() != util.ErrInvalidMethodParam.Error() ||
+		db.balanceMap[addr5][ledger.ViteTokenId].Cmp(balance5) != 0 {
+		t.Fatalf("receive invalid approve transaction error")
+	}
+
+	// propose another transfer, it expires without approval of addr2
+	block15Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigPropose, walletId, addr3, ledger.ViteTokenId, transferAmount)
+	hash15 := types.DataHash([]byte{1, 5})
+	block15 := &ledger.AccountBlock{
+		Height:         5,
+		ToAddress:      addr5,
+		AccountAddress: addr1,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		PrevHash:       hash14,
+		Data:           block15Data,
+		Hash:           hash15,
+	}
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveProposeBlock2, isRetry, err := vm.RunV2(db, block54, block15, NewTestGlobalStatus(0, snapshot2))
+	proposalId2 := hash15
+	if receiveProposeBlock2 == nil || isRetry || err != nil ||
+		len(db.storageMap[addr5][ToKey(abi.GetMultiSigProposalKey(walletId, proposalId2))]) == 0 {
+		t.Fatalf("receive propose transaction error")
+	}
+	db.accountBlockMap[addr5][hash54] = receiveProposeBlock2.AccountBlock
+
+	// addr2 can not cancel the proposal of addr1 before expiration
+	block22Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigCancel, walletId, proposalId2)
+	block22 := &ledger.AccountBlock{
+		Height:         2,
+		ToAddress:      addr5,
+		AccountAddress: addr2,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		Data:           block22Data,
+		Hash:           types.DataHash([]byte{2, 2}),
+	}
+	hash55 := types.DataHash([]byte{5, 5})
+	block55 := &ledger.AccountBlock{
+		Height:         5,
+		AccountAddress: addr5,
+		BlockType:      ledger.BlockTypeReceive,
+		PrevHash:       hash54,
+		FromBlockHash:  block22.Hash,
+		Hash:           hash55,
+	}
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveCancelBlock, isRetry, err := vm.RunV2(db, block55, block22, NewTestGlobalStatus(0, snapshot2))
+	if receiveCancelBlock == nil || isRetry ||
+		err == nil || err.Error() != util.ErrInvalidMethodParam.Error() {
+		t.Fatalf("receive invalid cancel transaction error")
+	}
+
+	// the expired proposal can not be approved, but can be cancelled by any owner
+	time3 := snapshot2.Timestamp.Add(time.Second)
+	snapshot3 := &ledger.SnapshotBlock{Height: expirationHeight + 1, Timestamp: &time3, Hash: types.DataHash([]byte{10, 3})}
+	db.snapshotBlockList = append(db.snapshotBlockList, snapshot3)
+	block23Data, _ := abi.ABIMultiSig.PackMethod(abi.MethodNameMultiSigApprove, walletId, proposalId2)
+	block23 := &ledger.AccountBlock{
+		Height:         3,
+		ToAddress:      addr5,
+		AccountAddress: addr2,
+		Amount:         big.NewInt(0),
+		TokenId:        ledger.ViteTokenId,
+		BlockType:      ledger.BlockTypeSendCall,
+		Fee:            big.NewInt(0),
+		Data:           block23Data,
+		Hash:           types.DataHash([]byte{2, 3}),
+	}
+	block55.FromBlockHash = block23.Hash
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveApproveBlock3, isRetry, err := vm.RunV2(db, block55, block23, NewTestGlobalStatus(0, snapshot3))
+	if receiveApproveBlock3 == nil || isRetry ||
+		err == nil || err.Error() != util.ErrInvalidMethodParam.Error() {
+		t.Fatalf("receive expired approve transaction error")
+	}
+
+	block55.FromBlockHash = block22.Hash
+	vm = NewVM(nil)
+	db.addr = addr5
+	receiveCancelBlock2, isRetry, err := vm.RunV2(db, block55, block22, NewTestGlobalStatus(0, snapshot3))
+	if receiveCancelBlock2 == nil || isRetry || err != nil ||
+		len(db.storageMap[addr5][ToKey(abi.GetMultiSigProposalKey(walletId, proposalId2))]) != 0 {
+		t.Fatalf("receive cancel transaction error")
+	}
+}
+
 func TestContractsMintageV2(t *testing.T) {
 	// prepare db
 	viteTotalSupply := new(big.Int).Mul(big.NewInt(1e9), big.NewInt(1e18))
@@ -2,6 +2,7 @@ package vm
 
 import (
 	"bytes"
+	"github.com/vitelabs/go-vite/common/fork"
 	"github.com/vitelabs/go-vite/common/helper"
 	"github.com/vitelabs/go-vite/common/types"
 	"github.com/vitelabs/go-vite/ledger"
@@ -331,13 +332,17 @@ func gasCall(vm *VM, c *contract, stack *stack, mem *memory, memorySize uint64)
 	toAddrBig, tokenIDBig, amount, inOffset, inSize := stack.back(0), stack.back(1), stack.back(2), stack.back(3), stack.back(4)
 	toAddress, _ := types.BigToAddress(toAddrBig)
 	tokenID, _ := types.BigToTokenTypeId(tokenIDBig)
+	sb, err := c.db.LatestSnapshotBlock()
+	if err != nil {
+		return 0, true, err
+	}
 	cost, err := gasRequiredForSendBlock(util.MakeSendBlock(
 		c.block.AccountAddress,
 		toAddress,
 		ledger.BlockTypeSendCall,
 		amount,
 		tokenID,
-		mem.get(inOffset.Int64(), inSize.Int64())))
+		mem.get(inOffset.Int64(), inSize.Int64())), sb.Height)
 	if err != nil {
 		return 0, true, err
 	}
@@ -373,7 +378,11 @@ func GasRequiredForBlock(db vm_db.VmDb, block *ledger.AccountBlock) (uint64, err
 	if block.BlockType == ledger.BlockTypeReceive {
 		return gasReceive(block, nil)
 	}
-	cost, err := gasRequiredForSendBlock(block)
+	sb, err := db.LatestSnapshotBlock()
+	if err != nil {
+		return 0, err
+	}
+	cost, err := gasRequiredForSendBlock(block, sb.Height)
 	if err != nil {
 		return 0, err
 	}
@@ -391,11 +400,11 @@ func GasRequiredForBlock(db vm_db.VmDb, block *ledger.AccountBlock) (uint64, err
 	return cost, nil
 }
 
-func gasRequiredForSendBlock(block *ledger.AccountBlock) (uint64, error) {
+func gasRequiredForSendBlock(block *ledger.AccountBlock, sbHeight uint64) (uint64, error) {
 	if block.BlockType == ledger.BlockTypeSendCreate {
 		return gasNormalSendCall(block)
 	} else if block.BlockType == ledger.BlockTypeSendCall {
-		return gasUserSendCall(block)
+		return gasUserSendCall(block, sbHeight)
 	} else {
 		return 0, util.ErrBlockTypeNotSupported
 	}
@@ -417,9 +426,9 @@ func gasReceive(block *ledger.AccountBlock, meta *ledger.ContractMeta) (uint64,
 	return util.IntrinsicGasCost(nil, false, confirmTime)
 }
 
-func gasUserSendCall(block *ledger.AccountBlock) (uint64, error) {
-	if types.IsBuiltinContractAddrInUse(block.ToAddress) {
-		method, ok, err := contracts.GetBuiltinContractMethod(block.ToAddress, block.Data)
+func gasUserSendCall(block *ledger.AccountBlock, sbHeight uint64) (uint64, error) {
+	if fork.IsBuiltinContractAddrInUse(block.ToAddress, sbHeight) {
+		method, ok, err := contracts.GetBuiltinContractMethod(block.ToAddress, block.Data, sbHeight)
 		if !ok || err != nil {
 			return 0, util.ErrAbiMethodNotFound
 		}
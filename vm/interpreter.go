@@ -72,6 +72,18 @@ func (i *interpreter) runLoop(vm *VM, c *contract) (ret []byte, err error) {
 			mem.resize(memorySize)
 		}
 
+		if vm.tracer != nil {
+			vm.tracer.CaptureState(&TraceState{
+				Addr:      c.codeAddr,
+				Pc:        currentPc,
+				Op:        opCodeToString[op],
+				Cost:      cost,
+				QuotaLeft: c.quotaLeft,
+				Stack:     st.data,
+				Memory:    mem.store,
+			})
+		}
+
 		res, err := operation.execute(&pc, vm, c, mem, st)
 
 		if nodeConfig.IsDebug {
@@ -0,0 +1,167 @@
+package vm
+
+import (
+	"math/big"
+
+	"github.com/vitelabs/go-vite/common/types"
+)
+
+// Tracer collects the execution information of the interpreter, a tracer is
+// set by VM.SetTracer and is called synchronously by the interpreter loop.
+type Tracer interface {
+	// CaptureStart is called before the code of a contract is run, data is the calldata of the contract.
+	CaptureStart(addr types.Address, data []byte, quotaLeft uint64)
+	// CaptureState is called before an opcode is executed, after the quota is used and the memory is resized.
+	CaptureState(state *TraceState)
+	// CaptureEnd is called after the code of a contract is run.
+	CaptureEnd(ret []byte, quotaLeft uint64, err error)
+}
+
+// TraceState is the interpreter state passed to Tracer.CaptureState.
+// Stack and Memory are shared with the interpreter and must not be modified or kept.
+type TraceState struct {
+	Addr      types.Address
+	Pc        uint64
+	Op        string
+	Cost      uint64
+	QuotaLeft uint64
+	Stack     []*big.Int
+	Memory    []byte
+}
+
+// SetTracer sets a tracer to collect the execution information of the interpreter
+func (vm *VM) SetTracer(tracer Tracer) {
+	vm.tracer = tracer
+}
+
+// MemoryWrite is the memory written by an opcode
+type MemoryWrite struct {
+	Offset uint64
+	Data   []byte
+}
+
+// StorageWrite is the storage written by SSTORE
+type StorageWrite struct {
+	Key   []byte
+	Value []byte
+}
+
+// StructLog is a step of the interpreter collected by StructLogger
+type StructLog struct {
+	Depth        int
+	Addr         types.Address
+	Pc           uint64
+	Op           string
+	Cost         uint64
+	QuotaLeft    uint64
+	StackTop     *big.Int
+	MemoryWrite  *MemoryWrite
+	StorageWrite *StorageWrite
+}
+
+// StructLogger is the default tracer, it records every step of the interpreter
+// with the stack top and the memory and storage written by the step.
+type StructLogger struct {
+	logs  []*StructLog
+	depth int
+
+	// memory written by the last step, filled with data when the next step or the end is captured
+	pendingMemory *MemoryWrite
+
+	ret []byte
+	err error
+}
+
+// NewStructLogger returns a StructLogger
+func NewStructLogger() *StructLogger {
+	return &StructLogger{}
+}
+
+// CaptureStart implements Tracer
+func (l *StructLogger) CaptureStart(addr types.Address, data []byte, quotaLeft uint64) {
+	l.depth++
+}
+
+// CaptureState implements Tracer
+func (l *StructLogger) CaptureState(state *TraceState) {
+	l.fillPendingMemory(state.Memory)
+
+	log := &StructLog{
+		Depth:     l.depth,
+		Addr:      state.Addr,
+		Pc:        state.Pc,
+		Op:        state.Op,
+		Cost:      state.Cost,
+		QuotaLeft: state.QuotaLeft,
+	}
+
+	stackLen := len(state.Stack)
+	if stackLen > 0 {
+		log.StackTop = new(big.Int).Set(state.Stack[stackLen-1])
+	}
+
+	back := func(n int) *big.Int {
+		return state.Stack[stackLen-n-1]
+	}
+	switch state.Op {
+	case "MSTORE":
+		l.setPendingMemory(back(0), big.NewInt(32))
+	case "MSTORE8":
+		l.setPendingMemory(back(0), big.NewInt(1))
+	case "CALLDATACOPY", "CODECOPY", "RETURNDATACOPY":
+		l.setPendingMemory(back(0), back(2))
+	case "EXTCODECOPY":
+		l.setPendingMemory(back(1), back(3))
+	case "SSTORE":
+		key, _ := types.BigToHash(back(0))
+		log.StorageWrite = &StorageWrite{Key: key.Bytes(), Value: back(1).Bytes()}
+	}
+	if l.pendingMemory != nil {
+		log.MemoryWrite = l.pendingMemory
+	}
+
+	l.logs = append(l.logs, log)
+}
+
+// CaptureEnd implements Tracer
+func (l *StructLogger) CaptureEnd(ret []byte, quotaLeft uint64, err error) {
+	l.pendingMemory = nil
+	l.depth--
+	if l.depth == 0 {
+		l.ret = ret
+		l.err = err
+	}
+}
+
+// StructLogs returns the steps captured
+func (l *StructLogger) StructLogs() []*StructLog {
+	return l.logs
+}
+
+// Output returns the return data of the outermost contract
+func (l *StructLogger) Output() []byte {
+	return l.ret
+}
+
+// Error returns the error of the outermost contract
+func (l *StructLogger) Error() error {
+	return l.err
+}
+
+func (l *StructLogger) setPendingMemory(offset, size *big.Int) {
+	if !offset.IsUint64() || !size.IsUint64() || size.Sign() == 0 {
+		return
+	}
+	l.pendingMemory = &MemoryWrite{Offset: offset.Uint64(), Data: make([]byte, size.Uint64())}
+}
+
+func (l *StructLogger) fillPendingMemory(memory []byte) {
+	if l.pendingMemory == nil {
+		return
+	}
+	offset := l.pendingMemory.Offset
+	if offset < uint64(len(memory)) {
+		copy(l.pendingMemory.Data, memory[offset:])
+	}
+	l.pendingMemory = nil
+}
@@ -0,0 +1,66 @@
+package vm
+
+import (
+	"bytes"
+	"math/big"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/helper"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/ledger"
+)
+
+func TestStructLogger(t *testing.T) {
+	// return 1+2
+	code := []byte{byte(PUSH1), 1, byte(PUSH1), 2, byte(ADD), byte(PUSH1), 0, byte(MSTORE), byte(PUSH1), 32, byte(PUSH1), 0, byte(RETURN)}
+	addr := types.Address{1, 1}
+
+	vm := NewVM(nil)
+	vm.i = newInterpreter(1, false)
+	tracer := NewStructLogger()
+	vm.SetTracer(tracer)
+
+	sendCallBlock := &ledger.AccountBlock{
+		ToAddress: addr,
+		BlockType: ledger.BlockTypeSendCall,
+		Amount:    big.NewInt(0),
+		Fee:       big.NewInt(0),
+		TokenId:   ledger.ViteTokenId,
+	}
+	receiveCallBlock := &ledger.AccountBlock{
+		AccountAddress: addr,
+		BlockType:      ledger.BlockTypeReceive,
+	}
+	c := newContract(receiveCallBlock, newNoDatabase(), sendCallBlock, nil, 1000000)
+	c.setCallCode(addr, code)
+	ret, err := c.run(vm)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := helper.LeftPadBytes([]byte{3}, 32)
+	if !bytes.Equal(tracer.Output(), ret) || !bytes.Equal(ret, result) {
+		t.Fatalf("output is %v, expected %v", tracer.Output(), result)
+	}
+
+	logs := tracer.StructLogs()
+	ops := []string{"PUSH1", "PUSH1", "ADD", "PUSH1", "MSTORE", "PUSH1", "PUSH1", "RETURN"}
+	if len(logs) != len(ops) {
+		t.Fatalf("step count is %d, expected %d", len(logs), len(ops))
+	}
+	for i, log := range logs {
+		if log.Op != ops[i] || log.Addr != addr || log.Depth != 1 {
+			t.Fatalf("step %d is %+v, expected op %s", i, log, ops[i])
+		}
+	}
+
+	if logs[2].StackTop.Cmp(big.NewInt(2)) != 0 {
+		t.Fatalf("stack top of ADD is %v, expected 2", logs[2].StackTop)
+	}
+	if logs[4].MemoryWrite == nil || logs[4].MemoryWrite.Offset != 0 || !bytes.Equal(logs[4].MemoryWrite.Data, result) {
+		t.Fatalf("memory write of MSTORE is %+v", logs[4].MemoryWrite)
+	}
+	if logs[len(logs)-1].QuotaLeft != c.quotaLeft {
+		t.Fatalf("quota left is %d, expected %d", logs[len(logs)-1].QuotaLeft, c.quotaLeft)
+	}
+}
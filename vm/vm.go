@@ -118,6 +118,7 @@ type VM struct {
 	i            *interpreter
 	globalStatus util.GlobalStatus
 	reader       util.ConsensusReader
+	tracer       Tracer
 }
 
 // NewVM constructor of VM
@@ -404,7 +405,11 @@ func (vm *VM) sendCall(db vm_db.VmDb, block *ledger.AccountBlock, useQuota bool,
 	defer monitor.LogTimerConsuming([]string{"vm", "sendCall"}, time.Now())
 	// check can make transaction
 	quotaLeft := quotaTotal
-	if p, ok, err := contracts.GetBuiltinContractMethod(block.ToAddress, block.Data); ok {
+	sb, err := db.LatestSnapshotBlock()
+	if err != nil {
+		return nil, err
+	}
+	if p, ok, err := contracts.GetBuiltinContractMethod(block.ToAddress, block.Data, sb.Height); ok {
 		if err != nil {
 			return nil, err
 		}
@@ -474,7 +479,9 @@ func (vm *VM) receiveCall(db vm_db.VmDb, block *ledger.AccountBlock, sendBlock *
 		vm.updateBlock(db, block, util.ErrDepth, 0, 0)
 		return &vm_db.VmAccountBlock{block, db}, noRetry, util.ErrDepth
 	}
-	if p, ok, _ := contracts.GetBuiltinContractMethod(block.AccountAddress, sendBlock.Data); ok {
+	sb, err := db.LatestSnapshotBlock()
+	util.DealWithErr(err)
+	if p, ok, _ := contracts.GetBuiltinContractMethod(block.AccountAddress, sendBlock.Data, sb.Height); ok {
 		util.AddBalance(db, &sendBlock.TokenId, sendBlock.Amount)
 		blockListToSend, err := p.DoReceive(db, block, sendBlock, vm)
 		if err == nil {
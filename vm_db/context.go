@@ -51,7 +51,12 @@ func (vdb *vmDb) IsContractAccount() (bool, error) {
 		return false, errors.New("No context, vdb.address is nil")
 	}
 
-	return vdb.chain.IsContractAccount(*vdb.address)
+	// the builtin contracts added by forks are in use since the fork point
+	sb, err := vdb.LatestSnapshotBlock()
+	if err != nil {
+		return false, err
+	}
+	return vdb.chain.IsContractAccountInSnapshot(*vdb.address, sb.Height)
 }
 
 func (vdb *vmDb) GetCallDepth(sendBlockHash *types.Hash) (uint16, error) {
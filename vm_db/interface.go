@@ -15,6 +15,8 @@ type VmAccountBlock struct {
 type Chain interface {
 	IsContractAccount(address types.Address) (bool, error)
 
+	IsContractAccountInSnapshot(address types.Address, snapshotHeight uint64) (bool, error)
+
 	GetQuotaUsedList(address types.Address) []types.QuotaInfo
 
 	GetBalance(addr types.Address, tokenId types.TokenTypeId) (*big.Int, error)
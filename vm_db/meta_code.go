@@ -22,6 +22,14 @@ func (vdb *vmDb) GetContractMeta() (*ledger.ContractMeta, error) {
 		}
 	}
 
+	if types.IsForkBuiltinContractAddr(*vdb.address) {
+		sb, err := vdb.LatestSnapshotBlock()
+		if err != nil {
+			return nil, err
+		}
+		return ledger.GetBuiltinContractMeta(*vdb.address, sb.Height), nil
+	}
+
 	return vdb.chain.GetContractMeta(*vdb.address)
 }
 
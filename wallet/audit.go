@@ -0,0 +1,64 @@
+package wallet
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/wallet/entropystore"
+)
+
+// AuditLog appends the unlock, lock and sign events to a file, one json object per line
+type AuditLog struct {
+	mutex sync.Mutex
+	file  *os.File
+}
+
+type auditRecord struct {
+	Time         string         `json:"time"`
+	Event        string         `json:"event"`
+	EntropyStore string         `json:"entropyStore"`
+	PrimaryAddr  types.Address  `json:"primaryAddr"`
+	Addr         *types.Address `json:"addr,omitempty"`
+	Timeout      uint64         `json:"timeout,omitempty"` // seconds
+	Indexes      []uint32       `json:"indexes,omitempty"`
+}
+
+func OpenAuditLog(path string) (*AuditLog, error) {
+	file, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0600)
+	if err != nil {
+		return nil, err
+	}
+	return &AuditLog{file: file}, nil
+}
+
+func (a *AuditLog) Write(event entropystore.UnlockEvent) error {
+	record := &auditRecord{
+		Time:         time.Now().Format(time.RFC3339),
+		Event:        event.Event(),
+		EntropyStore: event.EntropyStoreFile,
+		PrimaryAddr:  event.PrimaryAddr,
+		Timeout:      uint64(event.Timeout / time.Second),
+		Indexes:      event.Indexes,
+	}
+	if event.Signed() {
+		record.Addr = &event.Addr
+	}
+	data, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	_, err = a.file.Write(append(data, '\n'))
+	return err
+}
+
+func (a *AuditLog) Close() error {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return a.file.Close()
+}
@@ -0,0 +1,71 @@
+package wallet_test
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vitelabs/go-vite/wallet"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+func TestManager_AuditLog(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet_audit")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	auditFile := filepath.Join(dir, "audit.log")
+
+	manager := wallet.New(&wallet.Config{DataDir: dir, AuditLog: auditFile})
+	manager.Start()
+	_, em, err := manager.NewMnemonicAndEntropyStore("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key0, _ := em.DeriveForIndexPathWithPassphrase(0, "123456")
+	addr0, _ := key0.Address()
+	_, key1, _ := em.DeriveForIndexPathWithPassphrase(1, "123456")
+	addr1, _ := key1.Address()
+
+	if err = manager.UnlockWithScope(em.GetEntropyStoreFile(), "123456", 0, []uint32{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err = manager.SignData(*addr0, []byte("hello")); err != walleterrors.ErrAddressNotFound {
+		t.Fatalf("address out of scope should not be signed, err: %v", err)
+	}
+	if _, _, err = manager.SignData(*addr1, []byte("hello")); err != nil {
+		t.Fatal(err)
+	}
+	if err = manager.Lock(em.GetEntropyStoreFile()); err != nil {
+		t.Fatal(err)
+	}
+	manager.Stop()
+
+	f, err := os.Open(auditFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	var events []string
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var record struct {
+			Event string `json:"event"`
+			Addr  string `json:"addr"`
+		}
+		if err = json.Unmarshal(scanner.Bytes(), &record); err != nil {
+			t.Fatal(err)
+		}
+		if record.Event == "Signed" && record.Addr != addr1.String() {
+			t.Errorf("wrong address %s of signed event", record.Addr)
+		}
+		events = append(events, record.Event)
+	}
+	if len(events) < 3 || events[0] != "Unlocked" || events[1] != "Signed" || events[2] != "Locked" {
+		t.Fatalf("unexpected events %v", events)
+	}
+}
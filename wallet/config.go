@@ -3,4 +3,7 @@ package wallet
 type Config struct {
 	DataDir        string
 	MaxSearchIndex uint32
+	ExternalSigner string // endpoint of the external signer, http url or ipc path
+	AuditLog       string // file of the unlock, lock and sign events, disabled if empty
+	KDFProfile     string // kdf profile of the new and re-encrypted entropy stores, "standard" if empty
 }
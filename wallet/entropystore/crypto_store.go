@@ -2,15 +2,11 @@ package entropystore
 
 import (
 	"bytes"
-	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"github.com/tyler-smith/go-bip39"
 	"github.com/vitelabs/go-vite/common/types"
-	vcrypto "github.com/vitelabs/go-vite/crypto"
 	"github.com/vitelabs/go-vite/wallet/hd-bip/derivation"
-	"github.com/vitelabs/go-vite/wallet/walleterrors"
-	"golang.org/x/crypto/scrypt"
 	"io/ioutil"
 	"os"
 	"path/filepath"
@@ -26,8 +22,7 @@ const (
 	// memory and taking approximately 1s CPU time on chain modern processor.
 	StandardScryptP = 1
 
-	scryptR      = 8
-	scryptKeyLen = 32
+	scryptR = 8
 
 	aesMode    = "aes-256-gcm"
 	scryptName = "scrypt"
@@ -65,81 +60,82 @@ func (ks CryptoStore) ExtractEntropy(passphrase string) ([]byte, error) {
 }
 
 func (ks CryptoStore) StoreEntropy(entropy []byte, primaryAddr types.Address, passphrase string) error {
+	return ks.StoreEntropyWithProfile(entropy, primaryAddr, passphrase, kdfProfiles[DefaultKDFProfile])
+}
+
+func (ks CryptoStore) StoreEntropyWithProfile(entropy []byte, primaryAddr types.Address, passphrase string, profile KDFProfile) error {
 
-	keyjson, e := EncryptEntropy(entropy, primaryAddr, passphrase)
+	keyjson, e := EncryptEntropyWithProfile(entropy, primaryAddr, passphrase, profile)
 	if e != nil {
 		return e
 	}
 
-	e = writeKeyFile(ks.EntropyStoreFilename, keyjson)
+	e = WriteKeyFile(ks.EntropyStoreFilename, keyjson)
 	if e != nil {
 		return e
 	}
 	return nil
 }
 
-func parseJson(keyjson []byte) (k *entropyJSON, kAddress *types.Address, cipherData, nonce, salt []byte, err error) {
-	k = new(entropyJSON)
-	// parse and check entropyJSON params
-	if err := json.Unmarshal(keyjson, k); err != nil {
-		return nil, nil, nil, nil, nil, err
+// ChangePassphrase encrypts the entropy again by the new passphrase and profile,
+// the file is replaced by rename so it is either the old one or the new one.
+func (ks CryptoStore) ChangePassphrase(oldPassphrase, newPassphrase string, profile KDFProfile) error {
+	keyjson, err := ioutil.ReadFile(ks.EntropyStoreFilename)
+	if err != nil {
+		return err
 	}
-	if k.Version != cryptoStoreVersion {
-		return nil, nil, nil, nil, nil, fmt.Errorf("version number error : %v", k.Version)
+	entropy, err := DecryptEntropy(keyjson, oldPassphrase)
+	if err != nil {
+		return err
 	}
-
-	if !types.IsValidHexAddress(k.PrimaryAddress) {
-		return nil, nil, nil, nil, nil, fmt.Errorf("address invalid ： %v", k.PrimaryAddress)
+	k := new(entropyJSON)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return err
 	}
 	addr, err := types.HexToAddress(k.PrimaryAddress)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return err
 	}
+	return ks.StoreEntropyWithProfile(entropy, addr, newPassphrase, profile)
+}
 
-	// parse and check  cryptoJSON params
-	if k.Crypto.CipherName != aesMode {
-		return nil, nil, nil, nil, nil, fmt.Errorf("cipherName  error : %v", k.Crypto.CipherName)
+func parseJson(keyjson []byte) (k *entropyJSON, kAddress *types.Address, err error) {
+	k = new(entropyJSON)
+	// parse and check entropyJSON params
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return nil, nil, err
 	}
-	if k.Crypto.KDF != scryptName {
-		return nil, nil, nil, nil, nil, fmt.Errorf("scryptName  error : %v", k.Crypto.KDF)
+	if k.Version < cryptoStoreVersion || k.Version > cryptoStoreVersionMax {
+		return nil, nil, fmt.Errorf("version number error : %v, this node supports up to %v, please upgrade it", k.Version, cryptoStoreVersionMax)
 	}
-	cipherData, err = hex.DecodeString(k.Crypto.CipherText)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+
+	if !types.IsValidHexAddress(k.PrimaryAddress) {
+		return nil, nil, fmt.Errorf("address invalid ： %v", k.PrimaryAddress)
 	}
-	nonce, err = hex.DecodeString(k.Crypto.Nonce)
+	addr, err := types.HexToAddress(k.PrimaryAddress)
 	if err != nil {
-		return nil, nil, nil, nil, nil, err
+		return nil, nil, err
 	}
 
-	// parse and check  scryptParams params
-	scryptParams := k.Crypto.ScryptParams
-	salt, err = hex.DecodeString(scryptParams.Salt)
-	if err != nil {
-		return nil, nil, nil, nil, nil, err
+	// parse and check  CryptoJSON params
+	if err := k.Crypto.Check(k.Version); err != nil {
+		return nil, nil, err
 	}
 
-	return k, &addr, cipherData, nonce, salt, nil
+	return k, &addr, nil
 }
 
 func DecryptEntropy(entropyJson []byte, passphrase string) ([]byte, error) {
-	k, kAddress, cipherData, nonce, salt, err := parseJson(entropyJson)
+	k, kAddress, err := parseJson(entropyJson)
 	if err != nil {
 		return nil, err
 	}
-	scryptParams := k.Crypto.ScryptParams
 
-	// begin decrypt
-	derivedKey, err := scrypt.Key([]byte(passphrase), salt, scryptParams.N, scryptParams.R, scryptParams.P, scryptParams.KeyLen)
+	entropy, err := DecryptSecret(&k.Crypto, passphrase)
 	if err != nil {
 		return nil, err
 	}
 
-	entropy, err := vcrypto.AesGCMDecrypt(derivedKey[:32], cipherData, []byte(nonce))
-	if err != nil {
-		return nil, walleterrors.ErrDecryptEntropy
-	}
-
 	mnemonic, e := bip39.NewMnemonic(entropy)
 	if e != nil {
 		return nil, e
@@ -160,50 +156,28 @@ func DecryptEntropy(entropyJson []byte, passphrase string) ([]byte, error) {
 }
 
 func EncryptEntropy(seed []byte, addr types.Address, passphrase string) ([]byte, error) {
-	n := StandardScryptN
-	p := StandardScryptP
-	pwdArray := []byte(passphrase)
-	salt := vcrypto.GetEntropyCSPRNG(32)
-	derivedKey, err := scrypt.Key(pwdArray, salt, n, scryptR, p, scryptKeyLen)
-	if err != nil {
-		return nil, err
-	}
-	encryptKey := derivedKey[:32]
+	return EncryptEntropyWithProfile(seed, addr, passphrase, kdfProfiles[DefaultKDFProfile])
+}
 
-	ciphertext, nonce, err := vcrypto.AesGCMEncrypt(encryptKey, seed)
+func EncryptEntropyWithProfile(seed []byte, addr types.Address, passphrase string, profile KDFProfile) ([]byte, error) {
+	cryptoJSON, version, err := EncryptSecret(seed, passphrase, profile)
 	if err != nil {
 		return nil, err
 	}
 
-	ScryptParams := scryptParams{
-		N:      n,
-		R:      scryptR,
-		P:      p,
-		KeyLen: scryptKeyLen,
-		Salt:   hex.EncodeToString(salt),
-	}
-
-	cryptoJSON := cryptoJSON{
-		CipherName:   aesMode,
-		CipherText:   hex.EncodeToString(ciphertext),
-		Nonce:        hex.EncodeToString(nonce),
-		KDF:          scryptName,
-		ScryptParams: ScryptParams,
-	}
-
 	encryptedKeyJSON := entropyJSON{
 
 		PrimaryAddress: addr.String(),
 		Crypto:         cryptoJSON,
-		Version:        cryptoStoreVersion,
+		Version:        version,
 		Timestamp:      time.Now().UTC().Unix(),
 	}
 
 	return json.Marshal(encryptedKeyJSON)
 }
 
-func writeKeyFile(file string, content []byte) error {
-
+// WriteKeyFile writes the content to a temp file in the same dir first, then renames it to the file
+func WriteKeyFile(file string, content []byte) error {
 	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
 		return err
 	}
@@ -9,7 +9,10 @@ import (
 	"github.com/vitelabs/go-vite/wallet/entropystore"
 	"github.com/vitelabs/go-vite/wallet/hd-bip/derivation"
 	"github.com/vitelabs/go-vite/wallet/walleterrors"
+	"io/ioutil"
+	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -102,3 +105,92 @@ func TestDecryptEntropy(t *testing.T) {
 	}
 
 }
+
+func TestCryptoStore_ChangePassphrase(t *testing.T) {
+	dir, err := ioutil.TempDir("", "entropystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manager, err := entropystore.StoreNewEntropy(dir, TestMnemonic, "123456", entropystore.DefaultMaxIndex)
+	if err != nil {
+		t.Fatal(err)
+	}
+	profile, err := entropystore.GetKDFProfile(entropystore.Argon2idKDFProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := manager.ChangePassphrase("1234567", "654321", *profile); err != walleterrors.ErrDecryptEntropy {
+		t.Fatalf("wrong passphrase should be rejected, err: %v", err)
+	}
+	if err := manager.ChangePassphrase("123456", "654321", *profile); err != nil {
+		t.Fatal(err)
+	}
+
+	keyjson, err := ioutil.ReadFile(manager.GetEntropyStoreFile())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(keyjson), `"kdf":"argon2id"`) || !strings.Contains(string(keyjson), `"seedstoreversion":2`) {
+		t.Fatalf("not encrypted by argon2id: %s", keyjson)
+	}
+	if _, err := entropystore.DecryptEntropy(keyjson, "123456"); err != walleterrors.ErrDecryptEntropy {
+		t.Fatal("old passphrase should be rejected")
+	}
+	entropy, err := entropystore.DecryptEntropy(keyjson, "654321")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if hex.EncodeToString(entropy) != TestEntropy {
+		t.Fatal("entropy changed")
+	}
+
+	newer := strings.Replace(string(keyjson), `"seedstoreversion":2`, `"seedstoreversion":3`, 1)
+	if _, err := entropystore.DecryptEntropy([]byte(newer), "654321"); err == nil {
+		t.Fatal("newer version should be rejected")
+	}
+}
+
+func TestDecryptSecret_ScryptLimits(t *testing.T) {
+	profile := entropystore.KDFProfile{KDF: "scrypt", ScryptN: 1 << 10, ScryptP: 1}
+	c, _, e := entropystore.EncryptSecret([]byte("secret"), "123456", profile)
+	if e != nil {
+		t.Fatal(e)
+	}
+	if _, e := entropystore.DecryptSecret(&c, "123456"); e != nil {
+		t.Fatal(e)
+	}
+
+	// every param is in range, but n*r needs 64GB memory
+	c.ScryptParams.N, c.ScryptParams.R, c.ScryptParams.P = 1<<22, 32, 1
+	if _, e := entropystore.DecryptSecret(&c, "123456"); e == nil || e == walleterrors.ErrDecryptEntropy {
+		t.Fatal("n*r should be limited")
+	}
+
+	c.ScryptParams.N, c.ScryptParams.R, c.ScryptParams.P = 1<<10, 1<<20, 1
+	if _, e := entropystore.DecryptSecret(&c, "123456"); e == nil || e == walleterrors.ErrDecryptEntropy {
+		t.Fatal("r should be limited")
+	}
+
+	c.ScryptParams.N, c.ScryptParams.R, c.ScryptParams.P = 1<<10, 8, 1<<20
+	if _, e := entropystore.DecryptSecret(&c, "123456"); e == nil || e == walleterrors.ErrDecryptEntropy {
+		t.Fatal("p should be limited")
+	}
+
+	// scrypt and argon2 allocate the whole key
+	c.ScryptParams.N, c.ScryptParams.R, c.ScryptParams.P, c.ScryptParams.KeyLen = 1<<10, 8, 1, 1<<30
+	if _, e := entropystore.DecryptSecret(&c, "123456"); e == nil || e == walleterrors.ErrDecryptEntropy {
+		t.Fatal("keylen of scrypt should be limited")
+	}
+
+	argon2Profile := entropystore.KDFProfile{KDF: "argon2id", Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+	c, _, e = entropystore.EncryptSecret([]byte("secret"), "123456", argon2Profile)
+	if e != nil {
+		t.Fatal(e)
+	}
+	c.Argon2Params.KeyLen = 1 << 30
+	if _, e := entropystore.DecryptSecret(&c, "123456"); e == nil || e == walleterrors.ErrDecryptEntropy {
+		t.Fatal("keylen of argon2 should be limited")
+	}
+}
@@ -0,0 +1,199 @@
+package entropystore
+
+import (
+	"encoding/hex"
+	"fmt"
+	"sort"
+
+	"github.com/pkg/errors"
+	vcrypto "github.com/vitelabs/go-vite/crypto"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	DefaultKDFProfile  = "standard"
+	StrongKDFProfile   = "scrypt-strong"
+	Argon2idKDFProfile = "argon2id"
+
+	argon2idName = "argon2id"
+
+	kdfKeyLen  = 32
+	kdfSaltLen = 32
+
+	// limits of the params read from a file, so a crafted file can't exhaust the memory or the cpu of node.
+	// scrypt uses 128*N*R bytes of memory, N*R is limited to 1GB like the strong profile.
+	// The key length is always kdfKeyLen, the key is only used by AES-256.
+	scryptNMax       = 1 << 22
+	scryptRMax       = 32
+	scryptPMax       = 16
+	scryptNRMax      = 1 << 23
+	argon2TimeMax    = 64
+	argon2MemoryMax  = 4 * 1024 * 1024
+	argon2ThreadsMax = 64
+)
+
+// KDFProfile is the cost of deriving the encryption key from the passphrase
+type KDFProfile struct {
+	KDF string
+
+	ScryptN int
+	ScryptP int
+
+	Argon2Time    uint32
+	Argon2Memory  uint32 // KiB
+	Argon2Threads uint8
+}
+
+var kdfProfiles = map[string]KDFProfile{
+	// 256MB memory and approximately 1s CPU time
+	DefaultKDFProfile: {KDF: scryptName, ScryptN: StandardScryptN, ScryptP: StandardScryptP},
+	// 1GB memory and approximately 4s CPU time
+	StrongKDFProfile: {KDF: scryptName, ScryptN: 1 << 20, ScryptP: StandardScryptP},
+	// 256MB memory, 3 passes on 4 lanes
+	Argon2idKDFProfile: {KDF: argon2idName, Argon2Time: 3, Argon2Memory: 256 * 1024, Argon2Threads: 4},
+	// 1GB memory, 4 passes on 4 lanes
+	"argon2id-strong": {KDF: argon2idName, Argon2Time: 4, Argon2Memory: 1024 * 1024, Argon2Threads: 4},
+}
+
+func GetKDFProfile(name string) (*KDFProfile, error) {
+	if name == "" {
+		name = DefaultKDFProfile
+	}
+	profile, ok := kdfProfiles[name]
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("unknown kdf profile %s, should be one of %v", name, KDFProfileNames()))
+	}
+	return &profile, nil
+}
+
+func KDFProfileNames() []string {
+	names := make([]string, 0, len(kdfProfiles))
+	for name := range kdfProfiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newCryptoJSON derives the key by the profile with a random salt, and returns the key and its params in file
+func (p KDFProfile) newCryptoJSON(passphrase string) (derivedKey []byte, c CryptoJSON, version int, err error) {
+	salt := vcrypto.GetEntropyCSPRNG(kdfSaltLen)
+	c = CryptoJSON{CipherName: aesMode, KDF: p.KDF}
+	switch p.KDF {
+	case scryptName:
+		c.ScryptParams = &scryptParams{
+			N:      p.ScryptN,
+			R:      scryptR,
+			P:      p.ScryptP,
+			KeyLen: kdfKeyLen,
+			Salt:   hex.EncodeToString(salt),
+		}
+		version = cryptoStoreVersion
+	case argon2idName:
+		c.Argon2Params = &argon2Params{
+			Time:    p.Argon2Time,
+			Memory:  p.Argon2Memory,
+			Threads: p.Argon2Threads,
+			KeyLen:  kdfKeyLen,
+			Salt:    hex.EncodeToString(salt),
+		}
+		version = cryptoStoreVersionArgon2
+	default:
+		return nil, c, 0, errors.New(fmt.Sprintf("unsupported kdf %s", p.KDF))
+	}
+	derivedKey, err = deriveKey(&c, passphrase)
+	return derivedKey, c, version, err
+}
+
+// EncryptSecret encrypts the secret with the key derived by the profile, version is the lowest store version can read it
+func EncryptSecret(secret []byte, passphrase string, profile KDFProfile) (c CryptoJSON, version int, err error) {
+	derivedKey, c, version, err := profile.newCryptoJSON(passphrase)
+	if err != nil {
+		return c, 0, err
+	}
+	ciphertext, nonce, err := vcrypto.AesGCMEncrypt(derivedKey[:kdfKeyLen], secret)
+	if err != nil {
+		return c, 0, err
+	}
+	c.CipherText = hex.EncodeToString(ciphertext)
+	c.Nonce = hex.EncodeToString(nonce)
+	return c, version, nil
+}
+
+// DecryptSecret derives the key by the params in file, the params are checked by deriveKey before use
+func DecryptSecret(c *CryptoJSON, passphrase string) ([]byte, error) {
+	cipherData, err := hex.DecodeString(c.CipherText)
+	if err != nil {
+		return nil, err
+	}
+	nonce, err := hex.DecodeString(c.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	derivedKey, err := deriveKey(c, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := vcrypto.AesGCMDecrypt(derivedKey[:kdfKeyLen], cipherData, nonce)
+	if err != nil {
+		return nil, walleterrors.ErrDecryptEntropy
+	}
+	return secret, nil
+}
+
+// Check returns error if the cipher or the kdf is not supported by the store version
+func (c *CryptoJSON) Check(version int) error {
+	if c.CipherName != aesMode {
+		return fmt.Errorf("cipherName  error : %v", c.CipherName)
+	}
+	switch {
+	case c.KDF == scryptName && c.ScryptParams != nil:
+	case c.KDF == argon2idName && c.Argon2Params != nil && version >= cryptoStoreVersionArgon2:
+	default:
+		return fmt.Errorf("kdf  error : %v", c.KDF)
+	}
+	return nil
+}
+
+// deriveKey derives the key by the params in file
+func deriveKey(c *CryptoJSON, passphrase string) ([]byte, error) {
+	switch c.KDF {
+	case scryptName:
+		params := c.ScryptParams
+		if params == nil {
+			return nil, errors.New("scryptparams missing")
+		}
+		if params.N <= 1 || params.N > scryptNMax ||
+			params.R <= 0 || params.R > scryptRMax ||
+			params.P <= 0 || params.P > scryptPMax ||
+			params.N*params.R > scryptNRMax || params.KeyLen != kdfKeyLen {
+			return nil, errors.New(fmt.Sprintf("scryptparams invalid, n: %v, r: %v, p: %v, keylen: %v",
+				params.N, params.R, params.P, params.KeyLen))
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, err
+		}
+		return scrypt.Key([]byte(passphrase), salt, params.N, params.R, params.P, params.KeyLen)
+	case argon2idName:
+		params := c.Argon2Params
+		if params == nil {
+			return nil, errors.New("argon2params missing")
+		}
+		if params.Time == 0 || params.Time > argon2TimeMax ||
+			params.Memory == 0 || params.Memory > argon2MemoryMax ||
+			params.Threads == 0 || params.Threads > argon2ThreadsMax || params.KeyLen != kdfKeyLen {
+			return nil, errors.New(fmt.Sprintf("argon2params invalid, time: %v, memory: %v, threads: %v, keylen: %v",
+				params.Time, params.Memory, params.Threads, params.KeyLen))
+		}
+		salt, err := hex.DecodeString(params.Salt)
+		if err != nil {
+			return nil, err
+		}
+		return argon2.IDKey([]byte(passphrase), salt, params.Time, params.Memory, params.Threads, params.KeyLen), nil
+	default:
+		return nil, errors.New(fmt.Sprintf("unsupported kdf %s", c.KDF))
+	}
+}
@@ -2,6 +2,8 @@ package entropystore
 
 import (
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip39"
@@ -14,6 +16,7 @@ import (
 const (
 	Locked   = "Locked"
 	UnLocked = "Unlocked"
+	Signed   = "Signed"
 
 	DefaultMaxIndex = uint32(100)
 )
@@ -21,24 +24,68 @@ const (
 type UnlockEvent struct {
 	EntropyStoreFile string
 	PrimaryAddr      types.Address // represent which seed we use the seed`s PrimaryAddress represents the seed
-	event            string        // "Unlocked Locked"
+	event            string        // "Unlocked Locked Signed"
+
+	Timeout time.Duration // the store will be locked after timeout, 0 means never, only for Unlocked
+	Indexes []uint32      // the indexes can be used, nil means all, only for Unlocked
+	Addr    types.Address // the address signed with, only for Signed
 }
 
 func (ue UnlockEvent) String() string {
-	return ue.EntropyStoreFile + " " + ue.PrimaryAddr.String() + " " + ue.event
+	s := ue.EntropyStoreFile + " " + ue.PrimaryAddr.String() + " " + ue.event
+	if ue.event == Signed {
+		s += " " + ue.Addr.String()
+	}
+	return s
+}
+
+// NewLockEvent is for the key stores out of entropy stores
+func NewLockEvent(file string, addr types.Address, unlocked bool, timeout time.Duration) UnlockEvent {
+	event := UnlockEvent{
+		EntropyStoreFile: file,
+		PrimaryAddr:      addr,
+		event:            Locked,
+	}
+	if unlocked {
+		event.event = UnLocked
+		event.Timeout = timeout
+	}
+	return event
+}
+
+// NewSignedEvent is for the signers out of entropy stores
+func NewSignedEvent(source string, primaryAddr types.Address, addr types.Address) UnlockEvent {
+	return UnlockEvent{
+		EntropyStoreFile: source,
+		PrimaryAddr:      primaryAddr,
+		event:            Signed,
+		Addr:             addr,
+	}
+}
+
+func (ue UnlockEvent) Event() string {
+	return ue.event
 }
 
 func (ue UnlockEvent) Unlocked() bool {
 	return ue.event == UnLocked
 }
 
+func (ue UnlockEvent) Signed() bool {
+	return ue.event == Signed
+}
+
 type Manager struct {
 	primaryAddr    types.Address
 	ks             CryptoStore
 	maxSearchIndex uint32
 
+	mutex           sync.RWMutex
 	unlockedSeed    []byte
 	unlockedEntropy []byte
+	unlockedIndexes map[uint32]struct{} // nil means all indexes are unlocked
+	unlockSeq       uint64
+	lockTimer       *time.Timer
 
 	unlockChangedLis func(event UnlockEvent)
 
@@ -56,10 +103,7 @@ func NewManager(entropyStoreFilename string, primaryAddr types.Address, maxSearc
 }
 
 func (km *Manager) IsAddrUnlocked(addr types.Address) bool {
-	if !km.IsUnlocked() {
-		return false
-	}
-	_, _, e := FindAddrFromSeed(km.unlockedSeed, addr, km.maxSearchIndex)
+	_, _, e := km.FindAddr(addr)
 	if e != nil {
 		return false
 	}
@@ -67,19 +111,44 @@ func (km *Manager) IsAddrUnlocked(addr types.Address) bool {
 }
 
 func (km *Manager) IsUnlocked() bool {
+	km.mutex.RLock()
+	defer km.mutex.RUnlock()
 	return km.unlockedSeed != nil
 }
 
+// IsIndexUnlocked returns false if the store is locked or the index is out of the scope of unlock
+func (km *Manager) IsIndexUnlocked(index uint32) bool {
+	seed, indexes := km.unlocked()
+	return seed != nil && inUnlockScope(indexes, index)
+}
+
+func (km *Manager) unlocked() (seed []byte, indexes map[uint32]struct{}) {
+	km.mutex.RLock()
+	defer km.mutex.RUnlock()
+	return km.unlockedSeed, km.unlockedIndexes
+}
+
+func inUnlockScope(indexes map[uint32]struct{}, index uint32) bool {
+	if indexes == nil {
+		return true
+	}
+	_, ok := indexes[index]
+	return ok
+}
+
 func (km *Manager) ListAddress(from, to uint32) ([]types.Address, error) {
 	if from > to {
 		return nil, errors.New("from > to")
 	}
-	if km.unlockedSeed == nil {
+	if !km.IsUnlocked() {
 		return nil, walleterrors.ErrLocked
 	}
 	addr := make([]types.Address, to-from)
 	addrIndex := 0
 	for i := from; i < to; i++ {
+		if !km.IsIndexUnlocked(i) {
+			continue
+		}
 		_, key, e := km.DeriveForIndexPath(i)
 		if e != nil {
 			return nil, e
@@ -92,34 +161,100 @@ func (km *Manager) ListAddress(from, to uint32) ([]types.Address, error) {
 		addrIndex++
 	}
 
-	return addr, nil
+	return addr[:addrIndex], nil
 }
 
 func (km *Manager) Unlock(passphrase string) error {
+	return km.UnlockWithScope(passphrase, 0, nil)
+}
+
+// UnlockWithScope unlocks the store until timeout, and only the addresses of the indexes can be used.
+// A timeout of 0 keeps the store unlocked until Lock is called, nil indexes means all the indexes.
+// Unlock again replaces the timeout and the indexes of the last unlock.
+func (km *Manager) UnlockWithScope(passphrase string, timeout time.Duration, indexes []uint32) error {
 	seed, entropy, e := km.ks.ExtractSeed(passphrase)
 	if e != nil {
 		return e
 	}
+
+	km.mutex.Lock()
 	km.unlockedSeed = seed
 	km.unlockedEntropy = entropy
-
-	if km.unlockChangedLis != nil {
-		km.unlockChangedLis(UnlockEvent{
-			EntropyStoreFile: km.GetEntropyStoreFile(),
-			PrimaryAddr:      km.primaryAddr,
-			event:            UnLocked})
+	km.unlockedIndexes = nil
+	if indexes != nil {
+		km.unlockedIndexes = make(map[uint32]struct{}, len(indexes))
+		for _, index := range indexes {
+			km.unlockedIndexes[index] = struct{}{}
+		}
 	}
+	km.unlockSeq++
+	km.stopLockTimer()
+	if timeout > 0 {
+		seq := km.unlockSeq
+		km.lockTimer = time.AfterFunc(timeout, func() {
+			km.lockIfSeq(seq)
+		})
+	}
+	km.mutex.Unlock()
+
+	km.notify(UnlockEvent{
+		EntropyStoreFile: km.GetEntropyStoreFile(),
+		PrimaryAddr:      km.primaryAddr,
+		event:            UnLocked,
+		Timeout:          timeout,
+		Indexes:          indexes})
 	return nil
 }
 
 func (km *Manager) Lock() {
+	km.mutex.Lock()
+	km.lock()
+	km.mutex.Unlock()
+
+	km.notify(UnlockEvent{
+		EntropyStoreFile: km.GetEntropyStoreFile(),
+		PrimaryAddr:      km.primaryAddr,
+		event:            Locked})
+}
+
+// lockIfSeq is called when the unlock timeout, the store is not locked if it has been unlocked again
+func (km *Manager) lockIfSeq(seq uint64) {
+	km.mutex.Lock()
+	if km.unlockSeq != seq || km.unlockedSeed == nil {
+		km.mutex.Unlock()
+		return
+	}
+	km.lock()
+	km.mutex.Unlock()
+
+	km.log.Info("entropy store is locked for unlock timeout", "method", "lockIfSeq", "file", km.GetEntropyStoreFile())
+	km.notify(UnlockEvent{
+		EntropyStoreFile: km.GetEntropyStoreFile(),
+		PrimaryAddr:      km.primaryAddr,
+		event:            Locked})
+}
 
+// lock should be called with mutex held
+func (km *Manager) lock() {
 	km.unlockedSeed = nil
-	if km.unlockChangedLis != nil {
-		km.unlockChangedLis(UnlockEvent{
-			EntropyStoreFile: km.GetEntropyStoreFile(),
-			PrimaryAddr:      km.primaryAddr,
-			event:            Locked})
+	km.unlockedEntropy = nil
+	km.unlockedIndexes = nil
+	km.stopLockTimer()
+}
+
+func (km *Manager) stopLockTimer() {
+	if km.lockTimer != nil {
+		km.lockTimer.Stop()
+		km.lockTimer = nil
+	}
+}
+
+func (km *Manager) notify(event UnlockEvent) {
+	km.mutex.RLock()
+	lis := km.unlockChangedLis
+	km.mutex.RUnlock()
+	if lis != nil {
+		lis(event)
 	}
 }
 
@@ -131,23 +266,42 @@ func (km *Manager) FindAddrWithPassphrase(passphrase string, addr types.Address)
 	return FindAddrFromSeed(seed, addr, km.maxSearchIndex)
 }
 
+// FindAddr only finds the addresses in the scope of unlock
 func (km *Manager) FindAddr(addr types.Address) (key *derivation.Key, index uint32, e error) {
-	if !km.IsUnlocked() {
+	seed, indexes := km.unlocked()
+	if seed == nil {
 		return nil, 0, walleterrors.ErrLocked
 	}
 
-	return FindAddrFromSeed(km.unlockedSeed, addr, km.maxSearchIndex)
+	key, index, e = FindAddrFromSeed(seed, addr, km.maxSearchIndex)
+	if e != nil {
+		return nil, 0, e
+	}
+	if !inUnlockScope(indexes, index) {
+		return nil, 0, walleterrors.ErrAddressNotFound
+	}
+	return key, index, nil
 }
 
 func (km *Manager) SignData(a types.Address, data []byte) (signedData, pubkey []byte, err error) {
-	if !km.IsUnlocked() {
-		return nil, nil, walleterrors.ErrLocked
+	key, _, e := km.FindAddr(a)
+	if e == walleterrors.ErrLocked {
+		return nil, nil, e
 	}
-	key, _, e := FindAddrFromSeed(km.unlockedSeed, a, km.maxSearchIndex)
 	if e != nil {
 		return nil, nil, walleterrors.ErrAddressNotFound
 	}
-	return key.SignData(data)
+	signedData, pubkey, err = key.SignData(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	km.notify(UnlockEvent{
+		EntropyStoreFile: km.GetEntropyStoreFile(),
+		PrimaryAddr:      km.primaryAddr,
+		event:            Signed,
+		Addr:             a})
+	return signedData, pubkey, nil
 }
 
 func (km *Manager) SignDataWithPassphrase(addr types.Address, passphrase string, data []byte) (signedData, pubkey []byte, err error) {
@@ -164,11 +318,24 @@ func (km *Manager) SignDataWithPassphrase(addr types.Address, passphrase string,
 }
 
 func (km *Manager) DeriveForFullPath(path string) (fpath string, key *derivation.Key, err error) {
-	if km.unlockedSeed == nil {
+	seed, indexes := km.unlocked()
+	if seed == nil {
 		return "", nil, walleterrors.ErrLocked
 	}
+	if indexes != nil {
+		inScope := false
+		for index := range indexes {
+			if path == fmt.Sprintf(derivation.ViteAccountPathFormat, index) {
+				inScope = true
+				break
+			}
+		}
+		if !inScope {
+			return "", nil, walleterrors.ErrNotInUnlockScope
+		}
+	}
 
-	key, e := derivation.DeriveForPath(path, km.unlockedSeed)
+	key, e := derivation.DeriveForPath(path, seed)
 	if e != nil {
 		return "", nil, e
 	}
@@ -198,15 +365,15 @@ func (km *Manager) DeriveForIndexPathWithPassphrase(index uint32, passphrase str
 	return km.DeriveForFullPathWithPassphrase(fmt.Sprintf(derivation.ViteAccountPathFormat, index), passphrase)
 }
 
-func (km Manager) GetPrimaryAddr() (primaryAddr types.Address) {
+func (km *Manager) GetPrimaryAddr() (primaryAddr types.Address) {
 	return km.primaryAddr
 }
 
-func (km Manager) GetEntropyStoreFile() string {
+func (km *Manager) GetEntropyStoreFile() string {
 	return km.ks.EntropyStoreFilename
 }
 
-func (km Manager) ExtractMnemonic(passphrase string) (string, error) {
+func (km *Manager) ExtractMnemonic(passphrase string) (string, error) {
 	entropy, err := km.ks.ExtractEntropy(passphrase)
 	if err != nil {
 		return "", err
@@ -214,7 +381,16 @@ func (km Manager) ExtractMnemonic(passphrase string) (string, error) {
 	return bip39.NewMnemonic(entropy)
 }
 
+// ChangePassphrase encrypts the store again, it doesn't change the unlock state
+func (km *Manager) ChangePassphrase(oldPassphrase, newPassphrase string, profile KDFProfile) error {
+	return km.ks.ChangePassphrase(oldPassphrase, newPassphrase, profile)
+}
+
 func StoreNewEntropy(storeDir string, mnemonic string, pwd string, maxSearchIndex uint32) (*Manager, error) {
+	return StoreNewEntropyWithProfile(storeDir, mnemonic, pwd, maxSearchIndex, kdfProfiles[DefaultKDFProfile])
+}
+
+func StoreNewEntropyWithProfile(storeDir string, mnemonic string, pwd string, maxSearchIndex uint32, profile KDFProfile) (*Manager, error) {
 	entropy, e := bip39.EntropyFromMnemonic(mnemonic)
 	if e != nil {
 		return nil, e
@@ -224,7 +400,7 @@ func StoreNewEntropy(storeDir string, mnemonic string, pwd string, maxSearchInde
 
 	filename := FullKeyFileName(storeDir, *primaryAddress)
 	ss := CryptoStore{filename}
-	e = ss.StoreEntropy(entropy, *primaryAddress, pwd)
+	e = ss.StoreEntropyWithProfile(entropy, *primaryAddress, pwd, profile)
 	if e != nil {
 		return nil, e
 	}
@@ -259,9 +435,13 @@ func FindAddrFromSeed(seed []byte, addr types.Address, maxSearchIndex uint32) (k
 }
 
 func (km *Manager) SetLockEventListener(lis func(event UnlockEvent)) {
+	km.mutex.Lock()
+	defer km.mutex.Unlock()
 	km.unlockChangedLis = lis
 }
 
 func (km *Manager) RemoveUnlockChangeChannel() {
+	km.mutex.Lock()
+	defer km.mutex.Unlock()
 	km.unlockChangedLis = nil
 }
@@ -166,6 +166,55 @@ func TestManager_LockAndUnlock(t *testing.T) {
 
 }
 
+func TestManager_UnlockWithScope(t *testing.T) {
+	sm := testSeedStoreManager
+	events := make(chan entropystore.UnlockEvent, 10)
+	sm.SetLockEventListener(func(event entropystore.UnlockEvent) {
+		events <- event
+	})
+	defer sm.RemoveUnlockChangeChannel()
+
+	addr1, _ := types.HexToAddress(testTuples[1].address)
+	addr2, _ := types.HexToAddress(testTuples[2].address)
+	if e := sm.UnlockWithScope("123456", 100*time.Millisecond, []uint32{1}); e != nil {
+		t.Fatal(e)
+	}
+	if event := <-events; !event.Unlocked() || len(event.Indexes) != 1 || event.Timeout != 100*time.Millisecond {
+		t.Fatalf("unexpected event %v", event)
+	}
+
+	if !sm.IsAddrUnlocked(addr1) || sm.IsAddrUnlocked(addr2) {
+		t.Fatal("only index 1 should be unlocked")
+	}
+	if _, _, e := sm.SignData(addr2, []byte{1}); e != walleterrors.ErrAddressNotFound {
+		t.Fatalf("address out of scope should not be signed, err: %v", e)
+	}
+	if _, _, e := sm.DeriveForIndexPath(2); e != walleterrors.ErrNotInUnlockScope {
+		t.Fatalf("index out of scope should not be derived, err: %v", e)
+	}
+	if addrs, e := sm.ListAddress(0, 10); e != nil || len(addrs) != 1 || addrs[0] != addr1 {
+		t.Fatalf("unexpected addresses %v, err: %v", addrs, e)
+	}
+	if _, _, e := sm.SignData(addr1, []byte{1}); e != nil {
+		t.Fatal(e)
+	}
+	if event := <-events; !event.Signed() || event.Addr != addr1 {
+		t.Fatalf("unexpected event %v", event)
+	}
+
+	select {
+	case event := <-events:
+		if event.Unlocked() || event.Signed() {
+			t.Fatalf("unexpected event %v", event)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("store should be locked after timeout")
+	}
+	if sm.IsUnlocked() {
+		t.Fatal("store should be locked after timeout")
+	}
+}
+
 func TestFindAddrFromSeed(t *testing.T) {
 	seed, _ := hex.DecodeString(TestSeed)
 	s := time.Now()
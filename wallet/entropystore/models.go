@@ -2,21 +2,27 @@ package entropystore
 
 const (
 	cryptoStoreVersion = 1
+	// files encrypted with argon2id, the nodes only reading version 1 will refuse them
+	cryptoStoreVersionArgon2 = 2
+
+	cryptoStoreVersionMax = cryptoStoreVersionArgon2
 )
 
 type entropyJSON struct {
 	PrimaryAddress string     `json:"primaryAddress"`
-	Crypto         cryptoJSON `json:"crypto"`
+	Crypto         CryptoJSON `json:"crypto"`
 	Version        int        `json:"seedstoreversion"`
 	Timestamp      int64      `json:"timestamp"`
 }
 
-type cryptoJSON struct {
-	CipherName   string       `json:"ciphername"`
-	CipherText   string       `json:"ciphertext"`
-	Nonce        string       `json:"nonce"`
-	KDF          string       `json:"kdf"`
-	ScryptParams scryptParams `json:"scryptparams"`
+// CryptoJSON is the encrypted secret with the params of kdf, it is shared by the entropy stores and the key stores
+type CryptoJSON struct {
+	CipherName   string        `json:"ciphername"`
+	CipherText   string        `json:"ciphertext"`
+	Nonce        string        `json:"nonce"`
+	KDF          string        `json:"kdf"`
+	ScryptParams *scryptParams `json:"scryptparams,omitempty"`
+	Argon2Params *argon2Params `json:"argon2params,omitempty"`
 }
 
 type scryptParams struct {
@@ -26,3 +32,11 @@ type scryptParams struct {
 	KeyLen int    `json:"keylen"`
 	Salt   string `json:"salt"`
 }
+
+type argon2Params struct {
+	Time    uint32 `json:"time"`
+	Memory  uint32 `json:"memory"`
+	Threads uint8  `json:"threads"`
+	KeyLen  uint32 `json:"keylen"`
+	Salt    string `json:"salt"`
+}
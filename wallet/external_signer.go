@@ -0,0 +1,244 @@
+package wallet
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+const (
+	signerMethodAccounts = "signer_accounts"
+	signerMethodSignHash = "signer_signHash"
+	signerMethodSignData = "signer_signData"
+
+	externalSignerTimeout = 30 * time.Second
+)
+
+// ExternalSigner signs through a signer process by JSON-RPC 2.0, the endpoint is an http url or an ipc path.
+//
+//	signer_accounts()                  => ["vite_..."]
+//	signer_signHash(address, hash)     => {"signature": hex, "publicKey": hex}
+//	signer_signData(address, hexData)  => {"signature": hex, "publicKey": hex}
+//
+// The signer may ask its operator for confirmation, so the timeout is long.
+type ExternalSigner struct {
+	endpoint string
+
+	mu       sync.Mutex
+	conn     net.Conn
+	reader   *bufio.Reader
+	id       uint64
+	accounts map[types.Address]struct{}
+
+	log log15.Logger
+}
+
+type signerRequest struct {
+	Version string        `json:"jsonrpc"`
+	Id      uint64        `json:"id"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+}
+
+type signerError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+type signerResponse struct {
+	Id     uint64          `json:"id"`
+	Result json.RawMessage `json:"result"`
+	Error  *signerError    `json:"error"`
+}
+
+type signerResult struct {
+	Signature string `json:"signature"`
+	PublicKey string `json:"publicKey"`
+}
+
+func NewExternalSigner(endpoint string) *ExternalSigner {
+	return &ExternalSigner{
+		endpoint: endpoint,
+		log:      log15.New("module", "wallet/external_signer"),
+	}
+}
+
+func (s *ExternalSigner) Accounts() ([]types.Address, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var accounts []types.Address
+	if err := s.call(&accounts, signerMethodAccounts); err != nil {
+		return nil, err
+	}
+	s.accounts = make(map[types.Address]struct{}, len(accounts))
+	for _, addr := range accounts {
+		s.accounts[addr] = struct{}{}
+	}
+	return accounts, nil
+}
+
+// HasAccount uses the accounts listed before, and lists them again if the address is not found
+func (s *ExternalSigner) HasAccount(addr types.Address) bool {
+	s.mu.Lock()
+	_, ok := s.accounts[addr]
+	s.mu.Unlock()
+	if ok {
+		return true
+	}
+
+	accounts, err := s.Accounts()
+	if err != nil {
+		s.log.Error(fmt.Sprintf("list accounts failed, err: %v", err), "method", "HasAccount")
+		return false
+	}
+	for _, account := range accounts {
+		if account == addr {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *ExternalSigner) SignHash(addr types.Address, hash types.Hash) (signedData, pubkey []byte, err error) {
+	return s.sign(addr, hash.Bytes(), signerMethodSignHash, hash)
+}
+
+func (s *ExternalSigner) SignData(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+	return s.sign(addr, data, signerMethodSignData, hex.EncodeToString(data))
+}
+
+// sign checks the signature returned by the signer, so a wrong key won't be used to produce blocks
+func (s *ExternalSigner) sign(addr types.Address, data []byte, method string, param interface{}) (signedData, pubkey []byte, err error) {
+	if !s.HasAccount(addr) {
+		return nil, nil, walleterrors.ErrAddressNotFound
+	}
+
+	s.mu.Lock()
+	result := &signerResult{}
+	err = s.call(result, method, addr, param)
+	s.mu.Unlock()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if signedData, err = hex.DecodeString(result.Signature); err != nil {
+		return nil, nil, errors.New(fmt.Sprintf("invalid signature %s: %v", result.Signature, err))
+	}
+	if pubkey, err = hex.DecodeString(result.PublicKey); err != nil || len(pubkey) != ed25519.PublicKeySize {
+		return nil, nil, errors.New(fmt.Sprintf("invalid public key %s", result.PublicKey))
+	}
+	if types.PubkeyToAddress(pubkey) != addr {
+		return nil, nil, errors.New(fmt.Sprintf("public key %s is not of %s", result.PublicKey, addr))
+	}
+	if !ed25519.Verify(pubkey, data, signedData) {
+		return nil, nil, errors.New(fmt.Sprintf("wrong signature of %s", addr))
+	}
+	return signedData, pubkey, nil
+}
+
+// Close closes the ipc connection, it will be dialed again by the next call
+func (s *ExternalSigner) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closeConn()
+}
+
+func (s *ExternalSigner) closeConn() error {
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	s.reader = nil
+	return err
+}
+
+// call should be called with mu held
+func (s *ExternalSigner) call(result interface{}, method string, params ...interface{}) error {
+	s.id++
+	if params == nil {
+		params = make([]interface{}, 0)
+	}
+	req, err := json.Marshal(&signerRequest{Version: "2.0", Id: s.id, Method: method, Params: params})
+	if err != nil {
+		return err
+	}
+
+	var data []byte
+	if strings.HasPrefix(s.endpoint, "http://") || strings.HasPrefix(s.endpoint, "https://") {
+		data, err = s.postHTTP(req)
+	} else {
+		data, err = s.callIPC(req)
+	}
+	if err != nil {
+		return errors.New(fmt.Sprintf("call %s of external signer failed: %v", method, err))
+	}
+
+	resp := &signerResponse{}
+	if err = json.Unmarshal(data, resp); err != nil {
+		return errors.New(fmt.Sprintf("invalid response of %s: %v", method, err))
+	}
+	if resp.Id != s.id {
+		s.closeConn()
+		return errors.New(fmt.Sprintf("response id %d of %s should be %d", resp.Id, method, s.id))
+	}
+	if resp.Error != nil {
+		return errors.New(fmt.Sprintf("external signer refused %s: %s", method, resp.Error.Message))
+	}
+	return json.Unmarshal(resp.Result, result)
+}
+
+func (s *ExternalSigner) postHTTP(req []byte) ([]byte, error) {
+	client := &http.Client{Timeout: externalSignerTimeout}
+	resp, err := client.Post(s.endpoint, "application/json", bytes.NewReader(req))
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, errors.New(resp.Status)
+	}
+	var buf bytes.Buffer
+	if _, err = buf.ReadFrom(resp.Body); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// callIPC writes the request and reads the response as a line
+func (s *ExternalSigner) callIPC(req []byte) ([]byte, error) {
+	if s.conn == nil {
+		conn, err := net.DialTimeout("unix", s.endpoint, externalSignerTimeout)
+		if err != nil {
+			return nil, err
+		}
+		s.conn = conn
+		s.reader = bufio.NewReader(conn)
+	}
+
+	s.conn.SetDeadline(time.Now().Add(externalSignerTimeout))
+	if _, err := s.conn.Write(append(req, '\n')); err != nil {
+		s.closeConn()
+		return nil, err
+	}
+	line, err := s.reader.ReadBytes('\n')
+	if err != nil {
+		s.closeConn()
+		return nil, err
+	}
+	return line, nil
+}
@@ -0,0 +1,113 @@
+package wallet_test
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/wallet"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+func newTestSigner(t *testing.T, priv ed25519.PrivateKey, wrongKey bool) *httptest.Server {
+	addr := types.PubkeyToAddress(priv.PubByte())
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Id     uint64            `json:"id"`
+			Method string            `json:"method"`
+			Params []json.RawMessage `json:"params"`
+		}
+		body, _ := ioutil.ReadAll(r.Body)
+		if err := json.Unmarshal(body, &req); err != nil {
+			t.Error(err)
+			return
+		}
+
+		var result interface{}
+		switch req.Method {
+		case "signer_accounts":
+			result = []types.Address{addr}
+		case "signer_signHash", "signer_signData":
+			var data []byte
+			if req.Method == "signer_signHash" {
+				var hash types.Hash
+				json.Unmarshal(req.Params[1], &hash)
+				data = hash.Bytes()
+			} else {
+				var hexData string
+				json.Unmarshal(req.Params[1], &hexData)
+				data, _ = hex.DecodeString(hexData)
+			}
+			if wrongKey {
+				data = append(data, 0)
+			}
+			result = map[string]string{
+				"signature": hex.EncodeToString(ed25519.Sign(priv, data)),
+				"publicKey": hex.EncodeToString(priv.PubByte()),
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]interface{}{"jsonrpc": "2.0", "id": req.Id, "result": result})
+	}))
+}
+
+func TestExternalSigner(t *testing.T) {
+	pub, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := types.PubkeyToAddress(pub)
+
+	server := newTestSigner(t, priv, false)
+	defer server.Close()
+
+	signer := wallet.NewExternalSigner(server.URL)
+	accounts, err := signer.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 1 || accounts[0] != addr {
+		t.Fatalf("wrong accounts %v", accounts)
+	}
+
+	hash := types.Hash{1, 2, 3}
+	signature, pubkey, err := signer.SignHash(addr, hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pubkey, hash.Bytes(), signature) {
+		t.Error("wrong signature of hash")
+	}
+
+	data := []byte("hello")
+	if signature, pubkey, err = signer.SignData(addr, data); err != nil {
+		t.Fatal(err)
+	}
+	if !ed25519.Verify(pubkey, data, signature) {
+		t.Error("wrong signature of data")
+	}
+
+	if _, _, err = signer.SignData(types.Address{1}, data); err != walleterrors.ErrAddressNotFound {
+		t.Errorf("unknown address should not be signed, err: %v", err)
+	}
+}
+
+func TestExternalSigner_WrongSignature(t *testing.T) {
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	server := newTestSigner(t, priv, true)
+	defer server.Close()
+
+	signer := wallet.NewExternalSigner(server.URL)
+	if _, _, err = signer.SignHash(types.PubkeyToAddress(priv.PubByte()), types.Hash{1}); err == nil {
+		t.Error("wrong signature should be rejected")
+	}
+}
@@ -0,0 +1,172 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/wallet/keystore"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+// the key stores of single ed25519 keys are kept in a sub dir of the entropy stores
+const keyStoreDirName = "keys"
+
+func (m *Manager) GetKeyStoreDir() string {
+	return filepath.Join(m.config.DataDir, keyStoreDirName)
+}
+
+func (m *Manager) loadKeyStores() error {
+	files, err := ioutil.ReadDir(m.GetKeyStoreDir())
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	for _, file := range files {
+		if file.IsDir() || file.Mode()&os.ModeType != 0 {
+			continue
+		}
+		fn := file.Name()
+		if strings.HasPrefix(fn, ".") || strings.HasSuffix(fn, "~") {
+			continue
+		}
+		absFilePath := filepath.Join(m.GetKeyStoreDir(), fn)
+		b, addr, e := keystore.IsMayValidKeyStoreFile(absFilePath)
+		if e != nil || !b {
+			m.log.Warn("invalid key store file", "file", absFilePath, "err", e)
+			continue
+		}
+		m.addKeyStore(keystore.NewManager(absFilePath, *addr))
+	}
+	return nil
+}
+
+// addKeyStore replaces the key store of the same address, the old one is locked out of keyStoreMutex
+// because locking notifies the listeners
+func (m *Manager) addKeyStore(km *keystore.Manager) {
+	km.SetLockEventListener(m.notifyLockEvent)
+	m.keyStoreMutex.Lock()
+	old, ok := m.keyStoreManager[km.GetAddr()]
+	m.keyStoreManager[km.GetAddr()] = km
+	m.keyStoreMutex.Unlock()
+	if ok {
+		old.Lock()
+		old.RemoveUnlockChangeChannel()
+	}
+}
+
+func (m *Manager) getKeyStore(addr types.Address) (*keystore.Manager, bool) {
+	m.keyStoreMutex.RLock()
+	defer m.keyStoreMutex.RUnlock()
+	km, ok := m.keyStoreManager[addr]
+	return km, ok
+}
+
+// ImportPrivateKey encrypts the key into a new key store, the key store of the same address is replaced
+func (m *Manager) ImportPrivateKey(key ed25519.PrivateKey, passphrase string) (*keystore.Manager, error) {
+	profile, err := m.kdfProfile("")
+	if err != nil {
+		return nil, err
+	}
+	km, err := keystore.StoreNewKey(m.GetKeyStoreDir(), key, passphrase, *profile)
+	if err != nil {
+		return nil, err
+	}
+	m.addKeyStore(km)
+	return km, nil
+}
+
+// ImportKeyJSON copies the key store after the passphrase is checked
+func (m *Manager) ImportKeyJSON(keyjson []byte, passphrase string) (*keystore.Manager, error) {
+	key, err := keystore.DecryptKey(keyjson, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	key.Clear()
+	km, err := keystore.StoreKeyJSON(m.GetKeyStoreDir(), keyjson)
+	if err != nil {
+		return nil, err
+	}
+	m.addKeyStore(km)
+	return km, nil
+}
+
+// ExtractPrivateKey finds the key in the key stores first, then the entropy stores
+func (m *Manager) ExtractPrivateKey(addr types.Address, passphrase string) (ed25519.PrivateKey, error) {
+	if km, ok := m.getKeyStore(addr); ok {
+		return km.ExtractKey(passphrase)
+	}
+	_, key, _, err := m.GlobalFindAddrWithPassphrase(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	return key.PrivateKey()
+}
+
+// ExportKeyJSON exports the key of the key stores or the entropy stores as a key store encrypted by the newPassphrase
+func (m *Manager) ExportKeyJSON(addr types.Address, passphrase, newPassphrase string) ([]byte, error) {
+	key, err := m.ExtractPrivateKey(addr, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	defer key.Clear()
+	if km, ok := m.getKeyStore(addr); ok && passphrase == newPassphrase {
+		return km.KeyJSON()
+	}
+	profile, err := m.kdfProfile("")
+	if err != nil {
+		return nil, err
+	}
+	return keystore.EncryptKey(key, newPassphrase, *profile)
+}
+
+func (m *Manager) GetKeyStoreManager(addr types.Address) (*keystore.Manager, error) {
+	if km, ok := m.getKeyStore(addr); ok {
+		return km, nil
+	}
+	return nil, walleterrors.ErrStoreNotFound
+}
+
+func (m *Manager) ListKeyStores() []*keystore.Manager {
+	m.keyStoreMutex.RLock()
+	list := make([]*keystore.Manager, 0, len(m.keyStoreManager))
+	for _, km := range m.keyStoreManager {
+		list = append(list, km)
+	}
+	m.keyStoreMutex.RUnlock()
+	sort.Slice(list, func(i, j int) bool {
+		return list[i].GetKeyStoreFile() < list[j].GetKeyStoreFile()
+	})
+	return list
+}
+
+func (m *Manager) UnlockKeyStore(addr types.Address, passphrase string, timeout time.Duration) error {
+	km, err := m.GetKeyStoreManager(addr)
+	if err != nil {
+		return err
+	}
+	return km.Unlock(passphrase, timeout)
+}
+
+func (m *Manager) LockKeyStore(addr types.Address) error {
+	km, err := m.GetKeyStoreManager(addr)
+	if err != nil {
+		return err
+	}
+	km.Lock()
+	return nil
+}
+
+func (m *Manager) findUnlockedKeyStore(addr types.Address) *keystore.Manager {
+	if km, ok := m.getKeyStore(addr); ok && km.IsUnlocked() {
+		return km
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package wallet_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/wallet"
+)
+
+func TestManager_ImportExportKey(t *testing.T) {
+	dir, err := ioutil.TempDir("", "wallet_key")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	manager := wallet.New(&wallet.Config{DataDir: dir})
+	manager.Start()
+	defer manager.Stop()
+	_, em, err := manager.NewMnemonicAndEntropyStore("123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, key, _ := em.DeriveForIndexPathWithPassphrase(3, "123456")
+	addr, _ := key.Address()
+
+	// export the key of entropy store, then import it as a key store
+	keyjson, err := manager.ExportKeyJSON(*addr, "123456", "abcdef")
+	if err != nil {
+		t.Fatal(err)
+	}
+	manager.RemoveEntropyStore(em.GetEntropyStoreFile())
+	if _, err = manager.ImportKeyJSON(keyjson, "123456"); err == nil {
+		t.Fatal("wrong passphrase should not be imported")
+	}
+	if _, err = manager.ImportKeyJSON(keyjson, "abcdef"); err != nil {
+		t.Fatal(err)
+	}
+
+	if manager.GlobalCheckAddrUnlock(*addr) {
+		t.Fatal("key store should be locked")
+	}
+	if err = manager.UnlockKeyStore(*addr, "abcdef", 0); err != nil {
+		t.Fatal(err)
+	}
+	signature, pubkey, err := manager.SignData(*addr, []byte("hello"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected, _ := key.PrivateKey()
+	if !bytes.Equal(pubkey, expected.PubByte()) || !bytes.Equal(signature, ed25519.Sign(expected, []byte("hello"))) {
+		t.Fatal("wrong signature of key store")
+	}
+
+	// key stores are loaded when the wallet starts
+	manager.Stop()
+	manager.Start()
+	if list := manager.ListKeyStores(); len(list) != 1 || list[0].GetAddr() != *addr {
+		t.Fatalf("key store is not loaded, %v", list)
+	}
+	exported, err := manager.ExtractPrivateKey(*addr, "abcdef")
+	if err != nil || exported.Hex() != expected.Hex() {
+		t.Fatalf("wrong exported key, err: %v", err)
+	}
+}
@@ -0,0 +1,149 @@
+package keystore
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+// the key store keeps one ed25519 private key, only the 32 bytes seed of the key is encrypted
+const keySeedSize = 32
+
+func parseJson(keyjson []byte) (k *keyJSON, kAddress *types.Address, err error) {
+	k = new(keyJSON)
+	if err := json.Unmarshal(keyjson, k); err != nil {
+		return nil, nil, err
+	}
+	if k.Version < keyStoreVersion || k.Version > keyStoreVersionMax {
+		return nil, nil, fmt.Errorf("version number error : %v, this node supports up to %v, please upgrade it", k.Version, keyStoreVersionMax)
+	}
+
+	if !types.IsValidHexAddress(k.Address) {
+		return nil, nil, fmt.Errorf("address invalid ： %v", k.Address)
+	}
+	addr, err := types.HexToAddress(k.Address)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := k.Crypto.Check(k.Version); err != nil {
+		return nil, nil, err
+	}
+
+	return k, &addr, nil
+}
+
+// ParseKeyJSON checks the format of the key store and returns the address, the passphrase is not checked
+func ParseKeyJSON(keyjson []byte) (*types.Address, error) {
+	_, addr, err := parseJson(keyjson)
+	return addr, err
+}
+
+func DecryptKey(keyjson []byte, passphrase string) (ed25519.PrivateKey, error) {
+	k, kAddress, err := parseJson(keyjson)
+	if err != nil {
+		return nil, err
+	}
+
+	seed, err := entropystore.DecryptSecret(&k.Crypto, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	if len(seed) != keySeedSize {
+		return nil, walleterrors.ErrDecryptEntropy
+	}
+
+	key, err := KeyFromSeed(seed)
+	if err != nil {
+		return nil, err
+	}
+	if addr := types.PubkeyToAddress(key.PubByte()); !bytes.Equal(addr[:], kAddress[:]) {
+		return nil,
+			fmt.Errorf("address content not equal. In file it is : %s  but generated is : %s",
+				k.Address, addr.Hex())
+	}
+	return key, nil
+}
+
+// EncryptKey encrypts the seed of the key by the passphrase, the encryption key is derived with the profile
+func EncryptKey(key ed25519.PrivateKey, passphrase string, profile entropystore.KDFProfile) ([]byte, error) {
+	if !ed25519.IsValidPrivateKey(key) {
+		return nil, walleterrors.ErrInvalidPrikey
+	}
+	crypto, version, err := entropystore.EncryptSecret(key[:keySeedSize], passphrase, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(keyJSON{
+		Address:   types.PubkeyToAddress(key.PubByte()).String(),
+		Crypto:    crypto,
+		Version:   version,
+		Timestamp: time.Now().UTC().Unix(),
+	})
+}
+
+// KeyFromSeed restores the private key from the first 32 bytes of it
+func KeyFromSeed(seed []byte) (ed25519.PrivateKey, error) {
+	if len(seed) != keySeedSize {
+		return nil, walleterrors.ErrInvalidPrikey
+	}
+	var d [keySeedSize]byte
+	copy(d[:], seed)
+	_, key, err := ed25519.GenerateKeyFromD(d)
+	return key, err
+}
+
+// ParsePrivateKey accepts the hex of the 64 bytes private key or the 32 bytes seed of it
+func ParsePrivateKey(hexKey string) (ed25519.PrivateKey, error) {
+	b, err := hex.DecodeString(hexKey)
+	if err != nil {
+		return nil, walleterrors.ErrInvalidPrikey
+	}
+	switch len(b) {
+	case keySeedSize:
+		return KeyFromSeed(b)
+	case ed25519.PrivateKeySize:
+		key, err := KeyFromSeed(b[:keySeedSize])
+		if err != nil || !bytes.Equal(key, b) {
+			return nil, walleterrors.ErrInvalidPrikey
+		}
+		return key, nil
+	default:
+		return nil, walleterrors.ErrInvalidPrikey
+	}
+}
+
+// IsMayValidKeyStoreFile returns the address if the file looks like a key store
+func IsMayValidKeyStoreFile(path string) (bool, *types.Address, error) {
+	fi, err := os.Stat(path)
+	if err != nil {
+		return false, nil, err
+	}
+	if fi.Size() > 2*1024 {
+		return false, nil, nil
+	}
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return false, nil, err
+	}
+	addr, err := ParseKeyJSON(b)
+	if err != nil {
+		return false, nil, err
+	}
+	return true, addr, nil
+}
+
+func FullKeyFileName(keysDirPath string, keyAddr types.Address) string {
+	return filepath.Join(keysDirPath, keyAddr.Hex())
+}
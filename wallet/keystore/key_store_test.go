@@ -0,0 +1,117 @@
+package keystore_test
+
+import (
+	"crypto/rand"
+	"io/ioutil"
+	"os"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/keystore"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+var testProfile = entropystore.KDFProfile{KDF: "scrypt", ScryptN: 1 << 10, ScryptP: 1}
+
+func TestEncryptKey(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyjson, err := keystore.EncryptKey(key, "123456", testProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr, err := keystore.ParseKeyJSON(keyjson); err != nil || *addr != types.PubkeyToAddress(key.PubByte()) {
+		t.Fatalf("wrong address %v, err: %v", addr, err)
+	}
+
+	decrypted, err := keystore.DecryptKey(keyjson, "123456")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decrypted.Hex() != key.Hex() {
+		t.Fatal("decrypted key is not the same")
+	}
+	if _, err = keystore.DecryptKey(keyjson, "654321"); err != walleterrors.ErrDecryptEntropy {
+		t.Fatalf("wrong passphrase should fail, err: %v", err)
+	}
+
+	// the key is encrypted with the kdf of the profile
+	argon2Profile := entropystore.KDFProfile{KDF: "argon2id", Argon2Time: 1, Argon2Memory: 1024, Argon2Threads: 1}
+	keyjson, err = keystore.EncryptKey(key, "123456", argon2Profile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(keyjson), `"argon2id"`) || !strings.Contains(string(keyjson), `"keystoreversion":2`) {
+		t.Fatalf("key store is not encrypted with argon2id, %s", keyjson)
+	}
+	if decrypted, err = keystore.DecryptKey(keyjson, "123456"); err != nil || decrypted.Hex() != key.Hex() {
+		t.Fatalf("decrypt the argon2id key store failed, err: %v", err)
+	}
+}
+
+func TestDecryptKey_InvalidParams(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	keyjson, err := keystore.EncryptKey(key, "123456", testProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, replace := range []string{`"keylen":16`, `"n":1073741824`} {
+		crafted := regexp.MustCompile(strings.Split(replace, ":")[0]+`:\d+`).ReplaceAll(keyjson, []byte(replace))
+		if _, err = keystore.DecryptKey(crafted, "123456"); err == nil {
+			t.Fatalf("key store with %s should fail", replace)
+		}
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	if parsed, err := keystore.ParsePrivateKey(key.Hex()); err != nil || parsed.Hex() != key.Hex() {
+		t.Fatalf("parse private key failed, err: %v", err)
+	}
+	if parsed, err := keystore.ParsePrivateKey(key.Hex()[:64]); err != nil || parsed.Hex() != key.Hex() {
+		t.Fatalf("parse seed of private key failed, err: %v", err)
+	}
+	wrongPub := key.Hex()[:64] + ed25519.PrivateKey(make([]byte, 64)).Hex()[64:]
+	if _, err := keystore.ParsePrivateKey(wrongPub); err != walleterrors.ErrInvalidPrikey {
+		t.Fatalf("private key with wrong public key should fail, err: %v", err)
+	}
+}
+
+func TestManager_Unlock(t *testing.T) {
+	dir, err := ioutil.TempDir("", "keystore")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	_, key, _ := ed25519.GenerateKey(rand.Reader)
+	km, err := keystore.StoreNewKey(dir, key, "123456", testProfile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if km.GetAddr() != types.PubkeyToAddress(key.PubByte()) {
+		t.Fatal("wrong address of key store")
+	}
+	if _, _, err = km.SignData([]byte{1}); err != walleterrors.ErrLocked {
+		t.Fatalf("locked key should not sign, err: %v", err)
+	}
+
+	if err = km.Unlock("123456", 100*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	signature, pubkey, err := km.SignData([]byte{1})
+	if err != nil || !ed25519.Verify(pubkey, []byte{1}, signature) {
+		t.Fatalf("wrong signature, err: %v", err)
+	}
+	time.Sleep(300 * time.Millisecond)
+	if km.IsUnlocked() {
+		t.Fatal("key should be locked after timeout")
+	}
+}
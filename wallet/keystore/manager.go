@@ -0,0 +1,188 @@
+package keystore
+
+import (
+	"io/ioutil"
+	"sync"
+	"time"
+
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/crypto/ed25519"
+	"github.com/vitelabs/go-vite/log15"
+	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+// Manager holds a key store file, the key can be unlocked to sign like an entropy store
+type Manager struct {
+	filename string
+	addr     types.Address
+
+	mutex       sync.RWMutex
+	unlockedKey ed25519.PrivateKey
+	unlockSeq   uint64
+	lockTimer   *time.Timer
+
+	unlockChangedLis func(event entropystore.UnlockEvent)
+
+	log log15.Logger
+}
+
+func NewManager(filename string, addr types.Address) *Manager {
+	return &Manager{
+		filename: filename,
+		addr:     addr,
+
+		log: log15.New("module", "wallet/keystore/Manager"),
+	}
+}
+
+// StoreNewKey encrypts the key into the dir, the file is named by the address
+func StoreNewKey(dir string, key ed25519.PrivateKey, passphrase string, profile entropystore.KDFProfile) (*Manager, error) {
+	keyjson, err := EncryptKey(key, passphrase, profile)
+	if err != nil {
+		return nil, err
+	}
+	return StoreKeyJSON(dir, keyjson)
+}
+
+// StoreKeyJSON copies the key store into the dir, the file is named by the address
+func StoreKeyJSON(dir string, keyjson []byte) (*Manager, error) {
+	addr, err := ParseKeyJSON(keyjson)
+	if err != nil {
+		return nil, err
+	}
+	filename := FullKeyFileName(dir, *addr)
+	if err = entropystore.WriteKeyFile(filename, keyjson); err != nil {
+		return nil, err
+	}
+	return NewManager(filename, *addr), nil
+}
+
+func (km *Manager) GetAddr() types.Address {
+	return km.addr
+}
+
+func (km *Manager) GetKeyStoreFile() string {
+	return km.filename
+}
+
+func (km *Manager) KeyJSON() ([]byte, error) {
+	return ioutil.ReadFile(km.filename)
+}
+
+func (km *Manager) ExtractKey(passphrase string) (ed25519.PrivateKey, error) {
+	keyjson, err := km.KeyJSON()
+	if err != nil {
+		return nil, err
+	}
+	return DecryptKey(keyjson, passphrase)
+}
+
+func (km *Manager) IsUnlocked() bool {
+	km.mutex.RLock()
+	defer km.mutex.RUnlock()
+	return km.unlockedKey != nil
+}
+
+// Unlock keeps the key unlocked until timeout, 0 means until Lock is called
+func (km *Manager) Unlock(passphrase string, timeout time.Duration) error {
+	key, err := km.ExtractKey(passphrase)
+	if err != nil {
+		return err
+	}
+
+	km.mutex.Lock()
+	km.unlockedKey = key
+	km.unlockSeq++
+	km.stopLockTimer()
+	if timeout > 0 {
+		seq := km.unlockSeq
+		km.lockTimer = time.AfterFunc(timeout, func() {
+			km.lockIfSeq(seq)
+		})
+	}
+	km.mutex.Unlock()
+
+	km.notify(entropystore.NewLockEvent(km.filename, km.addr, true, timeout))
+	return nil
+}
+
+func (km *Manager) Lock() {
+	km.mutex.Lock()
+	km.lock()
+	km.mutex.Unlock()
+
+	km.notify(entropystore.NewLockEvent(km.filename, km.addr, false, 0))
+}
+
+func (km *Manager) lockIfSeq(seq uint64) {
+	km.mutex.Lock()
+	if km.unlockSeq != seq || km.unlockedKey == nil {
+		km.mutex.Unlock()
+		return
+	}
+	km.lock()
+	km.mutex.Unlock()
+
+	km.log.Info("key store is locked for unlock timeout", "method", "lockIfSeq", "file", km.filename)
+	km.notify(entropystore.NewLockEvent(km.filename, km.addr, false, 0))
+}
+
+// lock should be called with mutex held
+func (km *Manager) lock() {
+	if km.unlockedKey != nil {
+		km.unlockedKey.Clear()
+		km.unlockedKey = nil
+	}
+	km.stopLockTimer()
+}
+
+func (km *Manager) stopLockTimer() {
+	if km.lockTimer != nil {
+		km.lockTimer.Stop()
+		km.lockTimer = nil
+	}
+}
+
+func (km *Manager) SignData(data []byte) (signedData, pubkey []byte, err error) {
+	km.mutex.RLock()
+	if km.unlockedKey == nil {
+		km.mutex.RUnlock()
+		return nil, nil, walleterrors.ErrLocked
+	}
+	signedData, pubkey = ed25519.Sign(km.unlockedKey, data), km.unlockedKey.PubByte()
+	km.mutex.RUnlock()
+
+	km.notify(entropystore.NewSignedEvent(km.filename, km.addr, km.addr))
+	return signedData, pubkey, nil
+}
+
+func (km *Manager) SignDataWithPassphrase(passphrase string, data []byte) (signedData, pubkey []byte, err error) {
+	key, err := km.ExtractKey(passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer key.Clear()
+	return ed25519.Sign(key, data), key.PubByte(), nil
+}
+
+func (km *Manager) SetLockEventListener(lis func(event entropystore.UnlockEvent)) {
+	km.mutex.Lock()
+	defer km.mutex.Unlock()
+	km.unlockChangedLis = lis
+}
+
+func (km *Manager) RemoveUnlockChangeChannel() {
+	km.mutex.Lock()
+	defer km.mutex.Unlock()
+	km.unlockChangedLis = nil
+}
+
+func (km *Manager) notify(event entropystore.UnlockEvent) {
+	km.mutex.RLock()
+	lis := km.unlockChangedLis
+	km.mutex.RUnlock()
+	if lis != nil {
+		lis(event)
+	}
+}
@@ -0,0 +1,19 @@
+package keystore
+
+import "github.com/vitelabs/go-vite/wallet/entropystore"
+
+const (
+	// the key stores accept the kdf of the entropy stores of the same version, version 1 is scrypt
+	keyStoreVersion = 1
+	// key stores encrypted with argon2id, the nodes only reading version 1 will refuse them
+	keyStoreVersionArgon2 = 2
+
+	keyStoreVersionMax = keyStoreVersionArgon2
+)
+
+type keyJSON struct {
+	Address   string                  `json:"address"`
+	Crypto    entropystore.CryptoJSON `json:"crypto"`
+	Version   int                     `json:"keystoreversion"`
+	Timestamp int64                   `json:"timestamp"`
+}
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/pkg/errors"
 	"github.com/tyler-smith/go-bip39"
@@ -13,6 +14,7 @@ import (
 	"github.com/vitelabs/go-vite/log15"
 	"github.com/vitelabs/go-vite/wallet/entropystore"
 	"github.com/vitelabs/go-vite/wallet/hd-bip/derivation"
+	"github.com/vitelabs/go-vite/wallet/keystore"
 	"github.com/vitelabs/go-vite/wallet/walleterrors"
 )
 
@@ -20,8 +22,13 @@ type Manager struct {
 	config              *Config
 	unlockChangedIndex  int
 	entropyStoreManager map[string]*entropystore.Manager // key is the entropyStore`s abs path
+	keyStoreManager     map[types.Address]*keystore.Manager
+	keyStoreMutex       sync.RWMutex // guards keyStoreManager
 	unlockChangedLis    map[int]func(event entropystore.UnlockEvent)
 	mutex               sync.Mutex
+	external            *ExternalSigner
+	audit               *AuditLog
+	auditLisId          int
 
 	log log15.Logger
 }
@@ -39,6 +46,7 @@ func New(config *Config) *Manager {
 		config:              config,
 		unlockChangedLis:    make(map[int]func(event entropystore.UnlockEvent)),
 		entropyStoreManager: make(map[string]*entropystore.Manager),
+		keyStoreManager:     make(map[types.Address]*keystore.Manager),
 
 		log: log15.New("module", "wallet"),
 	}
@@ -61,6 +69,16 @@ func (m *Manager) Unlock(entropyStore, passphrase string) error {
 	return manager.Unlock(passphrase)
 }
 
+// UnlockWithScope unlocks the entropy store until timeout, and only the addresses of the indexes can be used
+func (m *Manager) UnlockWithScope(entropyStore, passphrase string, timeout time.Duration, indexes []uint32) error {
+	manager, e := m.GetEntropyStoreManager(entropyStore)
+	if e != nil {
+		return e
+	}
+
+	return manager.UnlockWithScope(passphrase, timeout, indexes)
+}
+
 func (m *Manager) IsUnlocked(entropyStore string) bool {
 	manager, e := m.GetEntropyStoreManager(entropyStore)
 	if e != nil {
@@ -78,9 +96,10 @@ func (m *Manager) Lock(entropyStore string) error {
 	return nil
 }
 
+// GlobalCheckAddrUnlock returns true if the address can be signed by the unlocked entropy stores, key stores or the external signer
 func (m Manager) GlobalCheckAddrUnlock(targetAdr types.Address) bool {
 	_, _, _, err := m.GlobalFindAddr(targetAdr)
-	return err == nil
+	return err == nil || m.findUnlockedKeyStore(targetAdr) != nil || m.hasExternalAccount(targetAdr)
 }
 
 func (m *Manager) RefreshCache() {
@@ -162,6 +181,27 @@ func (m Manager) ExtractMnemonic(entropyStore string, passphrase string) (string
 	return manager.ExtractMnemonic(passphrase)
 
 }
+
+// ChangePassphrase encrypts the entropy store again by the new passphrase, the kdf profile of config is used if kdfProfile is empty
+func (m *Manager) ChangePassphrase(entropyStore, oldPassphrase, newPassphrase string, kdfProfile string) error {
+	manager, e := m.GetEntropyStoreManager(entropyStore)
+	if e != nil {
+		return e
+	}
+	profile, e := m.kdfProfile(kdfProfile)
+	if e != nil {
+		return e
+	}
+	return manager.ChangePassphrase(oldPassphrase, newPassphrase, *profile)
+}
+
+func (m *Manager) kdfProfile(name string) (*entropystore.KDFProfile, error) {
+	if name == "" {
+		name = m.config.KDFProfile
+	}
+	return entropystore.GetKDFProfile(name)
+}
+
 func (m *Manager) GetEntropyStoreManager(entropyStore string) (*entropystore.Manager, error) {
 	absPath := entropyStore
 	if !filepath.IsAbs(absPath) {
@@ -191,13 +231,7 @@ func (m *Manager) AddEntropyStore(entropyStore string) error {
 		return nil
 	}
 	m.entropyStoreManager[absPath] = entropystore.NewManager(absPath, *addr, m.config.MaxSearchIndex)
-	m.entropyStoreManager[absPath].SetLockEventListener(func(event entropystore.UnlockEvent) {
-		for _, lis := range m.unlockChangedLis {
-			if lis != nil {
-				lis(event)
-			}
-		}
-	})
+	m.entropyStoreManager[absPath].SetLockEventListener(m.notifyLockEvent)
 	return nil
 }
 
@@ -215,18 +249,16 @@ func (m *Manager) RemoveEntropyStore(entropyStore string) {
 }
 
 func (m *Manager) RecoverEntropyStoreFromMnemonic(mnemonic string, passphrase string) (em *entropystore.Manager, err error) {
-	sm, e := entropystore.StoreNewEntropy(m.config.DataDir, mnemonic, passphrase, entropystore.DefaultMaxIndex)
+	profile, e := m.kdfProfile("")
+	if e != nil {
+		return nil, e
+	}
+	sm, e := entropystore.StoreNewEntropyWithProfile(m.config.DataDir, mnemonic, passphrase, entropystore.DefaultMaxIndex, *profile)
 	if e != nil {
 		return nil, e
 	}
 	m.entropyStoreManager[sm.GetEntropyStoreFile()] = sm
-	sm.SetLockEventListener(func(event entropystore.UnlockEvent) {
-		for _, lis := range m.unlockChangedLis {
-			if lis != nil {
-				lis(event)
-			}
-		}
-	})
+	sm.SetLockEventListener(m.notifyLockEvent)
 	return sm, nil
 }
 
@@ -253,6 +285,25 @@ func (m Manager) GetDataDir() string {
 
 func (m *Manager) Start() {
 	m.entropyStoreManager = make(map[string]*entropystore.Manager)
+	m.keyStoreMutex.Lock()
+	m.keyStoreManager = make(map[types.Address]*keystore.Manager)
+	m.keyStoreMutex.Unlock()
+	if m.config.ExternalSigner != "" {
+		m.external = NewExternalSigner(m.config.ExternalSigner)
+	}
+	if m.config.AuditLog != "" {
+		audit, e := OpenAuditLog(m.config.AuditLog)
+		if e != nil {
+			m.log.Error("wallet start OpenAuditLog", "err", e)
+		} else {
+			m.audit = audit
+			m.auditLisId = m.AddLockEventListener(func(event entropystore.UnlockEvent) {
+				if e := audit.Write(event); e != nil {
+					m.log.Error("write audit log failed", "err", e, "event", event.String())
+				}
+			})
+		}
+	}
 	files, e := m.ListEntropyFilesInStandardDir()
 	if e != nil {
 		m.log.Error("wallet start err", "err", e)
@@ -262,6 +313,9 @@ func (m *Manager) Start() {
 			m.log.Error("wallet start AddEntropyStore", "err", e)
 		}
 	}
+	if e = m.loadKeyStores(); e != nil {
+		m.log.Error("wallet start loadKeyStores", "err", e)
+	}
 }
 
 func (m *Manager) Stop() {
@@ -270,9 +324,27 @@ func (m *Manager) Stop() {
 		em.RemoveUnlockChangeChannel()
 	}
 	m.entropyStoreManager = nil
+	m.keyStoreMutex.Lock()
+	kms := m.keyStoreManager
+	m.keyStoreManager = nil
+	m.keyStoreMutex.Unlock()
+	for _, km := range kms {
+		km.Lock()
+		km.RemoveUnlockChangeChannel()
+	}
+	if m.external != nil {
+		m.external.Close()
+		m.external = nil
+	}
+	if m.audit != nil {
+		m.RemoveUnlockChangeChannel(m.auditLisId)
+		m.audit.Close()
+		m.audit = nil
+	}
 }
 
-func (m Manager) AddLockEventListener(lis func(event entropystore.UnlockEvent)) int {
+// AddLockEventListener listens the unlock, lock and sign events of all the entropy stores and the external signer
+func (m *Manager) AddLockEventListener(lis func(event entropystore.UnlockEvent)) int {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 
@@ -281,12 +353,36 @@ func (m Manager) AddLockEventListener(lis func(event entropystore.UnlockEvent))
 	return m.unlockChangedIndex
 }
 
-func (m Manager) RemoveUnlockChangeChannel(id int) {
+func (m *Manager) RemoveUnlockChangeChannel(id int) {
 	m.mutex.Lock()
 	defer m.mutex.Unlock()
 	delete(m.unlockChangedLis, id)
 }
+
+func (m *Manager) notifyLockEvent(event entropystore.UnlockEvent) {
+	m.mutex.Lock()
+	lisList := make([]func(event entropystore.UnlockEvent), 0, len(m.unlockChangedLis))
+	for _, lis := range m.unlockChangedLis {
+		if lis != nil {
+			lisList = append(lisList, lis)
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, lis := range lisList {
+		lis(event)
+	}
+}
+
+// MatchAddress checks the coinbase is derived from the entropy store, or held by the external signer if
+// the entropy store is not configured.
 func (m *Manager) MatchAddress(EntryPath string, coinbase types.Address, index uint32) error {
+	if EntryPath == "" && m.external != nil {
+		if !m.external.HasAccount(coinbase) {
+			return walleterrors.ErrAddressNotFound
+		}
+		return nil
+	}
 	manager, err := m.GetEntropyStoreManager(EntryPath)
 	if err != nil {
 		return err
@@ -0,0 +1,111 @@
+package wallet
+
+import (
+	"github.com/vitelabs/go-vite/common/types"
+	"github.com/vitelabs/go-vite/wallet/entropystore"
+	"github.com/vitelabs/go-vite/wallet/walleterrors"
+)
+
+// Signer holds the keys of accounts and signs with them, the keys may be kept out of the node process.
+type Signer interface {
+	Accounts() ([]types.Address, error)
+	// SignHash signs the hash of an account block or a snapshot block
+	SignHash(addr types.Address, hash types.Hash) (signedData, pubkey []byte, err error)
+	SignData(addr types.Address, data []byte) (signedData, pubkey []byte, err error)
+}
+
+// Accounts returns the accounts of the unlocked entropy stores, key stores and the external signer
+func (m *Manager) Accounts() ([]types.Address, error) {
+	accounts := make([]types.Address, 0)
+	for _, em := range m.entropyStoreManager {
+		if !em.IsUnlocked() {
+			continue
+		}
+		addrs, err := em.ListAddress(0, m.config.MaxSearchIndex)
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, addrs...)
+	}
+	for _, km := range m.ListKeyStores() {
+		if km.IsUnlocked() {
+			accounts = append(accounts, km.GetAddr())
+		}
+	}
+
+	if m.external != nil {
+		addrs, err := m.external.Accounts()
+		if err != nil {
+			return nil, err
+		}
+		accounts = append(accounts, addrs...)
+	}
+	return accounts, nil
+}
+
+// SignHash signs with the unlocked entropy stores and key stores first, then the external signer
+func (m *Manager) SignHash(addr types.Address, hash types.Hash) (signedData, pubkey []byte, err error) {
+	path, _, _, err := m.GlobalFindAddr(addr)
+	if err == walleterrors.ErrAddressNotFound {
+		if km := m.findUnlockedKeyStore(addr); km != nil {
+			return km.SignData(hash.Bytes())
+		}
+	}
+	if err == walleterrors.ErrAddressNotFound && m.external != nil {
+		if signedData, pubkey, err = m.external.SignHash(addr, hash); err == nil {
+			m.notifyLockEvent(entropystore.NewSignedEvent(m.external.endpoint, types.Address{}, addr))
+		}
+		return signedData, pubkey, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.entropyStoreManager[path].SignData(addr, hash.Bytes())
+}
+
+// SignData signs with the unlocked entropy stores and key stores first, then the external signer
+func (m *Manager) SignData(addr types.Address, data []byte) (signedData, pubkey []byte, err error) {
+	path, _, _, err := m.GlobalFindAddr(addr)
+	if err == walleterrors.ErrAddressNotFound {
+		if km := m.findUnlockedKeyStore(addr); km != nil {
+			return km.SignData(data)
+		}
+	}
+	if err == walleterrors.ErrAddressNotFound && m.external != nil {
+		if signedData, pubkey, err = m.external.SignData(addr, data); err == nil {
+			m.notifyLockEvent(entropystore.NewSignedEvent(m.external.endpoint, types.Address{}, addr))
+		}
+		return signedData, pubkey, err
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return m.entropyStoreManager[path].SignData(addr, data)
+}
+
+// SignDataWithPassphrase signs with the key stores first, then the entropy stores, they need not be unlocked
+func (m *Manager) SignDataWithPassphrase(addr types.Address, passphrase string, data []byte) (signedData, pubkey []byte, err error) {
+	if km, ok := m.getKeyStore(addr); ok {
+		return km.SignDataWithPassphrase(passphrase, data)
+	}
+	_, key, _, err := m.GlobalFindAddrWithPassphrase(addr, passphrase)
+	if err != nil {
+		return nil, nil, err
+	}
+	return key.SignData(data)
+}
+
+// ExternalSigner returns nil if the external signer is not configured
+func (m *Manager) ExternalSigner() Signer {
+	if m.external == nil {
+		return nil
+	}
+	return m.external
+}
+
+func (m *Manager) hasExternalAccount(addr types.Address) bool {
+	if m.external == nil {
+		return false
+	}
+	return m.external.HasAccount(addr)
+}
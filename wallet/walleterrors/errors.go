@@ -3,10 +3,11 @@ package walleterrors
 import "errors"
 
 var (
-	ErrLocked          = errors.New("the crypto store is locked")
-	ErrAddressNotFound = errors.New("not found the given address in the crypto store file")
-	ErrInvalidPrikey   = errors.New("invalid prikey")
-	ErrDecryptEntropy  = errors.New("error decrypt store")
-	ErrEmptyStore      = errors.New("error empty store")
-	ErrStoreNotFound   = errors.New("error given store not found ")
+	ErrLocked           = errors.New("the crypto store is locked")
+	ErrAddressNotFound  = errors.New("not found the given address in the crypto store file")
+	ErrInvalidPrikey    = errors.New("invalid prikey")
+	ErrDecryptEntropy   = errors.New("error decrypt store")
+	ErrEmptyStore       = errors.New("error empty store")
+	ErrStoreNotFound    = errors.New("error given store not found ")
+	ErrNotInUnlockScope = errors.New("the index is not in the scope of unlock")
 )